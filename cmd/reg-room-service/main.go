@@ -11,6 +11,7 @@ import (
 var (
 	configFilePath  string
 	migrateDatabase bool
+	applyChanges    bool
 )
 
 func main() {
@@ -36,6 +37,26 @@ func main() {
 					migrateDatabase,
 				)).Run()
 		},
+		Commands: []*cli.Command{
+			{
+				Name:  "reconcile",
+				Usage: "report attendees in groups or rooms who are no longer attending, and optionally remove them",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:        "apply",
+						Usage:       "remove the reported attendees (only if enabled in configuration)",
+						Destination: &applyChanges,
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					return app.New(
+						app.NewParams(
+							configFilePath,
+							migrateDatabase,
+						)).Reconcile(applyChanges, os.Stdout)
+				},
+			},
+		},
 	}
 
 	if err := application.Run(os.Args); err != nil {
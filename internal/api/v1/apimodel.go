@@ -28,6 +28,10 @@ type Group struct {
 	MaximumSize int64 `yaml:"maximum_size" json:"maximum_size"`
 	// the badge number of the group owner. Must be a member of the group. If you are not an admin, you can only create groups with yourself as owner.
 	Owner int64 `yaml:"owner" json:"owner"`
+	// the badge number of the member to whom the owner has offered ownership of the group, if any. READ ONLY, only visible to group members. Please use the transfer subresource API endpoints to transfer ownership.
+	PendingOwner int64 `yaml:"pending_owner,omitempty" json:"pending_owner,omitempty"`
+	// the id of the group the owner has asked to merge this group into, if any. READ ONLY, only visible to group members. Please use the merge subresource API endpoints to merge groups.
+	PendingMerge string `yaml:"pending_merge,omitempty" json:"pending_merge,omitempty"`
 	// the current group members. READ ONLY, provided for ease of use of the API, but completely ignored in all write requests. Please use the relevant subresource API endpoints to manipulate group membership.
 	Members []Member `yaml:"members,omitempty" json:"members,omitempty"`
 	// the current outstanding invites for this group. READ ONLY, provided for ease of use of the API, but completely ignored in all write requests. Please use the relevant subresource API endpoints to send/revoke invites.
@@ -47,8 +51,41 @@ type GroupCreate struct {
 	Owner int64 `yaml:"owner" json:"owner"`
 }
 
+type GroupSplit struct {
+	// the badge numbers of the members who move to the new group. At least one member must stay in the original group, and its owner cannot move.
+	Members []int64 `yaml:"members" json:"members"`
+	// the badge number of the owner of the new group. Must be one of the members who move.
+	Owner int64 `yaml:"owner" json:"owner"`
+}
+
 type GroupList struct {
 	Groups []*Group `yaml:"groups" json:"groups"`
+	// The total number of matching groups, regardless of paging.
+	Total int64 `yaml:"total" json:"total"`
+}
+
+type GroupBan struct {
+	// badge number (id in the attendee service) of the attendee whose invitations and join requests are automatically declined.
+	ID int64 `yaml:"id" json:"id"`
+	// Optional comments regarding the ban. Only visible to admins.
+	Comments *string `yaml:"comments,omitempty" json:"comments,omitempty"`
+}
+
+type GroupBanList struct {
+	Bans []GroupBan `yaml:"bans" json:"bans"`
+}
+
+type GroupWishes struct {
+	// The uuid of the wished room type. Omitted if the group has no preference.
+	Type *string `yaml:"type,omitempty" json:"type,omitempty"`
+	// The wished minimum number of beds. 0 means no preference.
+	Size int64 `yaml:"size,omitempty" json:"size,omitempty"`
+	// The wished room flags as declared in configuration, e.g. a floor.
+	RoomFlags []string `yaml:"room_flags" json:"room_flags"`
+	// The uuids of other groups whose rooms should be in the same block.
+	NearGroups []string `yaml:"near_groups" json:"near_groups"`
+	// Optional comments regarding the wishes. Not processed in any way.
+	Comments *string `yaml:"comments,omitempty" json:"comments,omitempty"`
 }
 
 type Member struct {
@@ -60,12 +97,25 @@ type Member struct {
 	Avatar *string `yaml:"avatar,omitempty" json:"avatar,omitempty"`
 	// A list of membership flags as declared in configuration. Flags are used to store yes/no-style information.
 	Flags []string `yaml:"flags,omitempty" json:"flags,omitempty"`
+	// The ISO date of the first night the occupant stays in the room. Only for room occupants, omitted if they arrive at the start of the convention.
+	Arrival *string `yaml:"arrival,omitempty" json:"arrival,omitempty"`
+	// The ISO date the occupant leaves the room. Only for room occupants, omitted if they leave at the end of the convention.
+	Departure *string `yaml:"departure,omitempty" json:"departure,omitempty"`
+	// The time the occupant checked in at the hotel, formatted as ISO datetime. Only for room occupants.
+	CheckedIn *string `yaml:"checked_in,omitempty" json:"checked_in,omitempty"`
+	// The time the occupant checked out of the hotel, formatted as ISO datetime. Only for room occupants.
+	CheckedOut *string `yaml:"checked_out,omitempty" json:"checked_out,omitempty"`
+}
+
+type MemberUpdate struct {
+	// The complete list of membership flags as declared in configuration. Replaces the current flags.
+	Flags []string `yaml:"flags" json:"flags"`
 }
 
 type Room struct {
 	// The internal primary key of the room, in the form of a UUID. Only set when reading rooms, completely ignored when you send a room to us.
 	ID string `yaml:"id" json:"id"`
-	// The name of the room, must be unique, but otherwise just used for display purposes
+	// The name of the room, must be unique within its block, but otherwise just used for display purposes
 	Name string `yaml:"name" json:"name"`
 	// A list of flags as declared in configuration. Flags are used to store yes/no-style information about the room.
 	Flags []string `yaml:"flags" json:"flags"`
@@ -73,12 +123,36 @@ type Room struct {
 	Comments *string `yaml:"comments,omitempty" json:"comments,omitempty"`
 	// the maximum room size, usually the number of sleeping spots/beds in the room.
 	Size int64 `yaml:"size" json:"size"`
+	// Optional id of the block (hotel or room contingent) this room belongs to.
+	Block *string `yaml:"block,omitempty" json:"block,omitempty"`
+	// the name of the block this room belongs to. READ ONLY, completely ignored in all write requests.
+	BlockName *string `yaml:"block_name,omitempty" json:"block_name,omitempty"`
+	// Optional id of the room type. Size, flags and comments that are left out when writing the room are taken from the room type.
+	Type *string `yaml:"type,omitempty" json:"type,omitempty"`
+	// the name of the room type. READ ONLY, completely ignored in all write requests.
+	TypeName *string `yaml:"type_name,omitempty" json:"type_name,omitempty"`
 	// the assigned room occupants. READ ONLY, provided for ease of use of the API, but completely ignored in all write requests. Please use the relevant subresource API endpoints to manipulate assignments.
 	Occupants []Member `yaml:"occupants,omitempty" json:"occupants,omitempty"`
+	// the number of occupants staying in the room for each night of the convention. READ ONLY, only present if the convention dates are configured.
+	Occupancy []NightOccupancy `yaml:"occupancy,omitempty" json:"occupancy,omitempty"`
+}
+
+type NightOccupancy struct {
+	// The ISO date of the night.
+	Night string `yaml:"night" json:"night"`
+	// The number of occupants staying in the room that night.
+	Occupied int `yaml:"occupied" json:"occupied"`
+}
+
+type Stay struct {
+	// The ISO date of the first night in the room. Omit or leave empty to arrive at the start of the convention.
+	Arrival string `yaml:"arrival,omitempty" json:"arrival,omitempty"`
+	// The ISO date of the day the room is vacated. Omit or leave empty to leave at the end of the convention.
+	Departure string `yaml:"departure,omitempty" json:"departure,omitempty"`
 }
 
 type RoomCreate struct {
-	// The name of the room, must be unique, but otherwise just used for display purposes
+	// The name of the room, must be unique within its block, but otherwise just used for display purposes
 	Name string `yaml:"name" json:"name"`
 	// A list of flags as declared in configuration. Flags are used to store yes/no-style information about the room.
 	Flags []string `yaml:"flags" json:"flags"`
@@ -86,14 +160,302 @@ type RoomCreate struct {
 	Comments *string `yaml:"comments,omitempty" json:"comments,omitempty"`
 	// the room size, usually the number of sleeping spots/beds in the room.
 	Size int64 `yaml:"size" json:"size"`
+	// Optional id of the block (hotel or room contingent) this room belongs to.
+	Block *string `yaml:"block,omitempty" json:"block,omitempty"`
+	// Optional id of the room type. Size, flags and comments that are left out are taken from the room type.
+	Type *string `yaml:"type,omitempty" json:"type,omitempty"`
 }
 
 type RoomList struct {
 	Rooms []*Room `yaml:"rooms" json:"rooms"`
+	// The total number of matching rooms, regardless of paging. Not included in the export.
+	Total int64 `yaml:"total,omitempty" json:"total"`
+}
+
+type RoomImportResult struct {
+	// Whether this was a dry run. If true, nothing was changed.
+	DryRun bool `yaml:"dry_run" json:"dry_run"`
+	// The number of rooms contained in the import.
+	Rows int `yaml:"rows" json:"rows"`
+	// The uuids of the created rooms, in the order of the import. Always empty for a dry run.
+	Created []string `yaml:"created" json:"created"`
+	// The rows that failed validation. Only set for a dry run, because a real import fails completely if any row is invalid.
+	Errors []RoomImportError `yaml:"errors,omitempty" json:"errors,omitempty"`
+}
+
+type RoomImportError struct {
+	// The row number. For CSV, this is the line in the file (the header is line 1). For YAML, this is the position in the list of rooms, starting with 1.
+	Row int `yaml:"row" json:"row"`
+	// The name of the room in this row, if any.
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// The validation errors by field name.
+	Details map[string][]string `yaml:"details" json:"details"`
+}
+
+type Block struct {
+	// The internal primary key of the block, in the form of a UUID. Only set when reading blocks, completely ignored when you send a block to us.
+	ID string `yaml:"id" json:"id"`
+	// The name of the block, such as the name of the hotel. Must be unique.
+	Name string `yaml:"name" json:"name"`
+	// Optional comment. Not processed in any way.
+	Comments *string `yaml:"comments,omitempty" json:"comments,omitempty"`
+	// The time at which the public booking code is revealed, in ISO format such as 2024-01-20T19:00:00+01:00.
+	PublicStart string `yaml:"public_start" json:"public_start"`
+	// The secret code word attendees need to give the hotel to book a room in this block.
+	PublicBookingCode string `yaml:"public_booking_code" json:"public_booking_code"`
+	// Optional time at which staff can see the staff booking code. Leave unset if staff get no early access.
+	StaffStart *string `yaml:"staff_start,omitempty" json:"staff_start,omitempty"`
+	// Optional code word that allows staff to book rooms in this block early.
+	StaffBookingCode *string `yaml:"staff_booking_code,omitempty" json:"staff_booking_code,omitempty"`
+}
+
+type BlockCreate struct {
+	// The name of the block, such as the name of the hotel. Must be unique.
+	Name string `yaml:"name" json:"name"`
+	// Optional comment. Not processed in any way.
+	Comments *string `yaml:"comments,omitempty" json:"comments,omitempty"`
+	// The time at which the public booking code is revealed, in ISO format such as 2024-01-20T19:00:00+01:00.
+	PublicStart string `yaml:"public_start" json:"public_start"`
+	// The secret code word attendees need to give the hotel to book a room in this block.
+	PublicBookingCode string `yaml:"public_booking_code" json:"public_booking_code"`
+	// Optional time at which staff can see the staff booking code. Leave unset if staff get no early access.
+	StaffStart *string `yaml:"staff_start,omitempty" json:"staff_start,omitempty"`
+	// Optional code word that allows staff to book rooms in this block early.
+	StaffBookingCode *string `yaml:"staff_booking_code,omitempty" json:"staff_booking_code,omitempty"`
+}
+
+type BlockList struct {
+	Blocks []*Block `yaml:"blocks" json:"blocks"`
+}
+
+type RoomType struct {
+	// The internal primary key of the room type, in the form of a UUID. Only set when reading room types, completely ignored when you send a room type to us.
+	ID string `yaml:"id" json:"id"`
+	// The name of the room type, such as "double standard". Must be unique.
+	Name string `yaml:"name" json:"name"`
+	// The default flags for rooms of this type.
+	Flags []string `yaml:"flags" json:"flags"`
+	// Optional default comment for rooms of this type.
+	Comments *string `yaml:"comments,omitempty" json:"comments,omitempty"`
+	// The default size for rooms of this type.
+	Size int64 `yaml:"size" json:"size"`
+	// Optional price category. Not processed in any way.
+	PriceCategory *string `yaml:"price_category,omitempty" json:"price_category,omitempty"`
+}
+
+type RoomTypeCreate struct {
+	// The name of the room type, such as "double standard". Must be unique.
+	Name string `yaml:"name" json:"name"`
+	// The default flags for rooms of this type.
+	Flags []string `yaml:"flags" json:"flags"`
+	// Optional default comment for rooms of this type.
+	Comments *string `yaml:"comments,omitempty" json:"comments,omitempty"`
+	// The default size for rooms of this type.
+	Size int64 `yaml:"size" json:"size"`
+	// Optional price category. Not processed in any way.
+	PriceCategory *string `yaml:"price_category,omitempty" json:"price_category,omitempty"`
+}
+
+type RoomTypeList struct {
+	RoomTypes []*RoomType `yaml:"room_types" json:"room_types"`
+}
+
+type CapacityReport struct {
+	// One entry per room type, sorted by name, followed by an entry for the rooms without a room type, if there are any.
+	Types []TypeCapacity `yaml:"types" json:"types"`
+}
+
+type TypeCapacity struct {
+	// The uuid of the room type. Empty for the entry that counts rooms without a room type.
+	Type string `yaml:"type" json:"type"`
+	// The name of the room type. Empty for the entry that counts rooms without a room type.
+	TypeName string `yaml:"type_name" json:"type_name"`
+	// The price category of the room type, if any.
+	PriceCategory string `yaml:"price_category,omitempty" json:"price_category,omitempty"`
+	// The number of rooms of this type.
+	Rooms int `yaml:"rooms" json:"rooms"`
+	// The total number of beds in rooms of this type, that is, the sum of their sizes.
+	Beds int64 `yaml:"beds" json:"beds"`
+	// The number of occupied beds. If the convention dates are configured, each room counts with its busiest night.
+	Occupied int64 `yaml:"occupied" json:"occupied"`
+	// The number of free beds.
+	Free int64 `yaml:"free" json:"free"`
+}
+
+type HistoryEntry struct {
+	// The time at which the change was made, formatted as ISO datetime.
+	Timestamp string `yaml:"timestamp" json:"timestamp"`
+	// The type of the changed entity, one of Group, GroupMember, GroupBan, GroupWish, Room, RoomMember.
+	Entity string `yaml:"entity" json:"entity"`
+	// The primary key of the changed entity. For groups and rooms, this is their uuid, for group and room memberships it is the badge number.
+	EntityID string `yaml:"entity_id" json:"entity_id"`
+	// The kind of change, e.g. add, update, delete.
+	Operation string `yaml:"operation" json:"operation"`
+	// The request id of the request that made the change, if available. Can be used to find the associated logs.
+	Requestid string `yaml:"requestid,omitempty" json:"requestid,omitempty"`
+	// The subject of the identity that made the change.
+	Identity string `yaml:"identity" json:"identity"`
+	// The changed fields. For additions, the initial values are listed. For updates and deletions, the values before the change are listed.
+	Diff []HistoryDiff `yaml:"diff" json:"diff"`
+}
+
+type HistoryDiff struct {
+	// The name of the changed field.
+	Field string `yaml:"field" json:"field"`
+	// The value of the field before the change (initial value for additions).
+	Value string `yaml:"value" json:"value"`
+}
+
+type HistoryList struct {
+	Entries []*HistoryEntry `yaml:"entries" json:"entries"`
+	// The total number of history entries, regardless of paging.
+	Total int64 `yaml:"total" json:"total"`
+}
+
+type AssignmentPlan struct {
+	// The proposed room assignments. When applying a plan, only this field is used.
+	Assignments []Assignment `yaml:"assignments" json:"assignments"`
+	// The groups that could not be placed in any room. READ ONLY, ignored when applying a plan.
+	Unplaced []UnplacedGroup `yaml:"unplaced,omitempty" json:"unplaced,omitempty"`
+}
+
+type Assignment struct {
+	// The uuid of the group that is placed. When applying a plan, the attendees must still be members of this group.
+	GroupID string `yaml:"group_id" json:"group_id"`
+	// The name of the group that is placed. Informational, ignored when applying a plan.
+	GroupName string `yaml:"group_name,omitempty" json:"group_name,omitempty"`
+	// The uuid of the room the attendees are placed in.
+	RoomID string `yaml:"room_id" json:"room_id"`
+	// The name of the room the attendees are placed in. Informational, ignored when applying a plan.
+	RoomName string `yaml:"room_name,omitempty" json:"room_name,omitempty"`
+	// The badge numbers of the attendees to add to the room.
+	BadgeNumbers []int64 `yaml:"badge_numbers" json:"badge_numbers"`
+}
+
+type UnplacedGroup struct {
+	// The uuid of the group that could not be placed.
+	GroupID string `yaml:"group_id" json:"group_id"`
+	// The name of the group that could not be placed.
+	GroupName string `yaml:"group_name" json:"group_name"`
+	// The badge numbers of the group members that are not yet in a room.
+	BadgeNumbers []int64 `yaml:"badge_numbers" json:"badge_numbers"`
+	// Why the group could not be placed, in English.
+	Reason string `yaml:"reason" json:"reason"`
+}
+
+type ReconciliationReport struct {
+	// Whether the attendees listed in the report have been removed from their groups and rooms. False for a report only, or if removal is disabled in configuration.
+	Applied bool `yaml:"applied" json:"applied"`
+	// The number of attendees in groups or rooms (including invitations) whose registration status was checked.
+	Checked int `yaml:"checked" json:"checked"`
+	// The attendees in groups or rooms who are no longer attending, sorted by badge number.
+	NotAttending []ReconciliationEntry `yaml:"not_attending" json:"not_attending"`
+}
+
+type ReconciliationEntry struct {
+	// The badge number of the attendee.
+	ID int64 `yaml:"id" json:"id"`
+	// The nickname of the attendee, as cached in this service.
+	Nickname string `yaml:"nickname" json:"nickname"`
+	// The registration status of the attendee, e.g. cancelled or deleted.
+	Status string `yaml:"status" json:"status"`
+	// The uuid of the group the attendee is in or invited to, if any.
+	GroupID string `yaml:"group_id,omitempty" json:"group_id,omitempty"`
+	// The name of the group the attendee is in or invited to, if any.
+	GroupName string `yaml:"group_name,omitempty" json:"group_name,omitempty"`
+	// True if the attendee is only invited to the group, or has asked to join it.
+	Invited bool `yaml:"invited,omitempty" json:"invited,omitempty"`
+	// True if the attendee owns the group. On removal, ownership passes to another attending member, or if there is none, the group is disbanded.
+	GroupOwner bool `yaml:"group_owner,omitempty" json:"group_owner,omitempty"`
+	// The uuid of the room the attendee is in, if any.
+	RoomID string `yaml:"room_id,omitempty" json:"room_id,omitempty"`
+	// The name of the room the attendee is in, if any.
+	RoomName string `yaml:"room_name,omitempty" json:"room_name,omitempty"`
+	// Whether the attendee has been removed from their group and room.
+	Removed bool `yaml:"removed" json:"removed"`
+}
+
+// AttendeeStatusEvent is sent by the attendee service when the status or nickname of a registration changes.
+type AttendeeStatusEvent struct {
+	// A unique id for this event, assigned by the sender. Events with an id that has already been processed are ignored.
+	EventID string `yaml:"event_id" json:"event_id"`
+	// The badge number of the attendee.
+	ID int64 `yaml:"id" json:"id"`
+	// The previous registration status. Informational only.
+	OldStatus string `yaml:"old_status,omitempty" json:"old_status,omitempty"`
+	// The new registration status.
+	NewStatus string `yaml:"new_status" json:"new_status"`
+	// The new nickname of the attendee, if it has changed.
+	Nickname string `yaml:"nickname,omitempty" json:"nickname,omitempty"`
+}
+
+type FlagMismatchList struct {
+	// The room occupants whose group requires room flags that their room does not have, sorted by room name and badge number.
+	Mismatches []FlagMismatch `yaml:"mismatches" json:"mismatches"`
+}
+
+type FlagMismatch struct {
+	// The uuid of the room.
+	RoomID string `yaml:"room_id" json:"room_id"`
+	// The name of the room.
+	RoomName string `yaml:"room_name" json:"room_name"`
+	// The badge number of the occupant.
+	ID int64 `yaml:"id" json:"id"`
+	// The nickname of the occupant.
+	Nickname string `yaml:"nickname" json:"nickname"`
+	// The uuid of the group the occupant is a member of.
+	GroupID string `yaml:"group_id" json:"group_id"`
+	// The name of the group the occupant is a member of.
+	GroupName string `yaml:"group_name" json:"group_name"`
+	// The room flags required by the group's flags, which the room does not have.
+	MissingFlags []string `yaml:"missing_flags" json:"missing_flags"`
+}
+
+type UnmetWishList struct {
+	// The groups whose rooms do not meet their room wishes, sorted by group name.
+	Groups []UnmetWish `yaml:"groups" json:"groups"`
+}
+
+type UnmetWish struct {
+	// The uuid of the group.
+	GroupID string `yaml:"group_id" json:"group_id"`
+	// The name of the group.
+	GroupName string `yaml:"group_name" json:"group_name"`
+	// The names of the rooms the group's members are in, sorted.
+	Rooms []string `yaml:"rooms" json:"rooms"`
+	// The wishes that are not met, a subset of type, size, room_flags, near_groups.
+	Unmet []string `yaml:"unmet" json:"unmet"`
+}
+
+type ArrivalReport struct {
+	// One entry per day on which arrivals were expected or occupants checked in, sorted by date.
+	Days []ArrivalDay `yaml:"days" json:"days"`
+}
+
+type ArrivalDay struct {
+	// The day, formatted as ISO date.
+	Date string `yaml:"date" json:"date"`
+	// The number of room occupants expected to arrive on this day.
+	Expected int `yaml:"expected" json:"expected"`
+	// The number of room occupants who checked in on this day.
+	Arrived int `yaml:"arrived" json:"arrived"`
+	// The badge numbers of the occupants expected on this day who have not checked in yet.
+	NotArrived []int64 `yaml:"not_arrived" json:"not_arrived"`
+}
+
+type MemberRefreshResult struct {
+	// The number of attendees in groups or rooms (including invitations) whose data was read from the attendee service.
+	Checked int `yaml:"checked" json:"checked"`
+	// The number of attendees whose cached nickname or avatar was out of date and has been updated.
+	Updated int `yaml:"updated" json:"updated"`
 }
 
 // Countdown contains information about the time until the secret is revealed, which is needed for the registration.
 type Countdown struct {
+	// Block is the id of the block this countdown is for, empty for the deprecated go_live configuration.
+	Block string `json:"block"`
+	// BlockName is the name of the block this countdown is for.
+	BlockName string `json:"blockName"`
 	// CurrentTimeIsoDateTime is the current time on the server.
 	CurrentTimeIsoDateTime string `json:"currentTime"`
 	// TargetTimeIsoDateTime is the time at which the countdown ends (may depend on authorization, e.g. staff may register earlier than normal users).
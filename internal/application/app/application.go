@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"encoding/json"
 	aulogging "github.com/StephanHCB/go-autumn-logging"
 	auzerolog "github.com/StephanHCB/go-autumn-logging-zerolog"
 	"github.com/eurofurence/reg-room-service/internal/application/common"
@@ -11,9 +12,16 @@ import (
 	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/attendeeservice"
 	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/authservice"
 	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/mailservice"
+	assignmentservice "github.com/eurofurence/reg-room-service/internal/service/assignment"
+	blockservice "github.com/eurofurence/reg-room-service/internal/service/blocks"
 	groupservice "github.com/eurofurence/reg-room-service/internal/service/groups"
+	historyservice "github.com/eurofurence/reg-room-service/internal/service/history"
+	reconcileservice "github.com/eurofurence/reg-room-service/internal/service/reconcile"
 	roomservice "github.com/eurofurence/reg-room-service/internal/service/rooms"
+	roomtypeservice "github.com/eurofurence/reg-room-service/internal/service/roomtypes"
 	"github.com/rs/zerolog"
+	"io"
+	"time"
 )
 
 type Params struct {
@@ -38,7 +46,74 @@ func New(params Params) *Application {
 	}
 }
 
+// services holds the service instances, which are used by both the server and the command line operations.
+type services struct {
+	group      groupservice.Service
+	room       roomservice.Service
+	history    historyservice.Service
+	assignment assignmentservice.Service
+	reconcile  reconcileservice.Service
+	block      blockservice.Service
+	roomType   roomtypeservice.Service
+}
+
 func (a *Application) Run() error {
+	ctx, conf, svc, err := a.setup()
+	if err != nil {
+		return err
+	}
+
+	// background jobs
+
+	jobCtx, stopJobs := context.WithCancel(ctx)
+	defer stopJobs()
+
+	if conf.Service.InvitationLifetimeHours > 0 {
+		interval := time.Duration(conf.Service.InvitationSweepIntervalMinutes) * time.Minute
+		startPeriodicJob(jobCtx, "invitation-sweeper", interval, countingJob(svc.group.SweepExpiredInvitations, "expired invitations deleted"))
+	}
+
+	ownerCheckInterval := time.Duration(conf.Service.OwnerCheckIntervalMinutes) * time.Minute
+	startPeriodicJob(jobCtx, "owner-check", ownerCheckInterval, countingJob(svc.group.ReassignCancelledOwners, "groups passed to a new owner"))
+
+	memberRefreshInterval := time.Duration(conf.Service.MemberRefreshIntervalMinutes) * time.Minute
+	startPeriodicJob(jobCtx, "member-refresh", memberRefreshInterval, countingJob(svc.reconcile.RunMemberRefresh, "members refreshed from the attendee service"))
+
+	// controllers wired in server because no instances, just routes
+
+	srv := server.New(conf, context.Background(), svc.group, svc.room, svc.history, svc.assignment, svc.reconcile, svc.block, svc.roomType)
+	err = srv.Serve()
+	if err != nil {
+		aulogging.ErrorErrf(ctx, err, "failure during serve phase - shutting down: %s", err.Error())
+		return err
+	}
+
+	aulogging.Info(ctx, "done serving web requests")
+	return nil
+}
+
+// Reconcile runs a single reconciliation from the command line, and writes the report to out as json.
+//
+// Unless apply is set, this is a dry run that only reports the attendees who are no longer attending.
+func (a *Application) Reconcile(apply bool, out io.Writer) error {
+	ctx, _, svc, err := a.setup()
+	if err != nil {
+		return err
+	}
+
+	report, err := svc.reconcile.RunReconciliation(jobContext(ctx, "reconcile"), apply)
+	if err != nil {
+		aulogging.ErrorErrf(ctx, err, "reconciliation failed: %s", err.Error())
+		return err
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// setup loads the configuration, sets up logging, and connects to the database and downstream services.
+func (a *Application) setup() (context.Context, *config.Config, *services, error) {
 	// config and logging
 
 	conf, err := config.UnmarshalFromYamlConfiguration(a.Params.configFilePath)
@@ -46,7 +121,7 @@ func (a *Application) Run() error {
 	ctx := auzerolog.AddLoggerToCtx(context.Background())
 	if err != nil {
 		aulogging.ErrorErrf(ctx, err, "failed to load configuration - bailing out: %s", err.Error())
-		return err
+		return ctx, nil, nil, err
 	}
 	aulogging.Info(ctx, "configuration file successfully loaded")
 
@@ -58,7 +133,7 @@ func (a *Application) Run() error {
 	err = conf.Validate()
 	if err != nil {
 		aulogging.ErrorErrf(ctx, err, "failed to validate configuration - bailing out: %s", err.Error())
-		return err
+		return ctx, nil, nil, err
 	}
 
 	// repos
@@ -66,33 +141,33 @@ func (a *Application) Run() error {
 	connectString := dbrepo.MysqlConnectString(conf.Database.Username, conf.Database.Password, conf.Database.Database, conf.Database.Parameters)
 	if err := dbrepo.Open(ctx, string(conf.Database.Use), connectString); err != nil {
 		aulogging.ErrorErrf(ctx, err, "failed to set up database connection - bailing out: %s", err.Error())
-		return err
+		return ctx, nil, nil, err
 	}
 
 	if a.Params.migrateDB {
 		err := dbrepo.Migrate(ctx)
 		if err != nil {
 			aulogging.ErrorErrf(ctx, err, "failed to migrate database - bailing out: %s", err.Error())
-			return err
+			return ctx, nil, nil, err
 		}
 	}
 
 	attRepo, err := attendeeservice.New(conf.Service.AttendeeServiceURL)
 	if err != nil {
 		aulogging.ErrorErrf(ctx, err, "failed to set up attendee service client - bailing out: %s", err.Error())
-		return err
+		return ctx, nil, nil, err
 	}
 
 	_, err = authservice.New(conf.Service.AuthServiceURL, conf.Security)
 	if err != nil {
 		aulogging.ErrorErrf(ctx, err, "failed to set up auth service client - bailing out: %s", err.Error())
-		return err
+		return ctx, nil, nil, err
 	}
 
 	mailRepo, err := mailservice.New(conf.Service.MailServiceURL, conf.Security.Fixed.API)
 	if err != nil {
 		aulogging.ErrorErrf(ctx, err, "failed to set up mail service client - bailing out: %s", err.Error())
-		return err
+		return ctx, nil, nil, err
 	}
 
 	dbRepo := dbrepo.GetRepository()
@@ -102,17 +177,15 @@ func (a *Application) Run() error {
 	groupSvc := groupservice.New(dbRepo, attRepo, mailRepo)
 	roomSvc := roomservice.New(dbRepo, attRepo, mailRepo)
 
-	// controllers wired in server because no instances, just routes
-
-	srv := server.New(conf, context.Background(), groupSvc, roomSvc)
-	err = srv.Serve()
-	if err != nil {
-		aulogging.ErrorErrf(ctx, err, "failure during serve phase - shutting down: %s", err.Error())
-		return err
-	}
-
-	aulogging.Info(ctx, "done serving web requests")
-	return nil
+	return ctx, conf, &services{
+		group:      groupSvc,
+		room:       roomSvc,
+		history:    historyservice.New(dbRepo),
+		assignment: assignmentservice.New(dbRepo, attRepo),
+		reconcile:  reconcileservice.New(dbRepo, attRepo, groupSvc, roomSvc),
+		block:      blockservice.New(dbRepo),
+		roomType:   roomtypeservice.New(dbRepo),
+	}, nil
 }
 
 const applicationName = "room-service"
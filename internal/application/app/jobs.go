@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"time"
+)
+
+// startPeriodicJob runs job in the background every interval, until ctx is cancelled.
+func startPeriodicJob(ctx context.Context, name string, interval time.Duration, job func(ctx context.Context) error) {
+	aulogging.Infof(ctx, "starting background job %s, runs every %s", name, interval)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				aulogging.Infof(ctx, "stopped background job %s", name)
+				return
+			case <-ticker.C:
+				runJob(ctx, name, job)
+			}
+		}
+	}()
+}
+
+// runJob runs a single execution of a job.
+//
+// Each run gets its own request id for logging, and the job name as subject, so changes made by the job
+// are historized with an identity that shows where they came from.
+func runJob(ctx context.Context, name string, job func(ctx context.Context) error) {
+	jobCtx := jobContext(ctx, name)
+
+	defer func() {
+		if r := recover(); r != nil {
+			aulogging.Errorf(jobCtx, "background job %s panicked: %v", name, r)
+		}
+	}()
+
+	if err := job(jobCtx); err != nil {
+		aulogging.WarnErrf(jobCtx, err, "background job %s failed: %s", name, err.Error())
+	}
+}
+
+// jobContext gives a job run its own request id for logging, and the job name as subject.
+func jobContext(ctx context.Context, name string) context.Context {
+	requestID := "ffffffff"
+	if reqUuid, err := uuid.NewRandom(); err == nil {
+		requestID = reqUuid.String()[:8]
+	}
+	jobCtx := context.WithValue(ctx, common.CtxKeyRequestID{}, requestID)
+	return context.WithValue(jobCtx, common.CtxKeyClaims{}, &common.AllClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "job:" + name,
+		},
+	})
+}
+
+// countingJob adapts a service operation that returns how many entries it changed to the job signature.
+//
+// what describes the changed entries for logging, e.g. "expired invitations deleted".
+func countingJob(run func(ctx context.Context) (int, error), what string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		count, err := run(ctx)
+		if err != nil {
+			return fmt.Errorf("%d %s before error: %w", count, what, err)
+		}
+		if count > 0 {
+			aulogging.Infof(ctx, "%d %s", count, what)
+		}
+		return nil
+	}
+}
@@ -26,35 +26,53 @@ type ErrorMessageCode string
 // Note: the OpenAPI Spec is the leading document for error codes. This should directly follow the list and explanations
 // in the Error schema.
 const (
-	DownstreamAttSrv ErrorMessageCode = "attendee.validation.error"     // attendee service downstream failure
-	NoSuchAttendee   ErrorMessageCode = "attendee.notfound"             // no such attendee, probably invalid badge number or your user has no registration
-	NotAttending     ErrorMessageCode = "attendee.status.not.attending" // attendee has a registration, but it is not in a status that allows being in a room, e.g. cancelled, waiting list
+	AttendeeEventInvalid ErrorMessageCode = "attendee.event.invalid"        // invalid attendee status change event, e.g. missing event id
+	DownstreamAttSrv     ErrorMessageCode = "attendee.validation.error"     // attendee service downstream failure
+	NoSuchAttendee       ErrorMessageCode = "attendee.notfound"             // no such attendee, probably invalid badge number or your user has no registration
+	NotAttending         ErrorMessageCode = "attendee.status.not.attending" // attendee has a registration, but it is not in a status that allows being in a room, e.g. cancelled, waiting list
+
+	AssignmentDataInvalid ErrorMessageCode = "assignment.data.invalid" // invalid room assignment plan, e.g. an attendee is listed twice
 
 	AuthForbidden    ErrorMessageCode = "auth.forbidden"    // permissions missing or not a registered attendee
 	AuthUnauthorized ErrorMessageCode = "auth.unauthorized" // token missing completely or invalid or expired
 
+	BlockDataDuplicate ErrorMessageCode = "block.data.duplicate" // block with same name already exists, cannot create or rename
+	BlockDataInvalid   ErrorMessageCode = "block.data.invalid"   // invalid field contents
+	BlockIDInvalid     ErrorMessageCode = "block.id.invalid"     // invalid uuid id format
+	BlockIDNotFound    ErrorMessageCode = "block.id.notfound"    // no such block
+	BlockNotEmpty      ErrorMessageCode = "block.not.empty"      // cannot delete a block that still has rooms
+	BlockReadError     ErrorMessageCode = "block.read.error"     // database error
+	BlockWriteError    ErrorMessageCode = "block.write.error"    // database error
+
 	GroupBanDuplicate      ErrorMessageCode = "group.ban.duplicate"       // an auto-decline entry with this badge number already exists - cannot add again
 	GroupBanNotFound       ErrorMessageCode = "group.ban.notfound"        // an auto-decline entry with this badge number did not exist - removal failed
 	GroupDataDuplicate     ErrorMessageCode = "group.data.duplicate"      // group with same name already exists, cannot create or rename
 	GroupDataInvalid       ErrorMessageCode = "group.data.invalid"        // invalid field contents
 	GroupIDInvalid         ErrorMessageCode = "group.id.invalid"          // invalid uuid id format
 	GroupIDNotFound        ErrorMessageCode = "group.id.notfound"         // no such group id
+	GroupInviteExpired     ErrorMessageCode = "group.invite.expired"      // the invitation has expired - the group owner needs to resend it
 	GroupInviteMismatch    ErrorMessageCode = "group.invite.mismatch"     // nickname or invitation code did not match - invite not sent or confirmed
 	GroupMailError         ErrorMessageCode = "group.mail.error"          // mail service reported error when sending notification email - usually the operation will still have proceeded
 	GroupMemberConflict    ErrorMessageCode = "group.member.conflict"     // attendee is already in or has been invited to another group
 	GroupMemberDuplicate   ErrorMessageCode = "group.member.duplicate"    // attendee is already in or invited to this group
 	GroupMemberNotFound    ErrorMessageCode = "group.member.notfound"     // attendee is not in or invited to this group
+	GroupMergeNotFound     ErrorMessageCode = "group.merge.notfound"      // there is no pending request to merge this group into the other group
 	GroupOwnerNotInGroup   ErrorMessageCode = "group.owner.notingroup"    // requested owner is not part of this group
 	GroupOwnerCannotRemove ErrorMessageCode = "group.owner.cannot.remove" // this attendee is currently the owner of the group. Either change the owner first, or disband the group completely
 	GroupReadError         ErrorMessageCode = "group.read.error"          // database error
 	GroupSizeFull          ErrorMessageCode = "group.size.full"           // group has reached its maximum size
+	GroupTransferNotFound  ErrorMessageCode = "group.transfer.notfound"   // there is no pending ownership transfer to this attendee
 	GroupWriteError        ErrorMessageCode = "group.write.error"         // database error
 
+	HistoryReadError ErrorMessageCode = "history.read.error" // database error
+
 	InternalErrorMessage ErrorMessageCode = "http.error.internal"  // Internal error
 	RequestParseFailed   ErrorMessageCode = "request.parse.failed" // Request could not be parsed properly
 
+	RoomCheckinConflict   ErrorMessageCode = "room.checkin.conflict"   // attendee has already checked in, or has not checked in and cannot check out
 	RoomDataDuplicate     ErrorMessageCode = "room.data.duplicate"     // room with same name already exists, cannot create or rename
 	RoomDataInvalid       ErrorMessageCode = "room.data.invalid"       // invalid field contents
+	RoomFlagsMismatch     ErrorMessageCode = "room.flags.mismatch"     // the attendee's group requires room flags that this room does not have
 	RoomIDInvalid         ErrorMessageCode = "room.id.invalid"         // invalid uuid id format
 	RoomIDNotFound        ErrorMessageCode = "room.id.notfound"        // no such room
 	RoomOccupantConflict  ErrorMessageCode = "room.occupant.conflict"  // attendee is already in another room
@@ -65,6 +83,14 @@ const (
 	RoomSizeFull          ErrorMessageCode = "room.size.full"          // not enough space in room to add another member
 	RoomSizeTooSmall      ErrorMessageCode = "room.size.too.small"     // too many occupants in room to allow reducing size
 	RoomWriteError        ErrorMessageCode = "room.write.error"        // database error
+
+	RoomTypeDataDuplicate ErrorMessageCode = "roomtype.data.duplicate" // room type with same name already exists, cannot create or rename
+	RoomTypeDataInvalid   ErrorMessageCode = "roomtype.data.invalid"   // invalid field contents
+	RoomTypeIDInvalid     ErrorMessageCode = "roomtype.id.invalid"     // invalid uuid id format
+	RoomTypeIDNotFound    ErrorMessageCode = "roomtype.id.notfound"    // no such room type
+	RoomTypeInUse         ErrorMessageCode = "roomtype.in.use"         // cannot delete a room type that is still referenced by rooms
+	RoomTypeReadError     ErrorMessageCode = "roomtype.read.error"     // database error
+	RoomTypeWriteError    ErrorMessageCode = "roomtype.write.error"    // database error
 )
 
 // construct specific API errors
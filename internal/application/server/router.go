@@ -3,18 +3,28 @@ package server
 import (
 	"github.com/StephanHCB/go-autumn-logging-zerolog/loggermiddleware"
 	"github.com/eurofurence/reg-room-service/internal/application/middleware"
+	"github.com/eurofurence/reg-room-service/internal/controller/v1/assignmentctl"
+	"github.com/eurofurence/reg-room-service/internal/controller/v1/blocksctl"
 	"github.com/eurofurence/reg-room-service/internal/controller/v1/countdownctl"
 	"github.com/eurofurence/reg-room-service/internal/controller/v1/groupsctl"
 	"github.com/eurofurence/reg-room-service/internal/controller/v1/healthctl"
+	"github.com/eurofurence/reg-room-service/internal/controller/v1/historyctl"
+	"github.com/eurofurence/reg-room-service/internal/controller/v1/reconcilectl"
 	"github.com/eurofurence/reg-room-service/internal/controller/v1/roomsctl"
+	"github.com/eurofurence/reg-room-service/internal/controller/v1/roomtypesctl"
 	"github.com/eurofurence/reg-room-service/internal/repository/config"
+	assignmentservice "github.com/eurofurence/reg-room-service/internal/service/assignment"
+	blockservice "github.com/eurofurence/reg-room-service/internal/service/blocks"
 	groupservice "github.com/eurofurence/reg-room-service/internal/service/groups"
+	historyservice "github.com/eurofurence/reg-room-service/internal/service/history"
+	reconcileservice "github.com/eurofurence/reg-room-service/internal/service/reconcile"
 	roomservice "github.com/eurofurence/reg-room-service/internal/service/rooms"
+	roomtypeservice "github.com/eurofurence/reg-room-service/internal/service/roomtypes"
 	"github.com/go-chi/chi/v5"
 	"net/http"
 )
 
-func Router(groupsvc groupservice.Service, roomsvc roomservice.Service) http.Handler {
+func Router(groupsvc groupservice.Service, roomsvc roomservice.Service, historysvc historyservice.Service, assignmentsvc assignmentservice.Service, reconcilesvc reconcileservice.Service, blocksvc blockservice.Service, roomtypesvc roomtypeservice.Service) http.Handler {
 	router := chi.NewMux()
 
 	conf, err := config.GetApplicationConfig()
@@ -31,7 +41,12 @@ func Router(groupsvc groupservice.Service, roomsvc roomservice.Service) http.Han
 
 	groupsctl.InitRoutes(router, groupsvc)
 	roomsctl.InitRoutes(router, roomsvc)
-	countdownctl.InitRoutes(router)
+	historyctl.InitRoutes(router, historysvc)
+	assignmentctl.InitRoutes(router, assignmentsvc)
+	reconcilectl.InitRoutes(router, reconcilesvc)
+	blocksctl.InitRoutes(router, blocksvc)
+	roomtypesctl.InitRoutes(router, roomtypesvc)
+	countdownctl.InitRoutes(router, blocksvc)
 	healthctl.InitRoutes(router)
 
 	return router
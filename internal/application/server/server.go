@@ -5,8 +5,13 @@ import (
 	"fmt"
 	aulogging "github.com/StephanHCB/go-autumn-logging"
 	"github.com/eurofurence/reg-room-service/internal/repository/config"
+	assignmentservice "github.com/eurofurence/reg-room-service/internal/service/assignment"
+	blockservice "github.com/eurofurence/reg-room-service/internal/service/blocks"
 	groupservice "github.com/eurofurence/reg-room-service/internal/service/groups"
+	historyservice "github.com/eurofurence/reg-room-service/internal/service/history"
+	reconcileservice "github.com/eurofurence/reg-room-service/internal/service/reconcile"
 	roomservice "github.com/eurofurence/reg-room-service/internal/service/rooms"
+	roomtypeservice "github.com/eurofurence/reg-room-service/internal/service/roomtypes"
 	"log"
 	"net"
 	"net/http"
@@ -31,8 +36,13 @@ type server struct {
 	interrupt chan os.Signal
 	shutdown  chan struct{}
 
-	groupsvc groupservice.Service
-	roomsvc  roomservice.Service
+	groupsvc      groupservice.Service
+	roomsvc       roomservice.Service
+	historysvc    historyservice.Service
+	assignmentsvc assignmentservice.Service
+	reconcilesvc  reconcileservice.Service
+	blocksvc      blockservice.Service
+	roomtypesvc   roomtypeservice.Service
 }
 
 var _ Server = (*server)(nil)
@@ -42,7 +52,7 @@ type Server interface {
 	Shutdown() error
 }
 
-func New(conf *config.Config, baseCtx context.Context, groupsvc groupservice.Service, roomsvc roomservice.Service) Server {
+func New(conf *config.Config, baseCtx context.Context, groupsvc groupservice.Service, roomsvc roomservice.Service, historysvc historyservice.Service, assignmentsvc assignmentservice.Service, reconcilesvc reconcileservice.Service, blocksvc blockservice.Service, roomtypesvc roomtypeservice.Service) Server {
 	s := new(server)
 
 	s.interrupt = make(chan os.Signal, 1)
@@ -59,12 +69,17 @@ func New(conf *config.Config, baseCtx context.Context, groupsvc groupservice.Ser
 
 	s.groupsvc = groupsvc
 	s.roomsvc = roomsvc
+	s.historysvc = historysvc
+	s.assignmentsvc = assignmentsvc
+	s.reconcilesvc = reconcilesvc
+	s.blocksvc = blocksvc
+	s.roomtypesvc = roomtypesvc
 
 	return s
 }
 
 func (s *server) Serve() error {
-	handler := Router(s.groupsvc, s.roomsvc)
+	handler := Router(s.groupsvc, s.roomsvc, s.historysvc, s.assignmentsvc, s.reconcilesvc, s.blocksvc, s.roomtypesvc)
 	s.srv = s.newServer(handler)
 
 	s.setupSignalHandler()
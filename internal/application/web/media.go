@@ -2,5 +2,7 @@ package web
 
 const (
 	ContentTypeApplicationJSON = "application/json"
+	ContentTypeApplicationYAML = "application/yaml"
+	ContentTypeTextCSV         = "text/csv; charset=utf-8"
 	ContentTypeTextPlain       = "text/plain; charset=utf-8"
 )
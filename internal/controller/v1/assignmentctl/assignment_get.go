@@ -0,0 +1,39 @@
+package assignmentctl
+
+import (
+	"context"
+	"github.com/eurofurence/reg-room-service/internal/application/web"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// PlanAssignment computes a proposed room assignment without changing anything.
+//
+// Details see OpenAPI spec.
+func (h *Controller) PlanAssignment(ctx context.Context, _ *modelsv1.Empty, w http.ResponseWriter) (*modelsv1.AssignmentPlan, error) {
+	return h.svc.PlanAssignment(ctx)
+}
+
+func (h *Controller) PlanAssignmentRequest(r *http.Request, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	return &modelsv1.Empty{}, nil
+}
+
+func (h *Controller) PlanAssignmentResponse(_ context.Context, res *modelsv1.AssignmentPlan, w http.ResponseWriter) error {
+	return web.EncodeWithStatus(http.StatusOK, res, w)
+}
+
+// ListUnmetWishes lists the groups whose rooms do not meet their room wishes.
+//
+// Details see OpenAPI spec.
+func (h *Controller) ListUnmetWishes(ctx context.Context, _ *modelsv1.Empty, w http.ResponseWriter) (*modelsv1.UnmetWishList, error) {
+	return h.svc.ListUnmetWishes(ctx)
+}
+
+func (h *Controller) ListUnmetWishesRequest(r *http.Request, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	return &modelsv1.Empty{}, nil
+}
+
+func (h *Controller) ListUnmetWishesResponse(_ context.Context, res *modelsv1.UnmetWishList, w http.ResponseWriter) error {
+	return web.EncodeWithStatus(http.StatusOK, res, w)
+}
@@ -0,0 +1,33 @@
+package assignmentctl
+
+import (
+	"context"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/controller/v1/util"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// ApplyAssignment adds attendees to rooms as listed in a (possibly reviewed and modified) assignment plan.
+//
+// Details see OpenAPI spec.
+func (h *Controller) ApplyAssignment(ctx context.Context, plan *modelsv1.AssignmentPlan, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	return &modelsv1.Empty{}, h.svc.ApplyAssignment(ctx, plan)
+}
+
+func (h *Controller) ApplyAssignmentRequest(r *http.Request, w http.ResponseWriter) (*modelsv1.AssignmentPlan, error) {
+	var plan modelsv1.AssignmentPlan
+
+	if err := util.NewStrictJSONDecoder(r.Body).Decode(&plan); err != nil {
+		return nil, common.NewBadRequest(r.Context(), common.AssignmentDataInvalid, common.Details("invalid json provided"))
+	}
+
+	return &plan, nil
+}
+
+// ApplyAssignmentResponse writes out a `No Content` status.
+func (h *Controller) ApplyAssignmentResponse(_ context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
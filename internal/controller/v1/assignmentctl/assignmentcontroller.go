@@ -0,0 +1,62 @@
+package assignmentctl
+
+import (
+	"github.com/eurofurence/reg-room-service/internal/application/web"
+	"net/http"
+
+	assignmentservice "github.com/eurofurence/reg-room-service/internal/service/assignment"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Controller implements methods which satisfy the endpoint format
+// in the `common` package.
+type Controller struct {
+	svc assignmentservice.Service
+}
+
+// InitRoutes creates the Controller instance and sets up all routes on it.
+func InitRoutes(router chi.Router, svc assignmentservice.Service) {
+	h := &Controller{
+		svc: svc,
+	}
+
+	router.Route("/api/rest/v1/assignment", func(sr chi.Router) {
+		initGetRoutes(sr, h)
+		initPostRoutes(sr, h)
+	})
+}
+
+func initGetRoutes(router chi.Router, h *Controller) {
+	router.Method(
+		http.MethodGet,
+		"/plan",
+		web.CreateHandler(
+			h.PlanAssignment,
+			h.PlanAssignmentRequest,
+			h.PlanAssignmentResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodGet,
+		"/unmet-wishes",
+		web.CreateHandler(
+			h.ListUnmetWishes,
+			h.ListUnmetWishesRequest,
+			h.ListUnmetWishesResponse,
+		),
+	)
+}
+
+func initPostRoutes(router chi.Router, h *Controller) {
+	router.Method(
+		http.MethodPost,
+		"/apply",
+		web.CreateHandler(
+			h.ApplyAssignment,
+			h.ApplyAssignmentRequest,
+			h.ApplyAssignmentResponse,
+		),
+	)
+}
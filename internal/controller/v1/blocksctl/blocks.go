@@ -0,0 +1,96 @@
+package blocksctl
+
+import (
+	"context"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/application/web"
+	blockservice "github.com/eurofurence/reg-room-service/internal/service/blocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+// Controller implements methods which satisfy the endpoint format
+// in the `common` package.
+type Controller struct {
+	svc blockservice.Service
+}
+
+func InitRoutes(router chi.Router, svc blockservice.Service) {
+	h := &Controller{
+		svc: svc,
+	}
+
+	router.Route("/api/rest/v1/blocks", func(sr chi.Router) {
+		initGetRoutes(sr, h)
+		initPostRoutes(sr, h)
+		initPutRoutes(sr, h)
+		initDeleteRoutes(sr, h)
+	})
+}
+
+func initGetRoutes(router chi.Router, h *Controller) {
+	router.Method(
+		http.MethodGet,
+		"/",
+		web.CreateHandler(
+			h.ListBlocks,
+			h.ListBlocksRequest,
+			h.ListBlocksResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodGet,
+		"/{uuid}",
+		web.CreateHandler(
+			h.GetBlockByID,
+			h.GetBlockByIDRequest,
+			h.GetBlockByIDResponse,
+		),
+	)
+}
+
+func initPostRoutes(router chi.Router, h *Controller) {
+	router.Method(
+		http.MethodPost,
+		"/",
+		web.CreateHandler(
+			h.CreateBlock,
+			h.CreateBlockRequest,
+			h.CreateBlockResponse,
+		),
+	)
+}
+
+func initPutRoutes(router chi.Router, h *Controller) {
+	router.Method(
+		http.MethodPut,
+		"/{uuid}",
+		web.CreateHandler(
+			h.UpdateBlock,
+			h.UpdateBlockRequest,
+			h.UpdateBlockResponse,
+		),
+	)
+}
+
+func initDeleteRoutes(router chi.Router, h *Controller) {
+	router.Method(
+		http.MethodDelete,
+		"/{uuid}",
+		web.CreateHandler(
+			h.DeleteBlock,
+			h.DeleteBlockRequest,
+			h.DeleteBlockResponse,
+		),
+	)
+}
+
+func validateBlockID(ctx context.Context, blockID string) error {
+	if err := uuid.Validate(blockID); err != nil {
+		return common.NewBadRequest(ctx, common.BlockIDInvalid, common.Details("you must specify a valid uuid"), err)
+	}
+
+	return nil
+}
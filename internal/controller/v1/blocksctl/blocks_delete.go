@@ -0,0 +1,37 @@
+package blocksctl
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+type DeleteBlockRequest struct {
+	UUID string
+}
+
+// DeleteBlock deletes an existing block by uuid. The block must not have any rooms.
+//
+// See OpenAPI Spec for further details.
+func (h *Controller) DeleteBlock(ctx context.Context, req *DeleteBlockRequest, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	err := h.svc.DeleteBlock(ctx, req.UUID)
+	return nil, err
+}
+
+func (h *Controller) DeleteBlockRequest(r *http.Request, w http.ResponseWriter) (*DeleteBlockRequest, error) {
+	blockID := chi.URLParam(r, "uuid")
+	if err := validateBlockID(r.Context(), blockID); err != nil {
+		return nil, err
+	}
+
+	return &DeleteBlockRequest{
+		UUID: blockID,
+	}, nil
+}
+
+func (h *Controller) DeleteBlockResponse(ctx context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
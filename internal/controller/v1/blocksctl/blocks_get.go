@@ -0,0 +1,60 @@
+package blocksctl
+
+import (
+	"context"
+	"github.com/eurofurence/reg-room-service/internal/application/web"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+type ListBlocksRequest struct{}
+
+// ListBlocks returns all blocks. Admin/API key only.
+//
+// See OpenAPI Spec for further details.
+func (h *Controller) ListBlocks(ctx context.Context, req *ListBlocksRequest, w http.ResponseWriter) (*modelsv1.BlockList, error) {
+	blocks, err := h.svc.FindBlocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &modelsv1.BlockList{
+		Blocks: blocks,
+	}, nil
+}
+
+func (h *Controller) ListBlocksRequest(r *http.Request, w http.ResponseWriter) (*ListBlocksRequest, error) {
+	return &ListBlocksRequest{}, nil
+}
+
+func (h *Controller) ListBlocksResponse(ctx context.Context, res *modelsv1.BlockList, w http.ResponseWriter) error {
+	return web.EncodeWithStatus(http.StatusOK, res, w)
+}
+
+type GetBlockByIDRequest struct {
+	UUID string
+}
+
+// GetBlockByID returns a single block. Admin/API key only.
+//
+// See OpenAPI Spec for further details.
+func (h *Controller) GetBlockByID(ctx context.Context, req *GetBlockByIDRequest, w http.ResponseWriter) (*modelsv1.Block, error) {
+	return h.svc.GetBlockByID(ctx, req.UUID)
+}
+
+func (h *Controller) GetBlockByIDRequest(r *http.Request, w http.ResponseWriter) (*GetBlockByIDRequest, error) {
+	blockID := chi.URLParam(r, "uuid")
+	if err := validateBlockID(r.Context(), blockID); err != nil {
+		return nil, err
+	}
+
+	return &GetBlockByIDRequest{
+		UUID: blockID,
+	}, nil
+}
+
+func (h *Controller) GetBlockByIDResponse(ctx context.Context, res *modelsv1.Block, w http.ResponseWriter) error {
+	return web.EncodeWithStatus(http.StatusOK, res, w)
+}
@@ -0,0 +1,55 @@
+package blocksctl
+
+import (
+	"context"
+	"errors"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/controller/v1/util"
+	"net/http"
+	"net/url"
+	"path"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+type CreateBlockRequest struct {
+	// Block is the expected representation for the request body
+	Block modelsv1.BlockCreate
+}
+
+// CreateBlock creates a new block without rooms.
+//
+// Endpoint access only for admin users or api token.
+//
+// Successful operations return status 201 with a location header that points to the created resource.
+func (h *Controller) CreateBlock(ctx context.Context, req *CreateBlockRequest, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	newBlockUUID, err := h.svc.CreateBlock(ctx, &(req.Block))
+	if err != nil {
+		return nil, err
+	}
+
+	requestURL, ok := ctx.Value(common.CtxKeyRequestURL{}).(*url.URL)
+	if !ok {
+		return nil, errors.New("could not retrieve base URL from context - this is an implementation error")
+	}
+
+	w.Header().Set("Location", path.Join(requestURL.Path, newBlockUUID))
+	return nil, nil
+}
+
+func (h *Controller) CreateBlockRequest(r *http.Request, w http.ResponseWriter) (*CreateBlockRequest, error) {
+	var block modelsv1.BlockCreate
+
+	if err := util.NewStrictJSONDecoder(r.Body).Decode(&block); err != nil {
+		return nil, common.NewBadRequest(r.Context(), common.BlockDataInvalid, common.Details("invalid json provided"))
+	}
+
+	return &CreateBlockRequest{
+		Block: block,
+	}, nil
+}
+
+func (h *Controller) CreateBlockResponse(ctx context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusCreated)
+	return nil
+}
@@ -0,0 +1,60 @@
+package blocksctl
+
+import (
+	"context"
+	"errors"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/controller/v1/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-http-utils/headers"
+	"net/http"
+	"net/url"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// UpdateBlockRequest is the request type for the UpdateBlock operation.
+type UpdateBlockRequest struct {
+	Block modelsv1.Block
+}
+
+// UpdateBlock updates an existing block by uuid.
+//
+// See OpenAPI Spec for further details.
+func (h *Controller) UpdateBlock(ctx context.Context, req *UpdateBlockRequest, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	if err := h.svc.UpdateBlock(ctx, &(req.Block)); err != nil {
+		return nil, err
+	}
+
+	reqURL, ok := ctx.Value(common.CtxKeyRequestURL{}).(*url.URL)
+	if !ok {
+		return nil, errors.New("unable to retrieve URL from context - this is an implementation error")
+	}
+
+	w.Header().Set(headers.Location, reqURL.Path)
+
+	return nil, nil
+}
+
+func (h *Controller) UpdateBlockRequest(r *http.Request, w http.ResponseWriter) (*UpdateBlockRequest, error) {
+	ctx := r.Context()
+
+	blockID := chi.URLParam(r, "uuid")
+	if err := validateBlockID(ctx, blockID); err != nil {
+		return nil, err
+	}
+
+	var block modelsv1.Block
+
+	if err := util.NewStrictJSONDecoder(r.Body).Decode(&block); err != nil {
+		return nil, common.NewBadRequest(ctx, common.BlockDataInvalid, common.Details("invalid json provided"))
+	}
+
+	block.ID = blockID
+	return &UpdateBlockRequest{Block: block}, nil
+}
+
+func (h *Controller) UpdateBlockResponse(ctx context.Context, res *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
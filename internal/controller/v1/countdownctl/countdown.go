@@ -2,15 +2,20 @@ package countdownctl
 
 import (
 	"github.com/eurofurence/reg-room-service/internal/application/web"
+	blockservice "github.com/eurofurence/reg-room-service/internal/service/blocks"
 	"github.com/go-chi/chi/v5"
 	"net/http"
 )
 
 // Handler implements methods, which satisfy the endpoint format.
-type Handler struct{}
+type Handler struct {
+	svc blockservice.Service
+}
 
-func InitRoutes(router chi.Router) {
-	h := &Handler{}
+func InitRoutes(router chi.Router, svc blockservice.Service) {
+	h := &Handler{
+		svc: svc,
+	}
 
 	router.Route("/api/rest/v1/countdown", func(sr chi.Router) {
 		initGetRoutes(sr, h)
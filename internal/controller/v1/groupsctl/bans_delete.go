@@ -0,0 +1,27 @@
+package groupsctl
+
+import (
+	"context"
+	groupservice "github.com/eurofurence/reg-room-service/internal/service/groups"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// RemoveGroupBan removes an attendee from the auto-decline list of a group.
+//
+// Details see OpenAPI spec.
+func (h *Controller) RemoveGroupBan(ctx context.Context, req *groupservice.GroupBanParams, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	return &modelsv1.Empty{}, h.svc.RemoveGroupBan(ctx, req)
+}
+
+// RemoveGroupBanRequest validates and creates the request for the RemoveGroupBan operation.
+func (h *Controller) RemoveGroupBanRequest(r *http.Request, w http.ResponseWriter) (*groupservice.GroupBanParams, error) {
+	return parseGroupBanParams(r)
+}
+
+// RemoveGroupBanResponse writes out a `No Content` status.
+func (h *Controller) RemoveGroupBanResponse(_ context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,38 @@
+package groupsctl
+
+import (
+	"context"
+	"github.com/eurofurence/reg-room-service/internal/application/web"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+type ListGroupBansRequest struct {
+	GroupID string
+}
+
+// ListGroupBans returns the auto-decline list of a group.
+//
+// Details see OpenAPI spec.
+func (h *Controller) ListGroupBans(ctx context.Context, req *ListGroupBansRequest, w http.ResponseWriter) (*modelsv1.GroupBanList, error) {
+	return h.svc.GetGroupBans(ctx, req.GroupID)
+}
+
+// ListGroupBansRequest validates and creates the request for the ListGroupBans operation.
+func (h *Controller) ListGroupBansRequest(r *http.Request, w http.ResponseWriter) (*ListGroupBansRequest, error) {
+	groupID := chi.URLParam(r, "uuid")
+	if err := validateGroupID(r.Context(), groupID); err != nil {
+		return nil, err
+	}
+
+	return &ListGroupBansRequest{
+		GroupID: groupID,
+	}, nil
+}
+
+// ListGroupBansResponse writes out the auto-decline list.
+func (h *Controller) ListGroupBansResponse(_ context.Context, res *modelsv1.GroupBanList, w http.ResponseWriter) error {
+	return web.EncodeWithStatus(http.StatusOK, res, w)
+}
@@ -0,0 +1,27 @@
+package groupsctl
+
+import (
+	"context"
+	groupservice "github.com/eurofurence/reg-room-service/internal/service/groups"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// AddGroupBan adds an attendee to the auto-decline list of a group.
+//
+// Details see OpenAPI spec.
+func (h *Controller) AddGroupBan(ctx context.Context, req *groupservice.GroupBanParams, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	return &modelsv1.Empty{}, h.svc.AddGroupBan(ctx, req)
+}
+
+// AddGroupBanRequest validates and creates the request for the AddGroupBan operation.
+func (h *Controller) AddGroupBanRequest(r *http.Request, w http.ResponseWriter) (*groupservice.GroupBanParams, error) {
+	return parseGroupBanParams(r)
+}
+
+// AddGroupBanResponse writes out a `No Content` status.
+func (h *Controller) AddGroupBanResponse(_ context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -59,6 +59,26 @@ func initGetRoutes(router chi.Router, h *Controller) {
 			h.FindGroupByIDResponse,
 		),
 	)
+
+	router.Method(
+		http.MethodGet,
+		"/{uuid}/bans",
+		web.CreateHandler(
+			h.ListGroupBans,
+			h.ListGroupBansRequest,
+			h.ListGroupBansResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodGet,
+		"/{uuid}/wishes",
+		web.CreateHandler(
+			h.GetGroupWishes,
+			h.GetGroupWishesRequest,
+			h.GetGroupWishesResponse,
+		),
+	)
 }
 
 func initPostRoutes(router chi.Router, h *Controller) {
@@ -81,6 +101,66 @@ func initPostRoutes(router chi.Router, h *Controller) {
 			h.AddMemberToGroupResponse,
 		),
 	)
+
+	router.Method(
+		http.MethodPost,
+		"/{uuid}/members/{badgenumber}/resend",
+		web.CreateHandler(
+			h.ResendInvitation,
+			h.ResendInvitationRequest,
+			h.ResendInvitationResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodPost,
+		"/{uuid}/transfer/accept",
+		web.CreateHandler(
+			h.AcceptOwnershipTransfer,
+			h.AcceptOwnershipTransferRequest,
+			h.AcceptOwnershipTransferResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodPost,
+		"/{uuid}/transfer/{badgenumber}",
+		web.CreateHandler(
+			h.ProposeOwnershipTransfer,
+			h.ProposeOwnershipTransferRequest,
+			h.ProposeOwnershipTransferResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodPost,
+		"/{uuid}/merge/{targetuuid}",
+		web.CreateHandler(
+			h.MergeGroups,
+			h.MergeGroupsRequest,
+			h.MergeGroupsResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodPost,
+		"/{uuid}/split",
+		web.CreateHandler(
+			h.SplitGroup,
+			h.SplitGroupRequest,
+			h.SplitGroupResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodPost,
+		"/{uuid}/bans/{badgenumber}",
+		web.CreateHandler(
+			h.AddGroupBan,
+			h.AddGroupBanRequest,
+			h.AddGroupBanResponse,
+		),
+	)
 }
 
 func initPutRoutes(router chi.Router, h *Controller) {
@@ -93,6 +173,26 @@ func initPutRoutes(router chi.Router, h *Controller) {
 			h.UpdateGroupResponse,
 		),
 	)
+
+	router.Method(
+		http.MethodPut,
+		"/{uuid}/members/{badgenumber}",
+		web.CreateHandler(
+			h.UpdateGroupMember,
+			h.UpdateGroupMemberRequest,
+			h.UpdateGroupMemberResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodPut,
+		"/{uuid}/wishes",
+		web.CreateHandler(
+			h.UpdateGroupWishes,
+			h.UpdateGroupWishesRequest,
+			h.UpdateGroupWishesResponse,
+		),
+	)
 }
 
 func initDeleteRoutes(router chi.Router, h *Controller) {
@@ -115,4 +215,34 @@ func initDeleteRoutes(router chi.Router, h *Controller) {
 			h.RemoveGroupMemberResponse,
 		),
 	)
+
+	router.Method(
+		http.MethodDelete,
+		"/{uuid}/transfer",
+		web.CreateHandler(
+			h.CancelOwnershipTransfer,
+			h.CancelOwnershipTransferRequest,
+			h.CancelOwnershipTransferResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodDelete,
+		"/{uuid}/merge",
+		web.CreateHandler(
+			h.CancelGroupMerge,
+			h.CancelGroupMergeRequest,
+			h.CancelGroupMergeResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodDelete,
+		"/{uuid}/bans/{badgenumber}",
+		web.CreateHandler(
+			h.RemoveGroupBan,
+			h.RemoveGroupBanRequest,
+			h.RemoveGroupBanResponse,
+		),
+	)
 }
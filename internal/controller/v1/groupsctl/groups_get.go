@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/eurofurence/reg-room-service/internal/application/web"
 	"github.com/eurofurence/reg-room-service/internal/controller/v1/util"
+	groupservice "github.com/eurofurence/reg-room-service/internal/service/groups"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"net/http"
@@ -13,22 +14,29 @@ import (
 	"github.com/eurofurence/reg-room-service/internal/application/common"
 )
 
+// maxListLimit is the largest page size that can be requested when listing groups.
+const maxListLimit = 1000
+
 type ListGroupsRequest struct {
 	MemberIDs  []int64
 	MinSize    uint
 	MaxSize    int
 	PublicOnly bool
+	List       util.ListParams
 }
 
 func (h *Controller) ListGroups(ctx context.Context, req *ListGroupsRequest, w http.ResponseWriter) (*modelsv1.GroupList, error) {
-	groups, err := h.svc.FindGroups(ctx, req.MinSize, req.MaxSize, req.MemberIDs, req.PublicOnly)
-	if err != nil {
-		return nil, err
-	}
-
-	return &modelsv1.GroupList{
-		Groups: groups,
-	}, nil
+	return h.svc.FindGroups(ctx, &groupservice.FindGroupParams{
+		MemberIDs:  req.MemberIDs,
+		MinSize:    req.MinSize,
+		MaxSize:    req.MaxSize,
+		PublicOnly: req.PublicOnly,
+		Name:       req.List.Name,
+		SortBy:     req.List.SortBy,
+		Descending: req.List.Descending,
+		Offset:     req.List.Offset,
+		Limit:      req.List.Limit,
+	})
 }
 
 func (h *Controller) ListGroupsRequest(r *http.Request, w http.ResponseWriter) (*ListGroupsRequest, error) {
@@ -75,6 +83,12 @@ func (h *Controller) ListGroupsRequest(r *http.Request, w http.ResponseWriter) (
 		}
 	}
 
+	list, err := util.ParseListParams(query, []string{"name", "size", "occupancy", "created"}, maxListLimit)
+	if err != nil {
+		return nil, common.NewBadRequest(ctx, common.RequestParseFailed, common.Details(err.Error()))
+	}
+	req.List = list
+
 	return &req, nil
 }
 
@@ -0,0 +1,45 @@
+package groupsctl
+
+import (
+	"context"
+	"github.com/eurofurence/reg-room-service/internal/controller/v1/util"
+	groupservice "github.com/eurofurence/reg-room-service/internal/service/groups"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+)
+
+// UpdateGroupMember replaces the membership flags of a group member.
+//
+// Details see OpenAPI spec.
+func (h *Controller) UpdateGroupMember(ctx context.Context, req *groupservice.UpdateGroupMemberParams, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	return &modelsv1.Empty{}, h.svc.UpdateMemberFlags(ctx, req)
+}
+
+// UpdateGroupMemberRequest validates and creates the request for the UpdateGroupMember operation.
+func (h *Controller) UpdateGroupMemberRequest(r *http.Request, w http.ResponseWriter) (*groupservice.UpdateGroupMemberParams, error) {
+	ctx := r.Context()
+
+	groupID, badgeNumber, err := parseGroupIDAndBadgeNumber(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var member modelsv1.MemberUpdate
+	if err := util.NewStrictJSONDecoder(r.Body).Decode(&member); err != nil {
+		return nil, common.NewBadRequest(ctx, common.GroupDataInvalid, common.Details("invalid json provided"))
+	}
+
+	return &groupservice.UpdateGroupMemberParams{
+		GroupID:     groupID,
+		BadgeNumber: badgeNumber,
+		Flags:       member.Flags,
+	}, nil
+}
+
+// UpdateGroupMemberResponse writes out a `No Content` status.
+func (h *Controller) UpdateGroupMemberResponse(_ context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
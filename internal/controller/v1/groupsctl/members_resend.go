@@ -0,0 +1,35 @@
+package groupsctl
+
+import (
+	"context"
+	groupservice "github.com/eurofurence/reg-room-service/internal/service/groups"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// ResendInvitation sends the invitation mail to an invited attendee again, and restarts the invitation lifetime.
+//
+// Details see OpenAPI spec.
+func (h *Controller) ResendInvitation(ctx context.Context, req *groupservice.ResendInvitationParams, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	return &modelsv1.Empty{}, h.svc.ResendInvitation(ctx, req)
+}
+
+// ResendInvitationRequest validates and creates the request for the ResendInvitation operation.
+func (h *Controller) ResendInvitationRequest(r *http.Request, w http.ResponseWriter) (*groupservice.ResendInvitationParams, error) {
+	groupID, badgeNumber, err := parseGroupIDAndBadgeNumber(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &groupservice.ResendInvitationParams{
+		GroupID:     groupID,
+		BadgeNumber: badgeNumber,
+	}, nil
+}
+
+// ResendInvitationResponse writes out a `No Content` status.
+func (h *Controller) ResendInvitationResponse(_ context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,37 @@
+package groupsctl
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// CancelGroupMergeRequest holds information, which is required to call the CancelGroupMerge operation.
+type CancelGroupMergeRequest struct {
+	groupID string
+}
+
+// CancelGroupMerge withdraws or declines a pending request to merge a group into another group.
+//
+// Details see OpenAPI spec.
+func (h *Controller) CancelGroupMerge(ctx context.Context, req *CancelGroupMergeRequest, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	return &modelsv1.Empty{}, h.svc.CancelGroupMerge(ctx, req.groupID)
+}
+
+// CancelGroupMergeRequest validates and creates the request for the CancelGroupMerge operation.
+func (h *Controller) CancelGroupMergeRequest(r *http.Request, w http.ResponseWriter) (*CancelGroupMergeRequest, error) {
+	groupID := chi.URLParam(r, "uuid")
+	if err := validateGroupID(r.Context(), groupID); err != nil {
+		return nil, err
+	}
+
+	return &CancelGroupMergeRequest{groupID: groupID}, nil
+}
+
+// CancelGroupMergeResponse writes out a `No Content` status.
+func (h *Controller) CancelGroupMergeResponse(_ context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,43 @@
+package groupsctl
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// MergeGroupsRequest holds information, which is required to call the MergeGroups operation.
+type MergeGroupsRequest struct {
+	groupID  string
+	targetID string
+}
+
+// MergeGroups requests or performs merging a group into another group.
+//
+// Details see OpenAPI spec.
+func (h *Controller) MergeGroups(ctx context.Context, req *MergeGroupsRequest, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	return &modelsv1.Empty{}, h.svc.MergeGroups(ctx, req.groupID, req.targetID)
+}
+
+// MergeGroupsRequest validates and creates the request for the MergeGroups operation.
+func (h *Controller) MergeGroupsRequest(r *http.Request, w http.ResponseWriter) (*MergeGroupsRequest, error) {
+	groupID := chi.URLParam(r, "uuid")
+	if err := validateGroupID(r.Context(), groupID); err != nil {
+		return nil, err
+	}
+
+	targetID := chi.URLParam(r, "targetuuid")
+	if err := validateGroupID(r.Context(), targetID); err != nil {
+		return nil, err
+	}
+
+	return &MergeGroupsRequest{groupID: groupID, targetID: targetID}, nil
+}
+
+// MergeGroupsResponse writes out a `No Content` status.
+func (h *Controller) MergeGroupsResponse(_ context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,61 @@
+package groupsctl
+
+import (
+	"context"
+	"errors"
+	"github.com/eurofurence/reg-room-service/internal/controller/v1/util"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"net/url"
+	"path"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+)
+
+// SplitGroupRequest holds information, which is required to call the SplitGroup operation.
+type SplitGroupRequest struct {
+	groupID string
+	// Split is the expected representation for the request body
+	Split modelsv1.GroupSplit
+}
+
+// SplitGroup moves some members of a group into a new group.
+//
+// Details see OpenAPI spec.
+func (h *Controller) SplitGroup(ctx context.Context, req *SplitGroupRequest, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	newGroupUUID, err := h.svc.SplitGroup(ctx, req.groupID, &req.Split)
+	if err != nil {
+		return nil, err
+	}
+
+	requestURL, ok := ctx.Value(common.CtxKeyRequestURL{}).(*url.URL)
+	if !ok {
+		return nil, errors.New("could not retrieve base URL from context - this is an implementation error")
+	}
+
+	// the request goes to .../groups/{uuid}/split, the new group is at .../groups/{newuuid}
+	w.Header().Set("Location", path.Join(path.Dir(path.Dir(requestURL.Path)), newGroupUUID))
+	return nil, nil
+}
+
+// SplitGroupRequest validates and creates the request for the SplitGroup operation.
+func (h *Controller) SplitGroupRequest(r *http.Request, w http.ResponseWriter) (*SplitGroupRequest, error) {
+	groupID := chi.URLParam(r, "uuid")
+	if err := validateGroupID(r.Context(), groupID); err != nil {
+		return nil, err
+	}
+
+	var split modelsv1.GroupSplit
+	if err := util.NewStrictJSONDecoder(r.Body).Decode(&split); err != nil {
+		return nil, common.NewBadRequest(r.Context(), common.GroupDataInvalid, common.Details("invalid json provided"))
+	}
+
+	return &SplitGroupRequest{groupID: groupID, Split: split}, nil
+}
+
+// SplitGroupResponse writes out a `Created` status, the location of the new group has already been set.
+func (h *Controller) SplitGroupResponse(_ context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusCreated)
+	return nil
+}
@@ -0,0 +1,37 @@
+package groupsctl
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// AcceptOwnershipTransferRequest holds information, which is required to call the AcceptOwnershipTransfer operation.
+type AcceptOwnershipTransferRequest struct {
+	groupID string
+}
+
+// AcceptOwnershipTransfer makes the logged in attendee the owner of the group, if ownership was offered to them.
+//
+// Details see OpenAPI spec.
+func (h *Controller) AcceptOwnershipTransfer(ctx context.Context, req *AcceptOwnershipTransferRequest, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	return &modelsv1.Empty{}, h.svc.AcceptOwnershipTransfer(ctx, req.groupID)
+}
+
+// AcceptOwnershipTransferRequest validates and creates the request for the AcceptOwnershipTransfer operation.
+func (h *Controller) AcceptOwnershipTransferRequest(r *http.Request, w http.ResponseWriter) (*AcceptOwnershipTransferRequest, error) {
+	groupID := chi.URLParam(r, "uuid")
+	if err := validateGroupID(r.Context(), groupID); err != nil {
+		return nil, err
+	}
+
+	return &AcceptOwnershipTransferRequest{groupID: groupID}, nil
+}
+
+// AcceptOwnershipTransferResponse writes out a `No Content` status.
+func (h *Controller) AcceptOwnershipTransferResponse(_ context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
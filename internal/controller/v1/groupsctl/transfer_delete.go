@@ -0,0 +1,37 @@
+package groupsctl
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// CancelOwnershipTransferRequest holds information, which is required to call the CancelOwnershipTransfer operation.
+type CancelOwnershipTransferRequest struct {
+	groupID string
+}
+
+// CancelOwnershipTransfer withdraws or declines a pending ownership transfer.
+//
+// Details see OpenAPI spec.
+func (h *Controller) CancelOwnershipTransfer(ctx context.Context, req *CancelOwnershipTransferRequest, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	return &modelsv1.Empty{}, h.svc.CancelOwnershipTransfer(ctx, req.groupID)
+}
+
+// CancelOwnershipTransferRequest validates and creates the request for the CancelOwnershipTransfer operation.
+func (h *Controller) CancelOwnershipTransferRequest(r *http.Request, w http.ResponseWriter) (*CancelOwnershipTransferRequest, error) {
+	groupID := chi.URLParam(r, "uuid")
+	if err := validateGroupID(r.Context(), groupID); err != nil {
+		return nil, err
+	}
+
+	return &CancelOwnershipTransferRequest{groupID: groupID}, nil
+}
+
+// CancelOwnershipTransferResponse writes out a `No Content` status.
+func (h *Controller) CancelOwnershipTransferResponse(_ context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
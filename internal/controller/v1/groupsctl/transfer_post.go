@@ -0,0 +1,35 @@
+package groupsctl
+
+import (
+	"context"
+	groupservice "github.com/eurofurence/reg-room-service/internal/service/groups"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// ProposeOwnershipTransfer offers ownership of a group to one of its members.
+//
+// Details see OpenAPI spec.
+func (h *Controller) ProposeOwnershipTransfer(ctx context.Context, req *groupservice.OwnershipTransferParams, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	return &modelsv1.Empty{}, h.svc.ProposeOwnershipTransfer(ctx, req)
+}
+
+// ProposeOwnershipTransferRequest validates and creates the request for the ProposeOwnershipTransfer operation.
+func (h *Controller) ProposeOwnershipTransferRequest(r *http.Request, w http.ResponseWriter) (*groupservice.OwnershipTransferParams, error) {
+	groupID, badgeNumber, err := parseGroupIDAndBadgeNumber(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &groupservice.OwnershipTransferParams{
+		GroupID:     groupID,
+		BadgeNumber: badgeNumber,
+	}, nil
+}
+
+// ProposeOwnershipTransferResponse writes out a `No Content` status.
+func (h *Controller) ProposeOwnershipTransferResponse(_ context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
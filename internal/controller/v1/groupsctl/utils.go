@@ -4,7 +4,11 @@ import (
 	"context"
 	"fmt"
 	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/controller/v1/util"
+	groupservice "github.com/eurofurence/reg-room-service/internal/service/groups"
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"net/http"
 	"net/url"
 )
 
@@ -15,3 +19,36 @@ func validateGroupID(ctx context.Context, groupID string) error {
 
 	return nil
 }
+
+func parseGroupBanParams(r *http.Request) (*groupservice.GroupBanParams, error) {
+	groupID, badgeNumber, err := parseGroupIDAndBadgeNumber(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &groupservice.GroupBanParams{
+		GroupID:     groupID,
+		BadgeNumber: badgeNumber,
+	}, nil
+}
+
+// parseGroupIDAndBadgeNumber validates and returns the uuid and badgenumber path parameters.
+func parseGroupIDAndBadgeNumber(r *http.Request) (string, int64, error) {
+	ctx := r.Context()
+
+	groupID := chi.URLParam(r, "uuid")
+	if err := validateGroupID(ctx, groupID); err != nil {
+		return "", 0, err
+	}
+
+	badge := chi.URLParam(r, "badgenumber")
+	badgeNumber, err := util.ParseInt[int64](badge)
+	if err != nil {
+		return "", 0, common.NewBadRequest(ctx, common.RequestParseFailed, common.Details("invalid badge number - must be positive integer"), err)
+	}
+	if badgeNumber < 1 {
+		return "", 0, common.NewBadRequest(ctx, common.GroupDataInvalid, common.Details("invalid badge number - must be positive integer"))
+	}
+
+	return groupID, badgeNumber, nil
+}
@@ -0,0 +1,38 @@
+package groupsctl
+
+import (
+	"context"
+	"github.com/eurofurence/reg-room-service/internal/application/web"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+type GetGroupWishesRequest struct {
+	GroupID string
+}
+
+// GetGroupWishes returns the room wishes of a group.
+//
+// Details see OpenAPI spec.
+func (h *Controller) GetGroupWishes(ctx context.Context, req *GetGroupWishesRequest, w http.ResponseWriter) (*modelsv1.GroupWishes, error) {
+	return h.svc.GetGroupWishes(ctx, req.GroupID)
+}
+
+// GetGroupWishesRequest validates and creates the request for the GetGroupWishes operation.
+func (h *Controller) GetGroupWishesRequest(r *http.Request, w http.ResponseWriter) (*GetGroupWishesRequest, error) {
+	groupID := chi.URLParam(r, "uuid")
+	if err := validateGroupID(r.Context(), groupID); err != nil {
+		return nil, err
+	}
+
+	return &GetGroupWishesRequest{
+		GroupID: groupID,
+	}, nil
+}
+
+// GetGroupWishesResponse writes out the room wishes.
+func (h *Controller) GetGroupWishesResponse(_ context.Context, res *modelsv1.GroupWishes, w http.ResponseWriter) error {
+	return web.EncodeWithStatus(http.StatusOK, res, w)
+}
@@ -0,0 +1,49 @@
+package groupsctl
+
+import (
+	"context"
+	"github.com/eurofurence/reg-room-service/internal/controller/v1/util"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+)
+
+type UpdateGroupWishesRequest struct {
+	GroupID string
+	Wishes  modelsv1.GroupWishes
+}
+
+// UpdateGroupWishes replaces the room wishes of a group.
+//
+// Details see OpenAPI spec.
+func (h *Controller) UpdateGroupWishes(ctx context.Context, req *UpdateGroupWishesRequest, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	return &modelsv1.Empty{}, h.svc.UpdateGroupWishes(ctx, req.GroupID, &req.Wishes)
+}
+
+// UpdateGroupWishesRequest validates and creates the request for the UpdateGroupWishes operation.
+func (h *Controller) UpdateGroupWishesRequest(r *http.Request, w http.ResponseWriter) (*UpdateGroupWishesRequest, error) {
+	ctx := r.Context()
+
+	groupID := chi.URLParam(r, "uuid")
+	if err := validateGroupID(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	var wishes modelsv1.GroupWishes
+	if err := util.NewStrictJSONDecoder(r.Body).Decode(&wishes); err != nil {
+		return nil, common.NewBadRequest(ctx, common.GroupDataInvalid, common.Details("invalid json provided"))
+	}
+
+	return &UpdateGroupWishesRequest{
+		GroupID: groupID,
+		Wishes:  wishes,
+	}, nil
+}
+
+// UpdateGroupWishesResponse writes out a `No Content` status.
+func (h *Controller) UpdateGroupWishesResponse(_ context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
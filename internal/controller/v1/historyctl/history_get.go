@@ -0,0 +1,145 @@
+package historyctl
+
+import (
+	"context"
+	"fmt"
+	"github.com/eurofurence/reg-room-service/internal/application/web"
+	"github.com/eurofurence/reg-room-service/internal/controller/v1/util"
+	historyservice "github.com/eurofurence/reg-room-service/internal/service/history"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+)
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+type GetGroupHistoryRequest struct {
+	GroupID string
+	Paging  historyservice.Paging
+}
+
+// GetGroupHistory returns the change history of a group.
+//
+// Details see OpenAPI spec.
+func (h *Controller) GetGroupHistory(ctx context.Context, req *GetGroupHistoryRequest, w http.ResponseWriter) (*modelsv1.HistoryList, error) {
+	return h.svc.GetGroupHistory(ctx, req.GroupID, &req.Paging)
+}
+
+func (h *Controller) GetGroupHistoryRequest(r *http.Request, w http.ResponseWriter) (*GetGroupHistoryRequest, error) {
+	ctx := r.Context()
+
+	groupID := chi.URLParam(r, "uuid")
+	if err := uuid.Validate(groupID); err != nil {
+		return nil, common.NewBadRequest(ctx, common.GroupIDInvalid, common.Details("you must specify a valid uuid"), err)
+	}
+
+	paging, err := parsePaging(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetGroupHistoryRequest{
+		GroupID: groupID,
+		Paging:  paging,
+	}, nil
+}
+
+type GetRoomHistoryRequest struct {
+	RoomID string
+	Paging historyservice.Paging
+}
+
+// GetRoomHistory returns the change history of a room.
+//
+// Details see OpenAPI spec.
+func (h *Controller) GetRoomHistory(ctx context.Context, req *GetRoomHistoryRequest, w http.ResponseWriter) (*modelsv1.HistoryList, error) {
+	return h.svc.GetRoomHistory(ctx, req.RoomID, &req.Paging)
+}
+
+func (h *Controller) GetRoomHistoryRequest(r *http.Request, w http.ResponseWriter) (*GetRoomHistoryRequest, error) {
+	ctx := r.Context()
+
+	roomID := chi.URLParam(r, "uuid")
+	if err := uuid.Validate(roomID); err != nil {
+		return nil, common.NewBadRequest(ctx, common.RoomIDInvalid, common.Details("you must specify a valid uuid"), err)
+	}
+
+	paging, err := parsePaging(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetRoomHistoryRequest{
+		RoomID: roomID,
+		Paging: paging,
+	}, nil
+}
+
+type GetAttendeeHistoryRequest struct {
+	BadgeNumber int64
+	Paging      historyservice.Paging
+}
+
+// GetAttendeeHistory returns the history of the group and room memberships of an attendee.
+//
+// Details see OpenAPI spec.
+func (h *Controller) GetAttendeeHistory(ctx context.Context, req *GetAttendeeHistoryRequest, w http.ResponseWriter) (*modelsv1.HistoryList, error) {
+	return h.svc.GetAttendeeHistory(ctx, req.BadgeNumber, &req.Paging)
+}
+
+func (h *Controller) GetAttendeeHistoryRequest(r *http.Request, w http.ResponseWriter) (*GetAttendeeHistoryRequest, error) {
+	ctx := r.Context()
+
+	badgeNumber, err := util.ParseInt[int64](chi.URLParam(r, "badgenumber"))
+	if err != nil || badgeNumber < 1 {
+		return nil, common.NewBadRequest(ctx, common.RequestParseFailed, common.Details("invalid badge number - must be positive integer"))
+	}
+
+	paging, err := parsePaging(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetAttendeeHistoryRequest{
+		BadgeNumber: badgeNumber,
+		Paging:      paging,
+	}, nil
+}
+
+func (h *Controller) HistoryResponse(_ context.Context, res *modelsv1.HistoryList, w http.ResponseWriter) error {
+	return web.EncodeWithStatus(http.StatusOK, res, w)
+}
+
+func parsePaging(r *http.Request) (historyservice.Paging, error) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	paging := historyservice.Paging{
+		Offset: 0,
+		Limit:  defaultLimit,
+	}
+
+	if offset := query.Get("offset"); offset != "" {
+		val, err := util.ParseUInt[uint](offset)
+		if err != nil {
+			return paging, common.NewBadRequest(ctx, common.RequestParseFailed, common.Details("offset must be a non-negative integer"))
+		}
+		paging.Offset = val
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		val, err := util.ParseUInt[uint](limit)
+		if err != nil || val < 1 || val > maxLimit {
+			return paging, common.NewBadRequest(ctx, common.RequestParseFailed, common.Details(fmt.Sprintf("limit must be an integer between 1 and %d", maxLimit)))
+		}
+		paging.Limit = val
+	}
+
+	return paging, nil
+}
@@ -0,0 +1,60 @@
+package historyctl
+
+import (
+	"github.com/eurofurence/reg-room-service/internal/application/web"
+	"net/http"
+
+	historyservice "github.com/eurofurence/reg-room-service/internal/service/history"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Controller implements methods which satisfy the endpoint format
+// in the `common` package.
+type Controller struct {
+	svc historyservice.Service
+}
+
+// InitRoutes creates the Controller instance and sets up all routes on it.
+//
+// The history endpoints are subresources of groups, rooms and attendees, so the routes
+// are set up with their full paths.
+func InitRoutes(router chi.Router, svc historyservice.Service) {
+	h := &Controller{
+		svc: svc,
+	}
+
+	initGetRoutes(router, h)
+}
+
+func initGetRoutes(router chi.Router, h *Controller) {
+	router.Method(
+		http.MethodGet,
+		"/api/rest/v1/groups/{uuid}/history",
+		web.CreateHandler(
+			h.GetGroupHistory,
+			h.GetGroupHistoryRequest,
+			h.HistoryResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodGet,
+		"/api/rest/v1/rooms/{uuid}/history",
+		web.CreateHandler(
+			h.GetRoomHistory,
+			h.GetRoomHistoryRequest,
+			h.HistoryResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodGet,
+		"/api/rest/v1/attendees/{badgenumber}/history",
+		web.CreateHandler(
+			h.GetAttendeeHistory,
+			h.GetAttendeeHistoryRequest,
+			h.HistoryResponse,
+		),
+	)
+}
@@ -0,0 +1,33 @@
+package reconcilectl
+
+import (
+	"context"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/controller/v1/util"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// AttendeeStatusEvent is called by the attendee service whenever the status or nickname of a registration changes.
+//
+// Details see OpenAPI spec.
+func (h *Controller) AttendeeStatusEvent(ctx context.Context, event *modelsv1.AttendeeStatusEvent, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	return &modelsv1.Empty{}, h.svc.ProcessAttendeeStatusEvent(ctx, event)
+}
+
+func (h *Controller) AttendeeStatusEventRequest(r *http.Request, w http.ResponseWriter) (*modelsv1.AttendeeStatusEvent, error) {
+	var event modelsv1.AttendeeStatusEvent
+
+	if err := util.NewStrictJSONDecoder(r.Body).Decode(&event); err != nil {
+		return nil, common.NewBadRequest(r.Context(), common.AttendeeEventInvalid, common.Details("invalid json provided"))
+	}
+
+	return &event, nil
+}
+
+// AttendeeStatusEventResponse writes out a `No Content` status.
+func (h *Controller) AttendeeStatusEventResponse(_ context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,24 @@
+package reconcilectl
+
+import (
+	"context"
+	"github.com/eurofurence/reg-room-service/internal/application/web"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// ReconciliationReport lists the attendees in groups or rooms who are no longer attending, without changing anything.
+//
+// Details see OpenAPI spec.
+func (h *Controller) ReconciliationReport(ctx context.Context, _ *modelsv1.Empty, w http.ResponseWriter) (*modelsv1.ReconciliationReport, error) {
+	return h.svc.Reconcile(ctx, false)
+}
+
+func (h *Controller) ReconciliationReportRequest(r *http.Request, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	return &modelsv1.Empty{}, nil
+}
+
+func (h *Controller) ReconciliationReportResponse(_ context.Context, res *modelsv1.ReconciliationReport, w http.ResponseWriter) error {
+	return web.EncodeWithStatus(http.StatusOK, res, w)
+}
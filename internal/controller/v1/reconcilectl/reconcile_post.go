@@ -0,0 +1,25 @@
+package reconcilectl
+
+import (
+	"context"
+	"github.com/eurofurence/reg-room-service/internal/application/web"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// ApplyReconciliation removes the attendees who are no longer attending from their groups and rooms,
+// if enabled in configuration.
+//
+// Details see OpenAPI spec.
+func (h *Controller) ApplyReconciliation(ctx context.Context, _ *modelsv1.Empty, w http.ResponseWriter) (*modelsv1.ReconciliationReport, error) {
+	return h.svc.Reconcile(ctx, true)
+}
+
+func (h *Controller) ApplyReconciliationRequest(r *http.Request, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	return &modelsv1.Empty{}, nil
+}
+
+func (h *Controller) ApplyReconciliationResponse(_ context.Context, res *modelsv1.ReconciliationReport, w http.ResponseWriter) error {
+	return web.EncodeWithStatus(http.StatusOK, res, w)
+}
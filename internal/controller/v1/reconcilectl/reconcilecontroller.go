@@ -0,0 +1,72 @@
+package reconcilectl
+
+import (
+	"github.com/eurofurence/reg-room-service/internal/application/web"
+	"net/http"
+
+	reconcileservice "github.com/eurofurence/reg-room-service/internal/service/reconcile"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Controller implements methods which satisfy the endpoint format
+// in the `common` package.
+type Controller struct {
+	svc reconcileservice.Service
+}
+
+// InitRoutes creates the Controller instance and sets up all routes on it.
+func InitRoutes(router chi.Router, svc reconcileservice.Service) {
+	h := &Controller{
+		svc: svc,
+	}
+
+	router.Route("/api/rest/v1/reconciliation", func(sr chi.Router) {
+		initGetRoutes(sr, h)
+		initPostRoutes(sr, h)
+	})
+}
+
+func initGetRoutes(router chi.Router, h *Controller) {
+	router.Method(
+		http.MethodGet,
+		"/report",
+		web.CreateHandler(
+			h.ReconciliationReport,
+			h.ReconciliationReportRequest,
+			h.ReconciliationReportResponse,
+		),
+	)
+}
+
+func initPostRoutes(router chi.Router, h *Controller) {
+	router.Method(
+		http.MethodPost,
+		"/apply",
+		web.CreateHandler(
+			h.ApplyReconciliation,
+			h.ApplyReconciliationRequest,
+			h.ApplyReconciliationResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodPost,
+		"/attendee-events",
+		web.CreateHandler(
+			h.AttendeeStatusEvent,
+			h.AttendeeStatusEventRequest,
+			h.AttendeeStatusEventResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodPost,
+		"/refresh-members",
+		web.CreateHandler(
+			h.RefreshMembers,
+			h.RefreshMembersRequest,
+			h.RefreshMembersResponse,
+		),
+	)
+}
@@ -0,0 +1,25 @@
+package reconcilectl
+
+import (
+	"context"
+	"github.com/eurofurence/reg-room-service/internal/application/web"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// RefreshMembers updates the cached nicknames and avatars of group members and room occupants
+// from the attendee service.
+//
+// Details see OpenAPI spec.
+func (h *Controller) RefreshMembers(ctx context.Context, _ *modelsv1.Empty, w http.ResponseWriter) (*modelsv1.MemberRefreshResult, error) {
+	return h.svc.RefreshMembers(ctx)
+}
+
+func (h *Controller) RefreshMembersRequest(r *http.Request, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	return &modelsv1.Empty{}, nil
+}
+
+func (h *Controller) RefreshMembersResponse(_ context.Context, res *modelsv1.MemberRefreshResult, w http.ResponseWriter) error {
+	return web.EncodeWithStatus(http.StatusOK, res, w)
+}
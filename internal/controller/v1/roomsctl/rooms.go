@@ -47,6 +47,46 @@ func initGetRoutes(router chi.Router, h *Controller) {
 		),
 	)
 
+	router.Method(
+		http.MethodGet,
+		"/export",
+		web.CreateHandler(
+			h.ExportRooms,
+			h.ExportRoomsRequest,
+			h.ExportRoomsResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodGet,
+		"/arrivals",
+		web.CreateHandler(
+			h.ArrivalReport,
+			h.ArrivalReportRequest,
+			h.ArrivalReportResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodGet,
+		"/flag-mismatches",
+		web.CreateHandler(
+			h.ListFlagMismatches,
+			h.ListFlagMismatchesRequest,
+			h.ListFlagMismatchesResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodGet,
+		"/capacity",
+		web.CreateHandler(
+			h.CapacityReport,
+			h.CapacityReportRequest,
+			h.CapacityReportResponse,
+		),
+	)
+
 	router.Method(
 		http.MethodGet,
 		"/{uuid}",
@@ -69,6 +109,16 @@ func initPostRoutes(router chi.Router, h *Controller) {
 		),
 	)
 
+	router.Method(
+		http.MethodPost,
+		"/import",
+		web.CreateHandler(
+			h.ImportRooms,
+			h.ImportRoomsRequest,
+			h.ImportRoomsResponse,
+		),
+	)
+
 	router.Method(
 		http.MethodPost,
 		"/{uuid}/occupants/{badgenumber}",
@@ -78,6 +128,56 @@ func initPostRoutes(router chi.Router, h *Controller) {
 			h.AddToRoomResponse,
 		),
 	)
+
+	router.Method(
+		http.MethodPost,
+		"/{uuid}/groups/{groupuuid}",
+		web.CreateHandler(
+			h.AddGroupToRoom,
+			h.AddGroupToRoomRequest,
+			h.AddGroupToRoomResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodPost,
+		"/{uuid}/occupants/{badgenumber}/move/{targetuuid}",
+		web.CreateHandler(
+			h.MoveOccupant,
+			h.MoveOccupantRequest,
+			h.MoveOccupantResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodPost,
+		"/{uuid}/occupants/{badgenumber}/swap/{otherbadgenumber}",
+		web.CreateHandler(
+			h.SwapOccupants,
+			h.SwapOccupantsRequest,
+			h.SwapOccupantsResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodPost,
+		"/{uuid}/occupants/{badgenumber}/checkin",
+		web.CreateHandler(
+			h.CheckIn,
+			h.CheckInRequest,
+			h.CheckInResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodPost,
+		"/{uuid}/occupants/{badgenumber}/checkout",
+		web.CreateHandler(
+			h.CheckOut,
+			h.CheckOutRequest,
+			h.CheckOutResponse,
+		),
+	)
 }
 
 func initPutRoutes(router chi.Router, h *Controller) {
@@ -90,6 +190,26 @@ func initPutRoutes(router chi.Router, h *Controller) {
 			h.UpdateRoomResponse,
 		),
 	)
+
+	router.Method(
+		http.MethodPut,
+		"/{uuid}/occupants/{badgenumber}",
+		web.CreateHandler(
+			h.UpdateOccupant,
+			h.UpdateOccupantRequest,
+			h.UpdateOccupantResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodPut,
+		"/{uuid}/occupants/{badgenumber}/stay",
+		web.CreateHandler(
+			h.UpdateOccupantStay,
+			h.UpdateOccupantStayRequest,
+			h.UpdateOccupantStayResponse,
+		),
+	)
 }
 
 func initDeleteRoutes(router chi.Router, h *Controller) {
@@ -112,4 +232,14 @@ func initDeleteRoutes(router chi.Router, h *Controller) {
 			h.RemoveFromRoomResponse,
 		),
 	)
+
+	router.Method(
+		http.MethodDelete,
+		"/{uuid}/groups/{groupuuid}",
+		web.CreateHandler(
+			h.RemoveGroupFromRoom,
+			h.RemoveGroupFromRoomRequest,
+			h.RemoveGroupFromRoomResponse,
+		),
+	)
 }
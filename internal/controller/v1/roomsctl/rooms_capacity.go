@@ -0,0 +1,24 @@
+package roomsctl
+
+import (
+	"context"
+	"github.com/eurofurence/reg-room-service/internal/application/web"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// CapacityReport lists the number of rooms, beds and free beds per room type.
+//
+// Endpoint access only for admin users or api token.
+func (h *Controller) CapacityReport(ctx context.Context, _ *modelsv1.Empty, w http.ResponseWriter) (*modelsv1.CapacityReport, error) {
+	return h.svc.CapacityReport(ctx)
+}
+
+func (h *Controller) CapacityReportRequest(r *http.Request, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	return &modelsv1.Empty{}, nil
+}
+
+func (h *Controller) CapacityReportResponse(_ context.Context, res *modelsv1.CapacityReport, w http.ResponseWriter) error {
+	return web.EncodeWithStatus(http.StatusOK, res, w)
+}
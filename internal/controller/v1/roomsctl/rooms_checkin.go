@@ -0,0 +1,146 @@
+package roomsctl
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"github.com/eurofurence/reg-room-service/internal/application/web"
+	"github.com/go-http-utils/headers"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"strings"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// OccupantRequest identifies a room occupant.
+type OccupantRequest struct {
+	// RoomID is the uuid of the room
+	RoomID string
+	// BadgeNumber is the registration number of the occupant
+	BadgeNumber int64
+}
+
+// CheckIn records that an occupant has arrived at the hotel.
+//
+// See OpenAPI Spec for further details.
+func (h *Controller) CheckIn(ctx context.Context, req *OccupantRequest, _ http.ResponseWriter) (*modelsv1.Empty, error) {
+	err := h.svc.CheckInOccupant(ctx, req.RoomID, req.BadgeNumber)
+	return &modelsv1.Empty{}, err
+}
+
+func (h *Controller) CheckInRequest(r *http.Request, _ http.ResponseWriter) (*OccupantRequest, error) {
+	return parseOccupantRequest(r)
+}
+
+func (h *Controller) CheckInResponse(_ context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// CheckOut records that an occupant has left the hotel.
+//
+// See OpenAPI Spec for further details.
+func (h *Controller) CheckOut(ctx context.Context, req *OccupantRequest, _ http.ResponseWriter) (*modelsv1.Empty, error) {
+	err := h.svc.CheckOutOccupant(ctx, req.RoomID, req.BadgeNumber)
+	return &modelsv1.Empty{}, err
+}
+
+func (h *Controller) CheckOutRequest(r *http.Request, _ http.ResponseWriter) (*OccupantRequest, error) {
+	return parseOccupantRequest(r)
+}
+
+func (h *Controller) CheckOutResponse(_ context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func parseOccupantRequest(r *http.Request) (*OccupantRequest, error) {
+	roomID, badgeNumber, err := parseRoomIDAndBadgeNumber(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OccupantRequest{
+		RoomID:      roomID,
+		BadgeNumber: badgeNumber,
+	}, nil
+}
+
+type ArrivalReportRequest struct {
+	// Format is either csv or yaml
+	Format string
+}
+
+type ArrivalReportResponse struct {
+	Format string
+	Report *modelsv1.ArrivalReport
+}
+
+// ArrivalReport compares expected arrivals to actual check-ins per day, as a CSV or YAML document.
+//
+// Endpoint access only for admin users or api token.
+//
+// See OpenAPI Spec for further details.
+func (h *Controller) ArrivalReport(ctx context.Context, req *ArrivalReportRequest, w http.ResponseWriter) (*ArrivalReportResponse, error) {
+	report, err := h.svc.ArrivalReport(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ArrivalReportResponse{
+		Format: req.Format,
+		Report: report,
+	}, nil
+}
+
+func (h *Controller) ArrivalReportRequest(r *http.Request, w http.ResponseWriter) (*ArrivalReportRequest, error) {
+	format, err := parseFormat(r.Context(), r.URL.Query().Get("format"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ArrivalReportRequest{
+		Format: format,
+	}, nil
+}
+
+func (h *Controller) ArrivalReportResponse(ctx context.Context, res *ArrivalReportResponse, w http.ResponseWriter) error {
+	if res.Format == formatCSV {
+		w.Header().Set(headers.ContentType, web.ContentTypeTextCSV)
+		w.WriteHeader(http.StatusOK)
+		return writeArrivalsCSV(w, res.Report)
+	}
+
+	w.Header().Set(headers.ContentType, web.ContentTypeApplicationYAML)
+	w.WriteHeader(http.StatusOK)
+	return yaml.NewEncoder(w).Encode(res.Report)
+}
+
+// writeArrivalsCSV writes one line per day. The not_arrived column lists the badge numbers of the occupants
+// expected on that day who have not checked in yet.
+func writeArrivalsCSV(w http.ResponseWriter, report *modelsv1.ArrivalReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"date", "expected", "arrived", "not_arrived"}); err != nil {
+		return err
+	}
+
+	for _, day := range report.Days {
+		notArrived := make([]string, 0, len(day.NotArrived))
+		for _, id := range day.NotArrived {
+			notArrived = append(notArrived, fmt.Sprintf("%d", id))
+		}
+
+		if err := writer.Write([]string{
+			day.Date,
+			fmt.Sprintf("%d", day.Expected),
+			fmt.Sprintf("%d", day.Arrived),
+			strings.Join(notArrived, ","),
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,100 @@
+package roomsctl
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/application/web"
+	roomservice "github.com/eurofurence/reg-room-service/internal/service/rooms"
+	"github.com/go-http-utils/headers"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"strings"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+type ExportRoomsRequest struct {
+	// Format is either csv or yaml
+	Format string
+}
+
+type ExportRoomsResponse struct {
+	Format string
+	Rooms  *modelsv1.RoomList
+}
+
+// ExportRooms returns all rooms with their occupants as a CSV or YAML document.
+//
+// Endpoint access only for admin users or api token.
+//
+// See OpenAPI Spec for further details.
+func (h *Controller) ExportRooms(ctx context.Context, req *ExportRoomsRequest, w http.ResponseWriter) (*ExportRoomsResponse, error) {
+	rooms, err := h.svc.FindRooms(ctx, &roomservice.FindRoomParams{MaxOccupants: -1})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExportRoomsResponse{
+		Format: req.Format,
+		Rooms: &modelsv1.RoomList{
+			Rooms: rooms.Rooms,
+		},
+	}, nil
+}
+
+func (h *Controller) ExportRoomsRequest(r *http.Request, w http.ResponseWriter) (*ExportRoomsRequest, error) {
+	format, err := parseFormat(r.Context(), r.URL.Query().Get("format"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExportRoomsRequest{
+		Format: format,
+	}, nil
+}
+
+func (h *Controller) ExportRoomsResponse(ctx context.Context, res *ExportRoomsResponse, w http.ResponseWriter) error {
+	if res.Format == formatCSV {
+		w.Header().Set(headers.ContentType, web.ContentTypeTextCSV)
+		w.WriteHeader(http.StatusOK)
+		return writeRoomsCSV(w, res.Rooms)
+	}
+
+	w.Header().Set(headers.ContentType, web.ContentTypeApplicationYAML)
+	w.WriteHeader(http.StatusOK)
+	return yaml.NewEncoder(w).Encode(res.Rooms)
+}
+
+// writeRoomsCSV writes one line per room. The occupants column lists the badge numbers of the occupants,
+// the block and type columns contain the ids of the block and room type of the room.
+func writeRoomsCSV(w http.ResponseWriter, rooms *modelsv1.RoomList) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"id", "name", "size", "flags", "comments", "occupants", "block", "type"}); err != nil {
+		return err
+	}
+
+	for _, room := range rooms.Rooms {
+		occupants := make([]string, 0, len(room.Occupants))
+		for _, occupant := range room.Occupants {
+			occupants = append(occupants, fmt.Sprintf("%d", occupant.ID))
+		}
+
+		if err := writer.Write([]string{
+			room.ID,
+			room.Name,
+			fmt.Sprintf("%d", room.Size),
+			strings.Join(room.Flags, ","),
+			common.Deref(room.Comments),
+			strings.Join(occupants, ","),
+			common.Deref(room.Block),
+			common.Deref(room.Type),
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
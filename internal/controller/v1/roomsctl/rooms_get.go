@@ -14,15 +14,11 @@ import (
 	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
 )
 
-func (h *Controller) ListRooms(ctx context.Context, req *roomservice.FindRoomParams, w http.ResponseWriter) (*modelsv1.RoomList, error) {
-	rooms, err := h.svc.FindRooms(ctx, req)
-	if err != nil {
-		return nil, err
-	}
+// maxListLimit is the largest page size that can be requested when listing rooms.
+const maxListLimit = 1000
 
-	return &modelsv1.RoomList{
-		Rooms: rooms,
-	}, nil
+func (h *Controller) ListRooms(ctx context.Context, req *roomservice.FindRoomParams, w http.ResponseWriter) (*modelsv1.RoomList, error) {
+	return h.svc.FindRooms(ctx, req)
 }
 
 func (h *Controller) ListRoomsRequest(r *http.Request, w http.ResponseWriter) (*roomservice.FindRoomParams, error) {
@@ -38,6 +34,22 @@ func (h *Controller) ListRoomsRequest(r *http.Request, w http.ResponseWriter) (*
 	}
 	req.MemberIDs = memberIDs
 
+	if blockID := query.Get("block"); blockID != "" {
+		if err := uuid.Validate(blockID); err != nil {
+			return nil, common.NewBadRequest(ctx, common.BlockIDInvalid, common.Details("you must specify a valid uuid"), err)
+		}
+
+		req.BlockID = blockID
+	}
+
+	if typeID := query.Get("type"); typeID != "" {
+		if err := uuid.Validate(typeID); err != nil {
+			return nil, common.NewBadRequest(ctx, common.RoomTypeIDInvalid, common.Details("you must specify a valid uuid"), err)
+		}
+
+		req.TypeID = typeID
+	}
+
 	if minSize := query.Get("min_size"); minSize != "" {
 		val, err := util.ParseUInt[uint](minSize)
 		if err != nil {
@@ -79,6 +91,25 @@ func (h *Controller) ListRoomsRequest(r *http.Request, w http.ResponseWriter) (*
 		req.MaxOccupants = -1
 	}
 
+	if checkedIn := query.Get("checked_in"); checkedIn != "" {
+		val, err := util.ParseOptionalBool(checkedIn)
+		if err != nil {
+			return nil, common.NewBadRequest(ctx, common.RequestParseFailed, common.Details("invalid checked_in parameter, try true, 1, false, 0 or omit"), err)
+		}
+
+		req.CheckedIn = &val
+	}
+
+	list, err := util.ParseListParams(query, []string{"name", "size", "occupancy", "created"}, maxListLimit)
+	if err != nil {
+		return nil, common.NewBadRequest(ctx, common.RequestParseFailed, common.Details(err.Error()))
+	}
+	req.Name = list.Name
+	req.SortBy = list.SortBy
+	req.Descending = list.Descending
+	req.Offset = list.Offset
+	req.Limit = list.Limit
+
 	return &req, nil
 }
 
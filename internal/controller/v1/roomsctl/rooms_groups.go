@@ -0,0 +1,85 @@
+package roomsctl
+
+import (
+	"context"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// RoomGroupRequest identifies a group to be added to or removed from a room.
+type RoomGroupRequest struct {
+	// RoomID is the uuid of the room
+	RoomID string
+	// GroupID is the uuid of the group
+	GroupID string
+	// Force allows admins to add the group even though the room lacks flags the group requires
+	Force bool
+}
+
+// AddGroupToRoom adds all members of a group to the room, or none of them.
+//
+// See OpenAPI Spec for further details.
+func (h *Controller) AddGroupToRoom(ctx context.Context, req *RoomGroupRequest, _ http.ResponseWriter) (*modelsv1.Empty, error) {
+	err := h.svc.AddGroupToRoom(ctx, req.RoomID, req.GroupID, req.Force)
+	return &modelsv1.Empty{}, err
+}
+
+func (h *Controller) AddGroupToRoomRequest(r *http.Request, _ http.ResponseWriter) (*RoomGroupRequest, error) {
+	req, err := parseRoomGroupRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	force, err := parseForce(r)
+	if err != nil {
+		return nil, err
+	}
+	req.Force = force
+
+	return req, nil
+}
+
+func (h *Controller) AddGroupToRoomResponse(_ context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// RemoveGroupFromRoom removes all members of a group from the room.
+//
+// See OpenAPI Spec for further details.
+func (h *Controller) RemoveGroupFromRoom(ctx context.Context, req *RoomGroupRequest, _ http.ResponseWriter) (*modelsv1.Empty, error) {
+	err := h.svc.RemoveGroupFromRoom(ctx, req.RoomID, req.GroupID)
+	return &modelsv1.Empty{}, err
+}
+
+func (h *Controller) RemoveGroupFromRoomRequest(r *http.Request, _ http.ResponseWriter) (*RoomGroupRequest, error) {
+	return parseRoomGroupRequest(r)
+}
+
+func (h *Controller) RemoveGroupFromRoomResponse(_ context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func parseRoomGroupRequest(r *http.Request) (*RoomGroupRequest, error) {
+	ctx := r.Context()
+
+	roomID := chi.URLParam(r, "uuid")
+	if err := validateRoomID(ctx, roomID); err != nil {
+		return nil, err
+	}
+
+	groupID := chi.URLParam(r, "groupuuid")
+	if err := uuid.Validate(groupID); err != nil {
+		return nil, common.NewBadRequest(ctx, common.GroupIDInvalid, common.Details("you must specify a valid group uuid"), err)
+	}
+
+	return &RoomGroupRequest{
+		RoomID:  roomID,
+		GroupID: groupID,
+	}, nil
+}
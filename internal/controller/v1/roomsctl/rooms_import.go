@@ -0,0 +1,178 @@
+package roomsctl
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/application/web"
+	"github.com/eurofurence/reg-room-service/internal/controller/v1/util"
+	roomservice "github.com/eurofurence/reg-room-service/internal/service/rooms"
+	"gopkg.in/yaml.v3"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// ImportRooms creates many rooms at once from a CSV or YAML document.
+//
+// Endpoint access only for admin users or api token.
+//
+// See OpenAPI Spec for further details.
+func (h *Controller) ImportRooms(ctx context.Context, req *roomservice.RoomImportParams, w http.ResponseWriter) (*modelsv1.RoomImportResult, error) {
+	return h.svc.ImportRooms(ctx, req)
+}
+
+func (h *Controller) ImportRoomsRequest(r *http.Request, w http.ResponseWriter) (*roomservice.RoomImportParams, error) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	dryRun, err := util.ParseOptionalBool(query.Get("dryrun"))
+	if err != nil {
+		return nil, common.NewBadRequest(ctx, common.RequestParseFailed, common.Details("invalid dryrun parameter, try true, 1, false, 0 or omit"), err)
+	}
+
+	format, err := parseFormat(ctx, query.Get("format"))
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []roomservice.RoomImportRow
+	if format == formatCSV {
+		rows, err = parseRoomsCSV(ctx, r.Body)
+	} else {
+		rows, err = parseRoomsYAML(ctx, r.Body)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &roomservice.RoomImportParams{
+		Rows:   rows,
+		DryRun: dryRun,
+	}, nil
+}
+
+func (h *Controller) ImportRoomsResponse(ctx context.Context, res *modelsv1.RoomImportResult, w http.ResponseWriter) error {
+	return web.EncodeWithStatus(http.StatusOK, res, w)
+}
+
+// parseRoomsCSV reads rooms from a CSV document with a header line.
+//
+// The column name is required, and so is either size or type. Flags, comments and block are optional, and all other
+// columns are ignored, so an export can be imported again. Multiple flags are separated by commas within the flags column.
+//
+// Empty size, flags or comments cells are taken from the room type, if the row has one.
+func parseRoomsCSV(ctx context.Context, body io.Reader) ([]roomservice.RoomImportRow, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return make([]roomservice.RoomImportRow, 0), nil
+		}
+		return nil, common.NewBadRequest(ctx, common.RequestParseFailed, common.Details("invalid csv provided"), err)
+	}
+
+	columns := make(map[string]int)
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	nameCol, hasName := columns["name"]
+	sizeCol, hasSize := columns["size"]
+	typeCol, hasType := columns["type"]
+	if !hasName || (!hasSize && !hasType) {
+		return nil, common.NewBadRequest(ctx, common.RequestParseFailed, common.Details("csv header line must contain the columns name and size or type"))
+	}
+	flagsCol, hasFlags := columns["flags"]
+	commentsCol, hasComments := columns["comments"]
+	blockCol, hasBlock := columns["block"]
+
+	result := make([]roomservice.RoomImportRow, 0)
+	for {
+		record, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, common.NewBadRequest(ctx, common.RequestParseFailed, common.Details("invalid csv provided"), err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		row := roomservice.RoomImportRow{
+			Row: line,
+			Room: modelsv1.RoomCreate{
+				Name: strings.TrimSpace(record[nameCol]),
+			},
+			ParseErrors: url.Values{},
+		}
+
+		// an empty size is left at 0, so it is either taken from the room type or rejected by validation
+		if hasSize {
+			if sizeValue := strings.TrimSpace(record[sizeCol]); sizeValue != "" {
+				size, err := util.ParseInt[int64](sizeValue)
+				if err != nil {
+					row.ParseErrors.Set("size", "room size must be a positive integer")
+				}
+				row.Room.Size = size
+			}
+		}
+
+		// flags stay nil if the cell is empty, so they are taken from the room type
+		if hasFlags {
+			for _, flag := range strings.Split(record[flagsCol], ",") {
+				if flag = strings.TrimSpace(flag); flag != "" {
+					row.Room.Flags = append(row.Room.Flags, flag)
+				}
+			}
+		}
+
+		if hasComments {
+			if comments := strings.TrimSpace(record[commentsCol]); comments != "" {
+				row.Room.Comments = &comments
+			}
+		}
+
+		if hasBlock {
+			if block := strings.TrimSpace(record[blockCol]); block != "" {
+				row.Room.Block = &block
+			}
+		}
+
+		if hasType {
+			if roomType := strings.TrimSpace(record[typeCol]); roomType != "" {
+				row.Room.Type = &roomType
+			}
+		}
+
+		result = append(result, row)
+	}
+
+	return result, nil
+}
+
+// parseRoomsYAML reads rooms from a YAML document in the same format as the export.
+//
+// Fields that cannot be set on import, such as id and occupants, are ignored, so an export can be imported again.
+func parseRoomsYAML(ctx context.Context, body io.Reader) ([]roomservice.RoomImportRow, error) {
+	var document struct {
+		Rooms []modelsv1.RoomCreate `yaml:"rooms"`
+	}
+	if err := yaml.NewDecoder(body).Decode(&document); err != nil && !errors.Is(err, io.EOF) {
+		return nil, common.NewBadRequest(ctx, common.RequestParseFailed, common.Details("invalid yaml provided"), err)
+	}
+
+	result := make([]roomservice.RoomImportRow, 0, len(document.Rooms))
+	for i, room := range document.Rooms {
+		result = append(result, roomservice.RoomImportRow{
+			Row:  i + 1,
+			Room: room,
+		})
+	}
+
+	return result, nil
+}
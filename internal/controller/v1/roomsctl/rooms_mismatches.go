@@ -0,0 +1,24 @@
+package roomsctl
+
+import (
+	"context"
+	"github.com/eurofurence/reg-room-service/internal/application/web"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// ListFlagMismatches lists all room occupants whose group requires room flags their room does not have.
+//
+// Endpoint access only for admin users or api token.
+func (h *Controller) ListFlagMismatches(ctx context.Context, _ *modelsv1.Empty, w http.ResponseWriter) (*modelsv1.FlagMismatchList, error) {
+	return h.svc.FindFlagMismatches(ctx)
+}
+
+func (h *Controller) ListFlagMismatchesRequest(r *http.Request, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	return &modelsv1.Empty{}, nil
+}
+
+func (h *Controller) ListFlagMismatchesResponse(_ context.Context, res *modelsv1.FlagMismatchList, w http.ResponseWriter) error {
+	return web.EncodeWithStatus(http.StatusOK, res, w)
+}
@@ -0,0 +1,114 @@
+package roomsctl
+
+import (
+	"context"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/controller/v1/util"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+type MoveOccupantRequest struct {
+	// RoomID is the uuid of the room the occupant is currently in
+	RoomID string
+	// BadgeNumber is the registration number of the occupant
+	BadgeNumber int64
+	// TargetRoomID is the uuid of the room the occupant moves to
+	TargetRoomID string
+	// Force allows admins to move the occupant even though the target room lacks flags their group requires
+	Force bool
+}
+
+// MoveOccupant moves an occupant to another room in one step.
+//
+// See OpenAPI Spec for further details.
+func (h *Controller) MoveOccupant(ctx context.Context, req *MoveOccupantRequest, _ http.ResponseWriter) (*modelsv1.Empty, error) {
+	err := h.svc.MoveOccupant(ctx, req.RoomID, req.BadgeNumber, req.TargetRoomID, req.Force)
+	return &modelsv1.Empty{}, err
+}
+
+func (h *Controller) MoveOccupantRequest(r *http.Request, _ http.ResponseWriter) (*MoveOccupantRequest, error) {
+	ctx := r.Context()
+
+	roomID, badgeNumber, err := parseRoomIDAndBadgeNumber(r)
+	if err != nil {
+		return nil, err
+	}
+
+	targetRoomID := chi.URLParam(r, "targetuuid")
+	if err := validateRoomID(ctx, targetRoomID); err != nil {
+		return nil, err
+	}
+
+	force, err := parseForce(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MoveOccupantRequest{
+		RoomID:       roomID,
+		BadgeNumber:  badgeNumber,
+		TargetRoomID: targetRoomID,
+		Force:        force,
+	}, nil
+}
+
+func (h *Controller) MoveOccupantResponse(_ context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+type SwapOccupantsRequest struct {
+	// RoomID is the uuid of the room the first occupant is currently in
+	RoomID string
+	// BadgeNumber is the registration number of the first occupant
+	BadgeNumber int64
+	// OtherBadgeNumber is the registration number of the occupant in the other room
+	OtherBadgeNumber int64
+	// Force allows admins to swap the occupants even though a room lacks flags required by an occupant's group
+	Force bool
+}
+
+// SwapOccupants exchanges the rooms of two occupants in one step.
+//
+// See OpenAPI Spec for further details.
+func (h *Controller) SwapOccupants(ctx context.Context, req *SwapOccupantsRequest, _ http.ResponseWriter) (*modelsv1.Empty, error) {
+	err := h.svc.SwapOccupants(ctx, req.RoomID, req.BadgeNumber, req.OtherBadgeNumber, req.Force)
+	return &modelsv1.Empty{}, err
+}
+
+func (h *Controller) SwapOccupantsRequest(r *http.Request, _ http.ResponseWriter) (*SwapOccupantsRequest, error) {
+	ctx := r.Context()
+
+	roomID, badgeNumber, err := parseRoomIDAndBadgeNumber(r)
+	if err != nil {
+		return nil, err
+	}
+
+	otherBadgeNumber, err := util.ParseInt[int64](chi.URLParam(r, "otherbadgenumber"))
+	if err != nil {
+		return nil, common.NewBadRequest(ctx, common.RequestParseFailed, common.Details("invalid badge number - must be positive integer"), err)
+	}
+	if otherBadgeNumber < 1 {
+		return nil, common.NewBadRequest(ctx, common.RoomDataInvalid, common.Details("invalid badge number - must be positive integer"))
+	}
+
+	force, err := parseForce(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SwapOccupantsRequest{
+		RoomID:           roomID,
+		BadgeNumber:      badgeNumber,
+		OtherBadgeNumber: otherBadgeNumber,
+		Force:            force,
+	}, nil
+}
+
+func (h *Controller) SwapOccupantsResponse(_ context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -62,13 +62,17 @@ type AddToRoomRequest struct {
 	RoomID string
 	// BadgeNumber is the registration number of an attendee
 	BadgeNumber int64
+	// Force adds the attendee even if the room lacks flags required by their group (admin only)
+	Force bool
+	// Stay contains the optional arrival and departure dates
+	Stay modelsv1.Stay
 }
 
 // AddToRoom adds an attendee to a room.
 //
 // See OpenAPI Spec for further details.
 func (h *Controller) AddToRoom(ctx context.Context, req *AddToRoomRequest, w http.ResponseWriter) (*modelsv1.Empty, error) {
-	err := h.svc.AddOccupantToRoom(ctx, req.RoomID, req.BadgeNumber)
+	err := h.svc.AddOccupantToRoom(ctx, req.RoomID, req.BadgeNumber, req.Force, &req.Stay)
 	return &modelsv1.Empty{}, err
 }
 
@@ -89,9 +93,19 @@ func (h *Controller) AddToRoomRequest(r *http.Request, w http.ResponseWriter) (*
 		return nil, common.NewBadRequest(ctx, common.RoomDataInvalid, common.Details("invalid badge number - must be positive integer"))
 	}
 
+	force, err := parseForce(r)
+	if err != nil {
+		return nil, err
+	}
+
 	return &AddToRoomRequest{
 		RoomID:      roomID,
 		BadgeNumber: badgeNumber,
+		Force:       force,
+		Stay: modelsv1.Stay{
+			Arrival:   r.URL.Query().Get("arrival"),
+			Departure: r.URL.Query().Get("departure"),
+		},
 	}, nil
 }
 
@@ -58,3 +58,89 @@ func (h *Controller) UpdateRoomResponse(ctx context.Context, res *modelsv1.Empty
 	w.WriteHeader(http.StatusNoContent)
 	return nil
 }
+
+// UpdateOccupantRequest is the request type for the UpdateOccupant operation.
+type UpdateOccupantRequest struct {
+	// RoomID is the uuid of the room
+	RoomID string
+	// BadgeNumber is the registration number of the occupant
+	BadgeNumber int64
+	// Member contains the new membership flags
+	Member modelsv1.MemberUpdate
+}
+
+// UpdateOccupant replaces the membership flags of a room occupant.
+//
+// See OpenAPI Spec for further details.
+func (h *Controller) UpdateOccupant(ctx context.Context, req *UpdateOccupantRequest, _ http.ResponseWriter) (*modelsv1.Empty, error) {
+	err := h.svc.UpdateOccupantFlags(ctx, req.RoomID, req.BadgeNumber, req.Member.Flags)
+	return &modelsv1.Empty{}, err
+}
+
+func (h *Controller) UpdateOccupantRequest(r *http.Request, _ http.ResponseWriter) (*UpdateOccupantRequest, error) {
+	ctx := r.Context()
+
+	roomID, badgeNumber, err := parseRoomIDAndBadgeNumber(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var member modelsv1.MemberUpdate
+	if err := util.NewStrictJSONDecoder(r.Body).Decode(&member); err != nil {
+		return nil, common.NewBadRequest(ctx, common.RoomDataInvalid, common.Details("invalid json provided"))
+	}
+
+	return &UpdateOccupantRequest{
+		RoomID:      roomID,
+		BadgeNumber: badgeNumber,
+		Member:      member,
+	}, nil
+}
+
+func (h *Controller) UpdateOccupantResponse(_ context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// UpdateOccupantStayRequest is the request type for the UpdateOccupantStay operation.
+type UpdateOccupantStayRequest struct {
+	// RoomID is the uuid of the room
+	RoomID string
+	// BadgeNumber is the registration number of the occupant
+	BadgeNumber int64
+	// Stay contains the new arrival and departure dates
+	Stay modelsv1.Stay
+}
+
+// UpdateOccupantStay changes the arrival and departure dates of a room occupant.
+//
+// See OpenAPI Spec for further details.
+func (h *Controller) UpdateOccupantStay(ctx context.Context, req *UpdateOccupantStayRequest, _ http.ResponseWriter) (*modelsv1.Empty, error) {
+	err := h.svc.UpdateOccupantStay(ctx, req.RoomID, req.BadgeNumber, &req.Stay)
+	return &modelsv1.Empty{}, err
+}
+
+func (h *Controller) UpdateOccupantStayRequest(r *http.Request, _ http.ResponseWriter) (*UpdateOccupantStayRequest, error) {
+	ctx := r.Context()
+
+	roomID, badgeNumber, err := parseRoomIDAndBadgeNumber(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var stay modelsv1.Stay
+	if err := util.NewStrictJSONDecoder(r.Body).Decode(&stay); err != nil {
+		return nil, common.NewBadRequest(ctx, common.RoomDataInvalid, common.Details("invalid json provided"))
+	}
+
+	return &UpdateOccupantStayRequest{
+		RoomID:      roomID,
+		BadgeNumber: badgeNumber,
+		Stay:        stay,
+	}, nil
+}
+
+func (h *Controller) UpdateOccupantStayResponse(_ context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
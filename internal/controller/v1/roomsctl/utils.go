@@ -3,7 +3,10 @@ package roomsctl
 import (
 	"context"
 	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/controller/v1/util"
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"net/http"
 )
 
 func validateRoomID(ctx context.Context, roomID string) error {
@@ -13,3 +16,49 @@ func validateRoomID(ctx context.Context, roomID string) error {
 
 	return nil
 }
+
+func parseRoomIDAndBadgeNumber(r *http.Request) (string, int64, error) {
+	ctx := r.Context()
+
+	roomID := chi.URLParam(r, "uuid")
+	if err := validateRoomID(ctx, roomID); err != nil {
+		return "", 0, err
+	}
+
+	badge := chi.URLParam(r, "badgenumber")
+	badgeNumber, err := util.ParseInt[int64](badge)
+	if err != nil {
+		return "", 0, common.NewBadRequest(ctx, common.RequestParseFailed, common.Details("invalid badge number - must be positive integer"), err)
+	}
+	if badgeNumber < 1 {
+		return "", 0, common.NewBadRequest(ctx, common.RoomDataInvalid, common.Details("invalid badge number - must be positive integer"))
+	}
+
+	return roomID, badgeNumber, nil
+}
+
+// parseForce parses the force query parameter, which lets admins override flag requirements.
+func parseForce(r *http.Request) (bool, error) {
+	force, err := util.ParseOptionalBool(r.URL.Query().Get("force"))
+	if err != nil {
+		return false, common.NewBadRequest(r.Context(), common.RequestParseFailed, common.Details("invalid force parameter, try true, 1, false, 0 or omit"), err)
+	}
+	return force, nil
+}
+
+const (
+	formatCSV  = "csv"
+	formatYAML = "yaml"
+)
+
+// parseFormat parses the format query parameter of the room import and export, which defaults to yaml.
+func parseFormat(ctx context.Context, format string) (string, error) {
+	switch format {
+	case "", formatYAML:
+		return formatYAML, nil
+	case formatCSV:
+		return formatCSV, nil
+	default:
+		return "", common.NewBadRequest(ctx, common.RequestParseFailed, common.Details("invalid format parameter, try csv, yaml or omit"))
+	}
+}
@@ -0,0 +1,96 @@
+package roomtypesctl
+
+import (
+	"context"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/application/web"
+	roomtypeservice "github.com/eurofurence/reg-room-service/internal/service/roomtypes"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+// Controller implements methods which satisfy the endpoint format
+// in the `common` package.
+type Controller struct {
+	svc roomtypeservice.Service
+}
+
+func InitRoutes(router chi.Router, svc roomtypeservice.Service) {
+	h := &Controller{
+		svc: svc,
+	}
+
+	router.Route("/api/rest/v1/roomtypes", func(sr chi.Router) {
+		initGetRoutes(sr, h)
+		initPostRoutes(sr, h)
+		initPutRoutes(sr, h)
+		initDeleteRoutes(sr, h)
+	})
+}
+
+func initGetRoutes(router chi.Router, h *Controller) {
+	router.Method(
+		http.MethodGet,
+		"/",
+		web.CreateHandler(
+			h.ListRoomTypes,
+			h.ListRoomTypesRequest,
+			h.ListRoomTypesResponse,
+		),
+	)
+
+	router.Method(
+		http.MethodGet,
+		"/{uuid}",
+		web.CreateHandler(
+			h.GetRoomTypeByID,
+			h.GetRoomTypeByIDRequest,
+			h.GetRoomTypeByIDResponse,
+		),
+	)
+}
+
+func initPostRoutes(router chi.Router, h *Controller) {
+	router.Method(
+		http.MethodPost,
+		"/",
+		web.CreateHandler(
+			h.CreateRoomType,
+			h.CreateRoomTypeRequest,
+			h.CreateRoomTypeResponse,
+		),
+	)
+}
+
+func initPutRoutes(router chi.Router, h *Controller) {
+	router.Method(
+		http.MethodPut,
+		"/{uuid}",
+		web.CreateHandler(
+			h.UpdateRoomType,
+			h.UpdateRoomTypeRequest,
+			h.UpdateRoomTypeResponse,
+		),
+	)
+}
+
+func initDeleteRoutes(router chi.Router, h *Controller) {
+	router.Method(
+		http.MethodDelete,
+		"/{uuid}",
+		web.CreateHandler(
+			h.DeleteRoomType,
+			h.DeleteRoomTypeRequest,
+			h.DeleteRoomTypeResponse,
+		),
+	)
+}
+
+func validateRoomTypeID(ctx context.Context, typeID string) error {
+	if err := uuid.Validate(typeID); err != nil {
+		return common.NewBadRequest(ctx, common.RoomTypeIDInvalid, common.Details("you must specify a valid uuid"), err)
+	}
+
+	return nil
+}
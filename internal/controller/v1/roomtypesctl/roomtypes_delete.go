@@ -0,0 +1,37 @@
+package roomtypesctl
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+type DeleteRoomTypeRequest struct {
+	UUID string
+}
+
+// DeleteRoomType deletes an existing room type by uuid. The room type must not be used by any rooms.
+//
+// See OpenAPI Spec for further details.
+func (h *Controller) DeleteRoomType(ctx context.Context, req *DeleteRoomTypeRequest, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	err := h.svc.DeleteRoomType(ctx, req.UUID)
+	return nil, err
+}
+
+func (h *Controller) DeleteRoomTypeRequest(r *http.Request, w http.ResponseWriter) (*DeleteRoomTypeRequest, error) {
+	typeID := chi.URLParam(r, "uuid")
+	if err := validateRoomTypeID(r.Context(), typeID); err != nil {
+		return nil, err
+	}
+
+	return &DeleteRoomTypeRequest{
+		UUID: typeID,
+	}, nil
+}
+
+func (h *Controller) DeleteRoomTypeResponse(ctx context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
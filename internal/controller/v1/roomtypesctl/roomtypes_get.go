@@ -0,0 +1,60 @@
+package roomtypesctl
+
+import (
+	"context"
+	"github.com/eurofurence/reg-room-service/internal/application/web"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+type ListRoomTypesRequest struct{}
+
+// ListRoomTypes returns all room types. Admin/API key only.
+//
+// See OpenAPI Spec for further details.
+func (h *Controller) ListRoomTypes(ctx context.Context, req *ListRoomTypesRequest, w http.ResponseWriter) (*modelsv1.RoomTypeList, error) {
+	roomTypes, err := h.svc.FindRoomTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &modelsv1.RoomTypeList{
+		RoomTypes: roomTypes,
+	}, nil
+}
+
+func (h *Controller) ListRoomTypesRequest(r *http.Request, w http.ResponseWriter) (*ListRoomTypesRequest, error) {
+	return &ListRoomTypesRequest{}, nil
+}
+
+func (h *Controller) ListRoomTypesResponse(ctx context.Context, res *modelsv1.RoomTypeList, w http.ResponseWriter) error {
+	return web.EncodeWithStatus(http.StatusOK, res, w)
+}
+
+type GetRoomTypeByIDRequest struct {
+	UUID string
+}
+
+// GetRoomTypeByID returns a single room type. Admin/API key only.
+//
+// See OpenAPI Spec for further details.
+func (h *Controller) GetRoomTypeByID(ctx context.Context, req *GetRoomTypeByIDRequest, w http.ResponseWriter) (*modelsv1.RoomType, error) {
+	return h.svc.GetRoomTypeByID(ctx, req.UUID)
+}
+
+func (h *Controller) GetRoomTypeByIDRequest(r *http.Request, w http.ResponseWriter) (*GetRoomTypeByIDRequest, error) {
+	typeID := chi.URLParam(r, "uuid")
+	if err := validateRoomTypeID(r.Context(), typeID); err != nil {
+		return nil, err
+	}
+
+	return &GetRoomTypeByIDRequest{
+		UUID: typeID,
+	}, nil
+}
+
+func (h *Controller) GetRoomTypeByIDResponse(ctx context.Context, res *modelsv1.RoomType, w http.ResponseWriter) error {
+	return web.EncodeWithStatus(http.StatusOK, res, w)
+}
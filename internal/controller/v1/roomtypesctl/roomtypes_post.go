@@ -0,0 +1,55 @@
+package roomtypesctl
+
+import (
+	"context"
+	"errors"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/controller/v1/util"
+	"net/http"
+	"net/url"
+	"path"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+type CreateRoomTypeRequest struct {
+	// RoomType is the expected representation for the request body
+	RoomType modelsv1.RoomTypeCreate
+}
+
+// CreateRoomType creates a new room type.
+//
+// Endpoint access only for admin users or api token.
+//
+// Successful operations return status 201 with a location header that points to the created resource.
+func (h *Controller) CreateRoomType(ctx context.Context, req *CreateRoomTypeRequest, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	newTypeUUID, err := h.svc.CreateRoomType(ctx, &(req.RoomType))
+	if err != nil {
+		return nil, err
+	}
+
+	requestURL, ok := ctx.Value(common.CtxKeyRequestURL{}).(*url.URL)
+	if !ok {
+		return nil, errors.New("could not retrieve base URL from context - this is an implementation error")
+	}
+
+	w.Header().Set("Location", path.Join(requestURL.Path, newTypeUUID))
+	return nil, nil
+}
+
+func (h *Controller) CreateRoomTypeRequest(r *http.Request, w http.ResponseWriter) (*CreateRoomTypeRequest, error) {
+	var roomType modelsv1.RoomTypeCreate
+
+	if err := util.NewStrictJSONDecoder(r.Body).Decode(&roomType); err != nil {
+		return nil, common.NewBadRequest(r.Context(), common.RoomTypeDataInvalid, common.Details("invalid json provided"))
+	}
+
+	return &CreateRoomTypeRequest{
+		RoomType: roomType,
+	}, nil
+}
+
+func (h *Controller) CreateRoomTypeResponse(ctx context.Context, _ *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusCreated)
+	return nil
+}
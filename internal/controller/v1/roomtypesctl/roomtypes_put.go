@@ -0,0 +1,60 @@
+package roomtypesctl
+
+import (
+	"context"
+	"errors"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/controller/v1/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-http-utils/headers"
+	"net/http"
+	"net/url"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// UpdateRoomTypeRequest is the request type for the UpdateRoomType operation.
+type UpdateRoomTypeRequest struct {
+	RoomType modelsv1.RoomType
+}
+
+// UpdateRoomType updates an existing room type by uuid.
+//
+// See OpenAPI Spec for further details.
+func (h *Controller) UpdateRoomType(ctx context.Context, req *UpdateRoomTypeRequest, w http.ResponseWriter) (*modelsv1.Empty, error) {
+	if err := h.svc.UpdateRoomType(ctx, &(req.RoomType)); err != nil {
+		return nil, err
+	}
+
+	reqURL, ok := ctx.Value(common.CtxKeyRequestURL{}).(*url.URL)
+	if !ok {
+		return nil, errors.New("unable to retrieve URL from context - this is an implementation error")
+	}
+
+	w.Header().Set(headers.Location, reqURL.Path)
+
+	return nil, nil
+}
+
+func (h *Controller) UpdateRoomTypeRequest(r *http.Request, w http.ResponseWriter) (*UpdateRoomTypeRequest, error) {
+	ctx := r.Context()
+
+	typeID := chi.URLParam(r, "uuid")
+	if err := validateRoomTypeID(ctx, typeID); err != nil {
+		return nil, err
+	}
+
+	var roomType modelsv1.RoomType
+
+	if err := util.NewStrictJSONDecoder(r.Body).Decode(&roomType); err != nil {
+		return nil, common.NewBadRequest(ctx, common.RoomTypeDataInvalid, common.Details("invalid json provided"))
+	}
+
+	roomType.ID = typeID
+	return &UpdateRoomTypeRequest{RoomType: roomType}, nil
+}
+
+func (h *Controller) UpdateRoomTypeResponse(ctx context.Context, res *modelsv1.Empty, w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -132,3 +132,55 @@ func SliceContains[T comparable](needle T, haystack []T) bool {
 	}
 	return false
 }
+
+// ListParams are the name search, sorting and paging parameters of the list endpoints.
+type ListParams struct {
+	Name       string // case-insensitive substring, empty means no condition
+	SortBy     string // one of the sort keys, defaults to the first
+	Descending bool
+	Offset     uint
+	Limit      uint // 0 means no limit
+}
+
+// ParseListParams is a helper function to parse the query parameters name, sort, order, offset and limit.
+//
+// sortKeys lists the allowed values for sort, the first one is the default.
+func ParseListParams(query url.Values, sortKeys []string, maxLimit uint) (ListParams, error) {
+	params := ListParams{
+		Name:   query.Get("name"),
+		SortBy: sortKeys[0],
+	}
+
+	if sortBy := query.Get("sort"); sortBy != "" {
+		if !SliceContains(sortBy, sortKeys) {
+			return params, fmt.Errorf("sort must be one of %s", strings.Join(sortKeys, ", "))
+		}
+		params.SortBy = sortBy
+	}
+
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		params.Descending = true
+	default:
+		return params, fmt.Errorf("order must be asc or desc")
+	}
+
+	if offset := query.Get("offset"); offset != "" {
+		val, err := ParseUInt[uint](offset)
+		if err != nil {
+			return params, fmt.Errorf("offset must be a non-negative integer")
+		}
+		params.Offset = val
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		val, err := ParseUInt[uint](limit)
+		if err != nil || val < 1 || val > maxLimit {
+			return params, fmt.Errorf("limit must be an integer between 1 and %d", maxLimit)
+		}
+		params.Limit = val
+	}
+
+	return params, nil
+}
@@ -2,9 +2,43 @@ package util
 
 import (
 	"fmt"
+	"net/url"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseInt(t *testing.T) {
 	fmt.Println(ParseUInt[uint8]("277"))
 }
+
+func TestParseListParams(t *testing.T) {
+	sortKeys := []string{"name", "size"}
+
+	params, err := ParseListParams(url.Values{}, sortKeys, 100)
+	require.NoError(t, err)
+	require.Equal(t, ListParams{SortBy: "name"}, params)
+
+	params, err = ParseListParams(url.Values{
+		"name":   {"Kit"},
+		"sort":   {"size"},
+		"order":  {"desc"},
+		"offset": {"20"},
+		"limit":  {"10"},
+	}, sortKeys, 100)
+	require.NoError(t, err)
+	require.Equal(t, ListParams{Name: "Kit", SortBy: "size", Descending: true, Offset: 20, Limit: 10}, params)
+
+	for query, expected := range map[string]string{
+		"sort=color":  "sort must be one of name, size",
+		"order=up":    "order must be asc or desc",
+		"offset=-1":   "offset must be a non-negative integer",
+		"limit=0":     "limit must be an integer between 1 and 100",
+		"limit=101":   "limit must be an integer between 1 and 100",
+		"limit=kitty": "limit must be an integer between 1 and 100",
+	} {
+		values, _ := url.ParseQuery(query)
+		_, err := ParseListParams(values, sortKeys, 100)
+		require.EqualError(t, err, expected, query)
+	}
+}
@@ -0,0 +1,24 @@
+package entity
+
+// Block is a hotel or room contingent. Rooms can belong to a block, which has its own booking code and go-live times.
+type Block struct {
+	Base
+
+	// Name is the name of the block, usually the name of the hotel
+	Name string `gorm:"type:varchar(80) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci;NOT NULL;uniqueIndex:room_block_name_uidx"`
+
+	// Comments are optional, not processed in any way
+	Comments string `gorm:"type:varchar(4096) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci" testdiff:"ignore"`
+
+	// PublicStart is the ISO datetime when the public booking code is revealed
+	PublicStart string `gorm:"type:varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci;NOT NULL"`
+
+	// PublicBookingCode is the secret revealed to everyone at PublicStart
+	PublicBookingCode string `gorm:"type:varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci"`
+
+	// StaffStart is the ISO datetime when the staff booking code is revealed to staff, empty means staff have no early access
+	StaffStart string `gorm:"type:varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci"`
+
+	// StaffBookingCode is the secret revealed to staff at StaffStart
+	StaffBookingCode string `gorm:"type:varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci"`
+}
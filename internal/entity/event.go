@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// ProcessedEvent records an event received from another service, so it is only processed once
+// even if it is delivered multiple times.
+type ProcessedEvent struct {
+	// ID is the event id assigned by the sending service
+	ID        string `gorm:"primaryKey; type:varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci;NOT NULL"`
+	CreatedAt time.Time
+
+	// Kind is the type of event, e.g. attendee-status
+	Kind string `gorm:"type:varchar(80) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci;NOT NULL"`
+
+	// AttendeeID is the badge number of the attendee the event was about
+	AttendeeID int64
+}
+
+// values used in ProcessedEvent.Kind
+const (
+	EventKindAttendeeStatus = "attendee-status"
+)
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// AggregateFlags converts a comma-separated list of flags as stored in the database into a sorted list.
+func AggregateFlags(input string) []string {
+	tags := strings.Split(input, ",")
+	tags = slices.DeleteFunc(tags, func(s string) bool {
+		return s == ""
+	})
+
+	if len(tags) == 0 {
+		return make([]string, 0)
+	}
+
+	slices.Sort(tags)
+	return tags
+}
+
+// CollectFlags converts a list of flags into the comma-separated form stored in the database,
+// with both leading and trailing comma.
+func CollectFlags(input []string) string {
+	if len(input) == 0 {
+		return ","
+	}
+	return fmt.Sprintf(",%s,", strings.Join(input, ","))
+}
+
+// PeakOccupancy is the highest number of occupants staying in a room on any single night.
+//
+// stayNights lists the nights of a stay, see config.ServiceConfig.StayNights. If it returns nil,
+// because the convention dates are not configured, everyone stays for the whole convention.
+func PeakOccupancy(occupants []*RoomMember, stayNights func(arrival string, departure string) []string) int {
+	if stayNights("", "") == nil {
+		return len(occupants)
+	}
+
+	occupied := make(map[string]int)
+	peak := 0
+	for _, occupant := range occupants {
+		for _, night := range stayNights(occupant.ArrivalDate, occupant.DepartureDate) {
+			occupied[night]++
+			peak = max(peak, occupied[night])
+		}
+	}
+	return peak
+}
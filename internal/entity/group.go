@@ -23,6 +23,14 @@ type Group struct {
 
 	// Owner is the badge number (attendee ID) of the attendee owning the group. Ownership can be passed to another attendee.
 	Owner int64
+
+	// PendingOwner is the badge number of the member to whom the owner has offered ownership of the group, or 0 if
+	// there is no pending ownership transfer. Ownership only changes when this member accepts.
+	PendingOwner int64
+
+	// PendingMerge is the id of the group the owner has asked to merge this group into, or empty if there is no
+	// pending merge request. The merge only happens when the owner of that group agrees.
+	PendingMerge string `gorm:"type:varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci;NOT NULL;default:''"`
 }
 
 // GroupMember associates attendees to a group, either as a member or as an invited member.
@@ -40,6 +48,10 @@ type GroupMember struct {
 	// Invitation code is generated internally, only used for group invitations that were initiated by inviting an attendee
 	InvitationCode string `gorm:"type:varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci"`
 
+	// InvitedAt is the time the invitation was last sent, only set for invitations with an invitation code.
+	// Invitations expire a configurable time after this.
+	InvitedAt *time.Time
+
 	// Comments are optional, not processed in any way
 	Comments string `gorm:"type:varchar(4096) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci" testdiff:"ignore"`
 }
@@ -61,3 +73,29 @@ type GroupBan struct {
 	// Comments are optional, not processed in any way
 	Comments string `gorm:"type:varchar(4096) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci" testdiff:"ignore"`
 }
+
+// GroupWish records what kind of room a group would like to be assigned to. There is at most one per group.
+//
+// Wishes are preferences only. Nothing prevents assigning a group to a room that does not fulfil them.
+type GroupWish struct {
+	// GroupID references the group that has these wishes
+	GroupID   string `gorm:"primaryKey;type:varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	// TypeID references the wished room type, or is empty if the group has no preference
+	TypeID string `gorm:"type:varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci;NOT NULL;default:''"`
+
+	// Size is the wished minimum number of beds, or 0 if the group has no preference
+	Size int64
+
+	// RoomFlags is a comma-separated list of wished room flags such as a floor, with both leading and trailing comma
+	RoomFlags string `gorm:"type:varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci"`
+
+	// NearGroups is a comma-separated list of the IDs of groups whose rooms should be in the same block,
+	// with both leading and trailing comma
+	NearGroups string `gorm:"type:varchar(1024) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci"`
+
+	// Comments are optional, not processed in any way
+	Comments string `gorm:"type:varchar(4096) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci" testdiff:"ignore"`
+}
@@ -11,3 +11,16 @@ type History struct {
 	Identity  string `gorm:"type:varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci;NOT NULL;index:att_histories_identity_idx"` // the subject that triggered the change
 	Diff      string `gorm:"type:text CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci"`
 }
+
+// values used in History.Entity
+const (
+	HistoryEntityGroup          = "Group"
+	HistoryEntityGroupMember    = "GroupMember"
+	HistoryEntityGroupBan       = "GroupBan"
+	HistoryEntityGroupWish      = "GroupWish"
+	HistoryEntityRoom           = "Room"
+	HistoryEntityRoomMember     = "RoomMember"
+	HistoryEntityBlock          = "Block"
+	HistoryEntityRoomType       = "RoomType"
+	HistoryEntityProcessedEvent = "ProcessedEvent"
+)
@@ -1,10 +1,22 @@
 package entity
 
+import "time"
+
 type Room struct {
 	Base
 
-	// Name is the name of the room
-	Name string `gorm:"type:varchar(80) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci;NOT NULL;uniqueIndex:room_room_name_uidx"`
+	// BlockID references the block (hotel or room contingent) the room belongs to, empty means no block
+	//
+	// Note: no foreign key constraint, because rooms without a block are allowed. Blocks with rooms cannot be deleted.
+	BlockID string `gorm:"type:varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci;NOT NULL;default:'';uniqueIndex:room_room_block_name_uidx,priority:1"`
+
+	// TypeID references the room type the room was created from, empty means no room type
+	//
+	// Note: no foreign key constraint, because rooms without a type are allowed. Room types with rooms cannot be deleted.
+	TypeID string `gorm:"type:varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci;NOT NULL;default:'';index:room_room_typeid"`
+
+	// Name is the name of the room, unique within its block
+	Name string `gorm:"type:varchar(80) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci;NOT NULL;uniqueIndex:room_room_block_name_uidx,priority:2"`
 
 	// Flags is a comma-separated list of flags, with both leading and trailing comma. The allowed flags are configuration dependent
 	Flags string `gorm:"type:varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci"`
@@ -23,4 +35,16 @@ type RoomMember struct {
 	//
 	// Note: foreign key constraint added programmatically in MysqlRepository.Migrate()
 	RoomID string `gorm:"type:varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci;NOT NULL;index:room_room_member_roomid"`
+
+	// ArrivalDate is the ISO date of the first night the attendee stays in the room, empty means the start of the convention
+	ArrivalDate string `gorm:"type:varchar(10) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci"`
+
+	// DepartureDate is the ISO date the attendee leaves the room, empty means the end of the convention
+	DepartureDate string `gorm:"type:varchar(10) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci"`
+
+	// CheckedInAt is the time the attendee arrived at the hotel, or nil if they have not checked in yet
+	CheckedInAt *time.Time
+
+	// CheckedOutAt is the time the attendee left the hotel, or nil if they have not checked out yet
+	CheckedOutAt *time.Time
 }
@@ -0,0 +1,22 @@
+package entity
+
+// RoomType is a catalog entry describing a kind of room, such as "double standard". Rooms can reference a room type,
+// which supplies default values for the room's size, flags and comments.
+type RoomType struct {
+	Base
+
+	// Name is the name of the room type
+	Name string `gorm:"type:varchar(80) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci;NOT NULL;uniqueIndex:room_room_type_name_uidx"`
+
+	// Flags is the default for Room.Flags, a comma-separated list of flags, with both leading and trailing comma
+	Flags string `gorm:"type:varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci"`
+
+	// Comments is the default for Room.Comments
+	Comments string `gorm:"type:varchar(4096) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci" testdiff:"ignore"`
+
+	// Size is the default for Room.Size
+	Size int64
+
+	// PriceCategory is an optional free-form price category, not processed in any way
+	PriceCategory string `gorm:"type:varchar(80) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci"`
+}
@@ -4,6 +4,10 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -11,8 +15,9 @@ import (
 var appConfig *Config
 
 type (
-	DatabaseType string
-	LogStyle     string
+	DatabaseType     string
+	LogStyle         string
+	FlagMismatchMode string
 )
 
 const (
@@ -21,6 +26,15 @@ const (
 
 	Plain LogStyle = "plain"
 	ECS   LogStyle = "ecs" // default
+
+	FlagMismatchWarn   FlagMismatchMode = "warn" // default
+	FlagMismatchRefuse FlagMismatchMode = "refuse"
+
+	// IsoDateTimeFormat is the format of the deprecated go_live start times
+	IsoDateTimeFormat = "2006-01-02T15:04:05-07:00"
+
+	// IsoDateFormat is the format of all configured dates, such as ConventionStart
+	IsoDateFormat = "2006-01-02"
 )
 
 type (
@@ -32,19 +46,34 @@ type (
 		Database DatabaseConfig `yaml:"database"`
 		Security SecurityConfig `yaml:"security"`
 		Logging  LoggingConfig  `yaml:"logging"`
-		GoLive   GoLiveConfig   `yaml:"go_live"`
+
+		// Deprecated: GoLive is only read for existing configurations. Set up blocks
+		// via the api instead, and configure the staff group as security.oidc.staff_group.
+		GoLive GoLiveConfig `yaml:"go_live"`
 	}
 
 	// ServiceConfig contains configuration values
 	// for service related tasks. E.g. URL to attendee service.
 	ServiceConfig struct {
-		AttendeeServiceURL string   `yaml:"attendee_service_url"`
-		MailServiceURL     string   `yaml:"mail_service_url"`
-		AuthServiceURL     string   `yaml:"auth_service_url"`
-		JoinLinkBaseURL    string   `yaml:"join_link_base_url"`
-		MaxGroupSize       int64    `yaml:"max_group_size"`
-		GroupFlags         []string `yaml:"group_flags"`
-		RoomFlags          []string `yaml:"room_flags"`
+		AttendeeServiceURL             string              `yaml:"attendee_service_url"`
+		MailServiceURL                 string              `yaml:"mail_service_url"`
+		AuthServiceURL                 string              `yaml:"auth_service_url"`
+		JoinLinkBaseURL                string              `yaml:"join_link_base_url"`
+		MaxGroupSize                   int64               `yaml:"max_group_size"`
+		GroupFlags                     []string            `yaml:"group_flags"`
+		RoomFlags                      []string            `yaml:"room_flags"`
+		MemberFlags                    []string            `yaml:"member_flags"`                      // flags for individual group members and room occupants, e.g. early-arrival
+		ConventionStart                string              `yaml:"convention_start"`                  // ISO date of the first night, occupants are expected to arrive on this day
+		ConventionEnd                  string              `yaml:"convention_end"`                    // ISO date of the departure day after the last night, enables per-night occupancy together with ConventionStart
+		InvitationLifetimeHours        int64               `yaml:"invitation_lifetime_hours"`         // 0 means invitations do not expire
+		InvitationSweepIntervalMinutes int64               `yaml:"invitation_sweep_interval_minutes"` // how often expired invitations are deleted
+		OwnerCheckIntervalMinutes      int64               `yaml:"owner_check_interval_minutes"`      // how often group owners are checked for cancelled registrations
+		ReconcileRemoveNonAttending    bool                `yaml:"reconcile_remove_non_attending"`    // whether reconciliation and attendee status events may remove attendees who are no longer attending
+		AvatarURLTemplate              string              `yaml:"avatar_url_template"`               // url of the avatar image of an attendee, {id} is replaced by the badge number, empty means no avatars
+		MemberRefreshIntervalMinutes   int64               `yaml:"member_refresh_interval_minutes"`   // how often cached nicknames and avatars are refreshed from the attendee service
+		MemberRefreshMaxAgeHours       int64               `yaml:"member_refresh_max_age_hours"`      // the periodic refresh only reads attendees who were last read longer ago than this, 0 means everyone
+		FlagRequirements               map[string][]string `yaml:"flag_requirements"`                 // group flag -> room flags that a room must have for members of groups with this flag
+		FlagMismatchMode               FlagMismatchMode    `yaml:"flag_mismatch_mode"`                // what happens when adding an occupant to a room that lacks flags their group requires
 	}
 
 	// ServerConfig contains all values for
@@ -83,6 +112,7 @@ type (
 		AccessTokenCookieName string   `yaml:"access_token_cookie_name"` // optional, but must both be set, then tokens are read from cookies
 		TokenPublicKeysPEM    []string `yaml:"token_public_keys_PEM"`    // a list of public RSA keys in PEM format, see https://github.com/Jumpy-Squirrel/jwks2pem for obtaining PEM from openid keyset endpoint
 		AdminGroup            string   `yaml:"admin_group"`              // the group claim that supplies admin rights
+		StaffGroup            string   `yaml:"staff_group"`              // the group claim that allows early access to block booking codes
 		AuthService           string   `yaml:"auth_service"`             // base url, usually http://localhost:nnnn, will skip userinfo checks if unset
 		Audience              string   `yaml:"audience"`
 		Issuer                string   `yaml:"issuer"`
@@ -93,12 +123,9 @@ type (
 		AllowOrigin string `yaml:"allow_origin"`
 	}
 
-	// LoggingConfig configures logging.
-	LoggingConfig struct {
-		Style    LogStyle `yaml:"style"`
-		Severity string   `yaml:"severity"`
-	}
-
+	// GoLiveConfig is the deprecated configuration of the countdown from before blocks existed.
+	//
+	// If set, it acts as an implicit block for the countdown as long as no blocks have been set up.
 	GoLiveConfig struct {
 		Public GoLiveConfigPerGroup `yaml:"public"`
 		Staff  GoLiveConfigPerGroup `yaml:"staff"`
@@ -107,7 +134,13 @@ type (
 	GoLiveConfigPerGroup struct {
 		StartISODatetime string `yaml:"start_iso_datetime"`
 		BookingCode      string `yaml:"booking_code"`
-		Group            string `yaml:"group"`
+		Group            string `yaml:"group"` // staff only, used if security.oidc.staff_group is not set
+	}
+
+	// LoggingConfig configures logging.
+	LoggingConfig struct {
+		Style    LogStyle `yaml:"style"`
+		Severity string   `yaml:"severity"`
 	}
 )
 
@@ -135,6 +168,53 @@ func UnmarshalFromYamlConfiguration(configPath string) (*Config, error) {
 	return &conf, nil
 }
 
+// AvatarURL returns the avatar url for the given badge number according to AvatarURLTemplate,
+// or the empty string if no template is configured.
+func (c ServiceConfig) AvatarURL(badgeNo int64) string {
+	if c.AvatarURLTemplate == "" {
+		return ""
+	}
+	return strings.ReplaceAll(c.AvatarURLTemplate, "{id}", strconv.FormatInt(badgeNo, 10))
+}
+
+// RequiredRoomFlags returns the room flags that FlagRequirements demands for a group with the given flags,
+// sorted and without duplicates.
+func (c ServiceConfig) RequiredRoomFlags(groupFlags []string) []string {
+	result := make([]string, 0)
+	for _, groupFlag := range groupFlags {
+		for _, roomFlag := range c.FlagRequirements[groupFlag] {
+			if !slices.Contains(result, roomFlag) {
+				result = append(result, roomFlag)
+			}
+		}
+	}
+	slices.Sort(result)
+	return result
+}
+
+// RequiredRoomFlags returns the room flags that the loaded configuration demands for a group with the given flags.
+func RequiredRoomFlags(groupFlags []string) []string {
+	return loadedServiceConfig("RequiredRoomFlags").RequiredRoomFlags(groupFlags)
+}
+
+// FlagMismatchRefused is true if the loaded configuration refuses to place groups in rooms that lack required flags,
+// rather than just warning about it.
+func FlagMismatchRefused() bool {
+	return loadedServiceConfig("FlagMismatchRefused").FlagMismatchMode == FlagMismatchRefuse
+}
+
+// StayNights lists the nights of a stay according to the loaded configuration, see ServiceConfig.StayNights.
+func StayNights(arrival string, departure string) []string {
+	return loadedServiceConfig("StayNights").StayNights(arrival, departure)
+}
+
+func loadedServiceConfig(caller string) ServiceConfig {
+	if appConfig == nil {
+		panic("configuration not loaded before call to " + caller + "() - this is a bug")
+	}
+	return appConfig.Service
+}
+
 func GetApplicationConfig() (*Config, error) {
 	if appConfig == nil {
 		return nil, errors.New("config was not yet loaded")
@@ -142,3 +222,38 @@ func GetApplicationConfig() (*Config, error) {
 
 	return appConfig, nil
 }
+
+// StayNights lists the nights of a stay as ISO dates, from arrival up to the night before departure.
+//
+// Empty arrival or departure default to ConventionStart and ConventionEnd. Returns nil if
+// the convention dates are not configured, which means every occupant stays for the whole convention.
+func (c ServiceConfig) StayNights(arrival string, departure string) []string {
+	if c.ConventionStart == "" || c.ConventionEnd == "" {
+		return nil
+	}
+	if arrival == "" {
+		arrival = c.ConventionStart
+	}
+	if departure == "" {
+		departure = c.ConventionEnd
+	}
+
+	result := make([]string, 0)
+	from, err := time.Parse(IsoDateFormat, arrival)
+	if err != nil {
+		return result
+	}
+	to, err := time.Parse(IsoDateFormat, departure)
+	if err != nil {
+		return result
+	}
+	for night := from; night.Before(to); night = night.AddDate(0, 0, 1) {
+		result = append(result, night.Format(IsoDateFormat))
+	}
+	return result
+}
+
+// ConventionNights lists all nights of the convention as ISO dates, or nil if the convention dates are not configured.
+func (c ServiceConfig) ConventionNights() []string {
+	return c.StayNights("", "")
+}
@@ -13,4 +13,19 @@ func (c *Config) AddDefaults() {
 	if c.Server.WriteTimeout <= 0 {
 		c.Server.WriteTimeout = 30
 	}
+	if c.Service.InvitationSweepIntervalMinutes <= 0 {
+		c.Service.InvitationSweepIntervalMinutes = 60
+	}
+	if c.Service.OwnerCheckIntervalMinutes <= 0 {
+		c.Service.OwnerCheckIntervalMinutes = 60
+	}
+	if c.Service.MemberRefreshIntervalMinutes <= 0 {
+		c.Service.MemberRefreshIntervalMinutes = 360
+	}
+	if c.Service.MemberRefreshMaxAgeHours <= 0 {
+		c.Service.MemberRefreshMaxAgeHours = 24
+	}
+	if c.Service.FlagMismatchMode == "" {
+		c.Service.FlagMismatchMode = FlagMismatchWarn
+	}
 }
@@ -3,6 +3,9 @@ package config
 import (
 	"errors"
 	aulogging "github.com/StephanHCB/go-autumn-logging"
+	"slices"
+	"strings"
+	"time"
 )
 
 func (c *Config) Validate() error {
@@ -18,6 +21,63 @@ func (c *Config) Validate() error {
 		ok = false
 	}
 
+	if c.Service.InvitationLifetimeHours < 0 {
+		aulogging.Logger.NoCtx().Warn().Printf("service.invitation_lifetime_hours cannot be negative, use 0 for invitations that do not expire")
+		ok = false
+	}
+
+	if c.Service.AvatarURLTemplate != "" && !strings.Contains(c.Service.AvatarURLTemplate, "{id}") {
+		aulogging.Logger.NoCtx().Warn().Printf("service.avatar_url_template must contain {id}, which is replaced by the badge number")
+		ok = false
+	}
+
+	for groupFlag, roomFlags := range c.Service.FlagRequirements {
+		if !slices.Contains(c.Service.GroupFlags, groupFlag) {
+			aulogging.Logger.NoCtx().Warn().Printf("service.flag_requirements refers to group flag %s, which is not in service.group_flags", groupFlag)
+			ok = false
+		}
+		for _, roomFlag := range roomFlags {
+			if !slices.Contains(c.Service.RoomFlags, roomFlag) {
+				aulogging.Logger.NoCtx().Warn().Printf("service.flag_requirements refers to room flag %s, which is not in service.room_flags", roomFlag)
+				ok = false
+			}
+		}
+	}
+
+	if c.Service.FlagMismatchMode != FlagMismatchWarn && c.Service.FlagMismatchMode != FlagMismatchRefuse {
+		aulogging.Logger.NoCtx().Warn().Printf("service.flag_mismatch_mode must be warn or refuse")
+		ok = false
+	}
+
+	if c.Service.ConventionStart != "" {
+		if _, err := time.Parse(IsoDateFormat, c.Service.ConventionStart); err != nil {
+			aulogging.Logger.NoCtx().Warn().Printf("service.convention_start must be an ISO date such as 2024-09-18")
+			ok = false
+		}
+	}
+
+	if c.Service.ConventionEnd != "" {
+		if _, err := time.Parse(IsoDateFormat, c.Service.ConventionEnd); err != nil {
+			aulogging.Logger.NoCtx().Warn().Printf("service.convention_end must be an ISO date such as 2024-09-22")
+			ok = false
+		} else if c.Service.ConventionStart == "" || c.Service.ConventionEnd <= c.Service.ConventionStart {
+			aulogging.Logger.NoCtx().Warn().Printf("service.convention_end requires service.convention_start and must be after it")
+			ok = false
+		}
+	}
+
+	if c.GoLive != (GoLiveConfig{}) {
+		aulogging.Logger.NoCtx().Warn().Printf("go_live is deprecated and only used for the countdown until the first block is set up - set up blocks via /api/rest/v1/blocks and move go_live.staff.group to security.oidc.staff_group")
+		if _, err := time.Parse(IsoDateTimeFormat, c.GoLive.Public.StartISODatetime); c.GoLive.Public.StartISODatetime != "" && err != nil {
+			aulogging.Logger.NoCtx().Warn().Printf("go_live.public.start_iso_datetime must be an ISO datetime such as 2024-09-18T12:00:00+02:00")
+			ok = false
+		}
+		if _, err := time.Parse(IsoDateTimeFormat, c.GoLive.Staff.StartISODatetime); c.GoLive.Staff.StartISODatetime != "" && err != nil {
+			aulogging.Logger.NoCtx().Warn().Printf("go_live.staff.start_iso_datetime must be an ISO datetime such as 2024-09-18T12:00:00+02:00")
+			ok = false
+		}
+	}
+
 	// TODO more validation
 
 	if ok {
@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"github.com/d4l3k/messagediff"
 	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"gorm.io/gorm"
+	"slices"
+	"time"
 
 	"github.com/eurofurence/reg-room-service/internal/entity"
 	"github.com/eurofurence/reg-room-service/internal/repository/database"
@@ -31,24 +34,44 @@ func (r *HistorizingRepository) Migrate(ctx context.Context) error {
 	return r.wrappedRepository.Migrate(ctx)
 }
 
+func (r *HistorizingRepository) Transaction(ctx context.Context, fn func(tx database.Repository) error) error {
+	return r.wrappedRepository.Transaction(ctx, func(tx database.Repository) error {
+		// history entries are written inside the same transaction
+		return fn(&HistorizingRepository{wrappedRepository: tx})
+	})
+}
+
 type entityType string
 
 const (
-	typeGroup       entityType = "Group"
-	typeGroupMember entityType = "GroupMember"
-	typeGroupBan    entityType = "GroupBan"
-	typeRoom        entityType = "Room"
-	typeRoomMember  entityType = "RoomMember"
+	typeGroup       entityType = entity.HistoryEntityGroup
+	typeGroupMember entityType = entity.HistoryEntityGroupMember
+	typeGroupBan    entityType = entity.HistoryEntityGroupBan
+	typeGroupWish   entityType = entity.HistoryEntityGroupWish
+	typeRoom        entityType = entity.HistoryEntityRoom
+	typeRoomMember  entityType = entity.HistoryEntityRoomMember
+	typeBlock       entityType = entity.HistoryEntityBlock
+	typeRoomType    entityType = entity.HistoryEntityRoomType
+	typeEvent       entityType = entity.HistoryEntityProcessedEvent
 )
 
 type operationType string
 
 const (
-	opAdd    operationType = "add"
-	opUpdate operationType = "update"
-	opDelete operationType = "delete"
+	opAdd      operationType = "add"
+	opUpdate   operationType = "update"
+	opDelete   operationType = "delete"
+	opUndelete operationType = "undelete"
+	opMove     operationType = "move"
 )
 
+// roomMoves is historized for moves of room occupants, mapping the badge number of each occupant
+// to the room they moved from and the room they moved to.
+type roomMoves struct {
+	From map[int64]string
+	To   map[int64]string
+}
+
 // group
 
 func (r *HistorizingRepository) GetGroups(ctx context.Context) ([]*entity.Group, error) {
@@ -56,11 +79,24 @@ func (r *HistorizingRepository) GetGroups(ctx context.Context) ([]*entity.Group,
 }
 
 func (r *HistorizingRepository) AddGroup(ctx context.Context, group *entity.Group) (string, error) {
-	return r.wrappedRepository.AddGroup(ctx, group)
+	// the id is only assigned when adding, so the history entry must be written afterwards
+	id, err := r.wrappedRepository.AddGroup(ctx, group)
+	if err != nil {
+		return id, err
+	}
+
+	initialVersion := *group
+	initialVersion.ID = id
+	hideTimes(&initialVersion.Base)
+
+	// diff against the empty value so the initial state is printed in the diff
+	histEntry := diffReverse(ctx, &initialVersion, &entity.Group{}, typeGroup, id, opAdd)
+
+	return id, r.wrappedRepository.RecordHistory(ctx, histEntry)
 }
 
-func (r *HistorizingRepository) FindGroups(ctx context.Context, name string, minOccupancy uint, maxOccupancy int, anyOfMemberID []int64) ([]string, error) {
-	return r.wrappedRepository.FindGroups(ctx, name, minOccupancy, maxOccupancy, anyOfMemberID)
+func (r *HistorizingRepository) FindGroups(ctx context.Context, criteria database.FindGroupCriteria, opts *database.FindOptions) ([]string, int64, error) {
+	return r.wrappedRepository.FindGroups(ctx, criteria, opts)
 }
 
 func (r *HistorizingRepository) UpdateGroup(ctx context.Context, group *entity.Group) error {
@@ -87,6 +123,10 @@ func (r *HistorizingRepository) GetGroupByID(ctx context.Context, id string) (*e
 	return r.wrappedRepository.GetGroupByID(ctx, id)
 }
 
+func (r *HistorizingRepository) LockGroupByID(ctx context.Context, id string) (*entity.Group, error) {
+	return r.wrappedRepository.LockGroupByID(ctx, id)
+}
+
 func (r *HistorizingRepository) DeleteGroupByID(ctx context.Context, id string) error {
 	oldVersion, err := r.wrappedRepository.GetGroupByID(ctx, id)
 	if err != nil {
@@ -104,6 +144,23 @@ func (r *HistorizingRepository) DeleteGroupByID(ctx context.Context, id string)
 	return r.wrappedRepository.DeleteGroupByID(ctx, id)
 }
 
+func (r *HistorizingRepository) UndeleteGroupByID(ctx context.Context, id string) error {
+	if err := r.wrappedRepository.UndeleteGroupByID(ctx, id); err != nil {
+		return err
+	}
+
+	restoredVersion, err := r.wrappedRepository.GetGroupByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	hideTimes(&restoredVersion.Base)
+
+	// like for add, the full restored state is printed in the diff
+	histEntry := diffReverse(ctx, restoredVersion, &entity.Group{}, typeGroup, id, opUndelete)
+
+	return r.wrappedRepository.RecordHistory(ctx, histEntry)
+}
+
 // group members
 
 func (r *HistorizingRepository) NewEmptyGroupMembership(ctx context.Context, groupID string, attendeeID int64, nickname string) *entity.GroupMember {
@@ -119,6 +176,14 @@ func (r *HistorizingRepository) GetGroupMembersByGroupID(ctx context.Context, gr
 }
 
 func (r *HistorizingRepository) AddGroupMembership(ctx context.Context, gm *entity.GroupMember) error {
+	// diff against the empty value so the initial state is printed in the diff.
+	// Like for all entities, the comments are left out, notes such as forced additions stay in the database.
+	histEntry := diffReverse(ctx, gm, &entity.GroupMember{}, typeGroupMember, fmt.Sprintf("%d", gm.ID), opAdd)
+
+	if err := r.recordMembershipHistory(ctx, histEntry, gm.GroupID); err != nil {
+		return err
+	}
+
 	return r.wrappedRepository.AddGroupMembership(ctx, gm)
 }
 
@@ -132,9 +197,13 @@ func (r *HistorizingRepository) UpdateGroupMembership(ctx context.Context, gm *e
 	oldVersion.CreatedAt = gm.CreatedAt
 	oldVersion.UpdatedAt = gm.UpdatedAt
 
-	histEntry := diffReverse(ctx, oldVersion, gm, typeGroupMember, fmt.Sprintf("%d", gm.ID), opUpdate)
+	// always print the badge number, so the entry in the group history shows who was changed
+	newVersion := *gm
+	newVersion.ID = 0
 
-	err = r.wrappedRepository.RecordHistory(ctx, histEntry)
+	histEntry := diffReverse(ctx, oldVersion, &newVersion, typeGroupMember, fmt.Sprintf("%d", gm.ID), opUpdate)
+
+	err = r.recordMembershipHistory(ctx, histEntry, oldVersion.GroupID, gm.GroupID)
 	if err != nil {
 		return err
 	}
@@ -152,25 +221,81 @@ func (r *HistorizingRepository) DeleteGroupMembership(ctx context.Context, atten
 
 	histEntry := diffReverse(ctx, oldVersion, newVersion, typeGroupMember, fmt.Sprintf("%d", attendeeID), opDelete)
 
-	if err := r.wrappedRepository.RecordHistory(ctx, histEntry); err != nil {
+	if err := r.recordMembershipHistory(ctx, histEntry, oldVersion.GroupID); err != nil {
 		return err
 	}
 
 	return r.wrappedRepository.DeleteGroupMembership(ctx, attendeeID)
 }
 
+func (r *HistorizingRepository) MoveGroupMemberships(ctx context.Context, targetGroupID string, attendeeIDs []int64) error {
+	histEntries := make([]*entity.History, 0, len(attendeeIDs)+2)
+	previousGroupIDs := make(map[int64]string)
+	groupIDs := []string{targetGroupID}
+	for _, id := range attendeeIDs {
+		oldVersion, err := r.wrappedRepository.GetGroupMembershipByAttendeeID(ctx, id)
+		if err != nil {
+			return err
+		}
+		previousGroupIDs[id] = oldVersion.GroupID
+		groupIDs = append(groupIDs, oldVersion.GroupID)
+
+		newVersion := *oldVersion
+		newVersion.GroupID = targetGroupID
+		histEntries = append(histEntries, diffReverse(ctx, oldVersion, &newVersion, typeGroupMember, fmt.Sprintf("%d", id), opMove))
+	}
+
+	// the same entry for each group involved, so the history of every group shows who moved where
+	slices.Sort(groupIDs)
+	for _, groupID := range slices.Compact(groupIDs) {
+		histEntries = append(histEntries, diffReverse(ctx, &previousGroupIDs, &map[int64]string{}, typeGroup, groupID, opMove))
+	}
+
+	for _, histEntry := range histEntries {
+		if err := r.wrappedRepository.RecordHistory(ctx, histEntry); err != nil {
+			return err
+		}
+	}
+
+	return r.wrappedRepository.MoveGroupMemberships(ctx, targetGroupID, attendeeIDs)
+}
+
 // group bans
 
 func (r *HistorizingRepository) HasGroupBan(ctx context.Context, groupID string, attendeeID int64) (bool, error) {
 	return r.wrappedRepository.HasGroupBan(ctx, groupID, attendeeID)
 }
 
+func (r *HistorizingRepository) GetGroupBans(ctx context.Context, groupID string) ([]*entity.GroupBan, error) {
+	return r.wrappedRepository.GetGroupBans(ctx, groupID)
+}
+
 func (r *HistorizingRepository) AddGroupBan(ctx context.Context, groupID string, attendeeID int64, comments string) error {
+	ban := &entity.GroupBan{
+		ID:       attendeeID,
+		GroupID:  groupID,
+		Comments: comments,
+	}
+
+	// keyed by the group so bans show up in its history, the badge number is in the diff.
+	// Diff against the empty value so the initial state is printed in the diff
+	histEntry := diffReverse(ctx, ban, &entity.GroupBan{}, typeGroupBan, groupID, opAdd)
+
+	if err := r.wrappedRepository.RecordHistory(ctx, histEntry); err != nil {
+		return err
+	}
+
 	return r.wrappedRepository.AddGroupBan(ctx, groupID, attendeeID, comments)
 }
 
 func (r *HistorizingRepository) RemoveGroupBan(ctx context.Context, groupID string, attendeeID int64) error {
-	histEntry := noDiffRecord(ctx, typeGroupBan, fmt.Sprintf("%s-%d", groupID, attendeeID), opDelete)
+	oldVersion := &entity.GroupBan{
+		ID:      attendeeID,
+		GroupID: groupID,
+	}
+
+	// keyed by the group like when adding, so the badge number is printed in the diff
+	histEntry := diffReverse(ctx, oldVersion, &entity.GroupBan{}, typeGroupBan, groupID, opDelete)
 
 	if err := r.wrappedRepository.RecordHistory(ctx, histEntry); err != nil {
 		return err
@@ -178,10 +303,186 @@ func (r *HistorizingRepository) RemoveGroupBan(ctx context.Context, groupID stri
 	return r.wrappedRepository.RemoveGroupBan(ctx, groupID, attendeeID)
 }
 
+// group wishes
+
+func (r *HistorizingRepository) GetGroupWish(ctx context.Context, groupID string) (*entity.GroupWish, error) {
+	return r.wrappedRepository.GetGroupWish(ctx, groupID)
+}
+
+func (r *HistorizingRepository) GetGroupWishes(ctx context.Context) ([]*entity.GroupWish, error) {
+	return r.wrappedRepository.GetGroupWishes(ctx)
+}
+
+func (r *HistorizingRepository) SetGroupWish(ctx context.Context, wish *entity.GroupWish) error {
+	var histEntry *entity.History
+	oldVersion, err := r.wrappedRepository.GetGroupWish(ctx, wish.GroupID)
+	if err == nil {
+		// hide always present diff in times
+		oldVersion.CreatedAt = wish.CreatedAt
+		oldVersion.UpdatedAt = wish.UpdatedAt
+
+		histEntry = diffReverse(ctx, oldVersion, wish, typeGroupWish, wish.GroupID, opUpdate)
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		initialVersion := *wish
+		initialVersion.CreatedAt = time.Time{}
+		initialVersion.UpdatedAt = time.Time{}
+
+		// diff against the empty value so the initial state is printed in the diff
+		histEntry = diffReverse(ctx, &initialVersion, &entity.GroupWish{}, typeGroupWish, wish.GroupID, opAdd)
+	} else {
+		return err
+	}
+
+	if err := r.wrappedRepository.RecordHistory(ctx, histEntry); err != nil {
+		return err
+	}
+
+	return r.wrappedRepository.SetGroupWish(ctx, wish)
+}
+
+func (r *HistorizingRepository) DeleteGroupWish(ctx context.Context, groupID string) error {
+	oldVersion, err := r.wrappedRepository.GetGroupWish(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	histEntry := diffReverse(ctx, oldVersion, &entity.GroupWish{}, typeGroupWish, groupID, opDelete)
+
+	if err := r.wrappedRepository.RecordHistory(ctx, histEntry); err != nil {
+		return err
+	}
+
+	return r.wrappedRepository.DeleteGroupWish(ctx, groupID)
+}
+
+// block
+
+func (r *HistorizingRepository) GetBlocks(ctx context.Context) ([]*entity.Block, error) {
+	return r.wrappedRepository.GetBlocks(ctx)
+}
+
+func (r *HistorizingRepository) AddBlock(ctx context.Context, block *entity.Block) (string, error) {
+	// the id is only assigned when adding, so the history entry must be written afterwards
+	id, err := r.wrappedRepository.AddBlock(ctx, block)
+	if err != nil {
+		return id, err
+	}
+
+	initialVersion := *block
+	initialVersion.ID = id
+	hideTimes(&initialVersion.Base)
+
+	// diff against the empty value so the initial state is printed in the diff
+	histEntry := diffReverse(ctx, &initialVersion, &entity.Block{}, typeBlock, id, opAdd)
+
+	return id, r.wrappedRepository.RecordHistory(ctx, histEntry)
+}
+
+func (r *HistorizingRepository) UpdateBlock(ctx context.Context, block *entity.Block) error {
+	oldVersion, err := r.wrappedRepository.GetBlockByID(ctx, block.ID)
+	if err != nil {
+		return err
+	}
+
+	// hide always present diff in times
+	oldVersion.CreatedAt = block.CreatedAt
+	oldVersion.UpdatedAt = block.UpdatedAt
+
+	histEntry := diffReverse(ctx, oldVersion, block, typeBlock, block.ID, opUpdate)
+
+	err = r.wrappedRepository.RecordHistory(ctx, histEntry)
+	if err != nil {
+		return err
+	}
+
+	return r.wrappedRepository.UpdateBlock(ctx, block)
+}
+
+func (r *HistorizingRepository) GetBlockByID(ctx context.Context, id string) (*entity.Block, error) {
+	return r.wrappedRepository.GetBlockByID(ctx, id)
+}
+
+func (r *HistorizingRepository) DeleteBlockByID(ctx context.Context, id string) error {
+	oldVersion, err := r.wrappedRepository.GetBlockByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	histEntry := diffReverse(ctx, oldVersion, &entity.Block{}, typeBlock, id, opDelete)
+
+	if err := r.wrappedRepository.RecordHistory(ctx, histEntry); err != nil {
+		return err
+	}
+
+	return r.wrappedRepository.DeleteBlockByID(ctx, id)
+}
+
+// room type
+
+func (r *HistorizingRepository) GetRoomTypes(ctx context.Context) ([]*entity.RoomType, error) {
+	return r.wrappedRepository.GetRoomTypes(ctx)
+}
+
+func (r *HistorizingRepository) AddRoomType(ctx context.Context, roomType *entity.RoomType) (string, error) {
+	// the id is only assigned when adding, so the history entry must be written afterwards
+	id, err := r.wrappedRepository.AddRoomType(ctx, roomType)
+	if err != nil {
+		return id, err
+	}
+
+	initialVersion := *roomType
+	initialVersion.ID = id
+	hideTimes(&initialVersion.Base)
+
+	// diff against the empty value so the initial state is printed in the diff
+	histEntry := diffReverse(ctx, &initialVersion, &entity.RoomType{}, typeRoomType, id, opAdd)
+
+	return id, r.wrappedRepository.RecordHistory(ctx, histEntry)
+}
+
+func (r *HistorizingRepository) UpdateRoomType(ctx context.Context, roomType *entity.RoomType) error {
+	oldVersion, err := r.wrappedRepository.GetRoomTypeByID(ctx, roomType.ID)
+	if err != nil {
+		return err
+	}
+
+	// hide always present diff in times
+	oldVersion.CreatedAt = roomType.CreatedAt
+	oldVersion.UpdatedAt = roomType.UpdatedAt
+
+	histEntry := diffReverse(ctx, oldVersion, roomType, typeRoomType, roomType.ID, opUpdate)
+
+	err = r.wrappedRepository.RecordHistory(ctx, histEntry)
+	if err != nil {
+		return err
+	}
+
+	return r.wrappedRepository.UpdateRoomType(ctx, roomType)
+}
+
+func (r *HistorizingRepository) GetRoomTypeByID(ctx context.Context, id string) (*entity.RoomType, error) {
+	return r.wrappedRepository.GetRoomTypeByID(ctx, id)
+}
+
+func (r *HistorizingRepository) DeleteRoomTypeByID(ctx context.Context, id string) error {
+	oldVersion, err := r.wrappedRepository.GetRoomTypeByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	histEntry := diffReverse(ctx, oldVersion, &entity.RoomType{}, typeRoomType, id, opDelete)
+
+	if err := r.wrappedRepository.RecordHistory(ctx, histEntry); err != nil {
+		return err
+	}
+
+	return r.wrappedRepository.DeleteRoomTypeByID(ctx, id)
+}
+
 // room
 
-func (r *HistorizingRepository) FindRooms(ctx context.Context, name string, minOccupancy uint, maxOccupancy int, minSize uint, maxSize uint, anyOfMemberID []int64) ([]string, error) {
-	return r.wrappedRepository.FindRooms(ctx, name, minOccupancy, maxOccupancy, minSize, maxSize, anyOfMemberID)
+func (r *HistorizingRepository) FindRooms(ctx context.Context, criteria database.FindRoomCriteria, opts *database.FindOptions) ([]string, int64, error) {
+	return r.wrappedRepository.FindRooms(ctx, criteria, opts)
 }
 
 func (r *HistorizingRepository) GetRooms(ctx context.Context) ([]*entity.Room, error) {
@@ -189,7 +490,20 @@ func (r *HistorizingRepository) GetRooms(ctx context.Context) ([]*entity.Room, e
 }
 
 func (r *HistorizingRepository) AddRoom(ctx context.Context, room *entity.Room) (string, error) {
-	return r.wrappedRepository.AddRoom(ctx, room)
+	// the id is only assigned when adding, so the history entry must be written afterwards
+	id, err := r.wrappedRepository.AddRoom(ctx, room)
+	if err != nil {
+		return id, err
+	}
+
+	initialVersion := *room
+	initialVersion.ID = id
+	hideTimes(&initialVersion.Base)
+
+	// diff against the empty value so the initial state is printed in the diff
+	histEntry := diffReverse(ctx, &initialVersion, &entity.Room{}, typeRoom, id, opAdd)
+
+	return id, r.wrappedRepository.RecordHistory(ctx, histEntry)
 }
 
 func (r *HistorizingRepository) UpdateRoom(ctx context.Context, room *entity.Room) error {
@@ -216,6 +530,10 @@ func (r *HistorizingRepository) GetRoomByID(ctx context.Context, id string) (*en
 	return r.wrappedRepository.GetRoomByID(ctx, id)
 }
 
+func (r *HistorizingRepository) LockRoomByID(ctx context.Context, id string) (*entity.Room, error) {
+	return r.wrappedRepository.LockRoomByID(ctx, id)
+}
+
 func (r *HistorizingRepository) DeleteRoomByID(ctx context.Context, id string) error {
 	oldVersion, err := r.wrappedRepository.GetRoomByID(ctx, id)
 	if err != nil {
@@ -233,6 +551,23 @@ func (r *HistorizingRepository) DeleteRoomByID(ctx context.Context, id string) e
 	return r.wrappedRepository.DeleteRoomByID(ctx, id)
 }
 
+func (r *HistorizingRepository) UndeleteRoomByID(ctx context.Context, id string) error {
+	if err := r.wrappedRepository.UndeleteRoomByID(ctx, id); err != nil {
+		return err
+	}
+
+	restoredVersion, err := r.wrappedRepository.GetRoomByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	hideTimes(&restoredVersion.Base)
+
+	// like for add, the full restored state is printed in the diff
+	histEntry := diffReverse(ctx, restoredVersion, &entity.Room{}, typeRoom, id, opUndelete)
+
+	return r.wrappedRepository.RecordHistory(ctx, histEntry)
+}
+
 // room members
 
 func (r *HistorizingRepository) NewEmptyRoomMembership(ctx context.Context, roomID string, attendeeID int64) *entity.RoomMember {
@@ -248,6 +583,13 @@ func (r *HistorizingRepository) GetRoomMembersByRoomID(ctx context.Context, room
 }
 
 func (r *HistorizingRepository) AddRoomMembership(ctx context.Context, rm *entity.RoomMember) error {
+	// diff against the empty value so the initial state is printed in the diff
+	histEntry := diffReverse(ctx, rm, &entity.RoomMember{}, typeRoomMember, fmt.Sprintf("%d", rm.ID), opAdd)
+
+	if err := r.recordMembershipHistory(ctx, histEntry, rm.RoomID); err != nil {
+		return err
+	}
+
 	return r.wrappedRepository.AddRoomMembership(ctx, rm)
 }
 
@@ -261,9 +603,13 @@ func (r *HistorizingRepository) UpdateRoomMembership(ctx context.Context, rm *en
 	oldVersion.CreatedAt = rm.CreatedAt
 	oldVersion.UpdatedAt = rm.UpdatedAt
 
-	histEntry := diffReverse(ctx, oldVersion, rm, typeRoomMember, fmt.Sprintf("%d", rm.ID), opUpdate)
+	// always print the badge number, so the entry in the room history shows who was changed
+	newVersion := *rm
+	newVersion.ID = 0
 
-	err = r.wrappedRepository.RecordHistory(ctx, histEntry)
+	histEntry := diffReverse(ctx, oldVersion, &newVersion, typeRoomMember, fmt.Sprintf("%d", rm.ID), opUpdate)
+
+	err = r.recordMembershipHistory(ctx, histEntry, oldVersion.RoomID, rm.RoomID)
 	if err != nil {
 		return err
 	}
@@ -281,20 +627,94 @@ func (r *HistorizingRepository) DeleteRoomMembership(ctx context.Context, attend
 
 	histEntry := diffReverse(ctx, oldVersion, newVersion, typeRoomMember, fmt.Sprintf("%d", attendeeID), opDelete)
 
-	if err := r.wrappedRepository.RecordHistory(ctx, histEntry); err != nil {
+	if err := r.recordMembershipHistory(ctx, histEntry, oldVersion.RoomID); err != nil {
 		return err
 	}
 
 	return r.wrappedRepository.DeleteRoomMembership(ctx, attendeeID)
 }
 
+func (r *HistorizingRepository) MoveRoomMemberships(ctx context.Context, targetRoomIDs map[int64]string) error {
+	ids := make([]int64, 0, len(targetRoomIDs))
+	for id := range targetRoomIDs {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	histEntries := make([]*entity.History, 0, 3*len(ids))
+	all := roomMoves{From: make(map[int64]string), To: make(map[int64]string)}
+	roomIDs := make([]string, 0, 2*len(ids))
+	for _, id := range ids {
+		oldVersion, err := r.wrappedRepository.GetRoomMembershipByAttendeeID(ctx, id)
+		if err != nil {
+			return err
+		}
+		all.From[id] = oldVersion.RoomID
+		all.To[id] = targetRoomIDs[id]
+		roomIDs = append(roomIDs, oldVersion.RoomID, targetRoomIDs[id])
+
+		// diff against the empty value so both rooms are printed in the diff
+		move := roomMoves{From: map[int64]string{id: oldVersion.RoomID}, To: map[int64]string{id: targetRoomIDs[id]}}
+		histEntries = append(histEntries, diffReverse(ctx, &move, &roomMoves{From: map[int64]string{}, To: map[int64]string{}}, typeRoomMember, fmt.Sprintf("%d", id), opMove))
+	}
+
+	// the same entry for each room involved, so the history of every room shows who moved where
+	slices.Sort(roomIDs)
+	for _, roomID := range slices.Compact(roomIDs) {
+		histEntries = append(histEntries, diffReverse(ctx, &all, &roomMoves{From: map[int64]string{}, To: map[int64]string{}}, typeRoom, roomID, opMove))
+	}
+
+	for _, histEntry := range histEntries {
+		if err := r.wrappedRepository.RecordHistory(ctx, histEntry); err != nil {
+			return err
+		}
+	}
+
+	return r.wrappedRepository.MoveRoomMemberships(ctx, targetRoomIDs)
+}
+
 // --- history ---
 
+// events
+
+func (r *HistorizingRepository) HasProcessedEvent(ctx context.Context, id string) (bool, error) {
+	return r.wrappedRepository.HasProcessedEvent(ctx, id)
+}
+
+func (r *HistorizingRepository) AddProcessedEvent(ctx context.Context, ev *entity.ProcessedEvent) error {
+	// duplicates are expected here, so only record history once the event has actually been added
+	if err := r.wrappedRepository.AddProcessedEvent(ctx, ev); err != nil {
+		return err
+	}
+
+	initialVersion := *ev
+	initialVersion.CreatedAt = time.Time{}
+
+	// diff against the empty value so the initial state is printed in the diff
+	histEntry := diffReverse(ctx, &initialVersion, &entity.ProcessedEvent{}, typeEvent, ev.ID, opAdd)
+
+	return r.wrappedRepository.RecordHistory(ctx, histEntry)
+}
+
+func (r *HistorizingRepository) DeleteProcessedEvent(ctx context.Context, id string) error {
+	histEntry := noDiffRecord(ctx, typeEvent, id, opDelete)
+
+	if err := r.wrappedRepository.RecordHistory(ctx, histEntry); err != nil {
+		return err
+	}
+
+	return r.wrappedRepository.DeleteProcessedEvent(ctx, id)
+}
+
 func (r *HistorizingRepository) RecordHistory(ctx context.Context, h *entity.History) error {
 	// it is an error to call this from the outside. From the inside use wrappedRepository.RecordHistory to bypass the error
 	return errors.New("not allowed to directly manipulate history")
 }
 
+func (r *HistorizingRepository) GetHistory(ctx context.Context, entityNames []string, entityID string, offset uint, limit uint) ([]*entity.History, int64, error) {
+	return r.wrappedRepository.GetHistory(ctx, entityNames, entityID, offset, limit)
+}
+
 func diffReverse[T any](ctx context.Context, oldVersion *T, newVersion *T, entityName entityType, entityID string, operation operationType) *entity.History {
 	// we diff reverse so the OLD value is printed in the diffs. The new value is in the database now.
 	histEntry := &entity.History{
@@ -309,6 +729,30 @@ func diffReverse[T any](ctx context.Context, oldVersion *T, newVersion *T, entit
 	return histEntry
 }
 
+// hideTimes clears the timestamps, which would otherwise always show up in the diffs of adds.
+func hideTimes(base *entity.Base) {
+	base.CreatedAt = time.Time{}
+	base.UpdatedAt = time.Time{}
+}
+
+// recordMembershipHistory records the history entry of a group or room membership, which is keyed by badge number,
+// and the same entry again for each group or room involved, so the change also shows up in their history.
+func (r *HistorizingRepository) recordMembershipHistory(ctx context.Context, histEntry *entity.History, containerIDs ...string) error {
+	if err := r.wrappedRepository.RecordHistory(ctx, histEntry); err != nil {
+		return err
+	}
+
+	slices.Sort(containerIDs)
+	for _, containerID := range slices.Compact(containerIDs) {
+		containerEntry := noDiffRecord(ctx, entityType(histEntry.Entity), containerID, operationType(histEntry.Operation))
+		containerEntry.Diff = histEntry.Diff
+		if err := r.wrappedRepository.RecordHistory(ctx, containerEntry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func noDiffRecord(ctx context.Context, entityName entityType, entityID string, operation operationType) *entity.History {
 	return &entity.History{
 		Entity:    string(entityName),
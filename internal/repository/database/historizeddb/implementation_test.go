@@ -0,0 +1,469 @@
+package historizeddb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+	"github.com/eurofurence/reg-room-service/internal/repository/database/inmemorydb"
+)
+
+// readOnlyMethods lists the methods of database.Repository that do not change any data,
+// plus the methods that are not about the data itself.
+var readOnlyMethods = map[string]bool{
+	"Open":                           true,
+	"Close":                          true,
+	"Migrate":                        true,
+	"Transaction":                    true, // changes are historized by the repository passed to fn
+	"GetGroups":                      true,
+	"FindGroups":                     true,
+	"GetGroupByID":                   true,
+	"LockGroupByID":                  true,
+	"NewEmptyGroupMembership":        true,
+	"GetGroupMembershipByAttendeeID": true,
+	"GetGroupMembersByGroupID":       true,
+	"HasGroupBan":                    true,
+	"GetGroupBans":                   true,
+	"GetGroupWish":                   true,
+	"GetGroupWishes":                 true,
+	"GetBlocks":                      true,
+	"GetBlockByID":                   true,
+	"GetRoomTypes":                   true,
+	"GetRoomTypeByID":                true,
+	"FindRooms":                      true,
+	"GetRooms":                       true,
+	"GetRoomByID":                    true,
+	"LockRoomByID":                   true,
+	"NewEmptyRoomMembership":         true,
+	"GetRoomMembershipByAttendeeID":  true,
+	"GetRoomMembersByRoomID":         true,
+	"HasProcessedEvent":              true,
+	"RecordHistory":                  true, // not allowed from the outside
+	"GetHistory":                     true,
+}
+
+type tstFixture struct {
+	inner   database.Repository
+	cut     database.Repository
+	groupID string
+	roomID  string
+	blockID string
+	typeID  string
+}
+
+type tstMutation struct {
+	// call performs the mutation, and returns the entity and entity id that should have history
+	call func(t *testing.T, ctx context.Context, f *tstFixture) (string, string)
+	// operation is the expected operation of the last history entry
+	operation string
+}
+
+var mutatingMethods = map[string]tstMutation{
+	"AddGroup": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			id, err := f.cut.AddGroup(ctx, &entity.Group{Name: "dogs", Flags: ",public,", MaximumSize: 6, Owner: 43})
+			require.NoError(t, err)
+			return entity.HistoryEntityGroup, id
+		},
+		operation: "add",
+	},
+	"UpdateGroup": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			grp, err := f.cut.GetGroupByID(ctx, f.groupID)
+			require.NoError(t, err)
+			grp.Name = "dogs"
+			require.NoError(t, f.cut.UpdateGroup(ctx, grp))
+			return entity.HistoryEntityGroup, f.groupID
+		},
+		operation: "update",
+	},
+	"DeleteGroupByID": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			require.NoError(t, f.inner.DeleteGroupMembership(ctx, 42))
+			require.NoError(t, f.cut.DeleteGroupByID(ctx, f.groupID))
+			return entity.HistoryEntityGroup, f.groupID
+		},
+		operation: "delete",
+	},
+	"UndeleteGroupByID": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			require.NoError(t, f.inner.DeleteGroupMembership(ctx, 42))
+			require.NoError(t, f.inner.DeleteGroupByID(ctx, f.groupID))
+			require.NoError(t, f.cut.UndeleteGroupByID(ctx, f.groupID))
+			return entity.HistoryEntityGroup, f.groupID
+		},
+		operation: "undelete",
+	},
+	"AddGroupMembership": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			require.NoError(t, f.cut.AddGroupMembership(ctx, f.cut.NewEmptyGroupMembership(ctx, f.groupID, 43, "snep")))
+			return entity.HistoryEntityGroupMember, "43"
+		},
+		operation: "add",
+	},
+	"UpdateGroupMembership": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			gm, err := f.cut.GetGroupMembershipByAttendeeID(ctx, 42)
+			require.NoError(t, err)
+			gm.Nickname = "Squirrel"
+			require.NoError(t, f.cut.UpdateGroupMembership(ctx, gm))
+			return entity.HistoryEntityGroupMember, "42"
+		},
+		operation: "update",
+	},
+	"DeleteGroupMembership": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			require.NoError(t, f.cut.DeleteGroupMembership(ctx, 42))
+			return entity.HistoryEntityGroupMember, "42"
+		},
+		operation: "delete",
+	},
+	"AddGroupBan": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			require.NoError(t, f.cut.AddGroupBan(ctx, f.groupID, 43, "no sneps"))
+			return entity.HistoryEntityGroupBan, f.groupID
+		},
+		operation: "add",
+	},
+	"RemoveGroupBan": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			require.NoError(t, f.inner.AddGroupBan(ctx, f.groupID, 43, "no sneps"))
+			require.NoError(t, f.cut.RemoveGroupBan(ctx, f.groupID, 43))
+			return entity.HistoryEntityGroupBan, f.groupID
+		},
+		operation: "delete",
+	},
+	"SetGroupWish": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			require.NoError(t, f.cut.SetGroupWish(ctx, &entity.GroupWish{GroupID: f.groupID, Size: 3, RoomFlags: ",handicapped,"}))
+			return entity.HistoryEntityGroupWish, f.groupID
+		},
+		operation: "add",
+	},
+	"DeleteGroupWish": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			require.NoError(t, f.inner.SetGroupWish(ctx, &entity.GroupWish{GroupID: f.groupID, Size: 3}))
+			require.NoError(t, f.cut.DeleteGroupWish(ctx, f.groupID))
+			return entity.HistoryEntityGroupWish, f.groupID
+		},
+		operation: "delete",
+	},
+	"AddBlock": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			id, err := f.cut.AddBlock(ctx, &entity.Block{Name: "Alster Hotel", PublicStart: "2030-01-20T19:00:00+01:00"})
+			require.NoError(t, err)
+			return entity.HistoryEntityBlock, id
+		},
+		operation: "add",
+	},
+	"UpdateBlock": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			blk, err := f.cut.GetBlockByID(ctx, f.blockID)
+			require.NoError(t, err)
+			blk.PublicBookingCode = "Dithmarschen"
+			require.NoError(t, f.cut.UpdateBlock(ctx, blk))
+			return entity.HistoryEntityBlock, f.blockID
+		},
+		operation: "update",
+	},
+	"DeleteBlockByID": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			require.NoError(t, f.cut.DeleteBlockByID(ctx, f.blockID))
+			return entity.HistoryEntityBlock, f.blockID
+		},
+		operation: "delete",
+	},
+	"AddRoomType": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			id, err := f.cut.AddRoomType(ctx, &entity.RoomType{Name: "triple accessible", Size: 3, Flags: ",wheelchair,"})
+			require.NoError(t, err)
+			return entity.HistoryEntityRoomType, id
+		},
+		operation: "add",
+	},
+	"UpdateRoomType": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			rt, err := f.cut.GetRoomTypeByID(ctx, f.typeID)
+			require.NoError(t, err)
+			rt.PriceCategory = "B"
+			require.NoError(t, f.cut.UpdateRoomType(ctx, rt))
+			return entity.HistoryEntityRoomType, f.typeID
+		},
+		operation: "update",
+	},
+	"DeleteRoomTypeByID": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			require.NoError(t, f.cut.DeleteRoomTypeByID(ctx, f.typeID))
+			return entity.HistoryEntityRoomType, f.typeID
+		},
+		operation: "delete",
+	},
+	"AddRoom": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			id, err := f.cut.AddRoom(ctx, &entity.Room{Name: "27182", Size: 3})
+			require.NoError(t, err)
+			return entity.HistoryEntityRoom, id
+		},
+		operation: "add",
+	},
+	"UpdateRoom": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			room, err := f.cut.GetRoomByID(ctx, f.roomID)
+			require.NoError(t, err)
+			room.Size = 3
+			require.NoError(t, f.cut.UpdateRoom(ctx, room))
+			return entity.HistoryEntityRoom, f.roomID
+		},
+		operation: "update",
+	},
+	"DeleteRoomByID": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			require.NoError(t, f.inner.DeleteRoomMembership(ctx, 42))
+			require.NoError(t, f.cut.DeleteRoomByID(ctx, f.roomID))
+			return entity.HistoryEntityRoom, f.roomID
+		},
+		operation: "delete",
+	},
+	"UndeleteRoomByID": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			require.NoError(t, f.inner.DeleteRoomMembership(ctx, 42))
+			require.NoError(t, f.inner.DeleteRoomByID(ctx, f.roomID))
+			require.NoError(t, f.cut.UndeleteRoomByID(ctx, f.roomID))
+			return entity.HistoryEntityRoom, f.roomID
+		},
+		operation: "undelete",
+	},
+	"AddRoomMembership": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			require.NoError(t, f.cut.AddRoomMembership(ctx, f.cut.NewEmptyRoomMembership(ctx, f.roomID, 43)))
+			return entity.HistoryEntityRoomMember, "43"
+		},
+		operation: "add",
+	},
+	"UpdateRoomMembership": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			rm, err := f.cut.GetRoomMembershipByAttendeeID(ctx, 42)
+			require.NoError(t, err)
+			rm.Nickname = "Squirrel"
+			require.NoError(t, f.cut.UpdateRoomMembership(ctx, rm))
+			return entity.HistoryEntityRoomMember, "42"
+		},
+		operation: "update",
+	},
+	"DeleteRoomMembership": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			require.NoError(t, f.cut.DeleteRoomMembership(ctx, 42))
+			return entity.HistoryEntityRoomMember, "42"
+		},
+		operation: "delete",
+	},
+	"MoveGroupMemberships": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			otherGroupID, err := f.inner.AddGroup(ctx, &entity.Group{Name: "dogs", MaximumSize: 6, Owner: 43})
+			require.NoError(t, err)
+			require.NoError(t, f.cut.MoveGroupMemberships(ctx, otherGroupID, []int64{42}))
+			return entity.HistoryEntityGroup, f.groupID
+		},
+		operation: "move",
+	},
+	"MoveRoomMemberships": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			otherRoomID, err := f.inner.AddRoom(ctx, &entity.Room{Name: "27182", Size: 2})
+			require.NoError(t, err)
+			require.NoError(t, f.inner.AddRoomMembership(ctx, f.inner.NewEmptyRoomMembership(ctx, otherRoomID, 43)))
+			require.NoError(t, f.cut.MoveRoomMemberships(ctx, map[int64]string{42: otherRoomID, 43: f.roomID}))
+			return entity.HistoryEntityRoom, f.roomID
+		},
+		operation: "move",
+	},
+	"AddProcessedEvent": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			require.NoError(t, f.cut.AddProcessedEvent(ctx, &entity.ProcessedEvent{ID: "ev-1", Kind: entity.EventKindAttendeeStatus, AttendeeID: 42}))
+			return entity.HistoryEntityProcessedEvent, "ev-1"
+		},
+		operation: "add",
+	},
+	"DeleteProcessedEvent": {
+		call: func(t *testing.T, ctx context.Context, f *tstFixture) (string, string) {
+			require.NoError(t, f.inner.AddProcessedEvent(ctx, &entity.ProcessedEvent{ID: "ev-1", Kind: entity.EventKindAttendeeStatus, AttendeeID: 42}))
+			require.NoError(t, f.cut.DeleteProcessedEvent(ctx, "ev-1"))
+			return entity.HistoryEntityProcessedEvent, "ev-1"
+		},
+		operation: "delete",
+	},
+}
+
+// tstSetupFixture creates a group and a room, each with attendee 42 in it, and a block, bypassing history.
+func tstSetupFixture(t *testing.T, ctx context.Context) *tstFixture {
+	inner := inmemorydb.New()
+	require.NoError(t, inner.Open(ctx))
+
+	groupID, err := inner.AddGroup(ctx, &entity.Group{Name: "kittens", MaximumSize: 6, Owner: 42})
+	require.NoError(t, err)
+	gm := inner.NewEmptyGroupMembership(ctx, groupID, 42, "squirrel")
+	gm.IsInvite = false
+	require.NoError(t, inner.AddGroupMembership(ctx, gm))
+
+	roomID, err := inner.AddRoom(ctx, &entity.Room{Name: "31415", Size: 2})
+	require.NoError(t, err)
+	require.NoError(t, inner.AddRoomMembership(ctx, inner.NewEmptyRoomMembership(ctx, roomID, 42)))
+
+	blockID, err := inner.AddBlock(ctx, &entity.Block{Name: "Elbe Hotel", PublicStart: "2030-01-20T19:00:00+01:00", PublicBookingCode: "Kaiser-Wilhelm-Koog"})
+	require.NoError(t, err)
+
+	typeID, err := inner.AddRoomType(ctx, &entity.RoomType{Name: "double standard", Size: 2, Flags: ",", PriceCategory: "A"})
+	require.NoError(t, err)
+
+	return &tstFixture{
+		inner:   inner,
+		cut:     New(inner),
+		groupID: groupID,
+		roomID:  roomID,
+		blockID: blockID,
+		typeID:  typeID,
+	}
+}
+
+func TestAllMethodsClassified(t *testing.T) {
+	repoType := reflect.TypeOf((*database.Repository)(nil)).Elem()
+	for i := 0; i < repoType.NumMethod(); i++ {
+		name := repoType.Method(i).Name
+		_, isMutating := mutatingMethods[name]
+		require.True(t, isMutating != readOnlyMethods[name],
+			"method %s of database.Repository must be listed exactly once, either in mutatingMethods or in readOnlyMethods", name)
+	}
+}
+
+func TestMutatingMethodsRecordHistory(t *testing.T) {
+	for name, mutation := range mutatingMethods {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			f := tstSetupFixture(t, ctx)
+
+			entityName, entityID := mutation.call(t, ctx, f)
+
+			entries, total, err := f.cut.GetHistory(ctx, []string{entityName}, entityID, 0, 100)
+			require.NoError(t, err)
+			require.Equal(t, int64(1), total, "expected exactly one history entry")
+			require.Equal(t, entityName, entries[0].Entity)
+			require.Equal(t, entityID, entries[0].EntityId)
+			require.Equal(t, mutation.operation, entries[0].Operation)
+		})
+	}
+}
+
+func TestAddRecordsInitialState(t *testing.T) {
+	ctx := context.Background()
+	f := tstSetupFixture(t, ctx)
+
+	id, err := f.cut.AddGroup(ctx, &entity.Group{Name: "dogs", Flags: ",public,", MaximumSize: 6, Owner: 43})
+	require.NoError(t, err)
+
+	entries, _, err := f.cut.GetHistory(ctx, []string{entity.HistoryEntityGroup}, id, 0, 100)
+	require.NoError(t, err)
+	require.Contains(t, entries[0].Diff, `.Name = "dogs"`)
+	require.Contains(t, entries[0].Diff, `.Flags = ",public,"`)
+	require.Contains(t, entries[0].Diff, `.MaximumSize = 6`)
+	require.Contains(t, entries[0].Diff, `.Owner = 43`)
+	require.Contains(t, entries[0].Diff, fmt.Sprintf(`.ID = "%s"`, id))
+	require.NotContains(t, entries[0].Diff, "CreatedAt")
+}
+
+func TestUndeleteNotSoftDeleted(t *testing.T) {
+	ctx := context.Background()
+	f := tstSetupFixture(t, ctx)
+
+	require.ErrorIs(t, f.cut.UndeleteGroupByID(ctx, f.groupID), gorm.ErrRecordNotFound)
+
+	_, total, err := f.cut.GetHistory(ctx, []string{entity.HistoryEntityGroup}, f.groupID, 0, 100)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), total)
+}
+
+func TestDeleteThenUndelete(t *testing.T) {
+	ctx := context.Background()
+	f := tstSetupFixture(t, ctx)
+	require.NoError(t, f.inner.DeleteGroupMembership(ctx, 42))
+
+	require.NoError(t, f.cut.DeleteGroupByID(ctx, f.groupID))
+	_, err := f.cut.GetGroupByID(ctx, f.groupID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	require.NoError(t, f.cut.UndeleteGroupByID(ctx, f.groupID))
+	restored, err := f.cut.GetGroupByID(ctx, f.groupID)
+	require.NoError(t, err)
+	require.Equal(t, "kittens", restored.Name)
+
+	entries, _, err := f.cut.GetHistory(ctx, []string{entity.HistoryEntityGroup}, f.groupID, 0, 100)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "delete", entries[0].Operation)
+	require.Equal(t, "undelete", entries[1].Operation)
+}
+
+func TestAddPurgesDeletedWithSameName(t *testing.T) {
+	ctx := context.Background()
+	f := tstSetupFixture(t, ctx)
+	require.NoError(t, f.inner.DeleteGroupMembership(ctx, 42))
+	require.NoError(t, f.cut.DeleteGroupByID(ctx, f.groupID))
+
+	_, err := f.cut.AddGroup(ctx, &entity.Group{Name: "kittens", MaximumSize: 6, Owner: 43})
+	require.NoError(t, err)
+
+	require.ErrorIs(t, f.cut.UndeleteGroupByID(ctx, f.groupID), gorm.ErrRecordNotFound)
+}
+
+func TestAddProcessedEventDuplicateNoHistory(t *testing.T) {
+	ctx := context.Background()
+	f := tstSetupFixture(t, ctx)
+	ev := &entity.ProcessedEvent{ID: "ev-1", Kind: entity.EventKindAttendeeStatus, AttendeeID: 42}
+	require.NoError(t, f.inner.AddProcessedEvent(ctx, ev))
+
+	require.ErrorIs(t, f.cut.AddProcessedEvent(ctx, ev), gorm.ErrDuplicatedKey)
+
+	_, total, err := f.cut.GetHistory(ctx, []string{entity.HistoryEntityProcessedEvent}, "ev-1", 0, 100)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), total)
+}
+
+func TestGroupBanHistoryKeyedByGroup(t *testing.T) {
+	ctx := context.Background()
+	f := tstSetupFixture(t, ctx)
+
+	require.NoError(t, f.cut.AddGroupBan(ctx, f.groupID, 43, "no sneps"))
+	require.NoError(t, f.cut.RemoveGroupBan(ctx, f.groupID, 43))
+
+	entries, _, err := f.cut.GetHistory(ctx, []string{entity.HistoryEntityGroupBan}, f.groupID, 0, 100)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		require.Contains(t, entry.Diff, `.ID = 43`)
+		require.NotContains(t, entry.Diff, "no sneps")
+	}
+}
+
+func TestMembershipHistoryAlsoKeyedByGroup(t *testing.T) {
+	ctx := context.Background()
+	f := tstSetupFixture(t, ctx)
+
+	gm, err := f.cut.GetGroupMembershipByAttendeeID(ctx, 42)
+	require.NoError(t, err)
+	gm.Nickname = "Squirrel"
+	require.NoError(t, f.cut.UpdateGroupMembership(ctx, gm))
+
+	byAttendee, _, err := f.cut.GetHistory(ctx, []string{entity.HistoryEntityGroupMember}, "42", 0, 100)
+	require.NoError(t, err)
+	byGroup, _, err := f.cut.GetHistory(ctx, []string{entity.HistoryEntityGroupMember}, f.groupID, 0, 100)
+	require.NoError(t, err)
+	require.Len(t, byAttendee, 1)
+	require.Len(t, byGroup, 1)
+	require.Equal(t, byAttendee[0].Diff, byGroup[0].Diff)
+	require.Contains(t, byGroup[0].Diff, `.Member.ID = 42`)
+	require.Contains(t, byGroup[0].Diff, `.Member.Nickname = "squirrel"`)
+}
@@ -1,10 +1,14 @@
 package inmemorydb
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"slices"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -26,31 +30,57 @@ type IMRoom struct {
 	Members []entity.RoomMember // intentionally not pointers so assignment makes a copy
 }
 
+type imData struct {
+	groups      map[string]*IMGroup
+	groupWishes map[string]*entity.GroupWish
+	rooms       map[string]*IMRoom
+	blocks      map[string]*entity.Block
+	roomTypes   map[string]*entity.RoomType
+	history     map[uint]*entity.History
+	events      map[string]*entity.ProcessedEvent
+	idSequence  uint32
+}
+
+// InMemoryRepository is safe for concurrent use.
+//
+// All data access is guarded by mu. A transaction holds the write lock for its whole duration,
+// and passes a view of the repository to its callback that shares data and mu, but does not lock again.
 type InMemoryRepository struct {
-	groups     map[string]*IMGroup
-	rooms      map[string]*IMRoom
-	history    map[uint]*entity.History
-	idSequence uint32
-	Now        func() time.Time
+	data *imData
+	mu   *sync.RWMutex
+	inTx bool
+	Now  func() time.Time
 }
 
 func New() database.Repository {
 	return &InMemoryRepository{
-		Now: time.Now,
+		data: &imData{},
+		mu:   &sync.RWMutex{},
+		Now:  time.Now,
 	}
 }
 
 func (r *InMemoryRepository) Open(_ context.Context) error {
-	r.groups = make(map[string]*IMGroup)
-	r.rooms = make(map[string]*IMRoom)
-	r.history = make(map[uint]*entity.History)
+	defer r.lock()()
+	r.data.groups = make(map[string]*IMGroup)
+	r.data.groupWishes = make(map[string]*entity.GroupWish)
+	r.data.rooms = make(map[string]*IMRoom)
+	r.data.blocks = make(map[string]*entity.Block)
+	r.data.roomTypes = make(map[string]*entity.RoomType)
+	r.data.history = make(map[uint]*entity.History)
+	r.data.events = make(map[string]*entity.ProcessedEvent)
 	return nil
 }
 
 func (r *InMemoryRepository) Close(_ context.Context) {
-	r.groups = nil
-	r.rooms = nil
-	r.history = nil
+	defer r.lock()()
+	r.data.groups = nil
+	r.data.groupWishes = nil
+	r.data.rooms = nil
+	r.data.blocks = nil
+	r.data.roomTypes = nil
+	r.data.history = nil
+	r.data.events = nil
 }
 
 func (r *InMemoryRepository) Migrate(_ context.Context) error {
@@ -58,11 +88,108 @@ func (r *InMemoryRepository) Migrate(_ context.Context) error {
 	return nil
 }
 
+func (r *InMemoryRepository) Transaction(_ context.Context, fn func(tx database.Repository) error) error {
+	if r.inTx {
+		return errors.New("nested transactions are not supported")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saved := r.snapshot()
+	committed := false
+	defer func() {
+		if !committed {
+			// rollback, also on panic
+			r.data.groups, r.data.groupWishes, r.data.rooms, r.data.blocks, r.data.roomTypes = saved.groups, saved.groupWishes, saved.rooms, saved.blocks, saved.roomTypes
+			r.data.history, r.data.events = saved.history, saved.events
+		}
+	}()
+
+	tx := &InMemoryRepository{
+		data: r.data,
+		mu:   r.mu,
+		inTx: true,
+		Now:  r.Now,
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+// lock obtains the write lock and returns the matching unlock function, intended use is defer r.lock()().
+//
+// Inside a transaction, the lock is already held, so this does nothing.
+func (r *InMemoryRepository) lock() func() {
+	if r.inTx {
+		return func() {}
+	}
+	r.mu.Lock()
+	return r.mu.Unlock
+}
+
+// rlock obtains the read lock and returns the matching unlock function, intended use is defer r.rlock()().
+//
+// Inside a transaction, the write lock is already held, so this does nothing.
+func (r *InMemoryRepository) rlock() func() {
+	if r.inTx {
+		return func() {}
+	}
+	r.mu.RLock()
+	return r.mu.RUnlock
+}
+
+// snapshot makes a deep copy of the data so a transaction can be rolled back.
+func (r *InMemoryRepository) snapshot() imData {
+	groups := make(map[string]*IMGroup, len(r.data.groups))
+	for id, grp := range r.data.groups {
+		groups[id] = &IMGroup{
+			Group:   grp.Group,
+			Members: slices.Clone(grp.Members),
+			Bans:    maps.Clone(grp.Bans),
+		}
+	}
+	groupWishes := make(map[string]*entity.GroupWish, len(r.data.groupWishes))
+	for id, gw := range r.data.groupWishes {
+		gwCopy := *gw
+		groupWishes[id] = &gwCopy
+	}
+	rooms := make(map[string]*IMRoom, len(r.data.rooms))
+	for id, rm := range r.data.rooms {
+		rooms[id] = &IMRoom{
+			Room:    rm.Room,
+			Members: slices.Clone(rm.Members),
+		}
+	}
+	blocks := make(map[string]*entity.Block, len(r.data.blocks))
+	for id, blk := range r.data.blocks {
+		blkCopy := *blk
+		blocks[id] = &blkCopy
+	}
+	roomTypes := make(map[string]*entity.RoomType, len(r.data.roomTypes))
+	for id, rt := range r.data.roomTypes {
+		rtCopy := *rt
+		roomTypes[id] = &rtCopy
+	}
+	return imData{
+		groups:      groups,
+		groupWishes: groupWishes,
+		rooms:       rooms,
+		blocks:      blocks,
+		roomTypes:   roomTypes,
+		history:     maps.Clone(r.data.history),
+		events:      maps.Clone(r.data.events),
+	}
+}
+
 // groups
 
 func (r *InMemoryRepository) GetGroups(_ context.Context) ([]*entity.Group, error) {
+	defer r.rlock()()
 	result := make([]*entity.Group, 0)
-	for _, grp := range r.groups {
+	for _, grp := range r.data.groups {
 		if !grp.Group.DeletedAt.Valid {
 			grpCopy := grp.Group
 			result = append(result, &grpCopy)
@@ -71,15 +198,17 @@ func (r *InMemoryRepository) GetGroups(_ context.Context) ([]*entity.Group, erro
 	return result, nil
 }
 
-func (r *InMemoryRepository) FindGroups(_ context.Context, name string, minOccupancy uint, maxOccupancy int, anyOfMemberID []int64) ([]string, error) {
-	result := make([]string, 0)
-	for _, grp := range r.groups {
+func (r *InMemoryRepository) FindGroups(_ context.Context, criteria database.FindGroupCriteria, opts *database.FindOptions) ([]string, int64, error) {
+	defer r.rlock()()
+	matching := make([]findEntry, 0)
+	for _, grp := range r.data.groups {
 		if !grp.Group.DeletedAt.Valid {
-			if len(grp.Members) >= int(minOccupancy) &&
-				(maxOccupancy == -1 || len(grp.Members) <= maxOccupancy) &&
-				(name == "" || name == grp.Group.Name) {
-				matches := len(anyOfMemberID) == 0
-				for _, wantedID := range anyOfMemberID {
+			if len(grp.Members) >= int(criteria.MinOccupancy) &&
+				(criteria.MaxOccupancy == -1 || len(grp.Members) <= criteria.MaxOccupancy) &&
+				(criteria.Name == "" || criteria.Name == grp.Group.Name) &&
+				(criteria.Flag == "" || strings.Contains(grp.Group.Flags, ","+criteria.Flag+",")) {
+				matches := len(criteria.AnyOfMemberID) == 0
+				for _, wantedID := range criteria.AnyOfMemberID {
 					for _, actualMember := range grp.Members {
 						if wantedID == actualMember.ID {
 							matches = true
@@ -87,18 +216,28 @@ func (r *InMemoryRepository) FindGroups(_ context.Context, name string, minOccup
 					}
 				}
 				if matches {
-					result = append(result, grp.Group.ID)
+					matching = append(matching, findEntry{
+						id:        grp.Group.ID,
+						name:      grp.Group.Name,
+						size:      grp.Group.MaximumSize,
+						occupancy: len(grp.Members),
+						created:   grp.Group.CreatedAt,
+					})
 				}
 			}
 		}
 	}
-	slices.Sort(result)
-	return result, nil
+	result, total := applyFindOptions(matching, opts)
+	return result, total, nil
 }
 
 func (r *InMemoryRepository) AddGroup(_ context.Context, group *entity.Group) (string, error) {
+	defer r.lock()()
+	r.purgeDeletedGroups(group.Name, "")
 	group.ID = uuid.NewString()
-	r.groups[group.ID] = &IMGroup{
+	group.CreatedAt = r.Now()
+	group.UpdatedAt = group.CreatedAt
+	r.data.groups[group.ID] = &IMGroup{
 		Group:   *group, // this makes a copy
 		Members: make([]entity.GroupMember, 0),
 		Bans:    make(map[int64]entity.GroupBan),
@@ -107,8 +246,10 @@ func (r *InMemoryRepository) AddGroup(_ context.Context, group *entity.Group) (s
 }
 
 func (r *InMemoryRepository) UpdateGroup(_ context.Context, group *entity.Group) error {
-	if orig, ok := r.groups[group.ID]; ok {
-		r.groups[group.ID] = &IMGroup{
+	defer r.lock()()
+	if orig, ok := r.data.groups[group.ID]; ok {
+		r.purgeDeletedGroups(group.Name, group.ID)
+		r.data.groups[group.ID] = &IMGroup{
 			Group:   *group,       // this makes a copy
 			Members: orig.Members, // keep members
 			Bans:    orig.Bans,    // keep bans
@@ -120,8 +261,9 @@ func (r *InMemoryRepository) UpdateGroup(_ context.Context, group *entity.Group)
 }
 
 func (r *InMemoryRepository) GetGroupByID(_ context.Context, id string) (*entity.Group, error) {
-	// allow deleted so history and undelete work
-	if result, ok := r.groups[id]; ok {
+	defer r.rlock()()
+	// like the database, soft deleted groups are not found
+	if result, ok := r.data.groups[id]; ok && !result.Group.DeletedAt.Valid {
 		grpCopy := result.Group
 		return &grpCopy, nil
 	} else {
@@ -129,15 +271,45 @@ func (r *InMemoryRepository) GetGroupByID(_ context.Context, id string) (*entity
 	}
 }
 
+func (r *InMemoryRepository) LockGroupByID(ctx context.Context, id string) (*entity.Group, error) {
+	// inside a transaction, the write lock is already held, so this is as good as a row lock
+	return r.GetGroupByID(ctx, id)
+}
+
 func (r *InMemoryRepository) DeleteGroupByID(_ context.Context, id string) error {
-	if _, ok := r.groups[id]; ok {
-		delete(r.groups, id)
+	defer r.lock()()
+	if grp, ok := r.data.groups[id]; ok && !grp.Group.DeletedAt.Valid {
+		if len(grp.Members) > 0 || len(grp.Bans) > 0 {
+			// like the foreign key constraints in the database
+			return gorm.ErrForeignKeyViolated
+		}
+		grp.Group.DeletedAt = gorm.DeletedAt{Time: r.Now(), Valid: true}
 		return nil
 	} else {
 		return gorm.ErrRecordNotFound
 	}
 }
 
+func (r *InMemoryRepository) UndeleteGroupByID(_ context.Context, id string) error {
+	defer r.lock()()
+	if grp, ok := r.data.groups[id]; ok && grp.Group.DeletedAt.Valid {
+		grp.Group.DeletedAt = gorm.DeletedAt{}
+		return nil
+	} else {
+		return gorm.ErrRecordNotFound
+	}
+}
+
+// purgeDeletedGroups permanently removes soft deleted groups with the given name, except for exceptID,
+// like the database does to keep the name uniqueness constraint working.
+//
+// Expects the caller to hold the lock.
+func (r *InMemoryRepository) purgeDeletedGroups(name string, exceptID string) {
+	maps.DeleteFunc(r.data.groups, func(id string, grp *IMGroup) bool {
+		return grp.Group.DeletedAt.Valid && grp.Group.Name == name && id != exceptID
+	})
+}
+
 // group members
 
 func (r *InMemoryRepository) NewEmptyGroupMembership(_ context.Context, groupID string, attendeeID int64, nickname string) *entity.GroupMember {
@@ -150,7 +322,13 @@ func (r *InMemoryRepository) NewEmptyGroupMembership(_ context.Context, groupID
 }
 
 func (r *InMemoryRepository) GetGroupMembershipByAttendeeID(_ context.Context, attendeeID int64) (*entity.GroupMember, error) {
-	for _, grp := range r.groups {
+	defer r.rlock()()
+	return r.findGroupMembership(attendeeID)
+}
+
+// findGroupMembership expects the caller to hold the lock.
+func (r *InMemoryRepository) findGroupMembership(attendeeID int64) (*entity.GroupMember, error) {
+	for _, grp := range r.data.groups {
 		for _, gm := range grp.Members {
 			if gm.ID == attendeeID {
 				gmCopy := gm
@@ -164,7 +342,8 @@ func (r *InMemoryRepository) GetGroupMembershipByAttendeeID(_ context.Context, a
 }
 
 func (r *InMemoryRepository) GetGroupMembersByGroupID(_ context.Context, groupID string) ([]*entity.GroupMember, error) {
-	if grp, ok := r.groups[groupID]; ok {
+	defer r.rlock()()
+	if grp, ok := r.data.groups[groupID]; ok {
 		result := make([]*entity.GroupMember, len(grp.Members))
 		for i := range grp.Members {
 			cpMem := grp.Members[i]
@@ -177,11 +356,12 @@ func (r *InMemoryRepository) GetGroupMembersByGroupID(_ context.Context, groupID
 }
 
 func (r *InMemoryRepository) AddGroupMembership(ctx context.Context, gm *entity.GroupMember) error {
-	_, err := r.GetGroupMembershipByAttendeeID(ctx, gm.ID)
+	defer r.lock()()
+	_, err := r.findGroupMembership(gm.ID)
 	if !errors.Is(err, gorm.ErrRecordNotFound) {
 		return gorm.ErrDuplicatedKey
 	}
-	if grp, ok := r.groups[gm.GroupID]; ok {
+	if grp, ok := r.data.groups[gm.GroupID]; ok {
 		grp.Members = append(grp.Members, *gm)
 		return nil
 	} else {
@@ -190,11 +370,12 @@ func (r *InMemoryRepository) AddGroupMembership(ctx context.Context, gm *entity.
 }
 
 func (r *InMemoryRepository) UpdateGroupMembership(ctx context.Context, gm *entity.GroupMember) error {
-	_, err := r.GetGroupMembershipByAttendeeID(ctx, gm.ID)
+	defer r.lock()()
+	_, err := r.findGroupMembership(gm.ID)
 	if err != nil {
 		return err
 	}
-	if grp, ok := r.groups[gm.GroupID]; ok {
+	if grp, ok := r.data.groups[gm.GroupID]; ok {
 		updatedMembers := make([]entity.GroupMember, len(grp.Members))
 		for i, m := range grp.Members {
 			if m.ID == gm.ID {
@@ -211,11 +392,12 @@ func (r *InMemoryRepository) UpdateGroupMembership(ctx context.Context, gm *enti
 }
 
 func (r *InMemoryRepository) DeleteGroupMembership(ctx context.Context, attendeeID int64) error {
-	current, err := r.GetGroupMembershipByAttendeeID(ctx, attendeeID)
+	defer r.lock()()
+	current, err := r.findGroupMembership(attendeeID)
 	if err != nil {
 		return err
 	}
-	if grp, ok := r.groups[current.GroupID]; ok {
+	if grp, ok := r.data.groups[current.GroupID]; ok {
 		updatedMembers := make([]entity.GroupMember, 0)
 		for _, m := range grp.Members {
 			if m.ID != attendeeID {
@@ -229,10 +411,35 @@ func (r *InMemoryRepository) DeleteGroupMembership(ctx context.Context, attendee
 	}
 }
 
+func (r *InMemoryRepository) MoveGroupMemberships(ctx context.Context, targetGroupID string, attendeeIDs []int64) error {
+	defer r.lock()()
+	target, ok := r.data.groups[targetGroupID]
+	if !ok {
+		return gorm.ErrForeignKeyViolated
+	}
+	moved := make([]entity.GroupMember, 0, len(attendeeIDs))
+	for _, id := range attendeeIDs {
+		current, err := r.findGroupMembership(id)
+		if err != nil {
+			return err
+		}
+		current.GroupID = targetGroupID
+		moved = append(moved, *current)
+	}
+	for _, grp := range r.data.groups {
+		grp.Members = slices.DeleteFunc(slices.Clone(grp.Members), func(m entity.GroupMember) bool {
+			return slices.Contains(attendeeIDs, m.ID)
+		})
+	}
+	target.Members = append(target.Members, moved...)
+	return nil
+}
+
 // group bans
 
 func (r *InMemoryRepository) HasGroupBan(_ context.Context, groupID string, attendeeID int64) (bool, error) {
-	if grp, ok := r.groups[groupID]; ok {
+	defer r.rlock()()
+	if grp, ok := r.data.groups[groupID]; ok {
 		_, ok := grp.Bans[attendeeID]
 		return ok, nil
 	} else {
@@ -240,8 +447,26 @@ func (r *InMemoryRepository) HasGroupBan(_ context.Context, groupID string, atte
 	}
 }
 
+func (r *InMemoryRepository) GetGroupBans(_ context.Context, groupID string) ([]*entity.GroupBan, error) {
+	defer r.rlock()()
+	if grp, ok := r.data.groups[groupID]; ok {
+		result := make([]*entity.GroupBan, 0, len(grp.Bans))
+		for _, ban := range grp.Bans {
+			banCopy := ban
+			result = append(result, &banCopy)
+		}
+		slices.SortFunc(result, func(a, b *entity.GroupBan) int {
+			return cmp.Compare(a.ID, b.ID)
+		})
+		return result, nil
+	} else {
+		return make([]*entity.GroupBan, 0), gorm.ErrRecordNotFound
+	}
+}
+
 func (r *InMemoryRepository) AddGroupBan(ctx context.Context, groupID string, attendeeID int64, comments string) error {
-	if grp, ok := r.groups[groupID]; ok {
+	defer r.lock()()
+	if grp, ok := r.data.groups[groupID]; ok {
 		if _, ok := grp.Bans[attendeeID]; ok {
 			return gorm.ErrDuplicatedKey
 		}
@@ -258,7 +483,8 @@ func (r *InMemoryRepository) AddGroupBan(ctx context.Context, groupID string, at
 }
 
 func (r *InMemoryRepository) RemoveGroupBan(ctx context.Context, groupID string, attendeeID int64) error {
-	if grp, ok := r.groups[groupID]; ok {
+	defer r.lock()()
+	if grp, ok := r.data.groups[groupID]; ok {
 		if _, ok := grp.Bans[attendeeID]; !ok {
 			return gorm.ErrRecordNotFound
 		}
@@ -269,19 +495,179 @@ func (r *InMemoryRepository) RemoveGroupBan(ctx context.Context, groupID string,
 	}
 }
 
+// group wishes
+
+func (r *InMemoryRepository) GetGroupWish(_ context.Context, groupID string) (*entity.GroupWish, error) {
+	defer r.rlock()()
+	if gw, ok := r.data.groupWishes[groupID]; ok {
+		gwCopy := *gw
+		return &gwCopy, nil
+	} else {
+		return &entity.GroupWish{}, gorm.ErrRecordNotFound
+	}
+}
+
+func (r *InMemoryRepository) GetGroupWishes(_ context.Context) ([]*entity.GroupWish, error) {
+	defer r.rlock()()
+	result := make([]*entity.GroupWish, 0, len(r.data.groupWishes))
+	for _, gw := range r.data.groupWishes {
+		gwCopy := *gw
+		result = append(result, &gwCopy)
+	}
+	slices.SortFunc(result, func(a, b *entity.GroupWish) int {
+		return cmp.Compare(a.GroupID, b.GroupID)
+	})
+	return result, nil
+}
+
+func (r *InMemoryRepository) SetGroupWish(_ context.Context, wish *entity.GroupWish) error {
+	defer r.lock()()
+	now := r.Now()
+	if orig, ok := r.data.groupWishes[wish.GroupID]; ok {
+		wish.CreatedAt = orig.CreatedAt
+	} else {
+		wish.CreatedAt = now
+	}
+	wish.UpdatedAt = now
+	gwCopy := *wish
+	r.data.groupWishes[wish.GroupID] = &gwCopy
+	return nil
+}
+
+func (r *InMemoryRepository) DeleteGroupWish(_ context.Context, groupID string) error {
+	defer r.lock()()
+	if _, ok := r.data.groupWishes[groupID]; ok {
+		delete(r.data.groupWishes, groupID)
+		return nil
+	} else {
+		return gorm.ErrRecordNotFound
+	}
+}
+
+// blocks
+
+func (r *InMemoryRepository) GetBlocks(_ context.Context) ([]*entity.Block, error) {
+	defer r.rlock()()
+	result := make([]*entity.Block, 0)
+	for _, blk := range r.data.blocks {
+		if !blk.DeletedAt.Valid {
+			blkCopy := *blk
+			result = append(result, &blkCopy)
+		}
+	}
+	return result, nil
+}
+
+func (r *InMemoryRepository) AddBlock(_ context.Context, block *entity.Block) (string, error) {
+	defer r.lock()()
+	block.ID = uuid.NewString()
+	blkCopy := *block
+	r.data.blocks[block.ID] = &blkCopy
+	return block.ID, nil
+}
+
+func (r *InMemoryRepository) UpdateBlock(_ context.Context, block *entity.Block) error {
+	defer r.lock()()
+	if _, ok := r.data.blocks[block.ID]; ok {
+		blkCopy := *block
+		r.data.blocks[block.ID] = &blkCopy
+		return nil
+	} else {
+		return gorm.ErrRecordNotFound
+	}
+}
+
+func (r *InMemoryRepository) GetBlockByID(_ context.Context, id string) (*entity.Block, error) {
+	defer r.rlock()()
+	// allow deleted so history works
+	if result, ok := r.data.blocks[id]; ok {
+		blkCopy := *result
+		return &blkCopy, nil
+	} else {
+		return &entity.Block{}, gorm.ErrRecordNotFound
+	}
+}
+
+func (r *InMemoryRepository) DeleteBlockByID(_ context.Context, id string) error {
+	defer r.lock()()
+	if _, ok := r.data.blocks[id]; ok {
+		delete(r.data.blocks, id)
+		return nil
+	} else {
+		return gorm.ErrRecordNotFound
+	}
+}
+
+// room types
+
+func (r *InMemoryRepository) GetRoomTypes(_ context.Context) ([]*entity.RoomType, error) {
+	defer r.rlock()()
+	result := make([]*entity.RoomType, 0)
+	for _, rt := range r.data.roomTypes {
+		if !rt.DeletedAt.Valid {
+			rtCopy := *rt
+			result = append(result, &rtCopy)
+		}
+	}
+	return result, nil
+}
+
+func (r *InMemoryRepository) AddRoomType(_ context.Context, roomType *entity.RoomType) (string, error) {
+	defer r.lock()()
+	roomType.ID = uuid.NewString()
+	rtCopy := *roomType
+	r.data.roomTypes[roomType.ID] = &rtCopy
+	return roomType.ID, nil
+}
+
+func (r *InMemoryRepository) UpdateRoomType(_ context.Context, roomType *entity.RoomType) error {
+	defer r.lock()()
+	if _, ok := r.data.roomTypes[roomType.ID]; ok {
+		rtCopy := *roomType
+		r.data.roomTypes[roomType.ID] = &rtCopy
+		return nil
+	} else {
+		return gorm.ErrRecordNotFound
+	}
+}
+
+func (r *InMemoryRepository) GetRoomTypeByID(_ context.Context, id string) (*entity.RoomType, error) {
+	defer r.rlock()()
+	// allow deleted so history works
+	if result, ok := r.data.roomTypes[id]; ok {
+		rtCopy := *result
+		return &rtCopy, nil
+	} else {
+		return &entity.RoomType{}, gorm.ErrRecordNotFound
+	}
+}
+
+func (r *InMemoryRepository) DeleteRoomTypeByID(_ context.Context, id string) error {
+	defer r.lock()()
+	if _, ok := r.data.roomTypes[id]; ok {
+		delete(r.data.roomTypes, id)
+		return nil
+	} else {
+		return gorm.ErrRecordNotFound
+	}
+}
+
 // rooms
 
-func (r *InMemoryRepository) FindRooms(ctx context.Context, name string, minOccupancy uint, maxOccupancy int, minSize uint, maxSize uint, anyOfMemberID []int64) ([]string, error) {
-	result := make([]string, 0)
-	for _, rm := range r.rooms {
+func (r *InMemoryRepository) FindRooms(ctx context.Context, criteria database.FindRoomCriteria, opts *database.FindOptions) ([]string, int64, error) {
+	defer r.rlock()()
+	matching := make([]findEntry, 0)
+	for _, rm := range r.data.rooms {
 		if !rm.Room.DeletedAt.Valid {
-			if len(rm.Members) >= int(minOccupancy) &&
-				(maxOccupancy == -1 || len(rm.Members) <= maxOccupancy) &&
-				uint(rm.Room.Size) >= minSize &&
-				(maxSize == 0 || uint(rm.Room.Size) <= maxSize) &&
-				(name == "" || rm.Room.Name == name) {
-				matches := len(anyOfMemberID) == 0
-				for _, wantedID := range anyOfMemberID {
+			if len(rm.Members) >= int(criteria.MinOccupancy) &&
+				(criteria.MaxOccupancy == -1 || len(rm.Members) <= criteria.MaxOccupancy) &&
+				uint(rm.Room.Size) >= criteria.MinSize &&
+				(criteria.MaxSize == 0 || uint(rm.Room.Size) <= criteria.MaxSize) &&
+				(criteria.Name == "" || rm.Room.Name == criteria.Name) &&
+				(criteria.BlockID == "" || rm.Room.BlockID == criteria.BlockID) &&
+				(criteria.TypeID == "" || rm.Room.TypeID == criteria.TypeID) {
+				matches := len(criteria.AnyOfMemberID) == 0
+				for _, wantedID := range criteria.AnyOfMemberID {
 					for _, actualMember := range rm.Members {
 						if wantedID == actualMember.ID {
 							matches = true
@@ -289,18 +675,25 @@ func (r *InMemoryRepository) FindRooms(ctx context.Context, name string, minOccu
 					}
 				}
 				if matches {
-					result = append(result, rm.Room.ID)
+					matching = append(matching, findEntry{
+						id:        rm.Room.ID,
+						name:      rm.Room.Name,
+						size:      rm.Room.Size,
+						occupancy: len(rm.Members),
+						created:   rm.Room.CreatedAt,
+					})
 				}
 			}
 		}
 	}
-	slices.Sort(result)
-	return result, nil
+	result, total := applyFindOptions(matching, opts)
+	return result, total, nil
 }
 
 func (r *InMemoryRepository) GetRooms(ctx context.Context) ([]*entity.Room, error) {
+	defer r.rlock()()
 	result := make([]*entity.Room, 0)
-	for _, rm := range r.rooms {
+	for _, rm := range r.data.rooms {
 		if !rm.Room.DeletedAt.Valid {
 			rmCopy := rm.Room
 			result = append(result, &rmCopy)
@@ -310,14 +703,20 @@ func (r *InMemoryRepository) GetRooms(ctx context.Context) ([]*entity.Room, erro
 }
 
 func (r *InMemoryRepository) AddRoom(ctx context.Context, room *entity.Room) (string, error) {
+	defer r.lock()()
+	r.purgeDeletedRooms(room.BlockID, room.Name, "")
 	room.ID = uuid.NewString()
-	r.rooms[room.ID] = &IMRoom{Room: *room}
+	room.CreatedAt = r.Now()
+	room.UpdatedAt = room.CreatedAt
+	r.data.rooms[room.ID] = &IMRoom{Room: *room}
 	return room.ID, nil
 }
 
 func (r *InMemoryRepository) UpdateRoom(ctx context.Context, room *entity.Room) error {
-	if orig, ok := r.rooms[room.ID]; ok {
-		r.rooms[room.ID] = &IMRoom{
+	defer r.lock()()
+	if orig, ok := r.data.rooms[room.ID]; ok {
+		r.purgeDeletedRooms(room.BlockID, room.Name, room.ID)
+		r.data.rooms[room.ID] = &IMRoom{
 			Room:    *room,        // this makes a copy
 			Members: orig.Members, // keep members
 		}
@@ -328,8 +727,9 @@ func (r *InMemoryRepository) UpdateRoom(ctx context.Context, room *entity.Room)
 }
 
 func (r *InMemoryRepository) GetRoomByID(ctx context.Context, id string) (*entity.Room, error) {
-	// allow deleted so history and undelete work
-	if result, ok := r.rooms[id]; ok {
+	defer r.rlock()()
+	// like the database, soft deleted rooms are not found
+	if result, ok := r.data.rooms[id]; ok && !result.Room.DeletedAt.Valid {
 		roomCopy := result.Room
 		return &roomCopy, nil
 	} else {
@@ -337,15 +737,45 @@ func (r *InMemoryRepository) GetRoomByID(ctx context.Context, id string) (*entit
 	}
 }
 
+func (r *InMemoryRepository) LockRoomByID(ctx context.Context, id string) (*entity.Room, error) {
+	// inside a transaction, the write lock is already held, so this is as good as a row lock
+	return r.GetRoomByID(ctx, id)
+}
+
 func (r *InMemoryRepository) DeleteRoomByID(ctx context.Context, id string) error {
-	if _, ok := r.rooms[id]; ok {
-		delete(r.rooms, id)
+	defer r.lock()()
+	if rm, ok := r.data.rooms[id]; ok && !rm.Room.DeletedAt.Valid {
+		if len(rm.Members) > 0 {
+			// like the foreign key constraint in the database
+			return gorm.ErrForeignKeyViolated
+		}
+		rm.Room.DeletedAt = gorm.DeletedAt{Time: r.Now(), Valid: true}
 		return nil
 	} else {
 		return gorm.ErrRecordNotFound
 	}
 }
 
+func (r *InMemoryRepository) UndeleteRoomByID(_ context.Context, id string) error {
+	defer r.lock()()
+	if rm, ok := r.data.rooms[id]; ok && rm.Room.DeletedAt.Valid {
+		rm.Room.DeletedAt = gorm.DeletedAt{}
+		return nil
+	} else {
+		return gorm.ErrRecordNotFound
+	}
+}
+
+// purgeDeletedRooms permanently removes soft deleted rooms with the given block and name, except for exceptID,
+// like the database does to keep the name uniqueness constraint working.
+//
+// Expects the caller to hold the lock.
+func (r *InMemoryRepository) purgeDeletedRooms(blockID string, name string, exceptID string) {
+	maps.DeleteFunc(r.data.rooms, func(id string, rm *IMRoom) bool {
+		return rm.Room.DeletedAt.Valid && rm.Room.BlockID == blockID && rm.Room.Name == name && id != exceptID
+	})
+}
+
 // room members
 
 func (r *InMemoryRepository) NewEmptyRoomMembership(_ context.Context, roomID string, attendeeID int64) *entity.RoomMember {
@@ -356,7 +786,13 @@ func (r *InMemoryRepository) NewEmptyRoomMembership(_ context.Context, roomID st
 }
 
 func (r *InMemoryRepository) GetRoomMembershipByAttendeeID(_ context.Context, attendeeID int64) (*entity.RoomMember, error) {
-	for _, room := range r.rooms {
+	defer r.rlock()()
+	return r.findRoomMembership(attendeeID)
+}
+
+// findRoomMembership expects the caller to hold the lock.
+func (r *InMemoryRepository) findRoomMembership(attendeeID int64) (*entity.RoomMember, error) {
+	for _, room := range r.data.rooms {
 		for _, mem := range room.Members {
 			if mem.ID == attendeeID {
 				rmCopy := mem
@@ -370,7 +806,8 @@ func (r *InMemoryRepository) GetRoomMembershipByAttendeeID(_ context.Context, at
 }
 
 func (r *InMemoryRepository) GetRoomMembersByRoomID(ctx context.Context, roomID string) ([]*entity.RoomMember, error) {
-	if rm, ok := r.rooms[roomID]; ok {
+	defer r.rlock()()
+	if rm, ok := r.data.rooms[roomID]; ok {
 		result := make([]*entity.RoomMember, len(rm.Members))
 		for i := range rm.Members {
 			cpRoom := rm.Members[i]
@@ -383,11 +820,12 @@ func (r *InMemoryRepository) GetRoomMembersByRoomID(ctx context.Context, roomID
 }
 
 func (r *InMemoryRepository) AddRoomMembership(ctx context.Context, rm *entity.RoomMember) error {
-	_, err := r.GetRoomMembershipByAttendeeID(ctx, rm.ID)
+	defer r.lock()()
+	_, err := r.findRoomMembership(rm.ID)
 	if !errors.Is(err, gorm.ErrRecordNotFound) {
 		return gorm.ErrDuplicatedKey
 	}
-	if room, ok := r.rooms[rm.RoomID]; ok {
+	if room, ok := r.data.rooms[rm.RoomID]; ok {
 		room.Members = append(room.Members, *rm)
 		return nil
 	} else {
@@ -396,11 +834,12 @@ func (r *InMemoryRepository) AddRoomMembership(ctx context.Context, rm *entity.R
 }
 
 func (r *InMemoryRepository) UpdateRoomMembership(ctx context.Context, rm *entity.RoomMember) error {
-	_, err := r.GetRoomMembershipByAttendeeID(ctx, rm.ID)
+	defer r.lock()()
+	_, err := r.findRoomMembership(rm.ID)
 	if err != nil {
 		return err
 	}
-	if room, ok := r.rooms[rm.RoomID]; ok {
+	if room, ok := r.data.rooms[rm.RoomID]; ok {
 		updatedMembers := make([]entity.RoomMember, len(room.Members))
 		for i, m := range room.Members {
 			if m.ID == rm.ID {
@@ -417,11 +856,12 @@ func (r *InMemoryRepository) UpdateRoomMembership(ctx context.Context, rm *entit
 }
 
 func (r *InMemoryRepository) DeleteRoomMembership(ctx context.Context, attendeeID int64) error {
-	current, err := r.GetRoomMembershipByAttendeeID(ctx, attendeeID)
+	defer r.lock()()
+	current, err := r.findRoomMembership(attendeeID)
 	if err != nil {
 		return err
 	}
-	if grp, ok := r.rooms[current.RoomID]; ok {
+	if grp, ok := r.data.rooms[current.RoomID]; ok {
 		updatedMembers := make([]entity.RoomMember, 0)
 		for _, m := range grp.Members {
 			if m.ID != attendeeID {
@@ -435,20 +875,164 @@ func (r *InMemoryRepository) DeleteRoomMembership(ctx context.Context, attendeeI
 	}
 }
 
+func (r *InMemoryRepository) MoveRoomMemberships(ctx context.Context, targetRoomIDs map[int64]string) error {
+	defer r.lock()()
+	moved := make([]entity.RoomMember, 0, len(targetRoomIDs))
+	for id, roomID := range targetRoomIDs {
+		current, err := r.findRoomMembership(id)
+		if err != nil {
+			return err
+		}
+		if _, ok := r.data.rooms[roomID]; !ok {
+			return gorm.ErrForeignKeyViolated
+		}
+		current.RoomID = roomID
+		moved = append(moved, *current)
+	}
+	for _, room := range r.data.rooms {
+		room.Members = slices.DeleteFunc(slices.Clone(room.Members), func(m entity.RoomMember) bool {
+			_, ok := targetRoomIDs[m.ID]
+			return ok
+		})
+	}
+	for _, m := range moved {
+		room := r.data.rooms[m.RoomID]
+		room.Members = append(room.Members, m)
+	}
+	return nil
+}
+
+// events
+
+func (r *InMemoryRepository) HasProcessedEvent(_ context.Context, id string) (bool, error) {
+	defer r.rlock()()
+	_, ok := r.data.events[id]
+	return ok, nil
+}
+
+func (r *InMemoryRepository) AddProcessedEvent(_ context.Context, ev *entity.ProcessedEvent) error {
+	defer r.lock()()
+	if _, ok := r.data.events[ev.ID]; ok {
+		return gorm.ErrDuplicatedKey
+	}
+	evCopy := *ev
+	evCopy.CreatedAt = r.Now()
+	r.data.events[ev.ID] = &evCopy
+	return nil
+}
+
+func (r *InMemoryRepository) DeleteProcessedEvent(_ context.Context, id string) error {
+	defer r.lock()()
+	if _, ok := r.data.events[id]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	delete(r.data.events, id)
+	return nil
+}
+
 // history
 
 func (r *InMemoryRepository) RecordHistory(_ context.Context, h *entity.History) error {
-	newID := uint(atomic.AddUint32(&r.idSequence, 1))
+	defer r.lock()()
+	newID := uint(atomic.AddUint32(&r.data.idSequence, 1))
 	h.ID = newID
-	r.history[newID] = h
+	h.CreatedAt = r.Now()
+	h.UpdatedAt = h.CreatedAt
+	r.data.history[newID] = h
 	return nil
 }
 
+func (r *InMemoryRepository) GetHistory(_ context.Context, entityNames []string, entityID string, offset uint, limit uint) ([]*entity.History, int64, error) {
+	defer r.rlock()()
+	matching := make([]*entity.History, 0)
+	for _, h := range r.data.history {
+		if h.EntityId == entityID && slices.Contains(entityNames, h.Entity) {
+			hCopy := *h
+			matching = append(matching, &hCopy)
+		}
+	}
+	// ids are assigned in sequence
+	slices.SortFunc(matching, func(a, b *entity.History) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	total := int64(len(matching))
+	start := min(int(offset), len(matching))
+	end := min(start+int(limit), len(matching))
+	return matching[start:end], total, nil
+}
+
 // GetHistoryByID is only offered for testing, and only on the in memory db.
 func (r *InMemoryRepository) GetHistoryByID(_ context.Context, id uint) (*entity.History, error) {
-	if h, ok := r.history[id]; ok {
+	defer r.rlock()()
+	if h, ok := r.data.history[id]; ok {
 		return h, nil
 	} else {
 		return &entity.History{}, fmt.Errorf("cannot get history entry %d - not present", id)
 	}
 }
+
+// find helpers
+
+// findEntry holds the values of a group or room that FindOptions can sort by.
+type findEntry struct {
+	id        string
+	name      string
+	size      int64
+	occupancy int
+	created   time.Time
+}
+
+// applyFindOptions filters, sorts and pages the matches like the mysql implementation, and returns
+// the ids of the selected page together with the total number of matches.
+func applyFindOptions(matching []findEntry, opts *database.FindOptions) ([]string, int64) {
+	if opts == nil {
+		opts = &database.FindOptions{}
+	}
+
+	if opts.NameContains != "" {
+		search := strings.ToLower(opts.NameContains)
+		matching = slices.DeleteFunc(matching, func(e findEntry) bool {
+			return !strings.Contains(strings.ToLower(e.name), search)
+		})
+	}
+
+	slices.SortFunc(matching, func(a, b findEntry) int {
+		result := 0
+		switch opts.SortBy {
+		case database.SortByName:
+			result = cmp.Compare(strings.ToLower(a.name), strings.ToLower(b.name))
+		case database.SortBySize:
+			result = cmp.Compare(a.size, b.size)
+		case database.SortByOccupancy:
+			result = cmp.Compare(a.occupancy, b.occupancy)
+		case database.SortByCreated:
+			result = a.created.Compare(b.created)
+		default:
+			return cmp.Compare(a.id, b.id)
+		}
+		if opts.Descending {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+		if result = cmp.Compare(strings.ToLower(a.name), strings.ToLower(b.name)); result != 0 {
+			return result
+		}
+		return cmp.Compare(a.id, b.id)
+	})
+
+	total := int64(len(matching))
+	start := min(int(opts.Offset), len(matching))
+	end := len(matching)
+	if opts.Limit > 0 {
+		end = min(start+int(opts.Limit), len(matching))
+	}
+
+	result := make([]string, 0, end-start)
+	for _, e := range matching[start:end] {
+		result = append(result, e.id)
+	}
+	return result, total
+}
@@ -11,22 +11,41 @@ type Repository interface {
 	Close(ctx context.Context)
 	Migrate(ctx context.Context) error
 
+	// Transaction runs fn inside a database transaction.
+	//
+	// All reads and writes that should be part of the transaction must go through the repository
+	// passed to fn. If fn returns an error (or panics), all changes are rolled back, otherwise they are committed.
+	//
+	// Transactions must not be nested, and fn should not call downstream services,
+	// because locks are held until fn returns.
+	Transaction(ctx context.Context, fn func(tx Repository) error) error
+
 	// GetGroups returns all groups.
 	GetGroups(ctx context.Context) ([]*entity.Group, error)
 	// FindGroups returns IDs of all groups satisfying the criteria.
 	//
-	// Occupancy is the number of people actually in the group, as opposed to its maximum size.
-	// If maxOccupancy is set to -1, it will be ignored as a criterion.
-	//
-	// A group matches the list of badge numbers in anyOfMemberID if at least one of those badge numbers
-	// is a member of the group. An empty list or nil means no condition.
+	// opts adds a name search, and controls sorting and paging. nil sorts by id and returns all matches.
+	// The total number of matches, regardless of paging, is returned together with the IDs.
+	FindGroups(ctx context.Context, criteria FindGroupCriteria, opts *FindOptions) ([]string, int64, error)
+	// AddGroup adds a new group.
 	//
-	// If name is not the empty string, finds only groups of that name.
-	FindGroups(ctx context.Context, name string, minOccupancy uint, maxOccupancy int, anyOfMemberID []int64) ([]string, error)
+	// A soft deleted group with the same name is purged first, so it can no longer be restored.
+	// The same applies when UpdateGroup renames a group.
 	AddGroup(ctx context.Context, group *entity.Group) (string, error)
 	UpdateGroup(ctx context.Context, group *entity.Group) error
-	GetGroupByID(ctx context.Context, id string) (*entity.Group, error) // may return soft deleted entities!
+	GetGroupByID(ctx context.Context, id string) (*entity.Group, error) // does not return soft deleted entities
+	// LockGroupByID reads a group like GetGroupByID, but also locks it until the end of the current transaction.
+	//
+	// Use this to serialize read-check-write sequences that depend on the group's members and invitations.
+	LockGroupByID(ctx context.Context, id string) (*entity.Group, error)
+	// DeleteGroupByID soft deletes a group.
+	//
+	// All members, invitations and bans of the group must have been removed first.
 	DeleteGroupByID(ctx context.Context, id string) error
+	// UndeleteGroupByID restores a soft deleted group.
+	//
+	// Returns gorm.ErrRecordNotFound if there is no soft deleted group with this id.
+	UndeleteGroupByID(ctx context.Context, id string) error
 
 	// NewEmptyGroupMembership pre-fills required and internal fields, including the groupID and attendeeID.
 	NewEmptyGroupMembership(ctx context.Context, groupID string, attendeeID int64, nickname string) *entity.GroupMember
@@ -35,28 +54,68 @@ type Repository interface {
 	AddGroupMembership(ctx context.Context, gm *entity.GroupMember) error
 	UpdateGroupMembership(ctx context.Context, gm *entity.GroupMember) error
 	DeleteGroupMembership(ctx context.Context, attendeeID int64) error
+	// MoveGroupMemberships moves group members to another group all at once, keeping their flags
+	// and nicknames.
+	//
+	// The move is historized for each moved member, and for each group involved, listing the previous
+	// groups of all moved members.
+	MoveGroupMemberships(ctx context.Context, targetGroupID string, attendeeIDs []int64) error
 
 	HasGroupBan(ctx context.Context, groupID string, attendeeID int64) (bool, error)
+	// GetGroupBans returns the auto-decline list of a group, sorted by badge number.
+	GetGroupBans(ctx context.Context, groupID string) ([]*entity.GroupBan, error)
 	AddGroupBan(ctx context.Context, groupID string, attendeeID int64, comments string) error
 	RemoveGroupBan(ctx context.Context, groupID string, attendeeID int64) error
 
-	// FindRooms returns IDs of all groups satisfying the criteria.
-	//
-	// Occupancy is the number of people actually in the room, as opposed to its size, which is the number of beds
-	// in the room.
-	//
-	// A room matches the list of badge numbers in anyOfMemberID if at least one of those badge numbers
-	// is in the room. An empty list or nil means no condition.
+	// GetGroupWish returns the room wishes of a group, or gorm.ErrRecordNotFound if it has none.
+	GetGroupWish(ctx context.Context, groupID string) (*entity.GroupWish, error)
+	// GetGroupWishes returns the room wishes of all groups that have any, sorted by group id.
+	GetGroupWishes(ctx context.Context) ([]*entity.GroupWish, error)
+	// SetGroupWish adds or replaces the room wishes of the group wish.GroupID.
+	SetGroupWish(ctx context.Context, wish *entity.GroupWish) error
+	// DeleteGroupWish removes the room wishes of a group. Returns gorm.ErrRecordNotFound if it has none.
+	DeleteGroupWish(ctx context.Context, groupID string) error
+
+	// GetBlocks returns all blocks.
+	GetBlocks(ctx context.Context) ([]*entity.Block, error)
+	AddBlock(ctx context.Context, block *entity.Block) (string, error)
+	UpdateBlock(ctx context.Context, block *entity.Block) error
+	GetBlockByID(ctx context.Context, id string) (*entity.Block, error) // may return soft deleted entities!
+	DeleteBlockByID(ctx context.Context, id string) error
+
+	// GetRoomTypes returns all room types.
+	GetRoomTypes(ctx context.Context) ([]*entity.RoomType, error)
+	AddRoomType(ctx context.Context, roomType *entity.RoomType) (string, error)
+	UpdateRoomType(ctx context.Context, roomType *entity.RoomType) error
+	GetRoomTypeByID(ctx context.Context, id string) (*entity.RoomType, error) // may return soft deleted entities!
+	DeleteRoomTypeByID(ctx context.Context, id string) error
+
+	// FindRooms returns IDs of all rooms satisfying the criteria.
 	//
-	// For minOccupancy, minSize, maxSize a value of 0 means no condition (because all rooms satisfy these),
-	// for maxOccupancy a value of -1 means no condition (maxOccupancy=0 searches for empty rooms).
-	FindRooms(ctx context.Context, name string, minOccupancy uint, maxOccupancy int, minSize uint, maxSize uint, anyOfMemberID []int64) ([]string, error)
+	// opts adds a name search, and controls sorting and paging. nil sorts by id and returns all matches.
+	// The total number of matches, regardless of paging, is returned together with the IDs.
+	FindRooms(ctx context.Context, criteria FindRoomCriteria, opts *FindOptions) ([]string, int64, error)
 	// GetRooms returns all rooms.
 	GetRooms(ctx context.Context) ([]*entity.Room, error)
+	// AddRoom adds a new room.
+	//
+	// A soft deleted room with the same name in the same block is purged first, so it can no longer
+	// be restored. The same applies when UpdateRoom renames a room.
 	AddRoom(ctx context.Context, room *entity.Room) (string, error)
 	UpdateRoom(ctx context.Context, room *entity.Room) error
-	GetRoomByID(ctx context.Context, id string) (*entity.Room, error) // may return soft deleted entities!
+	GetRoomByID(ctx context.Context, id string) (*entity.Room, error) // does not return soft deleted entities
+	// LockRoomByID reads a room like GetRoomByID, but also locks it until the end of the current transaction.
+	//
+	// Use this to serialize read-check-write sequences that depend on the room's occupants.
+	LockRoomByID(ctx context.Context, id string) (*entity.Room, error)
+	// DeleteRoomByID soft deletes a room.
+	//
+	// All occupants must have been removed from the room first.
 	DeleteRoomByID(ctx context.Context, id string) error
+	// UndeleteRoomByID restores a soft deleted room.
+	//
+	// Returns gorm.ErrRecordNotFound if there is no soft deleted room with this id.
+	UndeleteRoomByID(ctx context.Context, id string) error
 
 	// NewEmptyRoomMembership pre-fills some required and internal fields, including the
 	// RoomID and attendeeID.
@@ -66,6 +125,90 @@ type Repository interface {
 	AddRoomMembership(ctx context.Context, rm *entity.RoomMember) error
 	UpdateRoomMembership(ctx context.Context, rm *entity.RoomMember) error
 	DeleteRoomMembership(ctx context.Context, attendeeID int64) error
+	// MoveRoomMemberships moves room occupants to other rooms all at once, keeping their stay dates, flags
+	// and check-in times. targetRoomIDs maps the badge number of each occupant to the room they move to.
+	//
+	// The move is historized for each moved occupant, and for each room involved, listing the previous
+	// and new rooms of all moved occupants.
+	MoveRoomMemberships(ctx context.Context, targetRoomIDs map[int64]string) error
+
+	// HasProcessedEvent checks whether an event with this id has already been recorded by AddProcessedEvent.
+	HasProcessedEvent(ctx context.Context, id string) (bool, error)
+	// AddProcessedEvent records that an event has been processed. Returns gorm.ErrDuplicatedKey if an event
+	// with the same id has already been recorded, also if it was recorded concurrently.
+	AddProcessedEvent(ctx context.Context, ev *entity.ProcessedEvent) error
+	// DeleteProcessedEvent removes the record of an event, so it can be processed again.
+	DeleteProcessedEvent(ctx context.Context, id string) error
 
 	RecordHistory(ctx context.Context, h *entity.History) error
+	// GetHistory returns history entries in chronological order, together with the total number of matching entries.
+	//
+	// An entry matches if its Entity is one of entityNames and its EntityId is exactly entityID.
+	// At most limit entries are returned, skipping the first offset entries.
+	GetHistory(ctx context.Context, entityNames []string, entityID string, offset uint, limit uint) ([]*entity.History, int64, error)
+}
+
+// FindGroupCriteria are the conditions for FindGroups. The zero value matches all groups, except for
+// MaxOccupancy, which must be -1 for no condition.
+//
+// Occupancy is the number of people actually in the group, as opposed to its maximum size.
+type FindGroupCriteria struct {
+	// Name finds only groups of that name. Empty means no condition.
+	Name string
+	// Flag finds only groups that have this flag. Empty means no condition.
+	Flag string
+	// MinOccupancy of 0 means no condition (because all groups satisfy it).
+	MinOccupancy uint
+	// MaxOccupancy of -1 means no condition (MaxOccupancy=0 searches for empty groups).
+	MaxOccupancy int
+	// AnyOfMemberID matches a group if at least one of these badge numbers is a member of the group.
+	// An empty list or nil means no condition.
+	AnyOfMemberID []int64
+}
+
+// FindRoomCriteria are the conditions for FindRooms. The zero value matches all rooms, except for
+// MaxOccupancy, which must be -1 for no condition.
+//
+// Occupancy is the number of people actually in the room, as opposed to its size, which is the number of beds
+// in the room.
+type FindRoomCriteria struct {
+	// Name finds only rooms of that name. Empty means no condition.
+	Name string
+	// BlockID finds only rooms in that block. Empty means no condition.
+	BlockID string
+	// TypeID finds only rooms of that room type. Empty means no condition.
+	TypeID string
+	// MinOccupancy of 0 means no condition (because all rooms satisfy it).
+	MinOccupancy uint
+	// MaxOccupancy of -1 means no condition (MaxOccupancy=0 searches for empty rooms).
+	MaxOccupancy int
+	// MinSize of 0 means no condition.
+	MinSize uint
+	// MaxSize of 0 means no condition.
+	MaxSize uint
+	// AnyOfMemberID matches a room if at least one of these badge numbers is in the room.
+	// An empty list or nil means no condition.
+	AnyOfMemberID []int64
+}
+
+// Sort keys for FindOptions.
+const (
+	SortByName      = "name"
+	SortBySize      = "size" // maximum size for groups, number of beds for rooms
+	SortByOccupancy = "occupancy"
+	SortByCreated   = "created"
+)
+
+// FindOptions adds a name search to FindGroups and FindRooms, and controls sorting and paging of the results.
+type FindOptions struct {
+	// NameContains finds only entries whose name contains this string, ignoring case. Empty means no condition.
+	NameContains string
+	// SortBy is one of the SortBy constants. Ties are broken by name, then id. Empty sorts by id only.
+	SortBy string
+	// Descending reverses the order of SortBy, but not of the tie-breakers.
+	Descending bool
+	// Offset is the number of entries to skip.
+	Offset uint
+	// Limit is the maximum number of entries to return. 0 means no limit.
+	Limit uint
 }
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	aulogging "github.com/StephanHCB/go-autumn-logging"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 	"gorm.io/gorm/schema"
 
@@ -71,15 +73,27 @@ func (r *MysqlRepository) Migrate(ctx context.Context) error {
 		&entity.Group{},
 		&entity.GroupBan{},
 		&entity.GroupMember{},
+		&entity.GroupWish{},
 		&entity.History{},
+		&entity.ProcessedEvent{},
 		&entity.Room{},
 		&entity.RoomMember{},
+		&entity.Block{},
+		&entity.RoomType{},
 	)
 	if err != nil {
 		aulogging.ErrorErrf(ctx, err, "failed to migrate mysql db: %s", err.Error())
 		return err
 	}
 
+	// room names used to be unique across all rooms, now they are only unique within their block
+	if r.db.Migrator().HasIndex(&entity.Room{}, "room_room_name_uidx") {
+		if err := r.db.Migrator().DropIndex(&entity.Room{}, "room_room_name_uidx"); err != nil {
+			aulogging.ErrorErrf(ctx, err, "failed to drop obsolete room name index during migration: %s", err.Error())
+			return err
+		}
+	}
+
 	err = r.createConstraintIfNotExists(ctx, "room_group_members", "room_group_members_groupid_fk",
 		"group_id", "room_groups", "id")
 	if err != nil {
@@ -104,6 +118,16 @@ func (r *MysqlRepository) Migrate(ctx context.Context) error {
 	return nil
 }
 
+func (r *MysqlRepository) Transaction(ctx context.Context, fn func(tx database.Repository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&MysqlRepository{
+			db:            tx,
+			connectString: r.connectString,
+			Now:           r.Now,
+		})
+	})
+}
+
 func (r *MysqlRepository) createConstraintIfNotExists(_ context.Context,
 	tableName string, constraintName string, fieldName string,
 	referencesTable string, referencesField string,
@@ -146,36 +170,52 @@ func (r *MysqlRepository) GetGroups(ctx context.Context) ([]*entity.Group, error
 	return getAllNonDeleted[entity.Group](ctx, r.db, groupDesc)
 }
 
-func (r *MysqlRepository) FindGroups(ctx context.Context, name string, minOccupancy uint, maxOccupancy int, anyOfMemberID []int64) ([]string, error) {
-	query, params := buildFindQuery(name, minOccupancy, maxOccupancy, anyOfMemberID)
+func (r *MysqlRepository) FindGroups(ctx context.Context, criteria database.FindGroupCriteria, opts *database.FindOptions) ([]string, int64, error) {
+	query, countQuery, params := buildFindQuery(criteria, opts)
 
-	return r.findGroupIDsByQuery(ctx, query, params)
+	result, err := r.findGroupIDsByQuery(ctx, query, params)
+	if err != nil {
+		return result, 0, err
+	}
+
+	total, err := r.countByQuery(ctx, countQuery, params, result, opts)
+	return result, total, err
 }
 
-func buildFindQuery(name string, minOccupancy uint, maxOccupancy int, anyOfMemberID []int64) (string, map[string]any) {
+var groupSortColumns = map[string]string{
+	database.SortByName:      "g.name",
+	database.SortBySize:      "g.maximum_size",
+	database.SortByOccupancy: "(SELECT count(*) FROM room_group_members m WHERE m.group_id = g.id)",
+	database.SortByCreated:   "g.created_at",
+}
+
+func buildFindQuery(criteria database.FindGroupCriteria, opts *database.FindOptions) (string, string, map[string]any) {
 	params := make(map[string]any)
 	query := strings.Builder{}
-	query.WriteString("SELECT g.id AS id FROM room_groups g WHERE (@use_named_params = 1) ")
+	query.WriteString("FROM room_groups g WHERE (@use_named_params = 1) ")
 	params["use_named_params"] = 1 // must always have at least one named param, or you get an error when using a param map
-	if name != "" {
+	if criteria.Name != "" {
 		query.WriteString("AND name = @name ")
-		params["name"] = name
+		params["name"] = criteria.Name
+	}
+	if criteria.Flag != "" {
+		query.WriteString("AND g.flags LIKE @flag ")
+		params["flag"] = "%," + escapeLike(criteria.Flag) + ",%"
 	}
-	if minOccupancy > 0 {
+	if criteria.MinOccupancy > 0 {
 		query.WriteString("AND (SELECT count(*) FROM room_group_members m WHERE m.group_id = g.id) >= @min_occ ")
-		params["min_occ"] = minOccupancy
+		params["min_occ"] = criteria.MinOccupancy
 	}
-	if maxOccupancy >= 0 {
+	if criteria.MaxOccupancy >= 0 {
 		query.WriteString("AND (SELECT count(*) FROM room_group_members m WHERE m.group_id = g.id) <= @max_occ ")
-		params["max_occ"] = maxOccupancy
+		params["max_occ"] = criteria.MaxOccupancy
 	}
-	if len(anyOfMemberID) > 0 {
+	if len(criteria.AnyOfMemberID) > 0 {
 		query.WriteString("AND (SELECT count(*) FROM room_group_members m WHERE m.group_id = g.id AND m.id IN ( @any_member_id )) > 0 ")
-		params["any_member_id"] = anyOfMemberID
+		params["any_member_id"] = criteria.AnyOfMemberID
 	}
 	query.WriteString("AND g.deleted_at IS NULL ")
-	query.WriteString("ORDER BY g.id")
-	return query.String(), params
+	return finishFindQuery("g", query.String(), params, groupSortColumns, opts)
 }
 
 func (r *MysqlRepository) findGroupIDsByQuery(ctx context.Context, query string, params map[string]any) ([]string, error) {
@@ -208,12 +248,18 @@ func (r *MysqlRepository) findGroupIDsByQuery(ctx context.Context, query string,
 }
 
 func (r *MysqlRepository) AddGroup(ctx context.Context, group *entity.Group) (string, error) {
+	if err := purgeDeleted[entity.Group](ctx, r.db, groupDesc, "name = ?", group.Name); err != nil {
+		return "", err
+	}
 	group.ID = uuid.NewString()
 	err := add[entity.Group](ctx, r.db, group, groupDesc)
 	return group.ID, err
 }
 
 func (r *MysqlRepository) UpdateGroup(ctx context.Context, group *entity.Group) error {
+	if err := purgeDeleted[entity.Group](ctx, r.db, groupDesc, "name = ? AND id <> ?", group.Name, group.ID); err != nil {
+		return err
+	}
 	return update[entity.Group](ctx, r.db, group, groupDesc)
 }
 
@@ -221,8 +267,16 @@ func (r *MysqlRepository) GetGroupByID(ctx context.Context, id string) (*entity.
 	return getByID[entity.Group](ctx, r.db, id, groupDesc)
 }
 
+func (r *MysqlRepository) LockGroupByID(ctx context.Context, id string) (*entity.Group, error) {
+	return lockByID[entity.Group](ctx, r.db, id, groupDesc)
+}
+
 func (r *MysqlRepository) DeleteGroupByID(ctx context.Context, id string) error {
-	return deleteByID[entity.Group](ctx, r.db, id, groupDesc)
+	return softDeleteByID[entity.Group](ctx, r.db, id, groupDesc)
+}
+
+func (r *MysqlRepository) UndeleteGroupByID(ctx context.Context, id string) error {
+	return undeleteByID[entity.Group](ctx, r.db, id, groupDesc)
 }
 
 func (r *MysqlRepository) NewEmptyGroupMembership(_ context.Context, groupID string, attendeeID int64, nickname string) *entity.GroupMember {
@@ -258,6 +312,26 @@ func (r *MysqlRepository) DeleteGroupMembership(ctx context.Context, attendeeID
 	return deleteMembership[entity.GroupMember](ctx, r.db, attendeeID, groupMembershipDesc)
 }
 
+func (r *MysqlRepository) MoveGroupMemberships(ctx context.Context, targetGroupID string, attendeeIDs []int64) error {
+	// sorted, so concurrent moves lock the rows in the same order
+	ids := slices.Clone(attendeeIDs)
+	slices.Sort(ids)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, id := range ids {
+			result := tx.Model(&entity.GroupMember{}).Where("id = ?", id).Update("group_id", targetGroupID)
+			if result.Error != nil {
+				aulogging.WarnErrf(ctx, result.Error, "mysql error during %s move: %s", groupMembershipDesc, result.Error.Error())
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				aulogging.Warnf(ctx, "mysql error during %s move - no %s for attendee %d", groupMembershipDesc, groupMembershipDesc, id)
+				return gorm.ErrRecordNotFound
+			}
+		}
+		return nil
+	})
+}
+
 func (r *MysqlRepository) getGroupBan(ctx context.Context, groupID string, attendeeID int64) (*entity.GroupBan, error) {
 	var gb entity.GroupBan
 	if err := r.db.First(&gb, "id = ? and group_id = ?", attendeeID, groupID).Error; err != nil {
@@ -275,6 +349,15 @@ func (r *MysqlRepository) HasGroupBan(ctx context.Context, groupID string, atten
 	return gb != nil, err
 }
 
+func (r *MysqlRepository) GetGroupBans(ctx context.Context, groupID string) ([]*entity.GroupBan, error) {
+	result := make([]*entity.GroupBan, 0)
+	if err := r.db.Where("group_id = ?", groupID).Order("id").Find(&result).Error; err != nil {
+		aulogging.WarnErrf(ctx, err, "mysql error during group ban select: %s", err.Error())
+		return result, err
+	}
+	return result, nil
+}
+
 func (r *MysqlRepository) AddGroupBan(ctx context.Context, groupID string, attendeeID int64, comments string) error {
 	exists, err := r.HasGroupBan(ctx, groupID, attendeeID)
 	if err != nil {
@@ -317,47 +400,211 @@ func (r *MysqlRepository) RemoveGroupBan(ctx context.Context, groupID string, at
 	return nil
 }
 
+func (r *MysqlRepository) GetGroupWish(ctx context.Context, groupID string) (*entity.GroupWish, error) {
+	var gw entity.GroupWish
+	if err := r.db.First(&gw, "group_id = ?", groupID).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			aulogging.WarnErrf(ctx, err, "mysql error during group wish select: %s", err.Error())
+		}
+		return &gw, err
+	}
+	return &gw, nil
+}
+
+func (r *MysqlRepository) GetGroupWishes(ctx context.Context) ([]*entity.GroupWish, error) {
+	result := make([]*entity.GroupWish, 0)
+	if err := r.db.Order("group_id").Find(&result).Error; err != nil {
+		aulogging.WarnErrf(ctx, err, "mysql error during group wish select: %s", err.Error())
+		return result, err
+	}
+	return result, nil
+}
+
+func (r *MysqlRepository) SetGroupWish(ctx context.Context, wish *entity.GroupWish) error {
+	if err := r.db.Save(wish).Error; err != nil {
+		aulogging.WarnErrf(ctx, err, "mysql error during group wish save: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+func (r *MysqlRepository) DeleteGroupWish(ctx context.Context, groupID string) error {
+	gw, err := r.GetGroupWish(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	if err := r.db.Delete(gw).Error; err != nil {
+		aulogging.WarnErrf(ctx, err, "mysql error during group wish delete - deletion failed: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+const blockDesc = "block"
+
+func (r *MysqlRepository) GetBlocks(ctx context.Context) ([]*entity.Block, error) {
+	return getAllNonDeleted[entity.Block](ctx, r.db, blockDesc)
+}
+
+func (r *MysqlRepository) AddBlock(ctx context.Context, block *entity.Block) (string, error) {
+	block.ID = uuid.NewString()
+	err := add[entity.Block](ctx, r.db, block, blockDesc)
+	return block.ID, err
+}
+
+func (r *MysqlRepository) UpdateBlock(ctx context.Context, block *entity.Block) error {
+	return update[entity.Block](ctx, r.db, block, blockDesc)
+}
+
+func (r *MysqlRepository) GetBlockByID(ctx context.Context, id string) (*entity.Block, error) {
+	return getByID[entity.Block](ctx, r.db, id, blockDesc)
+}
+
+func (r *MysqlRepository) DeleteBlockByID(ctx context.Context, id string) error {
+	return deleteByID[entity.Block](ctx, r.db, id, blockDesc)
+}
+
+const roomTypeDesc = "room type"
+
+func (r *MysqlRepository) GetRoomTypes(ctx context.Context) ([]*entity.RoomType, error) {
+	return getAllNonDeleted[entity.RoomType](ctx, r.db, roomTypeDesc)
+}
+
+func (r *MysqlRepository) AddRoomType(ctx context.Context, roomType *entity.RoomType) (string, error) {
+	roomType.ID = uuid.NewString()
+	err := add[entity.RoomType](ctx, r.db, roomType, roomTypeDesc)
+	return roomType.ID, err
+}
+
+func (r *MysqlRepository) UpdateRoomType(ctx context.Context, roomType *entity.RoomType) error {
+	return update[entity.RoomType](ctx, r.db, roomType, roomTypeDesc)
+}
+
+func (r *MysqlRepository) GetRoomTypeByID(ctx context.Context, id string) (*entity.RoomType, error) {
+	return getByID[entity.RoomType](ctx, r.db, id, roomTypeDesc)
+}
+
+func (r *MysqlRepository) DeleteRoomTypeByID(ctx context.Context, id string) error {
+	return deleteByID[entity.RoomType](ctx, r.db, id, roomTypeDesc)
+}
+
 const roomDesc = "room"
 
-func (r *MysqlRepository) FindRooms(ctx context.Context, name string, minOccupancy uint, maxOccupancy int, minSize uint, maxSize uint, anyOfMemberID []int64) ([]string, error) {
-	query, params := buildFindRoomQuery(name, minOccupancy, maxOccupancy, minSize, maxSize, anyOfMemberID)
+func (r *MysqlRepository) FindRooms(ctx context.Context, criteria database.FindRoomCriteria, opts *database.FindOptions) ([]string, int64, error) {
+	query, countQuery, params := buildFindRoomQuery(criteria, opts)
+
+	result, err := r.findRoomIDsByQuery(ctx, query, params)
+	if err != nil {
+		return result, 0, err
+	}
+
+	total, err := r.countByQuery(ctx, countQuery, params, result, opts)
+	return result, total, err
+}
 
-	return r.findRoomIDsByQuery(ctx, query, params)
+var roomSortColumns = map[string]string{
+	database.SortByName:      "r.name",
+	database.SortBySize:      "r.size",
+	database.SortByOccupancy: "(SELECT count(*) FROM room_room_members m WHERE m.room_id = r.id)",
+	database.SortByCreated:   "r.created_at",
 }
 
-func buildFindRoomQuery(name string, minOccupancy uint, maxOccupancy int, minSize uint, maxSize uint, anyOfMemberID []int64) (string, map[string]any) {
+func buildFindRoomQuery(criteria database.FindRoomCriteria, opts *database.FindOptions) (string, string, map[string]any) {
 	params := make(map[string]any)
 	query := strings.Builder{}
-	query.WriteString("SELECT r.id AS id FROM room_rooms r WHERE (@use_named_params = 1) ")
+	query.WriteString("FROM room_rooms r WHERE (@use_named_params = 1) ")
 	params["use_named_params"] = 1 // must always have at least one named param, or you get an error when using a param map
 
-	if name != "" {
+	if criteria.Name != "" {
 		query.WriteString("AND r.name = @name ")
-		params["name"] = name
+		params["name"] = criteria.Name
+	}
+	if criteria.BlockID != "" {
+		query.WriteString("AND r.block_id = @block_id ")
+		params["block_id"] = criteria.BlockID
+	}
+	if criteria.TypeID != "" {
+		query.WriteString("AND r.type_id = @type_id ")
+		params["type_id"] = criteria.TypeID
 	}
-	if minOccupancy > 0 {
+	if criteria.MinOccupancy > 0 {
 		query.WriteString("AND (SELECT count(*) FROM room_room_members m WHERE m.room_id = r.id) >= @min_occ ")
-		params["min_occ"] = minOccupancy
+		params["min_occ"] = criteria.MinOccupancy
 	}
-	if maxOccupancy >= 0 {
+	if criteria.MaxOccupancy >= 0 {
 		query.WriteString("AND (SELECT count(*) FROM room_room_members m WHERE m.room_id = r.id) <= @max_occ ")
-		params["max_occ"] = maxOccupancy
+		params["max_occ"] = criteria.MaxOccupancy
 	}
-	if minSize > 0 {
+	if criteria.MinSize > 0 {
 		query.WriteString("AND r.size >= @min_size ")
-		params["min_size"] = minSize
+		params["min_size"] = criteria.MinSize
 	}
-	if maxSize > 0 {
+	if criteria.MaxSize > 0 {
 		query.WriteString("AND r.size <= @max_size ")
-		params["max_size"] = maxSize
+		params["max_size"] = criteria.MaxSize
 	}
-	if len(anyOfMemberID) > 0 {
+	if len(criteria.AnyOfMemberID) > 0 {
 		query.WriteString("AND (SELECT count(*) FROM room_room_members m WHERE m.room_id = r.id AND m.id IN ( @any_member_id )) > 0 ")
-		params["any_member_id"] = anyOfMemberID
+		params["any_member_id"] = criteria.AnyOfMemberID
 	}
 	query.WriteString("AND r.deleted_at IS NULL ")
-	query.WriteString("ORDER BY r.id")
-	return query.String(), params
+	return finishFindQuery("r", query.String(), params, roomSortColumns, opts)
+}
+
+// finishFindQuery adds the name search, sorting and paging from opts to the conditions in fromWhere,
+// and returns the query for the ids and the query for the total count.
+func finishFindQuery(alias string, fromWhere string, params map[string]any, sortColumns map[string]string, opts *database.FindOptions) (string, string, map[string]any) {
+	if opts == nil {
+		opts = &database.FindOptions{}
+	}
+
+	if opts.NameContains != "" {
+		fromWhere += "AND LOWER(" + alias + ".name) LIKE @name_search "
+		params["name_search"] = "%" + escapeLike(strings.ToLower(opts.NameContains)) + "%"
+	}
+
+	order := alias + ".id"
+	if column, ok := sortColumns[opts.SortBy]; ok {
+		direction := "ASC"
+		if opts.Descending {
+			direction = "DESC"
+		}
+		order = column + " " + direction + ", " + alias + ".name, " + alias + ".id"
+	}
+
+	query := "SELECT " + alias + ".id AS id " + fromWhere + "ORDER BY " + order
+	if opts.Limit > 0 {
+		query += " LIMIT @limit OFFSET @offset"
+		params["limit"] = opts.Limit
+		params["offset"] = opts.Offset
+	} else if opts.Offset > 0 {
+		// mysql does not support OFFSET without LIMIT
+		query += " LIMIT 18446744073709551615 OFFSET @offset"
+		params["offset"] = opts.Offset
+	}
+
+	countQuery := "SELECT count(*) AS total " + fromWhere
+	return query, countQuery, params
+}
+
+// countByQuery determines the total number of matches for a find. Without paging, this is simply the number of ids found.
+func (r *MysqlRepository) countByQuery(ctx context.Context, countQuery string, params map[string]any, ids []string, opts *database.FindOptions) (int64, error) {
+	if opts == nil || (opts.Offset == 0 && opts.Limit == 0) {
+		return int64(len(ids)), nil
+	}
+
+	var total int64
+	if err := r.db.Raw(countQuery, params).Scan(&total).Error; err != nil {
+		aulogging.Logger.Ctx(ctx).Error().WithErr(err).Printf("error counting during find: %s", err.Error())
+		return 0, err
+	}
+	return total, nil
+}
+
+// escapeLike escapes the wildcard characters of a LIKE pattern, so value is matched literally.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
 }
 
 func (r *MysqlRepository) findRoomIDsByQuery(ctx context.Context, query string, params map[string]any) ([]string, error) {
@@ -394,12 +641,18 @@ func (r *MysqlRepository) GetRooms(ctx context.Context) ([]*entity.Room, error)
 }
 
 func (r *MysqlRepository) AddRoom(ctx context.Context, room *entity.Room) (string, error) {
+	if err := purgeDeleted[entity.Room](ctx, r.db, roomDesc, "block_id = ? AND name = ?", room.BlockID, room.Name); err != nil {
+		return "", err
+	}
 	room.ID = uuid.NewString()
 	err := add[entity.Room](ctx, r.db, room, roomDesc)
 	return room.ID, err
 }
 
 func (r *MysqlRepository) UpdateRoom(ctx context.Context, room *entity.Room) error {
+	if err := purgeDeleted[entity.Room](ctx, r.db, roomDesc, "block_id = ? AND name = ? AND id <> ?", room.BlockID, room.Name, room.ID); err != nil {
+		return err
+	}
 	return update[entity.Room](ctx, r.db, room, roomDesc)
 }
 
@@ -407,8 +660,16 @@ func (r *MysqlRepository) GetRoomByID(ctx context.Context, id string) (*entity.R
 	return getByID[entity.Room](ctx, r.db, id, roomDesc)
 }
 
+func (r *MysqlRepository) LockRoomByID(ctx context.Context, id string) (*entity.Room, error) {
+	return lockByID[entity.Room](ctx, r.db, id, roomDesc)
+}
+
 func (r *MysqlRepository) DeleteRoomByID(ctx context.Context, id string) error {
-	return deleteByID[entity.Room](ctx, r.db, id, roomDesc)
+	return softDeleteByID[entity.Room](ctx, r.db, id, roomDesc)
+}
+
+func (r *MysqlRepository) UndeleteRoomByID(ctx context.Context, id string) error {
+	return undeleteByID[entity.Room](ctx, r.db, id, roomDesc)
 }
 
 const roomMembershipDesc = "room membership"
@@ -442,6 +703,63 @@ func (r *MysqlRepository) DeleteRoomMembership(ctx context.Context, attendeeID i
 	return deleteMembership[entity.RoomMember](ctx, r.db, attendeeID, roomMembershipDesc)
 }
 
+func (r *MysqlRepository) MoveRoomMemberships(ctx context.Context, targetRoomIDs map[int64]string) error {
+	// sorted, so concurrent moves lock the rows in the same order
+	ids := make([]int64, 0, len(targetRoomIDs))
+	for id := range targetRoomIDs {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, id := range ids {
+			result := tx.Model(&entity.RoomMember{}).Where("id = ?", id).Update("room_id", targetRoomIDs[id])
+			if result.Error != nil {
+				aulogging.WarnErrf(ctx, result.Error, "mysql error during %s move: %s", roomMembershipDesc, result.Error.Error())
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				aulogging.Warnf(ctx, "mysql error during %s move - no %s for attendee %d", roomMembershipDesc, roomMembershipDesc, id)
+				return gorm.ErrRecordNotFound
+			}
+		}
+		return nil
+	})
+}
+
+func (r *MysqlRepository) HasProcessedEvent(ctx context.Context, id string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&entity.ProcessedEvent{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		aulogging.WarnErrf(ctx, err, "mysql error during processed event check: %s", err.Error())
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *MysqlRepository) AddProcessedEvent(ctx context.Context, ev *entity.ProcessedEvent) error {
+	// no check before the insert, the primary key rejects concurrent duplicates
+	if err := r.db.Create(ev).Error; err != nil {
+		if translator, ok := r.db.Dialector.(gorm.ErrorTranslator); ok && errors.Is(translator.Translate(err), gorm.ErrDuplicatedKey) {
+			aulogging.Infof(ctx, "attempt to add duplicate processed event %s", ev.ID)
+			return gorm.ErrDuplicatedKey
+		}
+		aulogging.WarnErrf(ctx, err, "mysql error during processed event insert: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+func (r *MysqlRepository) DeleteProcessedEvent(ctx context.Context, id string) error {
+	result := r.db.Delete(&entity.ProcessedEvent{}, "id = ?", id)
+	if result.Error != nil {
+		aulogging.WarnErrf(ctx, result.Error, "mysql error during processed event delete: %s", result.Error.Error())
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 func (r *MysqlRepository) RecordHistory(ctx context.Context, h *entity.History) error {
 	err := r.db.Create(h).Error
 	if err != nil {
@@ -450,10 +768,34 @@ func (r *MysqlRepository) RecordHistory(ctx context.Context, h *entity.History)
 	return err
 }
 
+func (r *MysqlRepository) GetHistory(ctx context.Context, entityNames []string, entityID string, offset uint, limit uint) ([]*entity.History, int64, error) {
+	result := make([]*entity.History, 0)
+	if len(entityNames) == 0 {
+		return result, 0, nil
+	}
+
+	matching := func() *gorm.DB {
+		return r.db.Model(&entity.History{}).Where("entity IN ? AND entity_id = ?", entityNames, entityID)
+	}
+
+	var total int64
+	if err := matching().Count(&total).Error; err != nil {
+		aulogging.WarnErrf(ctx, err, "mysql error during history count: %s", err.Error())
+		return result, 0, err
+	}
+
+	if err := matching().Order("id").Offset(int(offset)).Limit(int(limit)).Find(&result).Error; err != nil {
+		aulogging.WarnErrf(ctx, err, "mysql error during history select: %s", err.Error())
+		return result, 0, err
+	}
+
+	return result, total, nil
+}
+
 // generics to reduce repetitions
 
 type anyMemberCollection interface {
-	entity.Group | entity.Room
+	entity.Group | entity.Room | entity.Block | entity.RoomType
 }
 
 func getAllNonDeleted[E anyMemberCollection](
@@ -504,6 +846,21 @@ func getByID[E anyMemberCollection](
 	return &g, err
 }
 
+// lockByID performs a SELECT ... FOR UPDATE, which only has an effect inside a transaction.
+func lockByID[E anyMemberCollection](
+	ctx context.Context,
+	db *gorm.DB,
+	id string,
+	logDescription string,
+) (*E, error) {
+	var g E
+	err := db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&g, "id = ?", id).Error
+	if err != nil {
+		aulogging.InfoErrf(ctx, err, "mysql error during %s select for update - might be ok: %s", logDescription, err.Error())
+	}
+	return &g, err
+}
+
 func deleteByID[E anyMemberCollection](
 	ctx context.Context,
 	db *gorm.DB,
@@ -525,6 +882,66 @@ func deleteByID[E anyMemberCollection](
 	return nil
 }
 
+// softDeleteByID only sets the deletion timestamp, so the entity can be restored with undeleteByID.
+func softDeleteByID[E anyMemberCollection](
+	ctx context.Context,
+	db *gorm.DB,
+	id string,
+	logDescription string,
+) error {
+	var g E
+	err := db.First(&g, "id = ?", id).Error
+	if err != nil {
+		aulogging.WarnErrf(ctx, err, "mysql error during %s soft delete - %s not found: %s", logDescription, logDescription, err.Error())
+		return err
+	}
+	err = db.Delete(&g).Error
+	if err != nil {
+		aulogging.WarnErrf(ctx, err, "mysql error during %s soft delete - deletion failed: %s", logDescription, err.Error())
+		return err
+	}
+	return nil
+}
+
+// purgeDeleted permanently removes soft deleted entities matching the condition.
+//
+// Soft deleted rows still count for the uniqueness constraints, so they must make way before
+// a new or renamed entity can take over their name.
+func purgeDeleted[E anyMemberCollection](
+	ctx context.Context,
+	db *gorm.DB,
+	logDescription string,
+	query string,
+	args ...any,
+) error {
+	var g E
+	err := db.Unscoped().Where("deleted_at IS NOT NULL").Where(query, args...).Delete(&g).Error
+	if err != nil {
+		aulogging.WarnErrf(ctx, err, "mysql error during %s purge of soft deleted entries: %s", logDescription, err.Error())
+		return err
+	}
+	return nil
+}
+
+func undeleteByID[E anyMemberCollection](
+	ctx context.Context,
+	db *gorm.DB,
+	id string,
+	logDescription string,
+) error {
+	var g E
+	result := db.Unscoped().Model(&g).Where("id = ? AND deleted_at IS NOT NULL", id).Update("deleted_at", nil)
+	if result.Error != nil {
+		aulogging.WarnErrf(ctx, result.Error, "mysql error during %s undelete: %s", logDescription, result.Error.Error())
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		aulogging.Warnf(ctx, "mysql error during %s undelete - no soft deleted %s with id %s", logDescription, logDescription, id)
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 type anyMembership interface {
 	entity.GroupMember | entity.RoomMember
 }
@@ -76,6 +76,14 @@ type StatusDto struct {
 }
 
 func (i *Impl) GetStatus(ctx context.Context, id int64) (Status, error) {
+	return i.getStatus(ctx, i.myTokenClient, id)
+}
+
+func (i *Impl) GetStatusWithApiToken(ctx context.Context, id int64) (Status, error) {
+	return i.getStatus(ctx, i.apiTokenClient, id)
+}
+
+func (i *Impl) getStatus(ctx context.Context, client aurestclientapi.Client, id int64) (Status, error) {
 	url := fmt.Sprintf("%s/api/rest/v1/attendees/%d/status", i.baseUrl, id)
 	bodyDto := StatusDto{
 		Status: StatusDeleted,
@@ -83,7 +91,7 @@ func (i *Impl) GetStatus(ctx context.Context, id int64) (Status, error) {
 	response := aurestclientapi.ParsedResponse{
 		Body: &bodyDto,
 	}
-	err := i.myTokenClient.Perform(ctx, http.MethodGet, url, nil, &response)
+	err := client.Perform(ctx, http.MethodGet, url, nil, &response)
 	if response.Status == http.StatusNotFound {
 		// not really an error - this user has no registrations - treat as deleted
 		return StatusDeleted, nil
@@ -92,11 +100,19 @@ func (i *Impl) GetStatus(ctx context.Context, id int64) (Status, error) {
 }
 
 func (i *Impl) GetAttendee(ctx context.Context, id int64) (Attendee, error) {
+	return i.getAttendee(ctx, i.myTokenClient, id)
+}
+
+func (i *Impl) GetAttendeeWithApiToken(ctx context.Context, id int64) (Attendee, error) {
+	return i.getAttendee(ctx, i.apiTokenClient, id)
+}
+
+func (i *Impl) getAttendee(ctx context.Context, client aurestclientapi.Client, id int64) (Attendee, error) {
 	url := fmt.Sprintf("%s/api/rest/v1/attendees/%d", i.baseUrl, id)
 	bodyDto := Attendee{}
 	response := aurestclientapi.ParsedResponse{
 		Body: &bodyDto,
 	}
-	err := i.myTokenClient.Perform(ctx, http.MethodGet, url, nil, &response)
+	err := client.Perform(ctx, http.MethodGet, url, nil, &response)
 	return bodyDto, downstreams.ErrByStatus(err, response.Status)
 }
@@ -52,10 +52,22 @@ type AttendeeService interface {
 	// Forwards the jwt from the request.
 	GetStatus(ctx context.Context, id int64) (Status, error)
 
+	// GetStatusWithApiToken is GetStatus for requests that have no user token to forward, such as
+	// background jobs, the command line, and requests made with an api token.
+	//
+	// Uses the api token for full access, so access control must be performed in the implementation.
+	GetStatusWithApiToken(ctx context.Context, id int64) (Status, error)
+
 	// GetAttendee obtains part of the registration information for given attendee id.
 	//
-	// Used for internal nickname lookups, etc.
+	// If your request was made by an admin, you can read everyone's registration. A user can only read their own.
 	//
-	// Uses the api token for full access, so access control must be performed in the implementation.
+	// Forwards the jwt from the request.
 	GetAttendee(ctx context.Context, id int64) (Attendee, error)
+
+	// GetAttendeeWithApiToken is GetAttendee for requests that have no user token to forward, and for
+	// internal nickname lookups, such as the recipients of mails.
+	//
+	// Uses the api token for full access, so access control must be performed in the implementation.
+	GetAttendeeWithApiToken(ctx context.Context, id int64) (Attendee, error)
 }
@@ -58,6 +58,14 @@ func (m *MockImpl) ListMyRegistrationIds(ctx context.Context) ([]int64, error) {
 }
 
 func (m *MockImpl) GetStatus(ctx context.Context, id int64) (Status, error) {
+	if !hasUserToken(ctx) {
+		// the real attendee service responds with 401
+		return StatusDeleted, downstreams.ErrDownStreamUnavailable
+	}
+	return m.GetStatusWithApiToken(ctx, id)
+}
+
+func (m *MockImpl) GetStatusWithApiToken(ctx context.Context, id int64) (Status, error) {
 	if m.IsUnavailable {
 		return StatusDeleted, downstreams.ErrDownStreamUnavailable
 	}
@@ -71,6 +79,14 @@ func (m *MockImpl) GetStatus(ctx context.Context, id int64) (Status, error) {
 }
 
 func (m *MockImpl) GetAttendee(ctx context.Context, id int64) (Attendee, error) {
+	if !hasUserToken(ctx) {
+		// the real attendee service responds with 401
+		return Attendee{}, downstreams.ErrDownStreamUnavailable
+	}
+	return m.GetAttendeeWithApiToken(ctx, id)
+}
+
+func (m *MockImpl) GetAttendeeWithApiToken(ctx context.Context, id int64) (Attendee, error) {
 	if m.IsUnavailable {
 		return Attendee{}, downstreams.ErrDownStreamUnavailable
 	}
@@ -83,6 +99,13 @@ func (m *MockImpl) GetAttendee(ctx context.Context, id int64) (Attendee, error)
 	return attendee, nil
 }
 
+// hasUserToken is true if the request has a user token that the real implementation would forward.
+func hasUserToken(ctx context.Context) bool {
+	idToken, _ := ctx.Value(common.CtxKeyIDToken{}).(string)
+	accessToken, _ := ctx.Value(common.CtxKeyAccessToken{}).(string)
+	return idToken != "" || accessToken != ""
+}
+
 func (m *MockImpl) Reset() {
 	m.IdsBySubject = make(map[string][]int64)
 	m.StatusById = make(map[int64]Status)
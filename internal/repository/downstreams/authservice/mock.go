@@ -3,6 +3,7 @@ package authservice
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/eurofurence/reg-room-service/internal/application/common"
 )
@@ -18,6 +19,7 @@ type Mock interface {
 }
 
 type MockImpl struct {
+	mu               sync.Mutex
 	responses        map[string]UserInfoResponse
 	recording        []string
 	simulateGetError error
@@ -41,6 +43,9 @@ func (m *MockImpl) UserInfo(ctx context.Context) (UserInfoResponse, error) {
 	idToken, _ := ctx.Value(common.CtxKeyIDToken{}).(string)
 
 	accessToken, ok := ctx.Value(common.CtxKeyAccessToken{}).(string)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.simulatorEnabled && ok {
 		key := fmt.Sprintf("userinfo %s %s", idToken, accessToken)
 		m.recording = append(m.recording, key)
@@ -60,30 +65,48 @@ func (m *MockImpl) UserInfo(ctx context.Context) (UserInfoResponse, error) {
 }
 
 func (m *MockImpl) IsEnabled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	return m.simulatorEnabled
 }
 
 // only used in tests
 
 func (m *MockImpl) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.recording = make([]string, 0)
 	m.simulateGetError = nil
 	m.simulatorEnabled = false
 }
 
 func (m *MockImpl) Enable() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.simulatorEnabled = true
 }
 
 func (m *MockImpl) Recording() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	return m.recording
 }
 
 func (m *MockImpl) SimulateGetError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.simulateGetError = err
 }
 
 func (m *MockImpl) SetupResponse(idToken string, acToken string, response UserInfoResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	key := fmt.Sprintf("userinfo %s %s", idToken, acToken)
 	m.responses[key] = response
 }
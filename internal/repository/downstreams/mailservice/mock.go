@@ -1,6 +1,10 @@
 package mailservice
 
-import "context"
+import (
+	"context"
+	"slices"
+	"sync"
+)
 
 type Mock interface {
 	MailService
@@ -11,6 +15,7 @@ type Mock interface {
 }
 
 type MockImpl struct {
+	mu            sync.Mutex
 	recording     []MailSendDto
 	simulateError error
 }
@@ -27,6 +32,9 @@ func NewMock() Mock {
 }
 
 func (m *MockImpl) SendEmail(ctx context.Context, request MailSendDto) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.simulateError != nil {
 		return m.simulateError
 	}
@@ -37,14 +45,23 @@ func (m *MockImpl) SendEmail(ctx context.Context, request MailSendDto) error {
 // only used in tests
 
 func (m *MockImpl) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.recording = make([]MailSendDto, 0)
 	m.simulateError = nil
 }
 
 func (m *MockImpl) Recording() []MailSendDto {
-	return m.recording
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return slices.Clone(m.recording)
 }
 
 func (m *MockImpl) SimulateError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.simulateError = err
 }
@@ -0,0 +1,661 @@
+package assignmentservice
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/config"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+	"github.com/eurofurence/reg-room-service/internal/repository/downstreams"
+	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/attendeeservice"
+	"github.com/eurofurence/reg-room-service/internal/service/rbac"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"slices"
+	"strings"
+)
+
+const reasonNoRoom = "no room with enough free beds and all required flags"
+
+// groupCandidate is a group that has members who are not yet in a room.
+type groupCandidate struct {
+	id   string
+	name string
+	// requiredFlags are the group flags that are also room flags, plus the room flags required by service.flag_requirements
+	requiredFlags []string
+	// unplaced are the badge numbers of the members not yet in a room, sorted
+	unplaced []int64
+	// preferredRoomID is the room the other members of the group are in, if they are all in the same room
+	preferredRoomID string
+	// wish is the room wish of the group, or nil if it has none
+	wish *entity.GroupWish
+}
+
+// roomCandidate is a room with at least one free bed.
+type roomCandidate struct {
+	id      string
+	name    string
+	flags   []string
+	free    int64
+	size    int64
+	typeID  string
+	blockID string
+}
+
+// values used in modelsv1.UnmetWish.Unmet, named after the fields of modelsv1.GroupWishes
+const (
+	wishType       = "type"
+	wishSize       = "size"
+	wishRoomFlags  = "room_flags"
+	wishNearGroups = "near_groups"
+)
+
+func (s *assignmentService) PlanAssignment(ctx context.Context) (*modelsv1.AssignmentPlan, error) {
+	if err := adminOnly(ctx); err != nil {
+		return nil, err
+	}
+
+	groups, err := s.loadGroupCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rooms, err := s.loadRoomCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	groupRooms, err := s.loadGroupRooms(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return computePlan(groups, rooms, groupBlocks(groupRooms)), nil
+}
+
+func (s *assignmentService) ListUnmetWishes(ctx context.Context) (*modelsv1.UnmetWishList, error) {
+	if err := adminOnly(ctx); err != nil {
+		return nil, err
+	}
+
+	wishes, err := s.DB.GetGroupWishes(ctx)
+	if err != nil {
+		return nil, errGroupRead(ctx, err.Error())
+	}
+
+	groupRooms, err := s.loadGroupRooms(ctx)
+	if err != nil {
+		return nil, err
+	}
+	blocks := groupBlocks(groupRooms)
+
+	result := &modelsv1.UnmetWishList{
+		Groups: make([]modelsv1.UnmetWish, 0),
+	}
+	for _, wish := range wishes {
+		rooms := groupRooms[wish.GroupID]
+		if len(rooms) == 0 {
+			continue // deleted, or not placed yet
+		}
+
+		unmet := make(map[string]bool)
+		roomNames := make([]string, 0, len(rooms))
+		for _, room := range rooms {
+			for _, item := range unmetWishes(wish, toRoomCandidate(room, 0), blocks) {
+				unmet[item] = true
+			}
+			roomNames = append(roomNames, room.Name)
+		}
+		if len(unmet) == 0 {
+			continue
+		}
+
+		grp, err := s.DB.GetGroupByID(ctx, wish.GroupID)
+		if err != nil {
+			return nil, errGroupRead(ctx, err.Error())
+		}
+
+		slices.Sort(roomNames)
+		entry := modelsv1.UnmetWish{
+			GroupID:   grp.ID,
+			GroupName: grp.Name,
+			Rooms:     roomNames,
+			Unmet:     make([]string, 0, len(unmet)),
+		}
+		for _, item := range []string{wishType, wishSize, wishRoomFlags, wishNearGroups} {
+			if unmet[item] {
+				entry.Unmet = append(entry.Unmet, item)
+			}
+		}
+		result.Groups = append(result.Groups, entry)
+	}
+
+	slices.SortFunc(result.Groups, func(a, b modelsv1.UnmetWish) int {
+		if c := cmp.Compare(a.GroupName, b.GroupName); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.GroupID, b.GroupID)
+	})
+	return result, nil
+}
+
+func (s *assignmentService) ApplyAssignment(ctx context.Context, plan *modelsv1.AssignmentPlan) error {
+	if err := adminOnly(ctx); err != nil {
+		return err
+	}
+
+	if err := validatePlan(ctx, plan); err != nil {
+		return err
+	}
+
+	conf, err := config.GetApplicationConfig()
+	if err != nil {
+		return common.NewInternalServerError(ctx, common.InternalErrorMessage, common.Details("configuration not loaded"))
+	}
+
+	// downstream calls must not happen inside the transaction
+	nicknames := make(map[int64]string)
+	for _, assignment := range plan.Assignments {
+		for _, badgeNo := range assignment.BadgeNumbers {
+			attendee, err := s.validateAttending(ctx, badgeNo)
+			if err != nil {
+				return err
+			}
+			nicknames[badgeNo] = attendee.Nickname
+		}
+	}
+
+	return s.DB.Transaction(ctx, func(tx database.Repository) error {
+		for _, assignment := range plan.Assignments {
+			room, err := tx.LockRoomByID(ctx, assignment.RoomID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return common.NewNotFound(ctx, common.RoomIDNotFound, common.Details(fmt.Sprintf("room %s does not exist", assignment.RoomID)))
+				}
+				return errRoomRead(ctx, err.Error())
+			}
+
+			occupants, err := tx.GetRoomMembersByRoomID(ctx, room.ID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return errRoomRead(ctx, err.Error())
+			}
+			if int64(entity.PeakOccupancy(occupants, config.StayNights)+len(assignment.BadgeNumbers)) > room.Size {
+				return common.NewConflict(ctx, common.RoomSizeFull, common.Details(fmt.Sprintf("room %s does not have enough free beds", room.Name)))
+			}
+
+			// the plan may be stale, so group membership and flags are checked again under the room lock
+			grp, err := tx.GetGroupByID(ctx, assignment.GroupID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return common.NewNotFound(ctx, common.GroupIDNotFound, common.Details(fmt.Sprintf("group %s does not exist", assignment.GroupID)))
+				}
+				return errGroupRead(ctx, err.Error())
+			}
+			if err := checkFlagRequirements(ctx, room, grp); err != nil {
+				return err
+			}
+
+			for _, badgeNo := range assignment.BadgeNumbers {
+				gm, err := tx.GetGroupMembershipByAttendeeID(ctx, badgeNo)
+				if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+					return errGroupRead(ctx, err.Error())
+				}
+				if err != nil || gm.IsInvite || gm.GroupID != grp.ID {
+					return common.NewNotFound(ctx, common.GroupMemberNotFound, common.Details(fmt.Sprintf("attendee %d is not a member of group %s", badgeNo, grp.Name)))
+				}
+
+				if _, err := tx.GetRoomMembershipByAttendeeID(ctx, badgeNo); err == nil {
+					return common.NewConflict(ctx, common.RoomOccupantConflict, common.Details(fmt.Sprintf("attendee %d is already in a room", badgeNo)))
+				} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+					return errRoomRead(ctx, err.Error())
+				}
+
+				membership := tx.NewEmptyRoomMembership(ctx, room.ID, badgeNo)
+				membership.Nickname = nicknames[badgeNo]
+				membership.AvatarURL = conf.Service.AvatarURL(badgeNo)
+				if err := tx.AddRoomMembership(ctx, membership); err != nil {
+					return errRoomWrite(ctx, err.Error())
+				}
+			}
+
+			aulogging.Infof(ctx, "room assignment applied - room %s badges %v by %s", room.ID, assignment.BadgeNumbers, common.GetSubject(ctx))
+		}
+		return nil
+	})
+}
+
+// computePlan places the groups into the rooms.
+//
+// Groups with more unplaced members are placed first, so they get the best chance of finding a room.
+// All ties are broken by name, then id, so the result is deterministic.
+//
+// blocks lists the blocks in which each existing group has rooms, see groupBlocks. It is updated as groups are placed,
+// so wishes to be near a group can be met by rooms planned for that group.
+func computePlan(groups []*groupCandidate, rooms []*roomCandidate, blocks map[string][]string) *modelsv1.AssignmentPlan {
+	slices.SortFunc(groups, func(a, b *groupCandidate) int {
+		if c := cmp.Compare(len(b.unplaced), len(a.unplaced)); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.name, b.name); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.id, b.id)
+	})
+
+	plan := &modelsv1.AssignmentPlan{
+		Assignments: make([]modelsv1.Assignment, 0),
+		Unplaced:    make([]modelsv1.UnplacedGroup, 0),
+	}
+	for _, grp := range groups {
+		room := bestRoom(grp, rooms, blocks)
+		if room == nil {
+			plan.Unplaced = append(plan.Unplaced, modelsv1.UnplacedGroup{
+				GroupID:      grp.id,
+				GroupName:    grp.name,
+				BadgeNumbers: grp.unplaced,
+				Reason:       reasonNoRoom,
+			})
+			continue
+		}
+
+		room.free -= int64(len(grp.unplaced))
+		if room.blockID != "" && !slices.Contains(blocks[grp.id], room.blockID) {
+			blocks[grp.id] = append(blocks[grp.id], room.blockID)
+		}
+		plan.Assignments = append(plan.Assignments, modelsv1.Assignment{
+			GroupID:      grp.id,
+			GroupName:    grp.name,
+			RoomID:       room.id,
+			RoomName:     room.name,
+			BadgeNumbers: grp.unplaced,
+		})
+	}
+	return plan
+}
+
+// bestRoom finds the room for a group, or nil if none fits.
+//
+// If the other group members are already in a room that fits, that room is used. Otherwise, the room
+// that meets the most room wishes of the group is chosen, then the one with the fewest free beds,
+// then the one with the fewest flags the group does not need, so special rooms remain available for groups that need them.
+func bestRoom(grp *groupCandidate, rooms []*roomCandidate, blocks map[string][]string) *roomCandidate {
+	var best *roomCandidate
+	bestUnmet := 0
+	for _, room := range rooms {
+		if room.free < int64(len(grp.unplaced)) || !hasAllFlags(room.flags, grp.requiredFlags) {
+			continue
+		}
+		if room.id == grp.preferredRoomID {
+			return room
+		}
+		unmet := len(unmetWishes(grp.wish, room, blocks))
+		if best == nil || unmet < bestUnmet || (unmet == bestUnmet && roomCompare(room, best) < 0) {
+			best = room
+			bestUnmet = unmet
+		}
+	}
+	return best
+}
+
+// unmetWishes lists the room wishes of a group that the room does not meet. A nil wish is always met.
+//
+// A wish to be near a group is met if that group has a room in the same block. Groups that no longer
+// exist are ignored.
+func unmetWishes(wish *entity.GroupWish, room *roomCandidate, blocks map[string][]string) []string {
+	result := make([]string, 0)
+	if wish == nil {
+		return result
+	}
+
+	if wish.TypeID != "" && wish.TypeID != room.typeID {
+		result = append(result, wishType)
+	}
+	if room.size < wish.Size {
+		result = append(result, wishSize)
+	}
+	if !hasAllFlags(room.flags, entity.AggregateFlags(wish.RoomFlags)) {
+		result = append(result, wishRoomFlags)
+	}
+	for _, nearID := range entity.AggregateFlags(wish.NearGroups) {
+		nearBlocks, exists := blocks[nearID]
+		if exists && (room.blockID == "" || !slices.Contains(nearBlocks, room.blockID)) {
+			result = append(result, wishNearGroups)
+			break
+		}
+	}
+	return result
+}
+
+func roomCompare(a, b *roomCandidate) int {
+	// all candidate rooms have the required flags, so fewer flags means fewer unneeded flags
+	if c := cmp.Compare(a.free, b.free); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(len(a.flags), len(b.flags)); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.name, b.name); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.id, b.id)
+}
+
+func hasAllFlags(flags []string, required []string) bool {
+	for _, flag := range required {
+		if !slices.Contains(flags, flag) {
+			return false
+		}
+	}
+	return true
+}
+
+// --- loading ---
+
+func (s *assignmentService) loadGroupCandidates(ctx context.Context) ([]*groupCandidate, error) {
+	groupIDs, _, err := s.DB.FindGroups(ctx, database.FindGroupCriteria{MinOccupancy: 1, MaxOccupancy: -1}, nil)
+	if err != nil {
+		return nil, errGroupRead(ctx, err.Error())
+	}
+
+	result := make([]*groupCandidate, 0)
+	for _, id := range groupIDs {
+		grp, err := s.DB.GetGroupByID(ctx, id)
+		if err != nil {
+			return nil, errGroupRead(ctx, err.Error())
+		}
+
+		members, err := s.DB.GetGroupMembersByGroupID(ctx, id)
+		if err != nil {
+			return nil, errGroupRead(ctx, err.Error())
+		}
+
+		candidate := &groupCandidate{
+			id:       grp.ID,
+			name:     grp.Name,
+			unplaced: make([]int64, 0),
+		}
+		if wish, err := s.DB.GetGroupWish(ctx, id); err == nil {
+			candidate.wish = wish
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errGroupRead(ctx, err.Error())
+		}
+		candidate.requiredFlags = requiredFlags(entity.AggregateFlags(grp.Flags))
+
+		placedRoomIDs := make(map[string]bool)
+		for _, member := range members {
+			if member.IsInvite {
+				continue
+			}
+
+			roomMembership, err := s.DB.GetRoomMembershipByAttendeeID(ctx, member.ID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					candidate.unplaced = append(candidate.unplaced, member.ID)
+					continue
+				}
+				return nil, errRoomRead(ctx, err.Error())
+			}
+			placedRoomIDs[roomMembership.RoomID] = true
+		}
+
+		if len(candidate.unplaced) == 0 {
+			continue
+		}
+		slices.Sort(candidate.unplaced)
+		if len(placedRoomIDs) == 1 {
+			for roomID := range placedRoomIDs {
+				candidate.preferredRoomID = roomID
+			}
+		}
+		result = append(result, candidate)
+	}
+	return result, nil
+}
+
+func (s *assignmentService) loadRoomCandidates(ctx context.Context) ([]*roomCandidate, error) {
+	roomIDs, _, err := s.DB.FindRooms(ctx, database.FindRoomCriteria{MaxOccupancy: -1}, nil)
+	if err != nil {
+		return nil, errRoomRead(ctx, err.Error())
+	}
+
+	result := make([]*roomCandidate, 0)
+	for _, id := range roomIDs {
+		room, err := s.DB.GetRoomByID(ctx, id)
+		if err != nil {
+			return nil, errRoomRead(ctx, err.Error())
+		}
+
+		occupants, err := s.DB.GetRoomMembersByRoomID(ctx, id)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errRoomRead(ctx, err.Error())
+		}
+
+		// assigned attendees stay for the whole convention, so they need a bed on the busiest night
+		free := room.Size - int64(entity.PeakOccupancy(occupants, config.StayNights))
+		if free > 0 {
+			result = append(result, toRoomCandidate(room, free))
+		}
+	}
+	return result, nil
+}
+
+// loadGroupRooms lists the rooms the members of each existing group are in, sorted by name.
+//
+// Groups without any members in a room are present with an empty list.
+func (s *assignmentService) loadGroupRooms(ctx context.Context) (map[string][]*entity.Room, error) {
+	groups, err := s.DB.GetGroups(ctx)
+	if err != nil {
+		return nil, errGroupRead(ctx, err.Error())
+	}
+
+	roomsByID := make(map[string]*entity.Room)
+	result := make(map[string][]*entity.Room, len(groups))
+	for _, grp := range groups {
+		members, err := s.DB.GetGroupMembersByGroupID(ctx, grp.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errGroupRead(ctx, err.Error())
+		}
+
+		rooms := make([]*entity.Room, 0)
+		for _, member := range members {
+			if member.IsInvite {
+				continue
+			}
+
+			roomMembership, err := s.DB.GetRoomMembershipByAttendeeID(ctx, member.ID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					continue
+				}
+				return nil, errRoomRead(ctx, err.Error())
+			}
+
+			room, ok := roomsByID[roomMembership.RoomID]
+			if !ok {
+				room, err = s.DB.GetRoomByID(ctx, roomMembership.RoomID)
+				if err != nil {
+					return nil, errRoomRead(ctx, err.Error())
+				}
+				roomsByID[room.ID] = room
+			}
+			if !slices.Contains(rooms, room) {
+				rooms = append(rooms, room)
+			}
+		}
+		slices.SortFunc(rooms, func(a, b *entity.Room) int {
+			if c := cmp.Compare(a.Name, b.Name); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.ID, b.ID)
+		})
+		result[grp.ID] = rooms
+	}
+	return result, nil
+}
+
+// groupBlocks lists the blocks each group has rooms in, with an entry for every group in groupRooms.
+func groupBlocks(groupRooms map[string][]*entity.Room) map[string][]string {
+	result := make(map[string][]string, len(groupRooms))
+	for groupID, rooms := range groupRooms {
+		blocks := make([]string, 0)
+		for _, room := range rooms {
+			if room.BlockID != "" && !slices.Contains(blocks, room.BlockID) {
+				blocks = append(blocks, room.BlockID)
+			}
+		}
+		result[groupID] = blocks
+	}
+	return result
+}
+
+func toRoomCandidate(room *entity.Room, free int64) *roomCandidate {
+	return &roomCandidate{
+		id:      room.ID,
+		name:    room.Name,
+		flags:   entity.AggregateFlags(room.Flags),
+		free:    free,
+		size:    room.Size,
+		typeID:  room.TypeID,
+		blockID: room.BlockID,
+	}
+}
+
+// --- helpers ---
+
+func adminOnly(ctx context.Context) error {
+	validator, err := rbac.NewValidator(ctx)
+	if err != nil {
+		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
+		return common.NewInternalServerError(ctx, common.InternalErrorMessage, common.Details("unexpected error when parsing user claims"))
+	}
+
+	if !validator.IsAdmin() {
+		aulogging.Warnf(ctx, "unauthorized attempt to use room assignment by %s", common.GetSubject(ctx))
+		return common.NewForbidden(ctx, common.AuthForbidden, common.Details("you are not authorized for this operation - the attempt has been logged"))
+	}
+
+	return nil
+}
+
+func validatePlan(ctx context.Context, plan *modelsv1.AssignmentPlan) error {
+	seen := make(map[int64]bool)
+	for _, assignment := range plan.Assignments {
+		if err := uuid.Validate(assignment.RoomID); err != nil {
+			return errAssignmentInvalid(ctx, fmt.Sprintf("'%s' is not a valid room uuid", assignment.RoomID))
+		}
+		if err := uuid.Validate(assignment.GroupID); err != nil {
+			return errAssignmentInvalid(ctx, fmt.Sprintf("'%s' is not a valid group uuid", assignment.GroupID))
+		}
+		if len(assignment.BadgeNumbers) == 0 {
+			return errAssignmentInvalid(ctx, fmt.Sprintf("assignment to room %s lists no attendees", assignment.RoomID))
+		}
+		for _, badgeNo := range assignment.BadgeNumbers {
+			if badgeNo <= 0 {
+				return errAssignmentInvalid(ctx, "attendee badge number must be positive integer")
+			}
+			if seen[badgeNo] {
+				return errAssignmentInvalid(ctx, fmt.Sprintf("attendee %d is listed more than once", badgeNo))
+			}
+			seen[badgeNo] = true
+		}
+	}
+	return nil
+}
+
+func (s *assignmentService) validateAttending(ctx context.Context, badgeNo int64) (attendeeservice.Attendee, error) {
+	attendee, err := s.AttSrv.GetAttendeeWithApiToken(ctx, badgeNo)
+	if err != nil {
+		if errors.Is(err, downstreams.ErrDownStreamNotFound) {
+			return attendeeservice.Attendee{}, common.NewNotFound(ctx, common.NoSuchAttendee, common.Details(fmt.Sprintf("no such attendee %d", badgeNo)))
+		}
+		aulogging.WarnErrf(ctx, err, "failed to query for attendee with badge number %d: %s", badgeNo, err.Error())
+		return attendeeservice.Attendee{}, errDownstream(ctx)
+	}
+
+	status, err := s.AttSrv.GetStatusWithApiToken(ctx, badgeNo)
+	if err != nil {
+		aulogging.WarnErrf(ctx, err, "failed to obtain status for badge number %d: %s", badgeNo, err.Error())
+		return attendeeservice.Attendee{}, errDownstream(ctx)
+	}
+
+	switch status {
+	case attendeeservice.StatusApproved, attendeeservice.StatusPartiallyPaid, attendeeservice.StatusPaid, attendeeservice.StatusCheckedIn:
+		return attendee, nil
+	default:
+		return attendeeservice.Attendee{}, common.NewConflict(ctx, common.NotAttending, common.Details(fmt.Sprintf("registration of attendee %d is not in attending status", badgeNo)))
+	}
+}
+
+// checkFlagRequirements ensures the room has the flags the group requires, by the same rule the plan uses.
+//
+// Like when adding an occupant to a room without admin override, service.flag_mismatch_mode decides
+// whether a mismatch is refused or only logged.
+func checkFlagRequirements(ctx context.Context, room *entity.Room, grp *entity.Group) error {
+	roomFlags := entity.AggregateFlags(room.Flags)
+	missing := make([]string, 0)
+	for _, flag := range requiredFlags(entity.AggregateFlags(grp.Flags)) {
+		if !slices.Contains(roomFlags, flag) {
+			missing = append(missing, flag)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if config.FlagMismatchRefused() {
+		return common.NewConflict(ctx, common.RoomFlagsMismatch, common.Details(fmt.Sprintf("group %s requires room flags room %s does not have: %s", grp.Name, room.Name, strings.Join(missing, ","))))
+	}
+	aulogging.Warnf(ctx, "room %s lacks flags %v required by group %s - assigning anyway", room.ID, missing, grp.ID)
+	return nil
+}
+
+// requiredFlags lists the flags a room must have to take in a group with the given flags: the group flags
+// that are also room flags, plus the room flags required by service.flag_requirements.
+func requiredFlags(groupFlags []string) []string {
+	roomFlags := configuredRoomFlags()
+	result := make([]string, 0)
+	for _, flag := range groupFlags {
+		if slices.Contains(roomFlags, flag) {
+			result = append(result, flag)
+		}
+	}
+	for _, flag := range config.RequiredRoomFlags(groupFlags) {
+		if !slices.Contains(result, flag) {
+			result = append(result, flag)
+		}
+	}
+	return result
+}
+
+func configuredRoomFlags() []string {
+	conf, err := config.GetApplicationConfig()
+	if err != nil {
+		panic("configuration not loaded before call to configuredRoomFlags() - this is a bug")
+	}
+	return conf.Service.RoomFlags
+}
+
+func errAssignmentInvalid(ctx context.Context, details string) error {
+	return common.NewBadRequest(ctx, common.AssignmentDataInvalid, common.Details(details))
+}
+
+func errDownstream(ctx context.Context) error {
+	return common.NewBadGateway(ctx, common.DownstreamAttSrv, common.Details("downstream error when contacting attendee service"))
+}
+
+func errGroupRead(ctx context.Context, details string) error {
+	return common.NewInternalServerError(ctx, common.GroupReadError, common.Details(details))
+}
+
+func errRoomRead(ctx context.Context, details string) error {
+	return common.NewInternalServerError(ctx, common.RoomReadError, common.Details(details))
+}
+
+func errRoomWrite(ctx context.Context, details string) error {
+	return common.NewInternalServerError(ctx, common.RoomWriteError, common.Details(details))
+}
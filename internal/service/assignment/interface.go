@@ -0,0 +1,46 @@
+package assignmentservice
+
+import (
+	"context"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/attendeeservice"
+)
+
+// Service defines the interface for the service function implementations for the room assignment endpoints.
+//
+// All operations are only available to admins.
+type Service interface {
+	// PlanAssignment computes a proposal which places the members of all groups who are not yet in a room
+	// into rooms with enough free beds. This is a dry run, nothing is changed.
+	//
+	// Groups are kept together, and a group is only placed in a room that has all of the group's flags that
+	// are also configured as room flags (e.g. wheelchair), and all room flags that service.flag_requirements
+	// demands for the group's flags. Among the rooms that fit, those meeting the most of the group's room wishes
+	// (room type, size, room flags, same block as the groups it wants to be near) are preferred. Of these,
+	// the one with the fewest free beds is chosen, so larger rooms remain available for larger groups.
+	//
+	// The result only depends on the current contents of the database, so repeated calls give the same plan.
+	PlanAssignment(ctx context.Context) (*modelsv1.AssignmentPlan, error)
+	// ApplyAssignment adds the attendees to the rooms as listed in the plan.
+	//
+	// The plan is usually the result of PlanAssignment, possibly after review and modification by an admin.
+	// It is applied completely or not at all.
+	ApplyAssignment(ctx context.Context, plan *modelsv1.AssignmentPlan) error
+	// ListUnmetWishes lists the groups that have members in rooms that do not meet the group's room wishes.
+	//
+	// Groups without any members in a room are not listed, see PlanAssignment for these.
+	ListUnmetWishes(ctx context.Context) (*modelsv1.UnmetWishList, error)
+}
+
+func New(db database.Repository, attsrv attendeeservice.AttendeeService) Service {
+	return &assignmentService{
+		DB:     db,
+		AttSrv: attsrv,
+	}
+}
+
+type assignmentService struct {
+	DB     database.Repository
+	AttSrv attendeeservice.AttendeeService
+}
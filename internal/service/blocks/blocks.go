@@ -0,0 +1,247 @@
+package blockservice
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+	"github.com/eurofurence/reg-room-service/internal/service/rbac"
+	"gorm.io/gorm"
+	"net/url"
+	"slices"
+	"time"
+)
+
+const isoDateTimeFormat = "2006-01-02T15:04:05-07:00"
+
+func (b *blockService) FindBlocks(ctx context.Context) ([]*modelsv1.Block, error) {
+	if err := b.adminOnly(ctx, "(all)"); err != nil {
+		return nil, err
+	}
+
+	blocks, err := b.DB.GetBlocks(ctx)
+	if err != nil {
+		return nil, errBlockRead(ctx, err.Error())
+	}
+
+	result := make([]*modelsv1.Block, 0, len(blocks))
+	for _, blk := range blocks {
+		result = append(result, toBlock(blk))
+	}
+	slices.SortFunc(result, func(a, b *modelsv1.Block) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return result, nil
+}
+
+func (b *blockService) GetBlockByID(ctx context.Context, blockID string) (*modelsv1.Block, error) {
+	if err := b.adminOnly(ctx, blockID); err != nil {
+		return nil, err
+	}
+
+	blk, err := b.getBlock(ctx, b.DB, blockID)
+	if err != nil {
+		return nil, err
+	}
+	return toBlock(blk), nil
+}
+
+func (b *blockService) CreateBlock(ctx context.Context, block *modelsv1.BlockCreate) (string, error) {
+	if err := b.adminOnly(ctx, "(new)"); err != nil {
+		return "", err
+	}
+
+	validation := validate(block.Name, block.PublicStart, common.Deref(block.StaffStart), common.Deref(block.StaffBookingCode))
+	if len(validation) > 0 {
+		return "", common.NewBadRequest(ctx, common.BlockDataInvalid, validation)
+	}
+
+	var blockID string
+	err := b.DB.Transaction(ctx, func(tx database.Repository) error {
+		if err := b.checkNameUnused(ctx, tx, block.Name); err != nil {
+			return err
+		}
+
+		id, err := tx.AddBlock(ctx, &entity.Block{
+			Name:              block.Name,
+			Comments:          common.Deref(block.Comments),
+			PublicStart:       block.PublicStart,
+			PublicBookingCode: block.PublicBookingCode,
+			StaffStart:        common.Deref(block.StaffStart),
+			StaffBookingCode:  common.Deref(block.StaffBookingCode),
+		})
+		if err != nil {
+			return errBlockWrite(ctx, err.Error())
+		}
+		blockID = id
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	aulogging.Infof(ctx, "block %s (%s) created by %s", blockID, url.PathEscape(block.Name), common.GetSubject(ctx))
+	return blockID, nil
+}
+
+func (b *blockService) UpdateBlock(ctx context.Context, block *modelsv1.Block) error {
+	if err := b.adminOnly(ctx, block.ID); err != nil {
+		return err
+	}
+
+	return b.DB.Transaction(ctx, func(tx database.Repository) error {
+		dbBlock, err := b.getBlock(ctx, tx, block.ID)
+		if err != nil {
+			return err
+		}
+
+		validation := validate(block.Name, block.PublicStart, common.Deref(block.StaffStart), common.Deref(block.StaffBookingCode))
+		if len(validation) > 0 {
+			return common.NewBadRequest(ctx, common.BlockDataInvalid, validation)
+		}
+
+		if dbBlock.Name != block.Name {
+			if err := b.checkNameUnused(ctx, tx, block.Name); err != nil {
+				return err
+			}
+		}
+
+		// do not touch fields that we do not wish to change, like createdAt
+		dbBlock.Name = block.Name
+		dbBlock.Comments = common.Deref(block.Comments)
+		dbBlock.PublicStart = block.PublicStart
+		dbBlock.PublicBookingCode = block.PublicBookingCode
+		dbBlock.StaffStart = common.Deref(block.StaffStart)
+		dbBlock.StaffBookingCode = common.Deref(block.StaffBookingCode)
+
+		if err := tx.UpdateBlock(ctx, dbBlock); err != nil {
+			return errBlockWrite(ctx, err.Error())
+		}
+		return nil
+	})
+}
+
+func (b *blockService) DeleteBlock(ctx context.Context, blockID string) error {
+	if err := b.adminOnly(ctx, blockID); err != nil {
+		return err
+	}
+
+	return b.DB.Transaction(ctx, func(tx database.Repository) error {
+		if _, err := b.getBlock(ctx, tx, blockID); err != nil {
+			return err
+		}
+
+		roomIDs, _, err := tx.FindRooms(ctx, database.FindRoomCriteria{BlockID: blockID, MaxOccupancy: -1}, nil)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errBlockRead(ctx, err.Error())
+		}
+		if len(roomIDs) > 0 {
+			aulogging.Infof(ctx, "attempt to delete block %s that still has rooms - rejected", url.PathEscape(blockID))
+			return common.NewConflict(ctx, common.BlockNotEmpty, common.Details("block still has rooms - please delete them or move them to another block first"))
+		}
+
+		if err := tx.DeleteBlockByID(ctx, blockID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errBlockNotFound(ctx)
+			}
+			return errBlockWrite(ctx, err.Error())
+		}
+
+		aulogging.Infof(ctx, "block %s deleted by %s", blockID, common.GetSubject(ctx))
+		return nil
+	})
+}
+
+// --- helpers ---
+
+func (b *blockService) adminOnly(ctx context.Context, blockID string) error {
+	validator, err := rbac.NewValidator(ctx)
+	if err != nil {
+		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
+		return common.NewInternalServerError(ctx, common.InternalErrorMessage, common.Details("unexpected error when parsing user claims"))
+	}
+
+	if !validator.IsAdmin() && !validator.IsAPITokenCall() {
+		aulogging.Warnf(ctx, "unauthorized attempt to access admin-only block %s by %s", blockID, common.GetSubject(ctx))
+		return common.NewForbidden(ctx, common.AuthForbidden, common.Details("you are not authorized for this operation - the attempt has been logged"))
+	}
+	return nil
+}
+
+func (b *blockService) getBlock(ctx context.Context, db database.Repository, blockID string) (*entity.Block, error) {
+	blk, err := db.GetBlockByID(ctx, blockID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errBlockNotFound(ctx)
+		}
+		return nil, errBlockRead(ctx, err.Error())
+	}
+	if blk.DeletedAt.Valid {
+		return nil, errBlockNotFound(ctx)
+	}
+	return blk, nil
+}
+
+func (b *blockService) checkNameUnused(ctx context.Context, db database.Repository, name string) error {
+	blocks, err := db.GetBlocks(ctx)
+	if err != nil {
+		return errBlockRead(ctx, err.Error())
+	}
+	for _, blk := range blocks {
+		if blk.Name == name {
+			return common.NewConflict(ctx, common.BlockDataDuplicate, common.Details("another block with this name already exists"))
+		}
+	}
+	return nil
+}
+
+func validate(name string, publicStart string, staffStart string, staffBookingCode string) url.Values {
+	result := url.Values{}
+	if len(name) == 0 {
+		result.Set("name", "block name cannot be empty")
+	}
+	if len(name) > 50 {
+		result.Set("name", "block name too long, max 50 characters")
+	}
+	if _, err := time.Parse(isoDateTimeFormat, publicStart); err != nil {
+		result.Set("public_start", "public_start must be an ISO datetime such as 2024-01-20T19:00:00+01:00")
+	}
+	if staffStart != "" {
+		if _, err := time.Parse(isoDateTimeFormat, staffStart); err != nil {
+			result.Set("staff_start", "staff_start must be an ISO datetime such as 2024-01-19T19:00:00+01:00")
+		}
+	} else if staffBookingCode != "" {
+		result.Set("staff_start", "staff_start is required if there is a staff booking code")
+	}
+	return result
+}
+
+func toBlock(blk *entity.Block) *modelsv1.Block {
+	return &modelsv1.Block{
+		ID:                blk.ID,
+		Name:              blk.Name,
+		Comments:          common.ToOmitEmpty(blk.Comments),
+		PublicStart:       blk.PublicStart,
+		PublicBookingCode: blk.PublicBookingCode,
+		StaffStart:        common.ToOmitEmpty(blk.StaffStart),
+		StaffBookingCode:  common.ToOmitEmpty(blk.StaffBookingCode),
+	}
+}
+
+// --- errors ---
+
+func errBlockNotFound(ctx context.Context) error {
+	return common.NewNotFound(ctx, common.BlockIDNotFound, common.Details("block does not exist"))
+}
+
+func errBlockRead(ctx context.Context, details string) error {
+	return common.NewInternalServerError(ctx, common.BlockReadError, common.Details(details))
+}
+
+func errBlockWrite(ctx context.Context, details string) error {
+	return common.NewInternalServerError(ctx, common.BlockWriteError, common.Details(details))
+}
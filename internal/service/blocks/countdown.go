@@ -0,0 +1,165 @@
+package blockservice
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/config"
+	"gorm.io/gorm"
+	"math"
+	"slices"
+	"time"
+)
+
+const (
+	demoPublicSecret = "[demo-secret]"
+	demoStaffSecret  = "[demo-staff-secret]"
+)
+
+func (b *blockService) Countdown(ctx context.Context, blockID string, mockTime *time.Time) (*modelsv1.Countdown, error) {
+	staff := hasStaffClaim(ctx)
+
+	var blk *entity.Block
+	if blockID != "" {
+		found, err := b.DB.GetBlockByID(ctx, blockID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errBlockNotFound(ctx)
+			}
+			return nil, errBlockRead(ctx, err.Error())
+		}
+		if found.DeletedAt.Valid {
+			return nil, errBlockNotFound(ctx)
+		}
+		blk = found
+	} else {
+		blocks, err := b.DB.GetBlocks(ctx)
+		if err != nil {
+			return nil, errBlockRead(ctx, err.Error())
+		}
+		if len(blocks) == 0 {
+			blocks = configuredBlocks()
+		}
+		if len(blocks) == 0 {
+			return nil, common.NewNotFound(ctx, common.BlockIDNotFound, common.Details("no blocks have been set up yet"))
+		}
+
+		// the block that opens first for this caller, ties broken by name so the result is stable
+		blk = slices.MinFunc(blocks, func(a, b *entity.Block) int {
+			if c := targetTime(ctx, a, staff).Compare(targetTime(ctx, b, staff)); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.Name, b.Name)
+		})
+	}
+
+	useStaff := staff && blk.StaffStart != ""
+	target := targetTime(ctx, blk, staff)
+
+	var result *modelsv1.Countdown
+	if mockTime != nil {
+		if useStaff {
+			result = countdown(*mockTime, target, demoStaffSecret)
+		} else {
+			result = countdown(*mockTime, target, demoPublicSecret)
+		}
+	} else {
+		if useStaff {
+			result = countdown(b.Now(), target, blk.StaffBookingCode)
+		} else {
+			result = countdown(b.Now(), target, blk.PublicBookingCode)
+		}
+	}
+
+	result.Block = blk.ID
+	result.BlockName = blk.Name
+	return result, nil
+}
+
+// --- helpers ---
+
+func countdown(current time.Time, target time.Time, secret string) *modelsv1.Countdown {
+	seconds := int64(math.Round(target.Sub(current).Seconds()))
+
+	result := &modelsv1.Countdown{
+		CurrentTimeIsoDateTime: current.Format(isoDateTimeFormat),
+		TargetTimeIsoDateTime:  target.Format(isoDateTimeFormat),
+		CountdownSeconds:       seconds,
+	}
+
+	if seconds <= 0 {
+		result.CountdownSeconds = 0
+		result.Secret = secret
+	}
+
+	return result
+}
+
+// targetTime is the time at which the booking code of a block is revealed to the caller.
+func targetTime(ctx context.Context, blk *entity.Block, staff bool) time.Time {
+	if staff && blk.StaffStart != "" {
+		return parseTime(ctx, blk.StaffStart)
+	}
+	return parseTime(ctx, blk.PublicStart)
+}
+
+func hasStaffClaim(ctx context.Context) bool {
+	group := staffGroup()
+	if group != "" {
+		if common.HasGroup(ctx, group) {
+			user := common.GetSubject(ctx)
+			if user == "" {
+				aulogging.Warn(ctx, "staff claim found but user name not found - not allowing early access")
+				return false
+			}
+
+			aulogging.Infof(ctx, "staff claim found for user '%s' - allowing early access", user)
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseTime(ctx context.Context, targetStr string) time.Time {
+	t, err := time.Parse(isoDateTimeFormat, targetStr)
+	if err != nil {
+		aulogging.Warn(ctx, "target time invalid - returning a time in the far future")
+		return time.Unix(1<<63-62135596801, 999999999) // maximally in the future
+	}
+	return t
+}
+
+// configuredBlocks returns the implicit block from the deprecated go_live configuration, if any.
+//
+// It has no id, because it only exists until the first block is set up.
+func configuredBlocks() []*entity.Block {
+	conf, err := config.GetApplicationConfig()
+	if err != nil {
+		panic("configuration not loaded before call to configuredBlocks() - this is a bug")
+	}
+	if conf.GoLive.Public.StartISODatetime == "" {
+		return nil
+	}
+	return []*entity.Block{{
+		PublicStart:       conf.GoLive.Public.StartISODatetime,
+		PublicBookingCode: conf.GoLive.Public.BookingCode,
+		StaffStart:        conf.GoLive.Staff.StartISODatetime,
+		StaffBookingCode:  conf.GoLive.Staff.BookingCode,
+	}}
+}
+
+func staffGroup() string {
+	conf, err := config.GetApplicationConfig()
+	if err != nil {
+		panic("configuration not loaded before call to staffGroup() - this is a bug")
+	}
+	if conf.Security.Oidc.StaffGroup == "" {
+		return conf.GoLive.Staff.Group // deprecated
+	}
+	return conf.Security.Oidc.StaffGroup
+}
@@ -0,0 +1,48 @@
+package blockservice
+
+import (
+	"context"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+	"time"
+)
+
+// Service defines the interface for the service function implementations for the block and countdown endpoints.
+//
+// A block is a hotel or room contingent. Each block has its own booking code and go-live times.
+type Service interface {
+	// FindBlocks returns all blocks, sorted by name. Only available to admins and api token.
+	FindBlocks(ctx context.Context) ([]*modelsv1.Block, error)
+	// GetBlockByID returns a single block. Only available to admins and api token.
+	GetBlockByID(ctx context.Context, blockID string) (*modelsv1.Block, error)
+	// CreateBlock creates a new block and returns its id. Only available to admins and api token.
+	CreateBlock(ctx context.Context, block *modelsv1.BlockCreate) (string, error)
+	// UpdateBlock replaces the data of a block. Only available to admins and api token.
+	UpdateBlock(ctx context.Context, block *modelsv1.Block) error
+	// DeleteBlock deletes a block. Only available to admins and api token.
+	//
+	// Blocks that still have rooms cannot be deleted.
+	DeleteBlock(ctx context.Context, blockID string) error
+
+	// Countdown returns the time until booking opens for a block, and the booking code once it has.
+	//
+	// Callers with the staff group claim (see security.oidc.staff_group) count down to the staff go-live time
+	// and receive the staff booking code, if the block has one.
+	//
+	// If blockID is empty, the block that opens first for the caller is used.
+	//
+	// If mockTime is set, it is used instead of the current time, and only demo secrets are revealed.
+	Countdown(ctx context.Context, blockID string, mockTime *time.Time) (*modelsv1.Countdown, error)
+}
+
+func New(db database.Repository) Service {
+	return &blockService{
+		DB:  db,
+		Now: time.Now,
+	}
+}
+
+type blockService struct {
+	DB  database.Repository
+	Now func() time.Time
+}
@@ -0,0 +1,129 @@
+package groupservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+	"gorm.io/gorm"
+)
+
+func (g *groupService) GetGroupBans(ctx context.Context, groupID string) (*modelsv1.GroupBanList, error) {
+	adminPerm, err := g.groupOwnerAuthCheck(ctx, groupID, "auto-decline list")
+	if err != nil {
+		return nil, err
+	}
+
+	bans, err := g.DB.GetGroupBans(ctx, groupID)
+	if err != nil {
+		return nil, errGroupRead(ctx, err.Error())
+	}
+
+	result := &modelsv1.GroupBanList{
+		Bans: make([]modelsv1.GroupBan, 0, len(bans)),
+	}
+	for _, ban := range bans {
+		apiBan := modelsv1.GroupBan{
+			ID: ban.ID,
+		}
+		if adminPerm && ban.Comments != "" {
+			comments := ban.Comments
+			apiBan.Comments = &comments
+		}
+		result.Bans = append(result.Bans, apiBan)
+	}
+	return result, nil
+}
+
+func (g *groupService) AddGroupBan(ctx context.Context, req *GroupBanParams) error {
+	if _, err := g.groupOwnerAuthCheck(ctx, req.GroupID, "auto-decline list"); err != nil {
+		return err
+	}
+
+	return g.DB.Transaction(ctx, func(tx database.Repository) error {
+		banned, err := tx.HasGroupBan(ctx, req.GroupID, req.BadgeNumber)
+		if err != nil {
+			return errGroupRead(ctx, err.Error())
+		}
+		if banned {
+			return common.NewConflict(ctx, common.GroupBanDuplicate, common.Details("this attendee is already on the auto-decline list of this group"))
+		}
+
+		aulogging.Infof(ctx, "group ban added - group %s badge %d by %s", req.GroupID, req.BadgeNumber, common.GetSubject(ctx))
+		comment := fmt.Sprintf("group ban added by %s", common.GetSubject(ctx))
+		if err := tx.AddGroupBan(ctx, req.GroupID, req.BadgeNumber, comment); err != nil {
+			return errGroupWrite(ctx, err.Error())
+		}
+		return nil
+	})
+}
+
+func (g *groupService) RemoveGroupBan(ctx context.Context, req *GroupBanParams) error {
+	if _, err := g.groupOwnerAuthCheck(ctx, req.GroupID, "auto-decline list"); err != nil {
+		return err
+	}
+
+	return g.DB.Transaction(ctx, func(tx database.Repository) error {
+		banned, err := tx.HasGroupBan(ctx, req.GroupID, req.BadgeNumber)
+		if err != nil {
+			return errGroupRead(ctx, err.Error())
+		}
+		if !banned {
+			return common.NewNotFound(ctx, common.GroupBanNotFound, common.Details("this attendee is not on the auto-decline list of this group"))
+		}
+
+		aulogging.Infof(ctx, "group ban removed - group %s badge %d by %s", req.GroupID, req.BadgeNumber, common.GetSubject(ctx))
+		if err := tx.RemoveGroupBan(ctx, req.GroupID, req.BadgeNumber); err != nil {
+			return errGroupWrite(ctx, err.Error())
+		}
+		return nil
+	})
+}
+
+// internals
+
+// groupOwnerAuthCheck ensures the group exists, and that the logged-in user is its owner or an admin.
+//
+// what names the part of the group that is being accessed, for the error message.
+// Returns whether the request was made with admin permissions.
+func (g *groupService) groupOwnerAuthCheck(ctx context.Context, groupID string, what string) (bool, error) {
+	adminPerm, loggedInAttendee, err := g.groupMembershipAuthCheck(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	grp, err := g.DB.GetGroupByID(ctx, groupID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, common.NewNotFound(ctx, common.GroupIDNotFound, common.Details("this group does not exist"))
+		} else {
+			return false, errGroupRead(ctx, err.Error())
+		}
+	}
+
+	if !adminPerm && grp.Owner != loggedInAttendee.ID {
+		aulogging.Warnf(ctx, "unauthorized attempt to access %s of group %s by %s", what, groupID, common.GetSubject(ctx))
+		return false, common.NewForbidden(ctx, common.AuthForbidden, common.Details(fmt.Sprintf("only the group owner or an admin can manage the %s of a group", what)))
+	}
+
+	return adminPerm, nil
+}
+
+// deleteGroupBans removes the auto-decline list of a group that is being deleted.
+//
+// The bans reference the group, so they must be gone before the group can be deleted.
+func deleteGroupBans(ctx context.Context, db database.Repository, groupID string) error {
+	bans, err := db.GetGroupBans(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	for _, ban := range bans {
+		if err := db.RemoveGroupBan(ctx, groupID, ban.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
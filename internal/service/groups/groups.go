@@ -7,15 +7,15 @@ import (
 	aulogging "github.com/StephanHCB/go-autumn-logging"
 	"github.com/eurofurence/reg-room-service/internal/controller/v1/util"
 	"net/url"
-	"slices"
 	"sort"
-	"strings"
 
 	"gorm.io/gorm"
 
 	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
 	"github.com/eurofurence/reg-room-service/internal/application/common"
 	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/config"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
 	"github.com/eurofurence/reg-room-service/internal/service/rbac"
 )
 
@@ -30,7 +30,10 @@ func (g *groupService) FindMyGroup(ctx context.Context) (*modelsv1.Group, error)
 		return nil, err
 	}
 
-	groups, err := g.findGroupsFullAccess(ctx, 0, -1, []int64{attendee.ID}, false)
+	groups, _, err := g.findGroupsFullAccess(ctx, &FindGroupParams{
+		MemberIDs: []int64{attendee.ID},
+		MaxSize:   -1,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -52,48 +55,59 @@ func (g *groupService) FindMyGroup(ctx context.Context) (*modelsv1.Group, error)
 	return filtered, nil
 }
 
-// FindGroups finds groups by size (number of members) and member badge numbers.
+// FindGroups finds groups by size (number of members), member badge numbers and name.
 //
-// A group matches if its size is in the range (maxSize -1 means no limit), and if it
-// contains at least one of the specified badge numbers (if memberIDs is not empty).
+// A group matches if its size is in the range (maxSize -1 means no limit), if it
+// contains at least one of the specified badge numbers (if memberIDs is not empty),
+// and if its name contains the name search, ignoring case.
 //
 // Admin or Api Key authorization: can see all groups.
 //
 // Normal users: can only see groups visible to them. If public groups are enabled in configuration,
 // this means all groups that are public and from which the user wasn't banned. Not all fields
 // will be filled in the results to protect the privacy of group members.
-func (g *groupService) FindGroups(ctx context.Context, minSize uint, maxSize int, memberIDs []int64, public bool) ([]*modelsv1.Group, error) {
+func (g *groupService) FindGroups(ctx context.Context, params *FindGroupParams) (*modelsv1.GroupList, error) {
 	validator, err := rbac.NewValidator(ctx)
 	if err != nil {
 		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
-		return make([]*modelsv1.Group, 0), errCouldNotGetValidator(ctx)
+		return nil, errCouldNotGetValidator(ctx)
 	}
 
-	permAdmin := (validator.IsAdmin() && !public) || validator.IsAPITokenCall()
-	permUser := validator.IsUser() || (validator.IsAdmin() && public)
+	permAdmin := (validator.IsAdmin() && !params.PublicOnly) || validator.IsAPITokenCall()
+	permUser := validator.IsUser() || (validator.IsAdmin() && params.PublicOnly)
 
 	if permAdmin {
-		return g.findGroupsFullAccess(ctx, minSize, maxSize, memberIDs, public)
-	} else if permUser {
-		result := make([]*modelsv1.Group, 0)
+		groups, total, err := g.findGroupsFullAccess(ctx, params)
+		if err != nil {
+			return nil, err
+		}
 
+		return &modelsv1.GroupList{
+			Groups: groups,
+			Total:  total,
+		}, nil
+	} else if permUser {
 		// normal users must set the public flag
-		if !public {
-			return result, common.NewForbidden(ctx, common.AuthForbidden, common.Details("regular users cannot list private groups, must set show=public"))
+		if !params.PublicOnly {
+			return nil, common.NewForbidden(ctx, common.AuthForbidden, common.Details("regular users cannot list private groups, must set show=public"))
 		}
 
 		// ensure attending registration
 		attendee, err := g.loggedInUserValidRegistration(ctx)
 		if err != nil {
-			return result, err
+			return nil, err
 		}
 
 		// normal users cannot specify memberIDs to filter for - ignore if set
-		unchecked, err := g.findGroupsFullAccess(ctx, minSize, maxSize, nil, true)
+		userParams := *params
+		userParams.MemberIDs = nil
+		unchecked, total, err := g.findGroupsFullAccess(ctx, &userParams)
 		if err != nil {
-			return result, err
+			return nil, err
 		}
 
+		// public groups are visible to everyone, so filtering does not change the total
+		result := make([]*modelsv1.Group, 0, len(unchecked))
 		for _, group := range unchecked {
 			filtered := g.filterGroupAndFieldVisibilityForAttendee(group, attendee)
 			if filtered != nil {
@@ -101,26 +115,50 @@ func (g *groupService) FindGroups(ctx context.Context, minSize uint, maxSize int
 			}
 		}
 
-		return result, nil
+		return &modelsv1.GroupList{
+			Groups: result,
+			Total:  total,
+		}, nil
 	} else {
-		return make([]*modelsv1.Group, 0), errNotAttending(ctx) // shouldn't ever happen, just in case
+		return nil, errNotAttending(ctx) // shouldn't ever happen, just in case
 	}
 }
 
 // findGroupsFullAccess searches for groups without permission checks.
 //
-// It returns all matching groups unfiltered, mapped to the API model with all fields visible.
-func (g *groupService) findGroupsFullAccess(ctx context.Context, minSize uint, maxSize int, memberIDs []int64, publicOnly bool) ([]*modelsv1.Group, error) {
+// It returns the requested page of matching groups unfiltered, mapped to the API model with all fields visible,
+// together with the total number of matching groups.
+func (g *groupService) findGroupsFullAccess(ctx context.Context, params *FindGroupParams) ([]*modelsv1.Group, int64, error) {
 	result := make([]*modelsv1.Group, 0)
 
-	groupIDs, err := g.DB.FindGroups(ctx, "", minSize, maxSize, memberIDs)
+	criteria := database.FindGroupCriteria{
+		MinOccupancy:  params.MinSize,
+		MaxOccupancy:  params.MaxSize,
+		AnyOfMemberID: params.MemberIDs,
+	}
+	if params.PublicOnly {
+		criteria.Flag = "public"
+	}
+
+	sortBy := params.SortBy
+	if sortBy == "" {
+		sortBy = database.SortByName
+	}
+
+	groupIDs, total, err := g.DB.FindGroups(ctx, criteria, &database.FindOptions{
+		NameContains: params.Name,
+		SortBy:       sortBy,
+		Descending:   params.Descending,
+		Offset:       params.Offset,
+		Limit:        params.Limit,
+	})
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return result, nil
+			return result, 0, nil
 		}
 
 		aulogging.ErrorErrf(ctx, err, "find groups failed: %s", err.Error())
-		return result, errInternal(ctx, "database error while finding groups - see logs for details")
+		return result, 0, errInternal(ctx, "database error while finding groups - see logs for details")
 	}
 
 	for _, id := range groupIDs {
@@ -128,24 +166,15 @@ func (g *groupService) findGroupsFullAccess(ctx context.Context, minSize uint, m
 		if err != nil {
 			if !errors.Is(err, gorm.ErrRecordNotFound) {
 				aulogging.WarnErrf(ctx, err, "find groups failed to read group %s - maybe intermittent change: %s", id, err.Error())
-				return make([]*modelsv1.Group, 0), errInternal(ctx, "database error while finding groups - see logs for details")
+				return make([]*modelsv1.Group, 0), 0, errInternal(ctx, "database error while finding groups - see logs for details")
 			}
+			continue
 		}
 
-		if publicOnly {
-			if groupHasFlag(group, "public") {
-				result = append(result, group)
-			}
-		} else {
-			result = append(result, group)
-		}
+		result = append(result, group)
 	}
 
-	sort.Slice(result, func(i, j int) bool {
-		return groupLessByName(result[i], result[j])
-	})
-
-	return result, nil
+	return result, total, nil
 }
 
 // GetGroupByID retrieves a group and its members and invites from the database by a given ID.
@@ -206,14 +235,16 @@ func (g *groupService) getGroupByIDFullAccess(ctx context.Context, groupID strin
 	}
 
 	return &modelsv1.Group{
-		ID:          grp.ID,
-		Name:        grp.Name,
-		Flags:       aggregateFlags(grp.Flags),
-		Comments:    common.ToOmitEmpty(grp.Comments),
-		MaximumSize: grp.MaximumSize,
-		Owner:       grp.Owner,
-		Members:     toMembers(groupMembers),
-		Invites:     toInvites(groupMembers),
+		ID:           grp.ID,
+		Name:         grp.Name,
+		Flags:        entity.AggregateFlags(grp.Flags),
+		Comments:     common.ToOmitEmpty(grp.Comments),
+		MaximumSize:  grp.MaximumSize,
+		Owner:        grp.Owner,
+		PendingOwner: grp.PendingOwner,
+		PendingMerge: grp.PendingMerge,
+		Members:      toMembers(groupMembers),
+		Invites:      toInvites(groupMembers),
 	}, nil
 }
 
@@ -233,7 +264,7 @@ func (g *groupService) CreateGroup(ctx context.Context, group *modelsv1.GroupCre
 	if validator.IsAdmin() {
 		ownerID = group.Owner
 		if ownerID > 0 {
-			attendee, err := g.AttSrv.GetAttendee(ctx, int64(ownerID))
+			attendee, err := g.AttSrv.GetAttendeeWithApiToken(ctx, int64(ownerID))
 			if err != nil {
 				return "", err
 			}
@@ -259,7 +290,7 @@ func (g *groupService) CreateGroup(ctx context.Context, group *modelsv1.GroupCre
 	}
 
 	// check for name conflicts
-	matchingIDs, err := g.DB.FindGroups(ctx, group.Name, 0, -1, nil)
+	matchingIDs, _, err := g.DB.FindGroups(ctx, database.FindGroupCriteria{Name: group.Name, MaxOccupancy: -1}, nil)
 	if err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
 			return "", errGroupRead(ctx, err.Error())
@@ -273,7 +304,7 @@ func (g *groupService) CreateGroup(ctx context.Context, group *modelsv1.GroupCre
 	// Create a new group in the database
 	groupID, err := g.DB.AddGroup(ctx, &entity.Group{
 		Name:        group.Name,
-		Flags:       collectFlags(group.Flags),
+		Flags:       entity.CollectFlags(group.Flags),
 		Comments:    common.Deref(group.Comments),
 		MaximumSize: maxGroupSize(),
 		Owner:       ownerID,
@@ -283,7 +314,13 @@ func (g *groupService) CreateGroup(ctx context.Context, group *modelsv1.GroupCre
 		return "", err
 	}
 
+	conf, err := config.GetApplicationConfig()
+	if err != nil {
+		return "", common.NewInternalServerError(ctx, common.InternalErrorMessage, common.Details("configuration not loaded"))
+	}
+
 	gm := g.DB.NewEmptyGroupMembership(ctx, groupID, ownerID, nickname)
+	gm.AvatarURL = conf.Service.AvatarURL(ownerID)
 	gm.IsInvite = false
 	return groupID, g.DB.AddGroupMembership(ctx, gm)
 }
@@ -322,9 +359,22 @@ func validate(name string, flags []string, maximumSize int64) url.Values {
 	return result
 }
 
+// validateMemberFlags checks membership flags against the service configuration.
+func validateMemberFlags(flags []string) url.Values {
+	result := url.Values{}
+	allowed := allowedMemberFlags()
+	for _, flag := range flags {
+		if !util.SliceContains(flag, allowed) {
+			result.Set("flags", fmt.Sprintf("no such flag '%s'", url.PathEscape(flag)))
+		}
+	}
+	return result
+}
+
 // UpdateGroup updates an existing group by uuid. Note that you cannot use this to change the group members!
 //
-// Admins or the current group owner can change the group owner to any member of the group.
+// Admins can directly change the group owner to any member of the group. If the current group owner
+// changes the owner, this only offers ownership to the member, see ProposeOwnershipTransfer.
 func (g *groupService) UpdateGroup(ctx context.Context, group *modelsv1.Group) error {
 	validator, err := rbac.NewValidator(ctx)
 	if err != nil {
@@ -341,16 +391,17 @@ func (g *groupService) UpdateGroup(ctx context.Context, group *modelsv1.Group) e
 		}
 	}
 
-	if validator.IsAdmin() || validator.IsAPITokenCall() {
+	adminPerm := validator.IsAdmin() || validator.IsAPITokenCall()
+	if adminPerm {
 		// admins and api token are allowed to make changes to any group
 
 		// owner validation for admins
 		if group.Owner > 0 {
-			if _, err := g.AttSrv.GetAttendee(ctx, group.Owner); err != nil {
+			if _, err := g.AttSrv.GetAttendeeWithApiToken(ctx, group.Owner); err != nil {
 				return err
 			}
 
-			if err := g.checkAttending(ctx, group.Owner); err != nil {
+			if err := g.checkAttending(ctx, group.Owner, g.AttSrv.GetStatusWithApiToken); err != nil {
 				return err
 			}
 		} else {
@@ -383,22 +434,42 @@ func (g *groupService) UpdateGroup(ctx context.Context, group *modelsv1.Group) e
 
 	// do not touch fields that we do not wish to change, like createdAt or referenced members
 	dbGroup.Name = group.Name
-	dbGroup.Flags = collectFlags(group.Flags)
+	dbGroup.Flags = entity.CollectFlags(group.Flags)
 	dbGroup.Comments = common.Deref(group.Comments)
 	dbGroup.MaximumSize = group.MaximumSize
 
+	offeredOwnership := false
 	if dbGroup.Owner != group.Owner {
 		err := g.canChangeGroupOwner(ctx, group)
 		if err != nil {
 			return err
 		}
 
-		_ = g.sendInfoMails(ctx, "", "group-new-owner", dbGroup, group.Owner, "")
+		if adminPerm {
+			_ = g.sendInfoMails(ctx, "", "group-new-owner", dbGroup, group.Owner, "")
 
-		dbGroup.Owner = group.Owner
+			dbGroup.Owner = group.Owner
+			dbGroup.PendingOwner = 0
+		} else {
+			// the new owner has to accept first
+			if err := g.checkAttending(ctx, group.Owner, g.AttSrv.GetStatusWithApiToken); err != nil {
+				return err
+			}
+
+			dbGroup.PendingOwner = group.Owner
+			offeredOwnership = true
+		}
+	}
+
+	if err := g.DB.UpdateGroup(ctx, dbGroup); err != nil {
+		return err
 	}
 
-	return g.DB.UpdateGroup(ctx, dbGroup)
+	if offeredOwnership {
+		aulogging.Infof(ctx, "group ownership offered - group %s badge %d by %s", dbGroup.ID, dbGroup.PendingOwner, common.GetSubject(ctx))
+		_ = g.sendInfoMails(ctx, "", "group-transfer-offered", dbGroup, dbGroup.PendingOwner, "")
+	}
+	return nil
 }
 
 func (g *groupService) canChangeGroupOwner(ctx context.Context, group *modelsv1.Group) error {
@@ -473,6 +544,16 @@ func (g *groupService) DeleteGroup(ctx context.Context, groupID string) error {
 		}
 	}
 
+	if err := deleteGroupBans(ctx, g.DB, groupID); err != nil {
+		aulogging.ErrorErrf(ctx, err, "error occurred when trying to remove auto-decline list of group %s. [error]: %s", groupID, err.Error())
+		return errInternal(ctx, fmt.Sprintf("could not remove auto-decline list of group %s", groupID))
+	}
+
+	if err := deleteGroupWish(ctx, g.DB, groupID); err != nil {
+		aulogging.ErrorErrf(ctx, err, "error occurred when trying to remove room wishes of group %s. [error]: %s", groupID, err.Error())
+		return errInternal(ctx, fmt.Sprintf("could not remove room wishes of group %s", groupID))
+	}
+
 	if err := g.DB.DeleteGroupByID(ctx, groupID); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return errGroupIDNotFound(ctx)
@@ -506,6 +587,7 @@ func toMembersFilteredSorted(groupMembers []*entity.GroupMember, invites bool) [
 		member := modelsv1.Member{
 			ID:       m.ID,
 			Nickname: m.Nickname,
+			Flags:    entity.AggregateFlags(m.Flags),
 		}
 		if m.AvatarURL != "" {
 			member.Avatar = &m.AvatarURL
@@ -521,27 +603,6 @@ func toMembersFilteredSorted(groupMembers []*entity.GroupMember, invites bool) [
 	return members
 }
 
-func aggregateFlags(input string) []string {
-	tags := strings.Split(input, ",")
-	tags = slices.DeleteFunc(tags, func(s string) bool {
-		return s == ""
-	})
-
-	if len(tags) == 0 {
-		return make([]string, 0)
-	}
-
-	slices.Sort(tags)
-	return tags
-}
-
-func collectFlags(input []string) string {
-	if len(input) == 0 {
-		return ","
-	}
-	return fmt.Sprintf(",%s,", strings.Join(input, ","))
-}
-
 func errNoGroup(ctx context.Context) error {
 	return common.NewNotFound(ctx, common.GroupMemberNotFound, common.Details("not in a group"))
 }
@@ -558,6 +619,10 @@ func errNewOwnerNotMember(ctx context.Context) error {
 	return common.NewInternalServerError(ctx, common.GroupMemberNotFound, common.Details("new owner must be a member of the group"))
 }
 
+func errGroupFull(ctx context.Context) error {
+	return common.NewConflict(ctx, common.GroupSizeFull, common.Details("this group is full - members and outstanding invitations have reached the maximum group size"))
+}
+
 func errCouldNotGetValidator(ctx context.Context) error {
 	return common.NewInternalServerError(ctx, common.InternalErrorMessage, common.Details("unexpected error when parsing user claims"))
 }
@@ -25,14 +25,16 @@ func (g *groupService) filterGroupAndFieldVisibilityForAttendee(group *modelsv1.
 	} else if groupContains(group, attendee.ID) {
 		// group members can see all group info, but no invites
 		return &modelsv1.Group{
-			ID:          group.ID,
-			Name:        group.Name,
-			Flags:       group.Flags,
-			Comments:    group.Comments,
-			MaximumSize: group.MaximumSize,
-			Owner:       group.Owner,
-			Members:     group.Members,
-			Invites:     nil,
+			ID:           group.ID,
+			Name:         group.Name,
+			Flags:        group.Flags,
+			Comments:     group.Comments,
+			MaximumSize:  group.MaximumSize,
+			Owner:        group.Owner,
+			PendingOwner: group.PendingOwner,
+			PendingMerge: group.PendingMerge,
+			Members:      hideOtherMemberFlags(group.Members, attendee.ID),
+			Invites:      nil,
 		}
 	} else if groupInvited(group, attendee.ID) {
 		// group invitees can see masked members and only their own invite
@@ -82,7 +84,7 @@ func (g *groupService) loggedInUserValidRegistration(ctx context.Context) (atten
 	}
 	myID := myRegIDs[0]
 
-	if err := g.checkAttending(ctx, myID); err != nil {
+	if err := g.checkAttending(ctx, myID, g.AttSrv.GetStatus); err != nil {
 		return attendeeservice.Attendee{}, err
 	}
 
@@ -96,18 +98,40 @@ func (g *groupService) loggedInUserValidRegistration(ctx context.Context) (atten
 	return attendee, nil
 }
 
-func (g *groupService) checkAttending(ctx context.Context, badgeNo int64) error {
-	status, err := g.AttSrv.GetStatus(ctx, badgeNo)
+// checkAttending fails unless the attendee is in attending status.
+//
+// A user can only read their own status, so for other attendees, pass the GetStatusWithApiToken of the attendee service.
+func (g *groupService) checkAttending(ctx context.Context, badgeNo int64, getStatus func(context.Context, int64) (attendeeservice.Status, error)) error {
+	attending, err := g.lookupAttending(ctx, badgeNo, getStatus)
+	if err != nil {
+		return err
+	}
+
+	if !attending {
+		return common.NewForbidden(ctx, common.NotAttending, common.Details("registration is not in attending status"))
+	}
+	return nil
+}
+
+// isAttending looks up the registration status of an attendee. Only fails if the attendee service fails.
+//
+// Uses the api token, because it is called from background jobs and reconciliation, which have no user token to forward.
+func (g *groupService) isAttending(ctx context.Context, badgeNo int64) (bool, error) {
+	return g.lookupAttending(ctx, badgeNo, g.AttSrv.GetStatusWithApiToken)
+}
+
+func (g *groupService) lookupAttending(ctx context.Context, badgeNo int64, getStatus func(context.Context, int64) (attendeeservice.Status, error)) (bool, error) {
+	status, err := getStatus(ctx, badgeNo)
 	if err != nil {
 		aulogging.WarnErrf(ctx, err, "failed to obtain status for badge number %d: %s", badgeNo, err.Error())
-		return common.NewBadGateway(ctx, common.DownstreamAttSrv, common.Details("downstream error when contacting attendee service"))
+		return false, common.NewBadGateway(ctx, common.DownstreamAttSrv, common.Details("downstream error when contacting attendee service"))
 	}
 
 	switch status {
 	case attendeeservice.StatusApproved, attendeeservice.StatusPartiallyPaid, attendeeservice.StatusPaid, attendeeservice.StatusCheckedIn:
-		return nil
+		return true, nil
 	default:
-		return common.NewForbidden(ctx, common.NotAttending, common.Details("registration is not in attending status"))
+		return false, nil
 	}
 }
 
@@ -127,6 +151,22 @@ func allowedFlags() []string {
 	return conf.Service.GroupFlags
 }
 
+func allowedRoomFlags() []string {
+	conf, err := config.GetApplicationConfig()
+	if err != nil {
+		panic("configuration not loaded before call to allowedRoomFlags() - this is a bug")
+	}
+	return conf.Service.RoomFlags
+}
+
+func allowedMemberFlags() []string {
+	conf, err := config.GetApplicationConfig()
+	if err != nil {
+		panic("configuration not loaded before call to allowedMemberFlags() - this is a bug")
+	}
+	return conf.Service.MemberFlags
+}
+
 func maskMembers(members []modelsv1.Member, myID int64) []modelsv1.Member {
 	result := make([]modelsv1.Member, 0)
 	for _, member := range members {
@@ -141,6 +181,18 @@ func maskMembers(members []modelsv1.Member, myID int64) []modelsv1.Member {
 	return result
 }
 
+// hideOtherMemberFlags removes the membership flags of everyone except myID, which only the owner and admins can see.
+func hideOtherMemberFlags(members []modelsv1.Member, myID int64) []modelsv1.Member {
+	result := make([]modelsv1.Member, 0)
+	for _, member := range members {
+		if member.ID != myID {
+			member.Flags = nil
+		}
+		result = append(result, member)
+	}
+	return result
+}
+
 func filterInvites(members []modelsv1.Member, myID int64) []modelsv1.Member {
 	result := make([]modelsv1.Member, 0)
 	for _, member := range members {
@@ -184,10 +236,3 @@ func groupHasFlag(group *modelsv1.Group, wantedFlag string) bool {
 	}
 	return false
 }
-
-func groupLessByName(left *modelsv1.Group, right *modelsv1.Group) bool {
-	if left == nil || right == nil {
-		return left == nil && right != nil
-	}
-	return left.Name < right.Name
-}
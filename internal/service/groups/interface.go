@@ -6,6 +6,7 @@ import (
 	"github.com/eurofurence/reg-room-service/internal/repository/database"
 	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/attendeeservice"
 	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/mailservice"
+	"time"
 )
 
 // Service defines the interface for the service function implementations for the group endpoints.
@@ -22,8 +23,102 @@ type Service interface {
 	// The same link will also be included in the email sent to the invited attendee.
 	AddMemberToGroup(ctx context.Context, req *AddGroupMemberParams) (string, error)
 	RemoveMemberFromGroup(ctx context.Context, req *RemoveGroupMemberParams) error
-	FindGroups(ctx context.Context, minSize uint, maxSize int, memberIDs []int64, public bool) ([]*modelsv1.Group, error)
+	// UpdateMemberFlags replaces the membership flags of a group member.
+	//
+	// The group owner, admins and the member themself can change member flags. Invitations have no flags.
+	UpdateMemberFlags(ctx context.Context, req *UpdateGroupMemberParams) error
+	// ResendInvitation sends the invitation mail to an invited attendee again.
+	//
+	// This also restarts the invitation lifetime, even if the invitation has already expired (but not yet been deleted).
+	// Only the group owner and admins can resend invitations.
+	ResendInvitation(ctx context.Context, req *ResendInvitationParams) error
+	// SweepExpiredInvitations deletes all expired invitations and returns how many were deleted.
+	//
+	// This is intended for the background job, so no permission checks are performed.
+	SweepExpiredInvitations(ctx context.Context) (int, error)
+	// ProposeOwnershipTransfer offers ownership of the group to another member. Ownership only changes once
+	// they accept.
+	//
+	// Only the group owner and admins can offer ownership. Replaces any previous offer.
+	ProposeOwnershipTransfer(ctx context.Context, req *OwnershipTransferParams) error
+	// AcceptOwnershipTransfer makes the logged in attendee the owner of the group, if ownership was offered to them.
+	AcceptOwnershipTransfer(ctx context.Context, groupID string) error
+	// CancelOwnershipTransfer withdraws (owner, admin) or declines (offered member) a pending ownership transfer.
+	CancelOwnershipTransfer(ctx context.Context, groupID string) error
+	// MergeGroups moves all members of the source group into the target group and deletes the source group.
+	//
+	// Admins merge immediately. Otherwise the owner of the source group requests the merge, and it only happens
+	// once the owner of the target group calls this too. The auto-decline lists of both groups are combined,
+	// and pending invitations to the source group are dropped.
+	MergeGroups(ctx context.Context, sourceID string, targetID string) error
+	// CancelGroupMerge withdraws (source owner, admin) or declines (target owner) a pending merge request.
+	CancelGroupMerge(ctx context.Context, sourceID string) error
+	// SplitGroup moves some members of a group into a new group, and returns the id of the new group.
+	//
+	// The new group gets a name derived from the original group, and the same flags and maximum size.
+	// The owner of the original group stays. Only admins can split groups.
+	SplitGroup(ctx context.Context, groupID string, split *modelsv1.GroupSplit) (string, error)
+	// ReassignCancelledOwners passes ownership of groups whose owner is no longer attending to another member,
+	// and returns how many groups got a new owner.
+	//
+	// This is intended for the background job, so no permission checks are performed.
+	ReassignCancelledOwners(ctx context.Context) (int, error)
+	// RemoveNonAttendingMember removes an attendee who is no longer attending from their group or invitation,
+	// and returns false if they were not in any group.
+	//
+	// If they own the group, ownership passes to another attending member first. If there is none,
+	// the group is disbanded, and everyone who was in it or invited to it is informed by mail.
+	//
+	// This is intended for reconciliation, so no permission checks are performed, and the caller is responsible
+	// for checking the registration status.
+	RemoveNonAttendingMember(ctx context.Context, badgeNo int64) (bool, error)
+	// FindGroups lists the groups matching params, sorted and paged as requested, together with the total number
+	// of matching groups.
+	FindGroups(ctx context.Context, params *FindGroupParams) (*modelsv1.GroupList, error)
 	FindMyGroup(ctx context.Context) (*modelsv1.Group, error)
+
+	// GetGroupBans returns the auto-decline list of the group, sorted by badge number.
+	//
+	// Only the group owner and admins can see the list.
+	GetGroupBans(ctx context.Context, groupID string) (*modelsv1.GroupBanList, error)
+	// AddGroupBan adds an attendee to the auto-decline list of the group.
+	//
+	// This does not remove the attendee from the group, it only prevents future invitations and join requests.
+	AddGroupBan(ctx context.Context, req *GroupBanParams) error
+	// RemoveGroupBan removes an attendee from the auto-decline list of the group.
+	RemoveGroupBan(ctx context.Context, req *GroupBanParams) error
+
+	// GetGroupWishes returns the room wishes of the group. A group without wishes has empty wishes.
+	//
+	// Only the group owner and admins can see the wishes.
+	GetGroupWishes(ctx context.Context, groupID string) (*modelsv1.GroupWishes, error)
+	// UpdateGroupWishes replaces the room wishes of the group. Empty wishes remove them.
+	//
+	// Only the group owner and admins can change the wishes.
+	UpdateGroupWishes(ctx context.Context, groupID string, wishes *modelsv1.GroupWishes) error
+}
+
+// FindGroupParams is the request type for the FindGroups operation.
+//
+// See OpenAPI spec for more details.
+type FindGroupParams struct {
+	MemberIDs []int64 // empty list or nil means no condition
+
+	MinSize uint // 0 means no condition
+	MaxSize int  // -1 means no condition
+
+	// PublicOnly finds only groups with the public flag. Normal users must set this.
+	PublicOnly bool
+
+	// Name finds only groups whose name contains this string, ignoring case. Empty means no condition.
+	Name string
+
+	// SortBy is one of the sort keys name, size, occupancy, created. Empty means name.
+	SortBy     string
+	Descending bool
+
+	Offset uint // number of groups to skip
+	Limit  uint // 0 means no limit
 }
 
 // AddGroupMemberParams is the request type for the AddMemberToGroup operation.
@@ -56,11 +151,54 @@ type RemoveGroupMemberParams struct {
 	AutoDeny bool
 }
 
+// UpdateGroupMemberParams is the request type for the UpdateMemberFlags operation.
+//
+// See OpenAPI spec for more details.
+type UpdateGroupMemberParams struct {
+	// GroupID is the ID of the group the member belongs to
+	GroupID string
+	// BadgeNumber is the registration number of the member
+	BadgeNumber int64
+	// Flags is the new list of membership flags, replacing the current flags
+	Flags []string
+}
+
+// ResendInvitationParams is the request type for the ResendInvitation operation.
+//
+// See OpenAPI spec for more details.
+type ResendInvitationParams struct {
+	// GroupID is the ID of the group the attendee has been invited to
+	GroupID string
+	// BadgeNumber is the registration number of the invited attendee
+	BadgeNumber int64
+}
+
+// OwnershipTransferParams is the request type for the ProposeOwnershipTransfer operation.
+//
+// See OpenAPI spec for more details.
+type OwnershipTransferParams struct {
+	// GroupID is the ID of the group whose ownership should be transferred
+	GroupID string
+	// BadgeNumber is the registration number of the member who should become the new owner
+	BadgeNumber int64
+}
+
+// GroupBanParams is the request type for the AddGroupBan and RemoveGroupBan operations.
+//
+// See OpenAPI spec for more details.
+type GroupBanParams struct {
+	// GroupID is the ID of the group whose auto-decline list should be changed
+	GroupID string
+	// BadgeNumber is the registration number of the attendee to add or remove
+	BadgeNumber int64
+}
+
 func New(db database.Repository, attsrv attendeeservice.AttendeeService, mailsrv mailservice.MailService) Service {
 	return &groupService{
 		DB:      db,
 		AttSrv:  attsrv,
 		MailSrv: mailsrv,
+		Now:     time.Now,
 	}
 }
 
@@ -68,4 +206,15 @@ type groupService struct {
 	DB      database.Repository
 	AttSrv  attendeeservice.AttendeeService
 	MailSrv mailservice.MailService
+	Now     func() time.Time
+}
+
+// withDB returns a copy of the service that uses the given repository, typically a transaction.
+func (g *groupService) withDB(db database.Repository) *groupService {
+	return &groupService{
+		DB:      db,
+		AttSrv:  g.AttSrv,
+		MailSrv: g.MailSrv,
+		Now:     g.Now,
+	}
 }
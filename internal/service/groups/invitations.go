@@ -0,0 +1,175 @@
+package groupservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/config"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+	"gorm.io/gorm"
+	"time"
+)
+
+func (g *groupService) ResendInvitation(ctx context.Context, req *ResendInvitationParams) error {
+	adminPerm, loggedInAttendee, err := g.groupMembershipAuthCheck(ctx)
+	if err != nil {
+		return err
+	}
+
+	var grp *entity.Group
+	var gm *entity.GroupMember
+	err = g.DB.Transaction(ctx, func(tx database.Repository) error {
+		tg := g.withDB(tx)
+
+		if _, err := tx.LockGroupByID(ctx, req.GroupID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errGroupRead(ctx, err.Error())
+		}
+
+		var err error
+		grp, gm, err = tg.groupMembershipExisting(ctx, req.GroupID, req.BadgeNumber) // gm may be nil if not exists
+		if err != nil {
+			return err
+		}
+
+		if !adminPerm && grp.Owner != loggedInAttendee.ID {
+			aulogging.Warnf(ctx, "unauthorized attempt to resend invitation - group %s badge %d by %s", req.GroupID, req.BadgeNumber, common.GetSubject(ctx))
+			return common.NewForbidden(ctx, common.AuthForbidden, common.Details("only the group owner or an admin can resend invitations"))
+		}
+
+		if gm == nil || gm.GroupID != grp.ID || (gm.IsInvite && gm.InvitationCode == "") {
+			// join requests have no invitation code, so there is nothing to resend
+			return common.NewNotFound(ctx, common.GroupMemberNotFound, common.Details("this attendee has not been invited to this group"))
+		}
+
+		if !gm.IsInvite {
+			return common.NewConflict(ctx, common.GroupMemberDuplicate, common.Details("this attendee is already a member of this group"))
+		}
+
+		// an expired invitation no longer counts towards the group size, so reviving it must fit
+		if g.invitationExpired(gm) {
+			full, err := tg.groupFull(ctx, grp)
+			if err != nil {
+				return err
+			}
+			if full {
+				return errGroupFull(ctx)
+			}
+		}
+
+		invitedAt := g.Now()
+		gm.InvitedAt = &invitedAt
+		if err := tx.UpdateGroupMembership(ctx, gm); err != nil {
+			return errGroupWrite(ctx, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	aulogging.Infof(ctx, "group invitation resent - group %s badge %d by %s", req.GroupID, req.BadgeNumber, common.GetSubject(ctx))
+	if err := g.sendInfoMails(ctx, "", "group-invited", grp, req.BadgeNumber, fmt.Sprintf("?code=%s", gm.InvitationCode)); err != nil {
+		// unlike for the other operations, sending the mail is the whole point here
+		return common.NewBadGateway(ctx, common.GroupMailError, common.Details("failed to send the invitation mail - see logs for details"))
+	}
+
+	return nil
+}
+
+func (g *groupService) SweepExpiredInvitations(ctx context.Context) (int, error) {
+	if invitationLifetime() <= 0 {
+		return 0, nil
+	}
+
+	groups, err := g.DB.GetGroups(ctx)
+	if err != nil {
+		return 0, errGroupRead(ctx, err.Error())
+	}
+
+	count := 0
+	for _, grp := range groups {
+		members, err := g.DB.GetGroupMembersByGroupID(ctx, grp.ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return count, errGroupRead(ctx, err.Error())
+		}
+
+		for _, member := range members {
+			if !g.invitationExpired(member) {
+				continue
+			}
+
+			deleted := false
+			err := g.DB.Transaction(ctx, func(tx database.Repository) error {
+				if _, err := tx.LockGroupByID(ctx, grp.ID); err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						return nil // group deleted concurrently
+					}
+					return errGroupRead(ctx, err.Error())
+				}
+
+				// the invitation may have been accepted or resent concurrently
+				current, err := tx.GetGroupMembershipByAttendeeID(ctx, member.ID)
+				if err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						return nil
+					}
+					return errGroupRead(ctx, err.Error())
+				}
+				if current.GroupID != grp.ID || !g.invitationExpired(current) {
+					return nil
+				}
+
+				if err := tx.DeleteGroupMembership(ctx, member.ID); err != nil {
+					return errGroupWrite(ctx, err.Error())
+				}
+				deleted = true
+				return nil
+			})
+			if err != nil {
+				return count, err
+			}
+
+			if deleted {
+				aulogging.Infof(ctx, "expired group invitation deleted - group %s badge %d", grp.ID, member.ID)
+				count++
+			}
+		}
+	}
+
+	return count, nil
+}
+
+// invitationExpired is true for invitations with an invitation code that were sent longer ago than the
+// configured invitation lifetime.
+//
+// Join requests (invitations without a code) and memberships never expire.
+func (g *groupService) invitationExpired(gm *entity.GroupMember) bool {
+	lifetime := invitationLifetime()
+	if lifetime <= 0 || !gm.IsInvite || gm.InvitationCode == "" {
+		return false
+	}
+
+	invitedAt := gm.CreatedAt // for invitations from before expiry was introduced
+	if gm.InvitedAt != nil {
+		invitedAt = *gm.InvitedAt
+	}
+	if invitedAt.IsZero() {
+		return false
+	}
+
+	return g.Now().After(invitedAt.Add(lifetime))
+}
+
+func invitationLifetime() time.Duration {
+	conf, err := config.GetApplicationConfig()
+	if err != nil {
+		panic("configuration not loaded before call to invitationLifetime() - this is a bug")
+	}
+	return time.Duration(conf.Service.InvitationLifetimeHours) * time.Hour
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/eurofurence/reg-room-service/internal/application/common"
 	"github.com/eurofurence/reg-room-service/internal/entity"
 	"github.com/eurofurence/reg-room-service/internal/repository/config"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
 	"github.com/eurofurence/reg-room-service/internal/repository/downstreams"
 	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/attendeeservice"
 	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/mailservice"
@@ -28,170 +29,225 @@ func (g *groupService) AddMemberToGroup(ctx context.Context, req *AddGroupMember
 		return "", err
 	}
 
-	grp, gm, err := g.groupMembershipExisting(ctx, req.GroupID, req.BadgeNumber) // gm may be nil if not exists
-	if err != nil {
-		return "", err
-	}
-
+	var grp *entity.Group
 	informOwnerTemplate := ""
 	informMemberTemplate := ""
 	inviteCode := ""
 
-	banned, err := g.DB.HasGroupBan(ctx, req.GroupID, req.BadgeNumber)
-	if err != nil {
-		return "", errGroupRead(ctx, err.Error())
-	}
+	// the group row lock ensures concurrent additions cannot exceed the maximum group size
+	err = g.DB.Transaction(ctx, func(tx database.Repository) error {
+		tg := g.withDB(tx)
 
-	if gm == nil {
-		// no existing membership entry
+		if _, err := tx.LockGroupByID(ctx, req.GroupID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errGroupRead(ctx, err.Error())
+		}
 
-		// TODO check group maximum_size (including invitations!)
+		var gm *entity.GroupMember
+		var err error
+		grp, gm, err = tg.groupMembershipExisting(ctx, req.GroupID, req.BadgeNumber) // gm may be nil if not exists
+		if err != nil {
+			return err
+		}
 
-		gm = g.DB.NewEmptyGroupMembership(ctx, req.GroupID, requestedAttendee.ID, requestedAttendee.Nickname)
+		banned, err := tx.HasGroupBan(ctx, req.GroupID, req.BadgeNumber)
+		if err != nil {
+			return errGroupRead(ctx, err.Error())
+		}
 
-		if adminPerm && req.Force {
-			// admin mode, directly add and even allow cross-user additions
+		if gm == nil {
+			// no existing membership entry
 
-			if banned {
-				aulogging.Infof(ctx, "group ban removed through force add - group %s badge %d by %s", req.GroupID, req.BadgeNumber, common.GetSubject(ctx))
-				err := g.DB.RemoveGroupBan(ctx, req.GroupID, requestedAttendee.ID)
-				if err != nil {
-					return "", err
+			full, err := tg.groupFull(ctx, grp)
+			if err != nil {
+				return err
+			}
+			forceOverLimit := false
+			if full {
+				if adminPerm && req.Force {
+					aulogging.Warnf(ctx, "admin force add exceeds group maximum size - group %s badge %d by %s", req.GroupID, req.BadgeNumber, common.GetSubject(ctx))
+					forceOverLimit = true
+				} else {
+					return errGroupFull(ctx)
 				}
 			}
 
-			gm.IsInvite = false
-			gm.InvitationCode = ""
-			gm.Comments = "forced join by admin " + common.GetSubject(ctx)
-
-			err := g.DB.AddGroupMembership(ctx, gm)
+			conf, err := config.GetApplicationConfig()
 			if err != nil {
-				return "", errGroupWrite(ctx, err.Error())
+				return common.NewInternalServerError(ctx, common.InternalErrorMessage, common.Details("configuration not loaded"))
 			}
 
-			informOwnerTemplate = "group-member-joined"
-		} else if req.BadgeNumber == loggedInAttendee.ID {
-			// trying to add self - invite coming from the joining attendee
-
-			if banned {
-				aulogging.Warnf(ctx, "user tried to circumvent ban - group %s badge %d by %s", req.GroupID, req.BadgeNumber, common.GetSubject(ctx))
-				return "", common.NewForbidden(ctx, common.AuthForbidden, common.Details("you cannot join this group - please stop trying"))
-			}
+			gm = tx.NewEmptyGroupMembership(ctx, req.GroupID, requestedAttendee.ID, requestedAttendee.Nickname)
+			gm.AvatarURL = conf.Service.AvatarURL(requestedAttendee.ID)
 
-			gm.IsInvite = true
-			gm.InvitationCode = "" // join request by owner, so no code
-			gm.Comments = "self join request by " + common.GetSubject(ctx)
+			if adminPerm && req.Force {
+				// admin mode, directly add and even allow cross-user additions
 
-			err := g.DB.AddGroupMembership(ctx, gm)
-			if err != nil {
-				return "", errGroupWrite(ctx, err.Error())
-			}
-
-			informOwnerTemplate = "group-member-applied"
-		} else if grp.Owner == loggedInAttendee.ID {
-			// owner trying to invite another attendee - check nickname matches
+				if banned {
+					aulogging.Infof(ctx, "group ban removed through force add - group %s badge %d by %s", req.GroupID, req.BadgeNumber, common.GetSubject(ctx))
+					err := tx.RemoveGroupBan(ctx, req.GroupID, requestedAttendee.ID)
+					if err != nil {
+						return err
+					}
+				}
 
-			if req.Nickname != requestedAttendee.Nickname {
-				return "", common.NewBadRequest(ctx, common.GroupInviteMismatch, common.Details("nickname did not match - you need to know the nickname to be able to invite this attendee"))
-			}
+				gm.IsInvite = false
+				gm.InvitationCode = ""
+				gm.Comments = "forced join by admin " + common.GetSubject(ctx)
+				if forceOverLimit {
+					// comments are not part of history diffs, but the forced add itself is historized
+					gm.Comments += fmt.Sprintf(" - exceeds maximum group size %d", groupSizeLimit(grp))
+				}
 
-			if banned {
-				aulogging.Infof(ctx, "group ban removed through owner add - group %s badge %d by %s", req.GroupID, req.BadgeNumber, common.GetSubject(ctx))
-				err := g.DB.RemoveGroupBan(ctx, req.GroupID, req.BadgeNumber)
+				err := tx.AddGroupMembership(ctx, gm)
 				if err != nil {
-					return "", err
+					return errGroupWrite(ctx, err.Error())
 				}
-			}
 
-			gm.IsInvite = true
-			gm.InvitationCode = rollInvitationCode()
-			gm.Comments = "invite by owner " + common.GetSubject(ctx)
+				informOwnerTemplate = "group-member-joined"
+			} else if req.BadgeNumber == loggedInAttendee.ID {
+				// trying to add self - invite coming from the joining attendee
+
+				if banned {
+					aulogging.Warnf(ctx, "user tried to circumvent ban - group %s badge %d by %s", req.GroupID, req.BadgeNumber, common.GetSubject(ctx))
+					return common.NewForbidden(ctx, common.AuthForbidden, common.Details("you cannot join this group - please stop trying"))
+				}
 
-			inviteCode = fmt.Sprintf("?code=%s", gm.InvitationCode)
+				gm.IsInvite = true
+				gm.InvitationCode = "" // join request by owner, so no code
+				gm.Comments = "self join request by " + common.GetSubject(ctx)
 
-			err = g.DB.AddGroupMembership(ctx, gm)
-			if err != nil {
-				return "", errGroupWrite(ctx, err.Error())
-			}
+				err := tx.AddGroupMembership(ctx, gm)
+				if err != nil {
+					return errGroupWrite(ctx, err.Error())
+				}
 
-			informMemberTemplate = "group-invited" // you have been invited and here's your link
-		} else {
-			return "", common.NewForbidden(ctx, common.AuthForbidden, common.Details("only the group owner or an admin can invite other people into a group"))
-		}
+				informOwnerTemplate = "group-member-applied"
+			} else if grp.Owner == loggedInAttendee.ID {
+				// owner trying to invite another attendee - check nickname matches
 
-		// TODO now check group maximum_size, remove again if exceeded and fail
-	} else {
-		// existing membership (possibly invitation)
+				if req.Nickname != requestedAttendee.Nickname {
+					return common.NewBadRequest(ctx, common.GroupInviteMismatch, common.Details("nickname did not match - you need to know the nickname to be able to invite this attendee"))
+				}
 
-		if grp.ID != gm.GroupID {
-			return "", common.NewConflict(ctx, common.GroupMemberConflict, common.Details("this attendee is already invited to another group or in another group"))
-		}
+				if banned {
+					aulogging.Infof(ctx, "group ban removed through owner add - group %s badge %d by %s", req.GroupID, req.BadgeNumber, common.GetSubject(ctx))
+					err := tx.RemoveGroupBan(ctx, req.GroupID, req.BadgeNumber)
+					if err != nil {
+						return err
+					}
+				}
 
-		if !gm.IsInvite {
-			return "", common.NewConflict(ctx, common.GroupMemberDuplicate, common.Details("this attendee is already a member of this group"))
-		}
+				invitedAt := g.Now()
+				gm.IsInvite = true
+				gm.InvitationCode = rollInvitationCode()
+				gm.InvitedAt = &invitedAt
+				gm.Comments = "invite by owner " + common.GetSubject(ctx)
 
-		if adminPerm && req.Force {
-			// admin mode, directly add and allow cross-user additions
+				inviteCode = fmt.Sprintf("?code=%s", gm.InvitationCode)
 
-			if banned {
-				// this is a rare timing edge case, normally an invitation or application with an active ban cannot happen
-				aulogging.Infof(ctx, "group ban override and remove through force add - group %s badge %d by %s", req.GroupID, req.BadgeNumber, common.GetSubject(ctx))
-				err := g.DB.RemoveGroupBan(ctx, req.GroupID, req.BadgeNumber)
+				err = tx.AddGroupMembership(ctx, gm)
 				if err != nil {
-					return "", err
+					return errGroupWrite(ctx, err.Error())
 				}
+
+				informMemberTemplate = "group-invited" // you have been invited and here's your link
+			} else {
+				return common.NewForbidden(ctx, common.AuthForbidden, common.Details("only the group owner or an admin can invite other people into a group"))
 			}
+		} else {
+			// existing membership (possibly invitation)
 
-			gm.IsInvite = false
-			gm.InvitationCode = ""
+			if grp.ID != gm.GroupID {
+				return common.NewConflict(ctx, common.GroupMemberConflict, common.Details("this attendee is already invited to another group or in another group"))
+			}
 
-			err = g.DB.UpdateGroupMembership(ctx, gm)
-			if err != nil {
-				return "", errGroupWrite(ctx, err.Error())
+			if !gm.IsInvite {
+				return common.NewConflict(ctx, common.GroupMemberDuplicate, common.Details("this attendee is already a member of this group"))
 			}
 
-			informOwnerTemplate = "group-member-joined"
-		} else if req.BadgeNumber == loggedInAttendee.ID {
-			// self accept after invite
+			if adminPerm && req.Force {
+				// admin mode, directly add and allow cross-user additions
 
-			if req.Code != gm.InvitationCode {
-				aulogging.Infof(ctx, "invited user failed to join due to invitation code mismatch - group %s badge %d by %s", req.GroupID, req.BadgeNumber, common.GetSubject(ctx))
-				return "", common.NewForbidden(ctx, common.AuthForbidden, common.Details("you must provide the invitation code you were sent in order to join"))
-			}
+				if banned {
+					// this is a rare timing edge case, normally an invitation or application with an active ban cannot happen
+					aulogging.Infof(ctx, "group ban override and remove through force add - group %s badge %d by %s", req.GroupID, req.BadgeNumber, common.GetSubject(ctx))
+					err := tx.RemoveGroupBan(ctx, req.GroupID, req.BadgeNumber)
+					if err != nil {
+						return err
+					}
+				}
 
-			gm.IsInvite = false
+				gm.IsInvite = false
+				gm.InvitationCode = ""
 
-			err = g.DB.UpdateGroupMembership(ctx, gm)
-			if err != nil {
-				return "", errGroupWrite(ctx, err.Error())
-			}
+				err = tx.UpdateGroupMembership(ctx, gm)
+				if err != nil {
+					return errGroupWrite(ctx, err.Error())
+				}
 
-			informOwnerTemplate = "group-member-joined"
-		} else if grp.Owner == loggedInAttendee.ID {
-			// owner accept after apply
+				informOwnerTemplate = "group-member-joined"
+			} else if req.BadgeNumber == loggedInAttendee.ID {
+				// self accept after invite
 
-			if banned {
-				// this is a rare timing edge case, normally an application with an active ban cannot happen
-				aulogging.Infof(ctx, "group ban removed through owner add - group %s badge %d by %s", req.GroupID, req.BadgeNumber, common.GetSubject(ctx))
-				err := g.DB.RemoveGroupBan(ctx, req.GroupID, req.BadgeNumber)
+				if req.Code != gm.InvitationCode {
+					aulogging.Infof(ctx, "invited user failed to join due to invitation code mismatch - group %s badge %d by %s", req.GroupID, req.BadgeNumber, common.GetSubject(ctx))
+					return common.NewForbidden(ctx, common.AuthForbidden, common.Details("you must provide the invitation code you were sent in order to join"))
+				}
+
+				if g.invitationExpired(gm) {
+					aulogging.Infof(ctx, "invited user failed to join due to expired invitation - group %s badge %d by %s", req.GroupID, req.BadgeNumber, common.GetSubject(ctx))
+					return common.NewConflict(ctx, common.GroupInviteExpired, common.Details("this invitation has expired - please ask the group owner to send it again"))
+				}
+
+				gm.IsInvite = false
+
+				err = tx.UpdateGroupMembership(ctx, gm)
 				if err != nil {
-					return "", err
+					return errGroupWrite(ctx, err.Error())
 				}
-			}
 
-			gm.IsInvite = false
-			// keep invitation code, multiple clicks should be idempotent
+				informOwnerTemplate = "group-member-joined"
+			} else if grp.Owner == loggedInAttendee.ID {
+				// owner accept after apply
+
+				// an expired invitation no longer counts towards the group size, so reviving it must fit
+				if g.invitationExpired(gm) {
+					full, err := tg.groupFull(ctx, grp)
+					if err != nil {
+						return err
+					}
+					if full {
+						return errGroupFull(ctx)
+					}
+				}
 
-			err = g.DB.UpdateGroupMembership(ctx, gm)
-			if err != nil {
-				return "", errGroupWrite(ctx, err.Error())
-			}
+				if banned {
+					// this is a rare timing edge case, normally an application with an active ban cannot happen
+					aulogging.Infof(ctx, "group ban removed through owner add - group %s badge %d by %s", req.GroupID, req.BadgeNumber, common.GetSubject(ctx))
+					err := tx.RemoveGroupBan(ctx, req.GroupID, req.BadgeNumber)
+					if err != nil {
+						return err
+					}
+				}
 
-			informMemberTemplate = "group-application-accepted" // you have been added to the group
-		} else {
-			return "", common.NewForbidden(ctx, common.AuthForbidden, common.Details("only the group owner or an admin can accept invitations from others into a group"))
+				gm.IsInvite = false
+				// keep invitation code, multiple clicks should be idempotent
+
+				err = tx.UpdateGroupMembership(ctx, gm)
+				if err != nil {
+					return errGroupWrite(ctx, err.Error())
+				}
+
+				informMemberTemplate = "group-application-accepted" // you have been added to the group
+			} else {
+				return common.NewForbidden(ctx, common.AuthForbidden, common.Details("only the group owner or an admin can accept invitations from others into a group"))
+			}
 		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
 	}
 
 	_ = g.sendInfoMails(ctx, informOwnerTemplate, informMemberTemplate, grp, req.BadgeNumber, inviteCode)
@@ -262,6 +318,14 @@ func (g *groupService) RemoveMemberFromGroup(ctx context.Context, req *RemoveGro
 		return errGroupWrite(ctx, err.Error())
 	}
 
+	if grp.PendingOwner == req.BadgeNumber {
+		// ownership can only be offered to members
+		grp.PendingOwner = 0
+		if err := g.DB.UpdateGroup(ctx, grp); err != nil {
+			return errGroupWrite(ctx, err.Error())
+		}
+	}
+
 	_ = g.sendInfoMails(ctx, informOwnerTemplate, informMemberTemplate, grp, req.BadgeNumber, "")
 	// can still see results in regsys, so do not fail at this point
 
@@ -285,6 +349,90 @@ func (g *groupService) RemoveMemberFromGroup(ctx context.Context, req *RemoveGro
 	return nil
 }
 
+func (g *groupService) UpdateMemberFlags(ctx context.Context, req *UpdateGroupMemberParams) error {
+	adminPerm, loggedInAttendee, err := g.groupMembershipAuthCheck(ctx)
+	if err != nil {
+		return err
+	}
+
+	if errs := validateMemberFlags(req.Flags); len(errs) != 0 {
+		return common.NewBadRequest(ctx, common.GroupDataInvalid, errs)
+	}
+
+	return g.DB.Transaction(ctx, func(tx database.Repository) error {
+		tg := g.withDB(tx)
+
+		if _, err := tx.LockGroupByID(ctx, req.GroupID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errGroupRead(ctx, err.Error())
+		}
+
+		grp, gm, err := tg.groupMembershipExisting(ctx, req.GroupID, req.BadgeNumber) // gm may be nil if not exists
+		if err != nil {
+			return err
+		}
+
+		if !adminPerm && grp.Owner != loggedInAttendee.ID && req.BadgeNumber != loggedInAttendee.ID {
+			aulogging.Warnf(ctx, "unauthorized attempt to change member flags - group %s badge %d by %s", req.GroupID, req.BadgeNumber, common.GetSubject(ctx))
+			return common.NewForbidden(ctx, common.AuthForbidden, common.Details("only the group owner, an admin or the member themself can change member flags"))
+		}
+
+		if gm == nil || gm.GroupID != grp.ID || gm.IsInvite {
+			return common.NewNotFound(ctx, common.GroupMemberNotFound, common.Details("this attendee is not a member of this group"))
+		}
+
+		gm.Flags = entity.CollectFlags(req.Flags)
+		if err := tx.UpdateGroupMembership(ctx, gm); err != nil {
+			return errGroupWrite(ctx, err.Error())
+		}
+
+		aulogging.Infof(ctx, "group member flags changed - group %s badge %d by %s", req.GroupID, req.BadgeNumber, common.GetSubject(ctx))
+		return nil
+	})
+}
+
+// group size
+
+// groupSizeLimit returns the maximum number of members plus invitations for a group.
+//
+// The group's MaximumSize is always capped by the configured service.max_group_size.
+func groupSizeLimit(grp *entity.Group) int64 {
+	limit := maxGroupSize()
+	if grp.MaximumSize > 0 && grp.MaximumSize < limit {
+		limit = grp.MaximumSize
+	}
+	return limit
+}
+
+// groupOccupancy counts both members and outstanding invitations of a group.
+//
+// Expired invitations are not counted.
+func (g *groupService) groupOccupancy(ctx context.Context, groupID string) (int64, error) {
+	members, err := g.DB.GetGroupMembersByGroupID(ctx, groupID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, errGroupRead(ctx, err.Error())
+	}
+
+	occupancy := int64(0)
+	for _, member := range members {
+		if !g.invitationExpired(member) {
+			occupancy++
+		}
+	}
+	return occupancy, nil
+}
+
+// groupFull is true if adding another member or invitation would exceed the group size limit.
+func (g *groupService) groupFull(ctx context.Context, grp *entity.Group) (bool, error) {
+	occupancy, err := g.groupOccupancy(ctx, grp.ID)
+	if err != nil {
+		return false, err
+	}
+	return occupancy >= groupSizeLimit(grp), nil
+}
+
 // mails
 
 func (g *groupService) sendInfoMails(ctx context.Context, informOwnerTemplate string, informMemberTemplate string, grp *entity.Group, memberID int64, inviteCode string) error {
@@ -292,13 +440,14 @@ func (g *groupService) sendInfoMails(ctx context.Context, informOwnerTemplate st
 		return nil
 	}
 
-	owner, err := g.AttSrv.GetAttendee(ctx, grp.Owner)
+	// the recipients are other attendees, and mails are also sent from background jobs
+	owner, err := g.AttSrv.GetAttendeeWithApiToken(ctx, grp.Owner)
 	if err != nil {
 		aulogging.WarnErrf(ctx, err, "failed to obtain attendee info for group owner %d: %s", grp.Owner, err.Error())
 		return err
 	}
 
-	member, err := g.AttSrv.GetAttendee(ctx, memberID)
+	member, err := g.AttSrv.GetAttendeeWithApiToken(ctx, memberID)
 	if err != nil {
 		aulogging.WarnErrf(ctx, err, "failed to obtain attendee info for group member %d: %s", memberID, err.Error())
 		return err
@@ -331,12 +480,6 @@ func (g *groupService) sendInfoMails(ctx context.Context, informOwnerTemplate st
 			return err
 		}
 
-		requestURL, ok := ctx.Value(common.CtxKeyRequestURL{}).(*url.URL)
-		if !ok {
-			aulogging.WarnErrf(ctx, err, "bug - request URL not in context - failed to send email to group member %d about %s: %s", memberID, informMemberTemplate, err.Error())
-			return errors.New("could not retrieve base URL from context - this is an implementation error")
-		}
-
 		mailRequest := mailservice.MailSendDto{
 			CommonID: informMemberTemplate,
 			Lang:     member.RegistrationLanguage,
@@ -349,7 +492,7 @@ func (g *groupService) sendInfoMails(ctx context.Context, informOwnerTemplate st
 		}
 		if inviteCode != "" {
 			// TODO this is probably not quite correct
-			mailRequest.Variables["url"] = conf.Service.JoinLinkBaseURL + requestURL.Path + inviteCode
+			mailRequest.Variables["url"] = conf.Service.JoinLinkBaseURL + invitationPath(grp.ID, memberID) + inviteCode
 		}
 
 		err = g.MailSrv.SendEmail(ctx, mailRequest)
@@ -369,7 +512,7 @@ func (g *groupService) validateRequestedAttendee(ctx context.Context, badgeNo in
 		return attendeeservice.Attendee{}, common.NewBadRequest(ctx, common.GroupDataInvalid, common.Details("attendee badge number must be positive integer"))
 	}
 
-	attendee, err := g.AttSrv.GetAttendee(ctx, badgeNo)
+	attendee, err := g.AttSrv.GetAttendeeWithApiToken(ctx, badgeNo)
 	if err != nil {
 		if errors.Is(err, downstreams.ErrDownStreamNotFound) {
 			return attendeeservice.Attendee{}, common.NewNotFound(ctx, common.NoSuchAttendee, common.Details("no such attendee"))
@@ -378,7 +521,7 @@ func (g *groupService) validateRequestedAttendee(ctx context.Context, badgeNo in
 		}
 	}
 
-	if err := g.checkAttending(ctx, badgeNo); err != nil {
+	if err := g.checkAttending(ctx, badgeNo, g.AttSrv.GetStatusWithApiToken); err != nil {
 		return attendeeservice.Attendee{}, err
 	}
 
@@ -429,6 +572,13 @@ func (g *groupService) groupMembershipExisting(ctx context.Context, groupID stri
 	return grp, gm, nil
 }
 
+// invitationPath is the path of the group membership, which an invited attendee uses to accept the invitation.
+//
+// This is independent of the current request, so the same link can be sent when an invitation is resent.
+func invitationPath(groupID string, badgeNo int64) string {
+	return fmt.Sprintf("/api/rest/v1/groups/%s/members/%d", url.PathEscape(groupID), badgeNo)
+}
+
 func rollInvitationCode() string {
 	return randomHumanReadableString(8) // 40 bits - about one in a trillion
 }
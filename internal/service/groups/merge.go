@@ -0,0 +1,306 @@
+package groupservice
+
+import (
+	"context"
+	"errors"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+	"gorm.io/gorm"
+	"slices"
+)
+
+func (g *groupService) MergeGroups(ctx context.Context, sourceID string, targetID string) error {
+	adminPerm, loggedInAttendee, err := g.groupMembershipAuthCheck(ctx)
+	if err != nil {
+		return err
+	}
+
+	if sourceID == targetID {
+		return common.NewBadRequest(ctx, common.GroupDataInvalid, common.Details("cannot merge a group into itself"))
+	}
+
+	var source, target *entity.Group
+	var members []int64
+	merged := false
+	err = g.DB.Transaction(ctx, func(tx database.Repository) error {
+		tg := g.withDB(tx)
+
+		if err := tg.lockGroups(ctx, sourceID, targetID); err != nil {
+			return err
+		}
+
+		var err error
+		if source, err = tg.existingGroup(ctx, sourceID); err != nil {
+			return err
+		}
+		if target, err = tg.existingGroup(ctx, targetID); err != nil {
+			return err
+		}
+
+		if !adminPerm {
+			if source.Owner == loggedInAttendee.ID {
+				// the owner of the target group has to agree first, replaces any previous request
+				source.PendingMerge = targetID
+				if err := tx.UpdateGroup(ctx, source); err != nil {
+					return errGroupWrite(ctx, err.Error())
+				}
+				return nil
+			} else if target.Owner == loggedInAttendee.ID {
+				if source.PendingMerge != targetID {
+					return common.NewNotFound(ctx, common.GroupMergeNotFound, common.Details("there is no pending request to merge this group into yours"))
+				}
+			} else {
+				return common.NewForbidden(ctx, common.AuthForbidden, common.Details("only the owners of both groups or an admin can merge groups"))
+			}
+		}
+
+		members, err = tg.mergeInto(ctx, source, target)
+		if err != nil {
+			return err
+		}
+		merged = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !merged {
+		aulogging.Infof(ctx, "group merge requested - group %s into %s by %s", sourceID, targetID, common.GetSubject(ctx))
+		_ = g.sendInfoMails(ctx, "group-merge-requested", "", target, source.Owner, "")
+		// the pending merge is visible on the group, so do not fail at this point
+		return nil
+	}
+
+	aulogging.Infof(ctx, "groups merged - group %s into %s by %s", sourceID, targetID, common.GetSubject(ctx))
+	for _, memberID := range members {
+		_ = g.sendInfoMails(ctx, "", "group-merged", target, memberID, "")
+	}
+	return nil
+}
+
+func (g *groupService) CancelGroupMerge(ctx context.Context, sourceID string) error {
+	adminPerm, loggedInAttendee, err := g.groupMembershipAuthCheck(ctx)
+	if err != nil {
+		return err
+	}
+
+	var source, target *entity.Group
+	declined := false
+	err = g.DB.Transaction(ctx, func(tx database.Repository) error {
+		tg := g.withDB(tx)
+
+		if _, err := tx.LockGroupByID(ctx, sourceID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errGroupRead(ctx, err.Error())
+		}
+
+		var err error
+		if source, err = tg.existingGroup(ctx, sourceID); err != nil {
+			return err
+		}
+
+		if source.PendingMerge == "" {
+			if !adminPerm && source.Owner != loggedInAttendee.ID {
+				return common.NewForbidden(ctx, common.AuthForbidden, common.Details("only the owners of both groups or an admin can cancel a group merge"))
+			}
+			return common.NewNotFound(ctx, common.GroupMergeNotFound, common.Details("there is no pending merge request for this group"))
+		}
+
+		// the target group may have been deleted meanwhile, then only the source owner or an admin can cancel
+		target, err = tg.existingGroup(ctx, source.PendingMerge)
+		if err != nil {
+			target = nil
+		}
+
+		declined = target != nil && target.Owner == loggedInAttendee.ID
+		if !adminPerm && source.Owner != loggedInAttendee.ID && !declined {
+			return common.NewForbidden(ctx, common.AuthForbidden, common.Details("only the owners of both groups or an admin can cancel a group merge"))
+		}
+
+		source.PendingMerge = ""
+		if err := tx.UpdateGroup(ctx, source); err != nil {
+			return errGroupWrite(ctx, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if declined {
+		aulogging.Infof(ctx, "group merge declined - group %s into %s by %s", sourceID, target.ID, common.GetSubject(ctx))
+		_ = g.sendInfoMails(ctx, "group-merge-declined", "", source, target.Owner, "")
+	} else {
+		aulogging.Infof(ctx, "group merge request withdrawn - group %s by %s", sourceID, common.GetSubject(ctx))
+		if target != nil {
+			_ = g.sendInfoMails(ctx, "group-merge-withdrawn", "", target, source.Owner, "")
+		}
+	}
+
+	return nil
+}
+
+// mergeInto moves all members of the source group into the target group as full members, and deletes the
+// source group. Returns the badge numbers of all members of the merged group.
+//
+// Pending invitations to the source group are dropped. The auto-decline lists of both groups are combined,
+// except for members of the merged group, because both owners have agreed to have them.
+func (g *groupService) mergeInto(ctx context.Context, source *entity.Group, target *entity.Group) ([]int64, error) {
+	sourceMembers, err := g.DB.GetGroupMembersByGroupID(ctx, source.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errGroupRead(ctx, err.Error())
+	}
+	targetMembers, err := g.DB.GetGroupMembersByGroupID(ctx, target.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errGroupRead(ctx, err.Error())
+	}
+
+	merged := make([]int64, 0, len(sourceMembers)+len(targetMembers))
+	for _, m := range targetMembers {
+		if !m.IsInvite {
+			merged = append(merged, m.ID)
+		}
+	}
+	joining := 0
+	for _, m := range sourceMembers {
+		if !m.IsInvite {
+			merged = append(merged, m.ID)
+			joining++
+		}
+	}
+	slices.Sort(merged)
+
+	occupancy, err := g.groupOccupancy(ctx, target.ID)
+	if err != nil {
+		return nil, err
+	}
+	if occupancy+int64(joining) > groupSizeLimit(target) {
+		return nil, common.NewConflict(ctx, common.GroupSizeFull, common.Details("the merged group would exceed the maximum group size"))
+	}
+
+	if err := g.mergeBans(ctx, source, target, merged); err != nil {
+		return nil, err
+	}
+
+	for _, m := range sourceMembers {
+		if err := g.DB.DeleteGroupMembership(ctx, m.ID); err != nil {
+			return nil, errGroupWrite(ctx, err.Error())
+		}
+		if m.IsInvite {
+			aulogging.Infof(ctx, "invitation dropped by group merge - group %s badge %d", source.ID, m.ID)
+			continue
+		}
+
+		moved := *m
+		moved.GroupID = target.ID
+		if err := g.DB.AddGroupMembership(ctx, &moved); err != nil {
+			return nil, errGroupWrite(ctx, err.Error())
+		}
+	}
+
+	if err := g.dropPendingMergesInto(ctx, source.ID); err != nil {
+		return nil, err
+	}
+	if target.PendingMerge == source.ID {
+		target.PendingMerge = ""
+	}
+
+	if err := deleteGroupWish(ctx, g.DB, source.ID); err != nil {
+		return nil, errGroupWrite(ctx, err.Error())
+	}
+	if err := g.DB.DeleteGroupByID(ctx, source.ID); err != nil {
+		return nil, errGroupWrite(ctx, err.Error())
+	}
+
+	return merged, nil
+}
+
+// mergeBans moves the auto-decline list of the source group to the target group, and removes the members
+// of the merged group from it.
+func (g *groupService) mergeBans(ctx context.Context, source *entity.Group, target *entity.Group, merged []int64) error {
+	sourceBans, err := g.DB.GetGroupBans(ctx, source.ID)
+	if err != nil {
+		return errGroupRead(ctx, err.Error())
+	}
+	for _, ban := range sourceBans {
+		if err := g.DB.RemoveGroupBan(ctx, source.ID, ban.ID); err != nil {
+			return errGroupWrite(ctx, err.Error())
+		}
+		if slices.Contains(merged, ban.ID) {
+			continue
+		}
+		banned, err := g.DB.HasGroupBan(ctx, target.ID, ban.ID)
+		if err != nil {
+			return errGroupRead(ctx, err.Error())
+		}
+		if !banned {
+			if err := g.DB.AddGroupBan(ctx, target.ID, ban.ID, ban.Comments); err != nil {
+				return errGroupWrite(ctx, err.Error())
+			}
+		}
+	}
+
+	for _, memberID := range merged {
+		banned, err := g.DB.HasGroupBan(ctx, target.ID, memberID)
+		if err != nil {
+			return errGroupRead(ctx, err.Error())
+		}
+		if banned {
+			aulogging.Infof(ctx, "group ban removed through group merge - group %s badge %d by %s", target.ID, memberID, common.GetSubject(ctx))
+			if err := g.DB.RemoveGroupBan(ctx, target.ID, memberID); err != nil {
+				return errGroupWrite(ctx, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// dropPendingMergesInto withdraws all requests to merge another group into the given group, because it is
+// about to be deleted.
+func (g *groupService) dropPendingMergesInto(ctx context.Context, groupID string) error {
+	groups, err := g.DB.GetGroups(ctx)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errGroupRead(ctx, err.Error())
+	}
+
+	for _, grp := range groups {
+		if grp.PendingMerge != groupID {
+			continue
+		}
+		grp.PendingMerge = ""
+		if err := g.DB.UpdateGroup(ctx, grp); err != nil {
+			return errGroupWrite(ctx, err.Error())
+		}
+		aulogging.Infof(ctx, "pending merge request dropped - group %s into group %s", grp.ID, groupID)
+	}
+	return nil
+}
+
+// lockGroups locks several groups in a fixed order, so concurrent requests cannot deadlock.
+func (g *groupService) lockGroups(ctx context.Context, groupIDs ...string) error {
+	sorted := slices.Clone(groupIDs)
+	slices.Sort(sorted)
+	for _, id := range sorted {
+		if _, err := g.DB.LockGroupByID(ctx, id); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errGroupRead(ctx, err.Error())
+		}
+	}
+	return nil
+}
+
+// existingGroup reads a group, failing if it does not exist or has been deleted.
+func (g *groupService) existingGroup(ctx context.Context, groupID string) (*entity.Group, error) {
+	grp, err := g.DB.GetGroupByID(ctx, groupID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.NewNotFound(ctx, common.GroupIDNotFound, common.Details("this group does not exist"))
+		}
+		return nil, errGroupRead(ctx, err.Error())
+	}
+	if grp.DeletedAt.Valid {
+		return nil, common.NewNotFound(ctx, common.GroupIDNotFound, common.Details("this group does not exist"))
+	}
+	return grp, nil
+}
@@ -0,0 +1,137 @@
+package groupservice
+
+import (
+	"context"
+	"errors"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+	"gorm.io/gorm"
+)
+
+func (g *groupService) RemoveNonAttendingMember(ctx context.Context, badgeNo int64) (bool, error) {
+	gm, err := g.DB.GetGroupMembershipByAttendeeID(ctx, badgeNo)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, errGroupRead(ctx, err.Error())
+	}
+
+	grp, err := g.DB.GetGroupByID(ctx, gm.GroupID)
+	if err != nil {
+		return false, errGroupRead(ctx, err.Error())
+	}
+
+	if grp.Owner == badgeNo {
+		reassigned, err := g.reassignOwner(ctx, grp)
+		if err != nil {
+			return false, err
+		}
+		if !reassigned {
+			return g.disbandGroup(ctx, grp.ID, badgeNo)
+		}
+	}
+
+	var removedFrom *entity.Group
+	err = g.DB.Transaction(ctx, func(tx database.Repository) error {
+		if _, err := tx.LockGroupByID(ctx, grp.ID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil // group deleted concurrently
+			}
+			return errGroupRead(ctx, err.Error())
+		}
+
+		current, err := tx.GetGroupByID(ctx, grp.ID)
+		if err != nil {
+			return errGroupRead(ctx, err.Error())
+		}
+
+		currentGm, err := tx.GetGroupMembershipByAttendeeID(ctx, badgeNo)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil // removed concurrently
+			}
+			return errGroupRead(ctx, err.Error())
+		}
+		if currentGm.GroupID != current.ID || current.Owner == badgeNo {
+			return nil // changed concurrently, try again next time
+		}
+
+		if err := tx.DeleteGroupMembership(ctx, badgeNo); err != nil {
+			return errGroupWrite(ctx, err.Error())
+		}
+
+		if current.PendingOwner == badgeNo {
+			current.PendingOwner = 0
+			if err := tx.UpdateGroup(ctx, current); err != nil {
+				return errGroupWrite(ctx, err.Error())
+			}
+		}
+
+		removedFrom = current
+		return nil
+	})
+	if err != nil || removedFrom == nil {
+		return false, err
+	}
+
+	aulogging.Infof(ctx, "attendee %d no longer attending - removed from group %s", badgeNo, removedFrom.ID)
+	if !gm.IsInvite {
+		_ = g.sendInfoMails(ctx, "group-member-removed", "", removedFrom, badgeNo, "")
+	}
+
+	return true, nil
+}
+
+// disbandGroup deletes a group whose owner is no longer attending, because no other member can take over.
+//
+// Any remaining invitations and the auto-decline list are deleted together with the group. Everyone
+// who was in the group or invited to it is informed by mail.
+func (g *groupService) disbandGroup(ctx context.Context, groupID string, owner int64) (bool, error) {
+	var disbanded *entity.Group
+	var members []*entity.GroupMember
+	err := g.DB.Transaction(ctx, func(tx database.Repository) error {
+		current, err := tx.LockGroupByID(ctx, groupID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return errGroupRead(ctx, err.Error())
+		}
+		if current.Owner != owner {
+			return nil // ownership changed concurrently
+		}
+
+		members, err = tx.GetGroupMembersByGroupID(ctx, groupID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errGroupRead(ctx, err.Error())
+		}
+		for _, member := range members {
+			if err := tx.DeleteGroupMembership(ctx, member.ID); err != nil {
+				return errGroupWrite(ctx, err.Error())
+			}
+		}
+
+		if err := deleteGroupBans(ctx, tx, groupID); err != nil {
+			return errGroupWrite(ctx, err.Error())
+		}
+		if err := deleteGroupWish(ctx, tx, groupID); err != nil {
+			return errGroupWrite(ctx, err.Error())
+		}
+		if err := tx.DeleteGroupByID(ctx, groupID); err != nil {
+			return errGroupWrite(ctx, err.Error())
+		}
+		disbanded = current
+		return nil
+	})
+	if err != nil || disbanded == nil {
+		return false, err
+	}
+
+	aulogging.Infof(ctx, "attendee %d no longer attending - group %s disbanded because nobody can take over ownership", owner, groupID)
+	for _, member := range members {
+		_ = g.sendInfoMails(ctx, "", "group-disbanded", disbanded, member.ID, "")
+	}
+	return true, nil
+}
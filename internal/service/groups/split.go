@@ -0,0 +1,131 @@
+package groupservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+	"github.com/eurofurence/reg-room-service/internal/service/rbac"
+	"gorm.io/gorm"
+	"slices"
+)
+
+func (g *groupService) SplitGroup(ctx context.Context, groupID string, split *modelsv1.GroupSplit) (string, error) {
+	validator, err := rbac.NewValidator(ctx)
+	if err != nil {
+		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
+		return "", errCouldNotGetValidator(ctx)
+	}
+
+	if !validator.IsAdmin() && !validator.IsAPITokenCall() {
+		return "", common.NewForbidden(ctx, common.AuthForbidden, common.Details("only an admin can split a group"))
+	}
+
+	moving := slices.Clone(split.Members)
+	slices.Sort(moving)
+	moving = slices.Compact(moving)
+	if len(moving) == 0 {
+		return "", common.NewBadRequest(ctx, common.GroupDataInvalid, common.Details("you must select at least one member to move to the new group"))
+	}
+	if !slices.Contains(moving, split.Owner) {
+		return "", common.NewBadRequest(ctx, common.GroupDataInvalid, common.Details("the owner of the new group must be one of the members who move"))
+	}
+
+	var newGroup *entity.Group
+	err = g.DB.Transaction(ctx, func(tx database.Repository) error {
+		tg := g.withDB(tx)
+
+		if _, err := tx.LockGroupByID(ctx, groupID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errGroupRead(ctx, err.Error())
+		}
+
+		grp, err := tg.existingGroup(ctx, groupID)
+		if err != nil {
+			return err
+		}
+
+		members, err := tx.GetGroupMembersByGroupID(ctx, groupID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errGroupRead(ctx, err.Error())
+		}
+		joined := make([]int64, 0, len(members))
+		for _, m := range members {
+			if !m.IsInvite {
+				joined = append(joined, m.ID)
+			}
+		}
+		for _, badgeNumber := range moving {
+			if !slices.Contains(joined, badgeNumber) {
+				return common.NewNotFound(ctx, common.GroupMemberNotFound, common.Details(fmt.Sprintf("attendee %d is not a member of this group", badgeNumber)))
+			}
+		}
+		if slices.Contains(moving, grp.Owner) {
+			return common.NewBadRequest(ctx, common.GroupOwnerCannotRemove, common.Details("the owner of the group cannot move to the new group, change the owner first"))
+		}
+
+		name, err := tg.splitGroupName(ctx, grp.Name)
+		if err != nil {
+			return err
+		}
+
+		newGroup = &entity.Group{
+			Name:        name,
+			Flags:       grp.Flags,
+			MaximumSize: grp.MaximumSize,
+			Owner:       split.Owner,
+		}
+		newGroup.ID, err = tx.AddGroup(ctx, newGroup)
+		if err != nil {
+			return errGroupWrite(ctx, err.Error())
+		}
+
+		if err := tx.MoveGroupMemberships(ctx, newGroup.ID, moving); err != nil {
+			return errGroupWrite(ctx, err.Error())
+		}
+
+		// the offer goes with the member, they no longer belong to the group
+		if slices.Contains(moving, grp.PendingOwner) {
+			grp.PendingOwner = 0
+			if err := tx.UpdateGroup(ctx, grp); err != nil {
+				return errGroupWrite(ctx, err.Error())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	aulogging.Infof(ctx, "group split - members %v of group %s moved to new group %s by %s", moving, groupID, newGroup.ID, common.GetSubject(ctx))
+	for _, badgeNumber := range moving {
+		_ = g.sendInfoMails(ctx, "", "group-split", newGroup, badgeNumber, "")
+	}
+
+	return newGroup.ID, nil
+}
+
+// splitGroupName derives the name of a new group split off from a group, such as "kittens (2)",
+// using the first number that is not taken by another group.
+func (g *groupService) splitGroupName(ctx context.Context, name string) (string, error) {
+	for n := 2; ; n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+		base := []rune(name)
+		// must still pass validation
+		for len(string(base))+len(suffix) > 50 {
+			base = base[:len(base)-1]
+		}
+		candidate := string(base) + suffix
+
+		matchingIDs, _, err := g.DB.FindGroups(ctx, database.FindGroupCriteria{Name: candidate, MaxOccupancy: -1}, nil)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errGroupRead(ctx, err.Error())
+		}
+		if len(matchingIDs) == 0 {
+			return candidate, nil
+		}
+	}
+}
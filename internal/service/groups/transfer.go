@@ -0,0 +1,302 @@
+package groupservice
+
+import (
+	"context"
+	"errors"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+	"gorm.io/gorm"
+	"sort"
+)
+
+func (g *groupService) ProposeOwnershipTransfer(ctx context.Context, req *OwnershipTransferParams) error {
+	adminPerm, loggedInAttendee, err := g.groupMembershipAuthCheck(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := g.validateRequestedAttendee(ctx, req.BadgeNumber); err != nil {
+		return err
+	}
+
+	var grp *entity.Group
+	err = g.DB.Transaction(ctx, func(tx database.Repository) error {
+		tg := g.withDB(tx)
+
+		if _, err := tx.LockGroupByID(ctx, req.GroupID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errGroupRead(ctx, err.Error())
+		}
+
+		var gm *entity.GroupMember
+		var err error
+		grp, gm, err = tg.groupMembershipExisting(ctx, req.GroupID, req.BadgeNumber) // gm may be nil if not exists
+		if err != nil {
+			return err
+		}
+
+		if !adminPerm && grp.Owner != loggedInAttendee.ID {
+			return common.NewForbidden(ctx, common.AuthForbidden, common.Details("only the group owner or an admin can transfer ownership of a group"))
+		}
+
+		return tg.offerOwnership(ctx, grp, gm, req.BadgeNumber)
+	})
+	if err != nil {
+		return err
+	}
+
+	aulogging.Infof(ctx, "group ownership offered - group %s badge %d by %s", req.GroupID, req.BadgeNumber, common.GetSubject(ctx))
+	_ = g.sendInfoMails(ctx, "", "group-transfer-offered", grp, req.BadgeNumber, "")
+	// the pending transfer is visible on the group, so do not fail at this point
+
+	return nil
+}
+
+// offerOwnership sets the pending owner of the group and writes it to the database.
+//
+// gm is the membership of the attendee who should become the new owner, and may be nil.
+func (g *groupService) offerOwnership(ctx context.Context, grp *entity.Group, gm *entity.GroupMember, badgeNo int64) error {
+	if gm == nil || gm.GroupID != grp.ID || gm.IsInvite {
+		return common.NewNotFound(ctx, common.GroupMemberNotFound, common.Details("ownership can only be transferred to a member of this group"))
+	}
+
+	if grp.Owner == badgeNo {
+		return common.NewBadRequest(ctx, common.GroupDataInvalid, common.Details("this attendee already owns the group"))
+	}
+
+	// replaces any previous offer
+	grp.PendingOwner = badgeNo
+	if err := g.DB.UpdateGroup(ctx, grp); err != nil {
+		return errGroupWrite(ctx, err.Error())
+	}
+	return nil
+}
+
+func (g *groupService) AcceptOwnershipTransfer(ctx context.Context, groupID string) error {
+	attendee, err := g.loggedInUserValidRegistration(ctx)
+	if err != nil {
+		return err
+	}
+
+	var grp *entity.Group
+	previousOwner := int64(0)
+	err = g.DB.Transaction(ctx, func(tx database.Repository) error {
+		tg := g.withDB(tx)
+
+		if _, err := tx.LockGroupByID(ctx, groupID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errGroupRead(ctx, err.Error())
+		}
+
+		var gm *entity.GroupMember
+		var err error
+		grp, gm, err = tg.groupMembershipExisting(ctx, groupID, attendee.ID) // gm may be nil if not exists
+		if err != nil {
+			return err
+		}
+
+		if grp.PendingOwner == 0 || grp.PendingOwner != attendee.ID || gm == nil || gm.GroupID != grp.ID || gm.IsInvite {
+			return common.NewNotFound(ctx, common.GroupTransferNotFound, common.Details("there is no pending ownership transfer to you for this group"))
+		}
+
+		previousOwner = grp.Owner
+		grp.Owner = attendee.ID
+		grp.PendingOwner = 0
+		if err := tx.UpdateGroup(ctx, grp); err != nil {
+			return errGroupWrite(ctx, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	aulogging.Infof(ctx, "group ownership transferred - group %s from %d to %d by self", groupID, previousOwner, attendee.ID)
+	informGrp := *grp
+	informGrp.Owner = previousOwner
+	_ = g.sendInfoMails(ctx, "group-transfer-accepted", "", &informGrp, attendee.ID, "")
+
+	return nil
+}
+
+func (g *groupService) CancelOwnershipTransfer(ctx context.Context, groupID string) error {
+	adminPerm, loggedInAttendee, err := g.groupMembershipAuthCheck(ctx)
+	if err != nil {
+		return err
+	}
+
+	var grp *entity.Group
+	pendingOwner := int64(0)
+	declined := false
+	err = g.DB.Transaction(ctx, func(tx database.Repository) error {
+		if _, err := tx.LockGroupByID(ctx, groupID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errGroupRead(ctx, err.Error())
+		}
+
+		var err error
+		grp, err = tx.GetGroupByID(ctx, groupID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return common.NewNotFound(ctx, common.GroupIDNotFound, common.Details("this group does not exist"))
+			}
+			return errGroupRead(ctx, err.Error())
+		}
+
+		pendingOwner = grp.PendingOwner
+		isOwner := grp.Owner == loggedInAttendee.ID
+		declined = pendingOwner != 0 && pendingOwner == loggedInAttendee.ID
+		if !adminPerm && !isOwner && !declined {
+			return common.NewForbidden(ctx, common.AuthForbidden, common.Details("only the group owner, the member who was offered ownership, or an admin can cancel an ownership transfer"))
+		}
+
+		if pendingOwner == 0 {
+			return common.NewNotFound(ctx, common.GroupTransferNotFound, common.Details("there is no pending ownership transfer for this group"))
+		}
+
+		grp.PendingOwner = 0
+		if err := tx.UpdateGroup(ctx, grp); err != nil {
+			return errGroupWrite(ctx, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if declined {
+		aulogging.Infof(ctx, "group ownership declined - group %s badge %d by self", groupID, pendingOwner)
+		_ = g.sendInfoMails(ctx, "group-transfer-declined", "", grp, pendingOwner, "")
+	} else {
+		aulogging.Infof(ctx, "group ownership offer withdrawn - group %s badge %d by %s", groupID, pendingOwner, common.GetSubject(ctx))
+		_ = g.sendInfoMails(ctx, "", "group-transfer-withdrawn", grp, pendingOwner, "")
+	}
+
+	return nil
+}
+
+func (g *groupService) ReassignCancelledOwners(ctx context.Context) (int, error) {
+	groups, err := g.DB.GetGroups(ctx)
+	if err != nil {
+		return 0, errGroupRead(ctx, err.Error())
+	}
+
+	count := 0
+	for _, grp := range groups {
+		attending, err := g.isAttending(ctx, grp.Owner)
+		if err != nil {
+			return count, err
+		}
+		if attending {
+			continue
+		}
+
+		reassigned, err := g.reassignOwner(ctx, grp)
+		if err != nil {
+			return count, err
+		}
+		if reassigned {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// reassignOwner passes ownership of a group whose owner is no longer attending to another attending member.
+//
+// The member who was offered ownership is preferred, otherwise the member with the lowest badge number is chosen.
+// Returns false if there is no other attending member.
+func (g *groupService) reassignOwner(ctx context.Context, grp *entity.Group) (bool, error) {
+	newOwner, err := g.fallbackOwner(ctx, grp)
+	if err != nil {
+		return false, err
+	}
+	if newOwner == 0 {
+		aulogging.Warnf(ctx, "group owner %d no longer attending, but there is no other member to take over group %s", grp.Owner, grp.ID)
+		return false, nil
+	}
+
+	var updated *entity.Group
+	err = g.DB.Transaction(ctx, func(tx database.Repository) error {
+		if _, err := tx.LockGroupByID(ctx, grp.ID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil // group deleted concurrently
+			}
+			return errGroupRead(ctx, err.Error())
+		}
+
+		current, err := tx.GetGroupByID(ctx, grp.ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return errGroupRead(ctx, err.Error())
+		}
+		if current.Owner != grp.Owner {
+			return nil // ownership changed concurrently
+		}
+
+		gm, err := tx.GetGroupMembershipByAttendeeID(ctx, newOwner)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return errGroupRead(ctx, err.Error())
+		}
+		if gm.GroupID != grp.ID || gm.IsInvite {
+			return nil // left the group concurrently, try again next time
+		}
+
+		current.Owner = newOwner
+		current.PendingOwner = 0
+		if err := tx.UpdateGroup(ctx, current); err != nil {
+			return errGroupWrite(ctx, err.Error())
+		}
+		updated = current
+		return nil
+	})
+	if err != nil || updated == nil {
+		return false, err
+	}
+
+	aulogging.Infof(ctx, "group owner %d no longer attending - ownership of group %s passed to %d", grp.Owner, grp.ID, newOwner)
+	_ = g.sendInfoMails(ctx, "group-owner-fallback", "", updated, grp.Owner, "")
+
+	return true, nil
+}
+
+// fallbackOwner chooses the attending member who should take over a group, or returns 0 if there is none.
+func (g *groupService) fallbackOwner(ctx context.Context, grp *entity.Group) (int64, error) {
+	members, err := g.DB.GetGroupMembersByGroupID(ctx, grp.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, errGroupRead(ctx, err.Error())
+	}
+
+	candidates := make([]int64, 0)
+	for _, member := range members {
+		if !member.IsInvite && member.ID != grp.Owner {
+			candidates = append(candidates, member.ID)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		// the member who was offered ownership comes first
+		if (candidates[i] == grp.PendingOwner) != (candidates[j] == grp.PendingOwner) {
+			return candidates[i] == grp.PendingOwner
+		}
+		return candidates[i] < candidates[j]
+	})
+
+	for _, candidate := range candidates {
+		attending, err := g.isAttending(ctx, candidate)
+		if err != nil {
+			return 0, err
+		}
+		if attending {
+			return candidate, nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,158 @@
+package groupservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/controller/v1/util"
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"net/url"
+	"slices"
+)
+
+const wishesDescription = "room wishes"
+
+func (g *groupService) GetGroupWishes(ctx context.Context, groupID string) (*modelsv1.GroupWishes, error) {
+	if _, err := g.groupOwnerAuthCheck(ctx, groupID, wishesDescription); err != nil {
+		return nil, err
+	}
+
+	wish, err := g.DB.GetGroupWish(ctx, groupID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return toGroupWishes(&entity.GroupWish{}), nil
+		}
+		return nil, errGroupRead(ctx, err.Error())
+	}
+
+	return toGroupWishes(wish), nil
+}
+
+func (g *groupService) UpdateGroupWishes(ctx context.Context, groupID string, wishes *modelsv1.GroupWishes) error {
+	if _, err := g.groupOwnerAuthCheck(ctx, groupID, wishesDescription); err != nil {
+		return err
+	}
+
+	wish := fromGroupWishes(groupID, wishes)
+	if errs := g.validateGroupWish(ctx, wish); len(errs) != 0 {
+		return common.NewBadRequest(ctx, common.GroupDataInvalid, errs)
+	}
+
+	return g.DB.Transaction(ctx, func(tx database.Repository) error {
+		if _, err := tx.LockGroupByID(ctx, groupID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errGroupIDNotFound(ctx)
+			}
+			return errGroupRead(ctx, err.Error())
+		}
+
+		existing, err := tx.GetGroupWish(ctx, groupID)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return errGroupRead(ctx, err.Error())
+			}
+			existing = nil
+		}
+
+		if emptyGroupWish(wish) {
+			if existing == nil {
+				return nil
+			}
+			aulogging.Infof(ctx, "group room wishes removed - group %s by %s", groupID, common.GetSubject(ctx))
+			if err := tx.DeleteGroupWish(ctx, groupID); err != nil {
+				return errGroupWrite(ctx, err.Error())
+			}
+			return nil
+		}
+
+		if existing != nil {
+			wish.CreatedAt = existing.CreatedAt
+		}
+		aulogging.Infof(ctx, "group room wishes changed - group %s by %s", groupID, common.GetSubject(ctx))
+		if err := tx.SetGroupWish(ctx, wish); err != nil {
+			return errGroupWrite(ctx, err.Error())
+		}
+		return nil
+	})
+}
+
+// validateGroupWish checks the wished room type and near groups against the database, and the wished room flags
+// against the service configuration.
+func (g *groupService) validateGroupWish(ctx context.Context, wish *entity.GroupWish) url.Values {
+	result := url.Values{}
+	if wish.TypeID != "" {
+		if err := uuid.Validate(wish.TypeID); err != nil {
+			result.Set("type", fmt.Sprintf("'%s' is not a valid UUID", url.PathEscape(wish.TypeID)))
+		} else if rt, err := g.DB.GetRoomTypeByID(ctx, wish.TypeID); err != nil || rt.DeletedAt.Valid {
+			result.Set("type", "no such room type")
+		}
+	}
+
+	if wish.Size < 0 {
+		result.Set("size", "size must not be negative")
+	}
+
+	allowed := allowedRoomFlags()
+	for _, flag := range entity.AggregateFlags(wish.RoomFlags) {
+		if !util.SliceContains(flag, allowed) {
+			result.Set("room_flags", fmt.Sprintf("no such room flag '%s'", url.PathEscape(flag)))
+		}
+	}
+
+	for _, nearID := range entity.AggregateFlags(wish.NearGroups) {
+		if nearID == wish.GroupID {
+			result.Set("near_groups", "a group cannot wish to be near itself")
+		} else if err := uuid.Validate(nearID); err != nil {
+			result.Set("near_groups", fmt.Sprintf("'%s' is not a valid UUID", url.PathEscape(nearID)))
+		} else if grp, err := g.DB.GetGroupByID(ctx, nearID); err != nil || grp.DeletedAt.Valid {
+			result.Set("near_groups", fmt.Sprintf("no such group '%s'", nearID))
+		}
+	}
+
+	return result
+}
+
+// deleteGroupWish removes the room wishes of a group that is being deleted, if it has any.
+func deleteGroupWish(ctx context.Context, db database.Repository, groupID string) error {
+	if err := db.DeleteGroupWish(ctx, groupID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+func emptyGroupWish(wish *entity.GroupWish) bool {
+	return wish.TypeID == "" && wish.Size == 0 && wish.RoomFlags == "," && wish.NearGroups == "," && wish.Comments == ""
+}
+
+func toGroupWishes(wish *entity.GroupWish) *modelsv1.GroupWishes {
+	return &modelsv1.GroupWishes{
+		Type:       common.ToOmitEmpty(wish.TypeID),
+		Size:       wish.Size,
+		RoomFlags:  entity.AggregateFlags(wish.RoomFlags),
+		NearGroups: entity.AggregateFlags(wish.NearGroups),
+		Comments:   common.ToOmitEmpty(wish.Comments),
+	}
+}
+
+func fromGroupWishes(groupID string, wishes *modelsv1.GroupWishes) *entity.GroupWish {
+	return &entity.GroupWish{
+		GroupID:    groupID,
+		TypeID:     common.Deref(wishes.Type),
+		Size:       wishes.Size,
+		RoomFlags:  entity.CollectFlags(sortedUnique(wishes.RoomFlags)),
+		NearGroups: entity.CollectFlags(sortedUnique(wishes.NearGroups)),
+		Comments:   common.Deref(wishes.Comments),
+	}
+}
+
+func sortedUnique(values []string) []string {
+	result := slices.Clone(values)
+	slices.Sort(result)
+	return slices.Compact(result)
+}
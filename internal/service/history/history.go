@@ -0,0 +1,145 @@
+package historyservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	"gorm.io/gorm"
+
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/service/rbac"
+)
+
+const isoDateTimeFormat = "2006-01-02T15:04:05-07:00"
+
+func (h *historyService) GetGroupHistory(ctx context.Context, groupID string, page *Paging) (*modelsv1.HistoryList, error) {
+	if err := h.adminOnly(ctx, "group "+groupID); err != nil {
+		return nil, err
+	}
+
+	result, err := h.history(ctx, []string{entity.HistoryEntityGroup, entity.HistoryEntityGroupWish, entity.HistoryEntityGroupMember, entity.HistoryEntityGroupBan}, groupID, page)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Total == 0 {
+		if _, err := h.DB.GetGroupByID(ctx, groupID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, common.NewNotFound(ctx, common.GroupIDNotFound, common.Details("group does not exist and has no history"))
+			}
+			return nil, errHistoryRead(ctx, err.Error())
+		}
+	}
+
+	return result, nil
+}
+
+func (h *historyService) GetRoomHistory(ctx context.Context, roomID string, page *Paging) (*modelsv1.HistoryList, error) {
+	if err := h.adminOnly(ctx, "room "+roomID); err != nil {
+		return nil, err
+	}
+
+	result, err := h.history(ctx, []string{entity.HistoryEntityRoom, entity.HistoryEntityRoomMember}, roomID, page)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Total == 0 {
+		if _, err := h.DB.GetRoomByID(ctx, roomID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, common.NewNotFound(ctx, common.RoomIDNotFound, common.Details("room does not exist and has no history"))
+			}
+			return nil, errHistoryRead(ctx, err.Error())
+		}
+	}
+
+	return result, nil
+}
+
+func (h *historyService) GetAttendeeHistory(ctx context.Context, badgeNumber int64, page *Paging) (*modelsv1.HistoryList, error) {
+	if err := h.adminOnly(ctx, fmt.Sprintf("attendee %d", badgeNumber)); err != nil {
+		return nil, err
+	}
+
+	return h.history(ctx, []string{entity.HistoryEntityGroupMember, entity.HistoryEntityRoomMember}, fmt.Sprintf("%d", badgeNumber), page)
+}
+
+// --- helpers ---
+
+func (h *historyService) adminOnly(ctx context.Context, description string) error {
+	validator, err := rbac.NewValidator(ctx)
+	if err != nil {
+		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
+		return common.NewInternalServerError(ctx, common.InternalErrorMessage, common.Details("unexpected error when parsing user claims"))
+	}
+
+	if !validator.IsAdmin() {
+		aulogging.Warnf(ctx, "unauthorized attempt to read history of %s by %s", description, common.GetSubject(ctx))
+		return common.NewForbidden(ctx, common.AuthForbidden, common.Details("you are not authorized for this operation - the attempt has been logged"))
+	}
+
+	return nil
+}
+
+func (h *historyService) history(ctx context.Context, entityNames []string, entityID string, page *Paging) (*modelsv1.HistoryList, error) {
+	entries, total, err := h.DB.GetHistory(ctx, entityNames, entityID, page.Offset, page.Limit)
+	if err != nil {
+		return nil, errHistoryRead(ctx, err.Error())
+	}
+
+	result := &modelsv1.HistoryList{
+		Entries: make([]*modelsv1.HistoryEntry, 0, len(entries)),
+		Total:   total,
+	}
+	for _, e := range entries {
+		result.Entries = append(result.Entries, &modelsv1.HistoryEntry{
+			Timestamp: e.CreatedAt.Format(isoDateTimeFormat),
+			Entity:    e.Entity,
+			EntityID:  e.EntityId,
+			Operation: e.Operation,
+			Requestid: e.RequestId,
+			Identity:  e.Identity,
+			Diff:      parseDiff(e.Diff),
+		})
+	}
+	return result, nil
+}
+
+// diffLineRegex matches a line of a messagediff pretty diff, such as
+//
+//	modified: .Name = "kittens"
+var diffLineRegex = regexp.MustCompile(`^(?:added|removed|modified): \.?(\S+) = (.*)$`)
+
+// parseDiff converts a diff as stored in the history into structured form.
+//
+// The history stores the diff in reverse, so the values are those before the change.
+func parseDiff(diff string) []modelsv1.HistoryDiff {
+	result := make([]modelsv1.HistoryDiff, 0)
+	for _, line := range strings.Split(diff, "\n") {
+		if line == "" {
+			continue
+		}
+		if m := diffLineRegex.FindStringSubmatch(line); m != nil {
+			result = append(result, modelsv1.HistoryDiff{
+				Field: m[1],
+				Value: m[2],
+			})
+		} else {
+			// should not happen, but never lose information
+			result = append(result, modelsv1.HistoryDiff{
+				Value: line,
+			})
+		}
+	}
+	return result
+}
+
+func errHistoryRead(ctx context.Context, details string) error {
+	return common.NewInternalServerError(ctx, common.HistoryReadError, common.Details(details))
+}
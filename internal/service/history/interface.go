@@ -0,0 +1,45 @@
+package historyservice
+
+import (
+	"context"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+)
+
+// Service defines the interface for the service function implementations for the history endpoints.
+//
+// All operations are only available to admins.
+type Service interface {
+	// GetGroupHistory returns the change history of a group, in chronological order.
+	//
+	// Covers the group itself, its room wishes, its bans, and all changes to its members and invitations.
+	//
+	// Works for groups that have since been deleted, as long as their history is still present.
+	GetGroupHistory(ctx context.Context, groupID string, page *Paging) (*modelsv1.HistoryList, error)
+	// GetRoomHistory returns the change history of a room, in chronological order.
+	//
+	// Covers the room itself and all changes to its occupants.
+	//
+	// Works for rooms that have since been deleted, as long as their history is still present.
+	GetRoomHistory(ctx context.Context, roomID string, page *Paging) (*modelsv1.HistoryList, error)
+	// GetAttendeeHistory returns the history of the group and room memberships of an attendee, in chronological order.
+	GetAttendeeHistory(ctx context.Context, badgeNumber int64, page *Paging) (*modelsv1.HistoryList, error)
+}
+
+// Paging selects the part of the history to return.
+type Paging struct {
+	// Offset is the number of entries to skip.
+	Offset uint
+	// Limit is the maximum number of entries to return.
+	Limit uint
+}
+
+func New(db database.Repository) Service {
+	return &historyService{
+		DB: db,
+	}
+}
+
+type historyService struct {
+	DB database.Repository
+}
@@ -0,0 +1,120 @@
+package reconcileservice
+
+import (
+	"context"
+	"errors"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/attendeeservice"
+	"github.com/eurofurence/reg-room-service/internal/service/rbac"
+	"gorm.io/gorm"
+)
+
+func (s *reconcileService) ProcessAttendeeStatusEvent(ctx context.Context, event *modelsv1.AttendeeStatusEvent) error {
+	validator, err := rbac.NewValidator(ctx)
+	if err != nil {
+		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
+		return common.NewInternalServerError(ctx, common.InternalErrorMessage, common.Details("unexpected error when parsing user claims"))
+	}
+
+	if !validator.IsAPITokenCall() {
+		aulogging.Warnf(ctx, "unauthorized attempt to send attendee status event by %s", common.GetSubject(ctx))
+		return common.NewForbidden(ctx, common.AuthForbidden, common.Details("you are not authorized for this operation - the attempt has been logged"))
+	}
+
+	if err := validateAttendeeStatusEvent(ctx, event); err != nil {
+		return err
+	}
+
+	// record the event first, so concurrent deliveries of the same event are only processed once
+	err = s.DB.AddProcessedEvent(ctx, &entity.ProcessedEvent{
+		ID:         event.EventID,
+		Kind:       entity.EventKindAttendeeStatus,
+		AttendeeID: event.ID,
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			aulogging.Infof(ctx, "ignoring duplicate attendee status event %s for attendee %d", event.EventID, event.ID)
+			return nil
+		}
+		return common.NewInternalServerError(ctx, common.InternalErrorMessage, common.Details("database error while recording event"))
+	}
+
+	aulogging.Infof(ctx, "processing attendee status event %s for attendee %d: %s -> %s", event.EventID, event.ID, event.OldStatus, event.NewStatus)
+
+	if err := s.processAttendeeStatusEvent(ctx, event); err != nil {
+		// processing is idempotent, so the event can be sent again
+		if err := s.DB.DeleteProcessedEvent(ctx, event.EventID); err != nil {
+			aulogging.WarnErrf(ctx, err, "failed to delete record of failed attendee status event %s: %s", event.EventID, err.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *reconcileService) processAttendeeStatusEvent(ctx context.Context, event *modelsv1.AttendeeStatusEvent) error {
+	if event.Nickname != "" {
+		if _, err := s.refreshMember(ctx, event.ID, event.Nickname); err != nil {
+			return err
+		}
+	}
+
+	if !attending(attendeeservice.Status(event.NewStatus)) && removalEnabled() {
+		if err := s.removeIfNotAttending(ctx, event.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateAttendeeStatusEvent(ctx context.Context, event *modelsv1.AttendeeStatusEvent) error {
+	if event.EventID == "" || len(event.EventID) > 64 {
+		return common.NewBadRequest(ctx, common.AttendeeEventInvalid, common.Details("event_id must be between 1 and 64 characters long"))
+	}
+	if event.ID <= 0 {
+		return common.NewBadRequest(ctx, common.AttendeeEventInvalid, common.Details("id must be a valid badge number"))
+	}
+	if !knownStatus(attendeeservice.Status(event.NewStatus)) {
+		return common.NewBadRequest(ctx, common.AttendeeEventInvalid, common.Details("new_status must be a valid registration status"))
+	}
+	if len(event.Nickname) > 80 {
+		return common.NewBadRequest(ctx, common.AttendeeEventInvalid, common.Details("nickname too long, max 80 characters"))
+	}
+	return nil
+}
+
+// removeIfNotAttending asks the attendee service for the current status before removing the attendee,
+// because events may be delivered out of order.
+//
+// Events are sent with an api token, so there is no user token to forward.
+func (s *reconcileService) removeIfNotAttending(ctx context.Context, badgeNo int64) error {
+	status, err := s.AttSrv.GetStatusWithApiToken(ctx, badgeNo)
+	if err != nil {
+		aulogging.WarnErrf(ctx, err, "failed to obtain status for badge number %d: %s", badgeNo, err.Error())
+		return common.NewBadGateway(ctx, common.DownstreamAttSrv, common.Details("downstream error when contacting attendee service"))
+	}
+	if attending(status) {
+		aulogging.Infof(ctx, "attendee %d is attending again - not removing", badgeNo)
+		return nil
+	}
+
+	if _, err := s.GroupSvc.RemoveNonAttendingMember(ctx, badgeNo); err != nil {
+		return err
+	}
+	if _, err := s.RoomSvc.RemoveNonAttendingOccupant(ctx, badgeNo); err != nil {
+		return err
+	}
+	return nil
+}
+
+func knownStatus(status attendeeservice.Status) bool {
+	switch status {
+	case attendeeservice.StatusNew, attendeeservice.StatusApproved, attendeeservice.StatusPartiallyPaid, attendeeservice.StatusPaid,
+		attendeeservice.StatusCheckedIn, attendeeservice.StatusCancelled, attendeeservice.StatusWaiting, attendeeservice.StatusDeleted:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,76 @@
+package reconcileservice
+
+import (
+	"context"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/attendeeservice"
+	groupservice "github.com/eurofurence/reg-room-service/internal/service/groups"
+	roomservice "github.com/eurofurence/reg-room-service/internal/service/rooms"
+	"sync"
+	"time"
+)
+
+// Service defines the interface for the service function implementations for the reconciliation endpoints.
+//
+// Membership in groups and rooms is only checked for attending status when it is created. Reconciliation
+// finds attendees whose registration has since been cancelled or deleted.
+type Service interface {
+	// Reconcile checks the registration status of everyone who is in a group or room, including invitations,
+	// and reports those who are no longer attending.
+	//
+	// If apply is true, and removal is enabled in configuration, they are also removed from their groups and rooms.
+	//
+	// Only available to admins and api token.
+	Reconcile(ctx context.Context, apply bool) (*modelsv1.ReconciliationReport, error)
+	// RunReconciliation is Reconcile without permission checks, intended for the command line.
+	RunReconciliation(ctx context.Context, apply bool) (*modelsv1.ReconciliationReport, error)
+
+	// ProcessAttendeeStatusEvent handles a notification from the attendee service that the status or nickname
+	// of a registration has changed.
+	//
+	// A changed nickname is updated in the attendee's group and room membership, together with the avatar url.
+	// If the attendee is no longer attending, and removal is enabled in configuration, they are removed from their
+	// group and room just like with Reconcile.
+	//
+	// Events are deduplicated by their event id. Processing is idempotent, so an event that failed can be sent again.
+	//
+	// Only available to api token.
+	ProcessAttendeeStatusEvent(ctx context.Context, event *modelsv1.AttendeeStatusEvent) error
+
+	// RefreshMembers reads the current nickname of everyone who is in a group or room, including invitations,
+	// from the attendee service, and updates the cached nickname and avatar url where they are out of date.
+	//
+	// Only available to admins and api token.
+	RefreshMembers(ctx context.Context) (*modelsv1.MemberRefreshResult, error)
+	// RunMemberRefresh is RefreshMembers without permission checks, intended for the periodic job.
+	//
+	// Only attendees whose data was last read longer ago than the configured maximum age are read again.
+	//
+	// Returns the number of attendees whose cached data was updated.
+	RunMemberRefresh(ctx context.Context) (int, error)
+}
+
+func New(db database.Repository, attsrv attendeeservice.AttendeeService, groupsvc groupservice.Service, roomsvc roomservice.Service) Service {
+	return &reconcileService{
+		DB:          db,
+		AttSrv:      attsrv,
+		GroupSvc:    groupsvc,
+		RoomSvc:     roomsvc,
+		Now:         time.Now,
+		refreshedAt: make(map[int64]time.Time),
+	}
+}
+
+type reconcileService struct {
+	DB       database.Repository
+	AttSrv   attendeeservice.AttendeeService
+	GroupSvc groupservice.Service
+	RoomSvc  roomservice.Service
+	Now      func() time.Time
+
+	// refreshedAt remembers when the data of each attendee was last read from the attendee service,
+	// so the periodic refresh can skip those that are not due. Not persisted, so it starts empty after a restart.
+	refreshedAt map[int64]time.Time
+	refreshedMu sync.Mutex
+}
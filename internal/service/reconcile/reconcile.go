@@ -0,0 +1,183 @@
+package reconcileservice
+
+import (
+	"context"
+	"errors"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/repository/config"
+	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/attendeeservice"
+	"github.com/eurofurence/reg-room-service/internal/service/rbac"
+	"gorm.io/gorm"
+	"slices"
+)
+
+func (s *reconcileService) Reconcile(ctx context.Context, apply bool) (*modelsv1.ReconciliationReport, error) {
+	validator, err := rbac.NewValidator(ctx)
+	if err != nil {
+		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
+		return nil, common.NewInternalServerError(ctx, common.InternalErrorMessage, common.Details("unexpected error when parsing user claims"))
+	}
+
+	if !validator.IsAdmin() && !validator.IsAPITokenCall() {
+		aulogging.Warnf(ctx, "unauthorized attempt to use reconciliation by %s", common.GetSubject(ctx))
+		return nil, common.NewForbidden(ctx, common.AuthForbidden, common.Details("you are not authorized for this operation - the attempt has been logged"))
+	}
+
+	return s.RunReconciliation(ctx, apply)
+}
+
+func (s *reconcileService) RunReconciliation(ctx context.Context, apply bool) (*modelsv1.ReconciliationReport, error) {
+	entries, err := s.loadMemberships(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	badgeNumbers := make([]int64, 0, len(entries))
+	for id := range entries {
+		badgeNumbers = append(badgeNumbers, id)
+	}
+	slices.Sort(badgeNumbers)
+
+	report := &modelsv1.ReconciliationReport{
+		Checked:      len(badgeNumbers),
+		NotAttending: make([]modelsv1.ReconciliationEntry, 0),
+	}
+	for _, id := range badgeNumbers {
+		// also runs from the command line, where there is no user token to forward
+		status, err := s.AttSrv.GetStatusWithApiToken(ctx, id)
+		if err != nil {
+			aulogging.WarnErrf(ctx, err, "failed to obtain status for badge number %d: %s", id, err.Error())
+			return nil, common.NewBadGateway(ctx, common.DownstreamAttSrv, common.Details("downstream error when contacting attendee service"))
+		}
+		if attending(status) {
+			continue
+		}
+
+		entry := entries[id]
+		entry.Status = string(status)
+		report.NotAttending = append(report.NotAttending, *entry)
+	}
+
+	if !apply || len(report.NotAttending) == 0 {
+		return report, nil
+	}
+	if !removalEnabled() {
+		aulogging.Infof(ctx, "reconciliation found %d attendees no longer attending, but removal is disabled in configuration", len(report.NotAttending))
+		return report, nil
+	}
+
+	report.Applied = true
+	removedCount := 0
+	for i := range report.NotAttending {
+		entry := &report.NotAttending[i]
+		// changed concurrently if not removed, the next run will try again
+		entry.Removed = true
+		if entry.GroupID != "" {
+			removed, err := s.GroupSvc.RemoveNonAttendingMember(ctx, entry.ID)
+			if err != nil {
+				return nil, err
+			}
+			entry.Removed = entry.Removed && removed
+		}
+		if entry.RoomID != "" {
+			removed, err := s.RoomSvc.RemoveNonAttendingOccupant(ctx, entry.ID)
+			if err != nil {
+				return nil, err
+			}
+			entry.Removed = entry.Removed && removed
+		}
+		if entry.Removed {
+			removedCount++
+		}
+	}
+
+	aulogging.Infof(ctx, "reconciliation removed %d of %d attendees no longer attending from their groups and rooms", removedCount, len(report.NotAttending))
+	return report, nil
+}
+
+// loadMemberships collects everyone who is in a group or room, including invitations, keyed by badge number.
+func (s *reconcileService) loadMemberships(ctx context.Context) (map[int64]*modelsv1.ReconciliationEntry, error) {
+	entries := make(map[int64]*modelsv1.ReconciliationEntry)
+	entryFor := func(id int64, nickname string) *modelsv1.ReconciliationEntry {
+		entry, ok := entries[id]
+		if !ok {
+			entry = &modelsv1.ReconciliationEntry{
+				ID:       id,
+				Nickname: nickname,
+			}
+			entries[id] = entry
+		}
+		return entry
+	}
+
+	groups, err := s.DB.GetGroups(ctx)
+	if err != nil {
+		return nil, errGroupRead(ctx, err.Error())
+	}
+	for _, grp := range groups {
+		members, err := s.DB.GetGroupMembersByGroupID(ctx, grp.ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, errGroupRead(ctx, err.Error())
+		}
+
+		for _, member := range members {
+			entry := entryFor(member.ID, member.Nickname)
+			entry.GroupID = grp.ID
+			entry.GroupName = grp.Name
+			entry.Invited = member.IsInvite
+			entry.GroupOwner = grp.Owner == member.ID
+		}
+	}
+
+	rooms, err := s.DB.GetRooms(ctx)
+	if err != nil {
+		return nil, errRoomRead(ctx, err.Error())
+	}
+	for _, room := range rooms {
+		occupants, err := s.DB.GetRoomMembersByRoomID(ctx, room.ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, errRoomRead(ctx, err.Error())
+		}
+
+		for _, occupant := range occupants {
+			entry := entryFor(occupant.ID, occupant.Nickname)
+			entry.RoomID = room.ID
+			entry.RoomName = room.Name
+		}
+	}
+
+	return entries, nil
+}
+
+func attending(status attendeeservice.Status) bool {
+	switch status {
+	case attendeeservice.StatusApproved, attendeeservice.StatusPartiallyPaid, attendeeservice.StatusPaid, attendeeservice.StatusCheckedIn:
+		return true
+	default:
+		return false
+	}
+}
+
+func removalEnabled() bool {
+	conf, err := config.GetApplicationConfig()
+	if err != nil {
+		panic("configuration not loaded before call to removalEnabled() - this is a bug")
+	}
+	return conf.Service.ReconcileRemoveNonAttending
+}
+
+func errGroupRead(ctx context.Context, details string) error {
+	return common.NewInternalServerError(ctx, common.GroupReadError, common.Details(details))
+}
+
+func errRoomRead(ctx context.Context, details string) error {
+	return common.NewInternalServerError(ctx, common.RoomReadError, common.Details(details))
+}
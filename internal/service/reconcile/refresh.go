@@ -0,0 +1,184 @@
+package reconcileservice
+
+import (
+	"context"
+	"errors"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/config"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+	"github.com/eurofurence/reg-room-service/internal/repository/downstreams"
+	"github.com/eurofurence/reg-room-service/internal/service/rbac"
+	"gorm.io/gorm"
+	"slices"
+	"time"
+)
+
+func (s *reconcileService) RefreshMembers(ctx context.Context) (*modelsv1.MemberRefreshResult, error) {
+	validator, err := rbac.NewValidator(ctx)
+	if err != nil {
+		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
+		return nil, common.NewInternalServerError(ctx, common.InternalErrorMessage, common.Details("unexpected error when parsing user claims"))
+	}
+
+	if !validator.IsAdmin() && !validator.IsAPITokenCall() {
+		aulogging.Warnf(ctx, "unauthorized attempt to refresh members by %s", common.GetSubject(ctx))
+		return nil, common.NewForbidden(ctx, common.AuthForbidden, common.Details("you are not authorized for this operation - the attempt has been logged"))
+	}
+
+	return s.refreshMembers(ctx, false)
+}
+
+func (s *reconcileService) RunMemberRefresh(ctx context.Context) (int, error) {
+	result, err := s.refreshMembers(ctx, true)
+	if err != nil {
+		return 0, err
+	}
+	return result.Updated, nil
+}
+
+// refreshMembers reads the data of everyone in a group or room from the attendee service, or if onlyDue is set,
+// only of those whose data was last read longer ago than the configured maximum age.
+//
+// Uses the api token, because the periodic job has no user token to forward.
+func (s *reconcileService) refreshMembers(ctx context.Context, onlyDue bool) (*modelsv1.MemberRefreshResult, error) {
+	entries, err := s.loadMemberships(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	badgeNumbers := make([]int64, 0, len(entries))
+	for id := range entries {
+		badgeNumbers = append(badgeNumbers, id)
+	}
+	slices.Sort(badgeNumbers)
+
+	result := &modelsv1.MemberRefreshResult{}
+	for _, id := range badgeNumbers {
+		if onlyDue && !s.refreshDue(id) {
+			continue
+		}
+
+		result.Checked++
+		attendee, err := s.AttSrv.GetAttendeeWithApiToken(ctx, id)
+		if err != nil {
+			if errors.Is(err, downstreams.ErrDownStreamNotFound) {
+				// deleted registrations are left to reconciliation
+				s.markRefreshed(id)
+				continue
+			}
+			aulogging.WarnErrf(ctx, err, "failed to query for attendee with badge number %d: %s", id, err.Error())
+			return nil, common.NewBadGateway(ctx, common.DownstreamAttSrv, common.Details("downstream error when contacting attendee service"))
+		}
+
+		updated, err := s.refreshMember(ctx, id, attendee.Nickname)
+		if err != nil {
+			return nil, err
+		}
+		if updated {
+			result.Updated++
+		}
+	}
+
+	return result, nil
+}
+
+// refreshDue is true if the data of the attendee has not been read from the attendee service
+// within the configured maximum age.
+func (s *reconcileService) refreshDue(badgeNo int64) bool {
+	maxAge := memberRefreshMaxAge()
+	if maxAge <= 0 {
+		return true
+	}
+
+	s.refreshedMu.Lock()
+	defer s.refreshedMu.Unlock()
+
+	refreshedAt, ok := s.refreshedAt[badgeNo]
+	return !ok || !s.Now().Before(refreshedAt.Add(maxAge))
+}
+
+func (s *reconcileService) markRefreshed(badgeNo int64) {
+	s.refreshedMu.Lock()
+	defer s.refreshedMu.Unlock()
+
+	s.refreshedAt[badgeNo] = s.Now()
+}
+
+func memberRefreshMaxAge() time.Duration {
+	conf, err := config.GetApplicationConfig()
+	if err != nil {
+		panic("configuration not loaded before call to memberRefreshMaxAge() - this is a bug")
+	}
+	return time.Duration(conf.Service.MemberRefreshMaxAgeHours) * time.Hour
+}
+
+// refreshMember updates the cached nickname and avatar url of the attendee in their group and room membership, if any.
+//
+// Returns true if anything was changed.
+func (s *reconcileService) refreshMember(ctx context.Context, badgeNo int64, nickname string) (bool, error) {
+	conf, err := config.GetApplicationConfig()
+	if err != nil {
+		return false, common.NewInternalServerError(ctx, common.InternalErrorMessage, common.Details("configuration not loaded"))
+	}
+	avatarURL := conf.Service.AvatarURL(badgeNo)
+
+	stale := func(m entity.Member) bool {
+		return m.Nickname != nickname || m.AvatarURL != avatarURL
+	}
+
+	updated := false
+	err = s.DB.Transaction(ctx, func(tx database.Repository) error {
+		gm, err := tx.GetGroupMembershipByAttendeeID(ctx, badgeNo)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errGroupRead(ctx, err.Error())
+		}
+		if err == nil && stale(gm.Member) {
+			// lock, then read again, so we do not overwrite concurrent changes
+			if _, err := tx.LockGroupByID(ctx, gm.GroupID); err != nil {
+				return errGroupRead(ctx, err.Error())
+			}
+			if gm, err = tx.GetGroupMembershipByAttendeeID(ctx, badgeNo); err != nil {
+				return errGroupRead(ctx, err.Error())
+			}
+			gm.Nickname = nickname
+			gm.AvatarURL = avatarURL
+			if err := tx.UpdateGroupMembership(ctx, gm); err != nil {
+				return common.NewInternalServerError(ctx, common.GroupWriteError, common.Details(err.Error()))
+			}
+			updated = true
+		}
+
+		rm, err := tx.GetRoomMembershipByAttendeeID(ctx, badgeNo)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errRoomRead(ctx, err.Error())
+		}
+		if err == nil && stale(rm.Member) {
+			if _, err := tx.LockRoomByID(ctx, rm.RoomID); err != nil {
+				return errRoomRead(ctx, err.Error())
+			}
+			if rm, err = tx.GetRoomMembershipByAttendeeID(ctx, badgeNo); err != nil {
+				return errRoomRead(ctx, err.Error())
+			}
+			rm.Nickname = nickname
+			rm.AvatarURL = avatarURL
+			if err := tx.UpdateRoomMembership(ctx, rm); err != nil {
+				return common.NewInternalServerError(ctx, common.RoomWriteError, common.Details(err.Error()))
+			}
+			updated = true
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	s.markRefreshed(badgeNo)
+	if updated {
+		aulogging.Infof(ctx, "refreshed cached nickname and avatar of attendee %d", badgeNo)
+	}
+	return updated, nil
+}
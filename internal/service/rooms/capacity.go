@@ -0,0 +1,82 @@
+package roomservice
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/config"
+	"github.com/eurofurence/reg-room-service/internal/service/rbac"
+	"gorm.io/gorm"
+	"slices"
+)
+
+func (r *roomService) CapacityReport(ctx context.Context) (*modelsv1.CapacityReport, error) {
+	validator, err := rbac.NewValidator(ctx)
+	if err != nil {
+		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
+		return nil, errCouldNotGetValidator(ctx)
+	}
+
+	if !validator.IsAdmin() && !validator.IsAPITokenCall() {
+		return nil, errNotAdminOrApiToken(ctx, "(not loaded)", "(not loaded)")
+	}
+
+	roomTypes, err := r.DB.GetRoomTypes(ctx)
+	if err != nil {
+		return nil, errRoomRead(ctx, err.Error())
+	}
+
+	typed := make([]*modelsv1.TypeCapacity, 0, len(roomTypes))
+	byType := make(map[string]*modelsv1.TypeCapacity, len(roomTypes))
+	for _, roomType := range roomTypes {
+		entry := &modelsv1.TypeCapacity{
+			Type:          roomType.ID,
+			TypeName:      roomType.Name,
+			PriceCategory: roomType.PriceCategory,
+		}
+		typed = append(typed, entry)
+		byType[roomType.ID] = entry
+	}
+	slices.SortFunc(typed, func(a, b *modelsv1.TypeCapacity) int {
+		return cmp.Compare(a.TypeName, b.TypeName)
+	})
+
+	rooms, err := r.DB.GetRooms(ctx)
+	if err != nil {
+		return nil, errRoomRead(ctx, err.Error())
+	}
+
+	untyped := &modelsv1.TypeCapacity{}
+	for _, room := range rooms {
+		occupants, err := r.DB.GetRoomMembersByRoomID(ctx, room.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errRoomRead(ctx, err.Error())
+		}
+
+		// rooms whose room type was deleted concurrently are counted as untyped
+		entry, ok := byType[room.TypeID]
+		if !ok {
+			entry = untyped
+		}
+		entry.Rooms++
+		entry.Beds += room.Size
+		entry.Occupied += int64(entity.PeakOccupancy(occupants, config.StayNights))
+	}
+
+	report := &modelsv1.CapacityReport{
+		Types: make([]modelsv1.TypeCapacity, 0, len(typed)+1),
+	}
+	for _, entry := range typed {
+		entry.Free = max(entry.Beds-entry.Occupied, 0)
+		report.Types = append(report.Types, *entry)
+	}
+	if untyped.Rooms > 0 {
+		untyped.Free = max(untyped.Beds-untyped.Occupied, 0)
+		report.Types = append(report.Types, *untyped)
+	}
+
+	return report, nil
+}
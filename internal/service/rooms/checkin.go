@@ -0,0 +1,189 @@
+package roomservice
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/config"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+	"github.com/eurofurence/reg-room-service/internal/service/rbac"
+	"gorm.io/gorm"
+	"slices"
+	"time"
+)
+
+const (
+	isoDateFormat     = "2006-01-02"
+	isoDateTimeFormat = "2006-01-02T15:04:05-07:00"
+)
+
+func (r *roomService) CheckInOccupant(ctx context.Context, roomID string, badgeNumber int64) error {
+	return r.updateCheckin(ctx, roomID, badgeNumber, func(rm *entity.RoomMember) error {
+		if rm.CheckedInAt != nil && rm.CheckedOutAt == nil {
+			return common.NewConflict(ctx, common.RoomCheckinConflict, common.Details("this attendee has already checked in"))
+		}
+
+		now := r.Now()
+		rm.CheckedInAt = &now
+		rm.CheckedOutAt = nil
+		aulogging.Infof(ctx, "room occupant checked in - room %s badge %d by %s", roomID, badgeNumber, common.GetSubject(ctx))
+		return nil
+	})
+}
+
+func (r *roomService) CheckOutOccupant(ctx context.Context, roomID string, badgeNumber int64) error {
+	return r.updateCheckin(ctx, roomID, badgeNumber, func(rm *entity.RoomMember) error {
+		if rm.CheckedInAt == nil || rm.CheckedOutAt != nil {
+			return common.NewConflict(ctx, common.RoomCheckinConflict, common.Details("this attendee has not checked in"))
+		}
+
+		now := r.Now()
+		rm.CheckedOutAt = &now
+		aulogging.Infof(ctx, "room occupant checked out - room %s badge %d by %s", roomID, badgeNumber, common.GetSubject(ctx))
+		return nil
+	})
+}
+
+// updateCheckin applies change to the room membership of an occupant and saves it, so the change is historized.
+func (r *roomService) updateCheckin(ctx context.Context, roomID string, badgeNumber int64, change func(rm *entity.RoomMember) error) error {
+	validator, err := rbac.NewValidator(ctx)
+	if err != nil {
+		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
+		return errCouldNotGetValidator(ctx)
+	}
+
+	if !validator.IsAdmin() && !validator.IsAPITokenCall() {
+		return errNotAdminOrApiToken(ctx, roomID, "(not loaded)")
+	}
+
+	return r.DB.Transaction(ctx, func(tx database.Repository) error {
+		tr := r.withDB(tx)
+
+		if _, err := tx.LockRoomByID(ctx, roomID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errRoomRead(ctx, err.Error())
+		}
+
+		room, existingMembership, err := tr.roomMembershipExisting(ctx, roomID, badgeNumber) // existingMembership may be nil if not exists
+		if err != nil {
+			return err
+		}
+
+		if existingMembership == nil {
+			return common.NewNotFound(ctx, common.RoomOccupantNotFound, common.Details("this attendee is not in any room"))
+		}
+
+		if room.ID != existingMembership.RoomID {
+			return common.NewConflict(ctx, common.RoomOccupantConflict, common.Details("this attendee is in a different room"))
+		}
+
+		if err := change(existingMembership); err != nil {
+			return err
+		}
+
+		if err := tx.UpdateRoomMembership(ctx, existingMembership); err != nil {
+			return errRoomWrite(ctx, err.Error())
+		}
+		return nil
+	})
+}
+
+func (r *roomService) ArrivalReport(ctx context.Context) (*modelsv1.ArrivalReport, error) {
+	validator, err := rbac.NewValidator(ctx)
+	if err != nil {
+		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
+		return nil, errCouldNotGetValidator(ctx)
+	}
+
+	if !validator.IsAdmin() && !validator.IsAPITokenCall() {
+		return nil, errNotAdminOrApiToken(ctx, "(not loaded)", "(not loaded)")
+	}
+
+	rooms, err := r.DB.GetRooms(ctx)
+	if err != nil {
+		return nil, errRoomRead(ctx, err.Error())
+	}
+
+	days := make(map[string]*modelsv1.ArrivalDay)
+	dayFor := func(date string) *modelsv1.ArrivalDay {
+		day, ok := days[date]
+		if !ok {
+			day = &modelsv1.ArrivalDay{
+				Date:       date,
+				NotArrived: make([]int64, 0),
+			}
+			days[date] = day
+		}
+		return day
+	}
+
+	for _, room := range rooms {
+		occupants, err := r.DB.GetRoomMembersByRoomID(ctx, room.ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, errRoomRead(ctx, err.Error())
+		}
+
+		for _, occupant := range occupants {
+			expectedDate := occupant.ArrivalDate
+			if expectedDate == "" {
+				expectedDate = conventionStart()
+			}
+			if expectedDate != "" {
+				day := dayFor(expectedDate)
+				day.Expected++
+				if occupant.CheckedInAt == nil {
+					day.NotArrived = append(day.NotArrived, occupant.ID)
+				}
+			}
+			if occupant.CheckedInAt != nil {
+				dayFor(occupant.CheckedInAt.In(time.Local).Format(isoDateFormat)).Arrived++
+			}
+		}
+	}
+
+	report := &modelsv1.ArrivalReport{
+		Days: make([]modelsv1.ArrivalDay, 0, len(days)),
+	}
+	for _, day := range days {
+		slices.Sort(day.NotArrived)
+		report.Days = append(report.Days, *day)
+	}
+	slices.SortFunc(report.Days, func(a, b modelsv1.ArrivalDay) int {
+		return cmp.Compare(a.Date, b.Date)
+	})
+
+	return report, nil
+}
+
+// checkedInMatches is true if the room has at least one occupant whose current check-in state is wanted.
+func checkedInMatches(room *modelsv1.Room, wanted bool) bool {
+	for _, occupant := range room.Occupants {
+		checkedIn := occupant.CheckedIn != nil && occupant.CheckedOut == nil
+		if checkedIn == wanted {
+			return true
+		}
+	}
+	return false
+}
+
+func formatTimestamp(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(isoDateTimeFormat)
+	return &formatted
+}
+
+func conventionStart() string {
+	conf, err := config.GetApplicationConfig()
+	if err != nil {
+		panic("configuration not loaded before call to conventionStart() - this is a bug")
+	}
+	return conf.Service.ConventionStart
+}
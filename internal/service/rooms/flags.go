@@ -0,0 +1,128 @@
+package roomservice
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/config"
+	"github.com/eurofurence/reg-room-service/internal/service/rbac"
+	"gorm.io/gorm"
+	"slices"
+	"strings"
+)
+
+func (r *roomService) FindFlagMismatches(ctx context.Context) (*modelsv1.FlagMismatchList, error) {
+	validator, err := rbac.NewValidator(ctx)
+	if err != nil {
+		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
+		return nil, errCouldNotGetValidator(ctx)
+	}
+
+	if !validator.IsAdmin() && !validator.IsAPITokenCall() {
+		return nil, errNotAdminOrApiToken(ctx, "(not loaded)", "(not loaded)")
+	}
+
+	rooms, err := r.DB.GetRooms(ctx)
+	if err != nil {
+		return nil, errRoomRead(ctx, err.Error())
+	}
+	slices.SortFunc(rooms, func(a, b *entity.Room) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	result := &modelsv1.FlagMismatchList{
+		Mismatches: make([]modelsv1.FlagMismatch, 0),
+	}
+	for _, room := range rooms {
+		occupants, err := r.DB.GetRoomMembersByRoomID(ctx, room.ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, errRoomRead(ctx, err.Error())
+		}
+		slices.SortFunc(occupants, func(a, b *entity.RoomMember) int {
+			return cmp.Compare(a.ID, b.ID)
+		})
+
+		for _, occupant := range occupants {
+			grp, missing, err := r.missingRoomFlags(ctx, room, occupant.ID)
+			if err != nil {
+				return nil, err
+			}
+			if len(missing) == 0 {
+				continue
+			}
+
+			result.Mismatches = append(result.Mismatches, modelsv1.FlagMismatch{
+				RoomID:       room.ID,
+				RoomName:     room.Name,
+				ID:           occupant.ID,
+				Nickname:     occupant.Nickname,
+				GroupID:      grp.ID,
+				GroupName:    grp.Name,
+				MissingFlags: missing,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// checkFlagRequirements checks that the room has all room flags required by the flags of the attendee's group.
+//
+// Depending on configuration, a mismatch is either logged or refused. Admins can override a refusal.
+func (r *roomService) checkFlagRequirements(ctx context.Context, room *entity.Room, badgeNumber int64, override bool) error {
+	grp, missing, err := r.missingRoomFlags(ctx, room, badgeNumber)
+	if err != nil {
+		return err
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if override {
+		aulogging.Warnf(ctx, "admin override of flag requirements - room %s lacks flags %v required by group %s of badge %d, by %s", room.ID, missing, grp.ID, badgeNumber, common.GetSubject(ctx))
+		return nil
+	}
+	if config.FlagMismatchRefused() {
+		return common.NewConflict(ctx, common.RoomFlagsMismatch, common.Details(fmt.Sprintf("the attendee's group requires room flags this room does not have: %s", strings.Join(missing, ","))))
+	}
+	aulogging.Warnf(ctx, "room %s lacks flags %v required by group %s of badge %d - adding anyway", room.ID, missing, grp.ID, badgeNumber)
+	return nil
+}
+
+// missingRoomFlags returns the attendee's group and the room flags it requires, but the room does not have.
+//
+// Returns a nil group if the attendee is not a member of any group. Invitations do not count.
+func (r *roomService) missingRoomFlags(ctx context.Context, room *entity.Room, badgeNumber int64) (*entity.Group, []string, error) {
+	gm, err := r.DB.GetGroupMembershipByAttendeeID(ctx, badgeNumber)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, nil
+		}
+		return nil, nil, common.NewInternalServerError(ctx, common.GroupReadError, common.Details(err.Error()))
+	}
+	if gm.IsInvite {
+		return nil, nil, nil
+	}
+
+	grp, err := r.DB.GetGroupByID(ctx, gm.GroupID)
+	if err != nil {
+		return nil, nil, common.NewInternalServerError(ctx, common.GroupReadError, common.Details(err.Error()))
+	}
+
+	roomFlags := entity.AggregateFlags(room.Flags)
+	missing := make([]string, 0)
+	for _, flag := range config.RequiredRoomFlags(entity.AggregateFlags(grp.Flags)) {
+		if !slices.Contains(roomFlags, flag) {
+			missing = append(missing, flag)
+		}
+	}
+	return grp, missing, nil
+}
@@ -0,0 +1,220 @@
+package roomservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/config"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+	"github.com/eurofurence/reg-room-service/internal/service/rbac"
+	"gorm.io/gorm"
+	"slices"
+)
+
+func (r *roomService) AddGroupToRoom(ctx context.Context, roomID string, groupID string, force bool) error {
+	validator, err := rbac.NewValidator(ctx)
+	if err != nil {
+		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
+		return errCouldNotGetValidator(ctx)
+	}
+
+	if !validator.IsAdmin() && !validator.IsAPITokenCall() {
+		return errNotAdminOrApiToken(ctx, roomID, "(not loaded)")
+	}
+
+	if _, err := r.DB.GetRoomByID(ctx, roomID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errRoomNotFound(ctx)
+		}
+		return errRoomRead(ctx, err.Error())
+	}
+
+	badges, err := r.joinedGroupMembers(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	// downstream calls must happen before the transaction
+	nicknames := make(map[int64]string)
+	for _, badgeNumber := range badges {
+		occupant, err := r.validateRequestedAttendee(ctx, badgeNumber)
+		if err != nil {
+			return err
+		}
+		if err := r.checkAttending(ctx, badgeNumber, r.AttSrv.GetStatusWithApiToken, common.NewConflict(ctx, common.NotAttending, common.Details(fmt.Sprintf("registration of group member %d is not in attending status", badgeNumber)))); err != nil {
+			return err
+		}
+		nicknames[badgeNumber] = occupant.Nickname
+	}
+
+	// the room and group row locks ensure concurrent changes cannot overfill the room or change the members
+	return r.DB.Transaction(ctx, func(tx database.Repository) error {
+		tr := r.withDB(tx)
+
+		room, err := tx.LockRoomByID(ctx, roomID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errRoomNotFound(ctx)
+			}
+			return errRoomRead(ctx, err.Error())
+		}
+
+		if _, err := tx.LockGroupByID(ctx, groupID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errGroupNotFound(ctx)
+			}
+			return errGroupRead(ctx, err.Error())
+		}
+
+		// re-check under lock, the members were checked against the attendee service before
+		current, err := tr.joinedGroupMembers(ctx, groupID)
+		if err != nil {
+			return err
+		}
+		if !slices.Equal(current, badges) {
+			return common.NewConflict(ctx, common.GroupMemberConflict, common.Details("the members of this group have changed meanwhile, please try again"))
+		}
+
+		toAdd := make([]int64, 0, len(badges))
+		for _, badgeNumber := range badges {
+			existing, err := tx.GetRoomMembershipByAttendeeID(ctx, badgeNumber)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					toAdd = append(toAdd, badgeNumber)
+					continue
+				}
+				return errRoomRead(ctx, err.Error())
+			}
+			if existing.RoomID != roomID {
+				return common.NewConflict(ctx, common.RoomOccupantConflict, common.Details(fmt.Sprintf("group member %d is already in another room", badgeNumber)))
+			}
+		}
+		if len(toAdd) == 0 {
+			return common.NewConflict(ctx, common.RoomOccupantDuplicate, common.Details("all members of this group are already in this room"))
+		}
+
+		occupants, err := tx.GetRoomMembersByRoomID(ctx, roomID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errRoomRead(ctx, err.Error())
+		}
+		// group members are added for the whole convention
+		if entity.PeakOccupancy(occupants, config.StayNights)+len(toAdd) > int(room.Size) {
+			return common.NewConflict(ctx, common.RoomSizeFull, common.Details(fmt.Sprintf("this room does not have %d free beds for the group", len(toAdd))))
+		}
+
+		// all members share the group flags, so checking one of them is enough
+		if err := tr.checkFlagRequirements(ctx, room, toAdd[0], validator.IsAdmin() && force); err != nil {
+			return err
+		}
+
+		conf, err := config.GetApplicationConfig()
+		if err != nil {
+			return common.NewInternalServerError(ctx, common.InternalErrorMessage, common.Details("configuration not loaded"))
+		}
+
+		for _, badgeNumber := range toAdd {
+			newMembership := tx.NewEmptyRoomMembership(ctx, roomID, badgeNumber)
+			newMembership.Nickname = nicknames[badgeNumber]
+			newMembership.AvatarURL = conf.Service.AvatarURL(badgeNumber)
+
+			if err := tx.AddRoomMembership(ctx, newMembership); err != nil {
+				return errRoomWrite(ctx, err.Error())
+			}
+		}
+
+		aulogging.Infof(ctx, "group %s added to room %s with members %v by %s", groupID, roomID, toAdd, common.GetSubject(ctx))
+		return nil
+	})
+}
+
+func (r *roomService) RemoveGroupFromRoom(ctx context.Context, roomID string, groupID string) error {
+	validator, err := rbac.NewValidator(ctx)
+	if err != nil {
+		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
+		return errCouldNotGetValidator(ctx)
+	}
+
+	if !validator.IsAdmin() && !validator.IsAPITokenCall() {
+		return errNotAdminOrApiToken(ctx, roomID, "(not loaded)")
+	}
+
+	return r.DB.Transaction(ctx, func(tx database.Repository) error {
+		tr := r.withDB(tx)
+
+		if _, err := tx.LockRoomByID(ctx, roomID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errRoomNotFound(ctx)
+			}
+			return errRoomRead(ctx, err.Error())
+		}
+
+		badges, err := tr.joinedGroupMembers(ctx, groupID)
+		if err != nil {
+			return err
+		}
+
+		removed := make([]int64, 0, len(badges))
+		for _, badgeNumber := range badges {
+			existing, err := tx.GetRoomMembershipByAttendeeID(ctx, badgeNumber)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					continue
+				}
+				return errRoomRead(ctx, err.Error())
+			}
+			if existing.RoomID != roomID {
+				continue
+			}
+
+			if err := tx.DeleteRoomMembership(ctx, badgeNumber); err != nil {
+				return errRoomWrite(ctx, err.Error())
+			}
+			removed = append(removed, badgeNumber)
+		}
+		if len(removed) == 0 {
+			return common.NewNotFound(ctx, common.RoomOccupantNotFound, common.Details("no member of this group is in this room"))
+		}
+
+		aulogging.Infof(ctx, "group %s removed from room %s with members %v by %s", groupID, roomID, removed, common.GetSubject(ctx))
+		return nil
+	})
+}
+
+// joinedGroupMembers returns the badge numbers of the members of a group, sorted, leaving out pending invitations.
+func (r *roomService) joinedGroupMembers(ctx context.Context, groupID string) ([]int64, error) {
+	grp, err := r.DB.GetGroupByID(ctx, groupID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errGroupNotFound(ctx)
+		}
+		return nil, errGroupRead(ctx, err.Error())
+	}
+	if grp.DeletedAt.Valid {
+		return nil, errGroupNotFound(ctx)
+	}
+
+	members, err := r.DB.GetGroupMembersByGroupID(ctx, groupID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errGroupRead(ctx, err.Error())
+	}
+
+	result := make([]int64, 0, len(members))
+	for _, m := range members {
+		if !m.IsInvite {
+			result = append(result, m.ID)
+		}
+	}
+	slices.Sort(result)
+	return result, nil
+}
+
+func errGroupNotFound(ctx context.Context) error {
+	return common.NewNotFound(ctx, common.GroupIDNotFound, common.Details("group does not exist"))
+}
+
+func errGroupRead(ctx context.Context, details string) error {
+	return common.NewInternalServerError(ctx, common.GroupReadError, common.Details(details))
+}
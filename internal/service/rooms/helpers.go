@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	aulogging "github.com/StephanHCB/go-autumn-logging"
-	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
 	"github.com/eurofurence/reg-room-service/internal/application/common"
 	"github.com/eurofurence/reg-room-service/internal/repository/downstreams"
 	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/attendeeservice"
@@ -22,7 +21,7 @@ func (r *roomService) loggedInUserValidRegistrationBadgeNo(ctx context.Context)
 	}
 	myID := myRegIDs[0]
 
-	if err := r.checkAttending(ctx, myID, common.NewForbidden(ctx, common.NotAttending, common.Details("registration is not in attending status"))); err != nil {
+	if err := r.checkAttending(ctx, myID, r.AttSrv.GetStatus, common.NewForbidden(ctx, common.NotAttending, common.Details("registration is not in attending status"))); err != nil {
 		return attendeeservice.Attendee{}, err
 	}
 
@@ -41,7 +40,7 @@ func (r *roomService) validateRequestedAttendee(ctx context.Context, badgeNo int
 		return attendeeservice.Attendee{}, common.NewBadRequest(ctx, common.RoomDataInvalid, common.Details("attendee badge number must be positive integer"))
 	}
 
-	attendee, err := r.AttSrv.GetAttendee(ctx, badgeNo)
+	attendee, err := r.AttSrv.GetAttendeeWithApiToken(ctx, badgeNo)
 	if err != nil {
 		if errors.Is(err, downstreams.ErrDownStreamNotFound) {
 			return attendeeservice.Attendee{}, common.NewNotFound(ctx, common.NoSuchAttendee, common.Details("no such attendee"))
@@ -54,8 +53,11 @@ func (r *roomService) validateRequestedAttendee(ctx context.Context, badgeNo int
 	return attendee, nil
 }
 
-func (r *roomService) checkAttending(ctx context.Context, badgeNo int64, notAttendingErr error) error {
-	status, err := r.AttSrv.GetStatus(ctx, badgeNo)
+// checkAttending returns notAttendingErr unless the attendee is in attending status.
+//
+// A user can only read their own status, so for other attendees, pass the GetStatusWithApiToken of the attendee service.
+func (r *roomService) checkAttending(ctx context.Context, badgeNo int64, getStatus func(context.Context, int64) (attendeeservice.Status, error), notAttendingErr error) error {
+	status, err := getStatus(ctx, badgeNo)
 	if err != nil {
 		aulogging.WarnErrf(ctx, err, "failed to obtain status for badge number %d: %s", badgeNo, err.Error())
 		return common.NewBadGateway(ctx, common.DownstreamAttSrv, common.Details("downstream error when contacting attendee service"))
@@ -69,9 +71,12 @@ func (r *roomService) checkAttending(ctx context.Context, badgeNo int64, notAtte
 	}
 }
 
-func roomLessByName(left *modelsv1.Room, right *modelsv1.Room) bool {
-	if left == nil || right == nil {
-		return left == nil && right != nil
+// pageOf applies offset and limit to a list of results. A limit of 0 means no limit.
+func pageOf[E any](entries []E, offset uint, limit uint) []E {
+	start := min(int(offset), len(entries))
+	end := len(entries)
+	if limit > 0 {
+		end = min(start+int(limit), len(entries))
 	}
-	return left.Name < right.Name
+	return entries[start:end]
 }
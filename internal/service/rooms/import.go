@@ -0,0 +1,185 @@
+package roomservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+	"github.com/eurofurence/reg-room-service/internal/service/rbac"
+	"gorm.io/gorm"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+func (r *roomService) ImportRooms(ctx context.Context, params *RoomImportParams) (*modelsv1.RoomImportResult, error) {
+	validator, err := rbac.NewValidator(ctx)
+	if err != nil {
+		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
+		return nil, errCouldNotGetValidator(ctx)
+	}
+
+	if !validator.IsAdmin() && !validator.IsAPITokenCall() {
+		return nil, errNotAdminOrApiToken(ctx, "(import)", "(import)")
+	}
+
+	if len(params.Rows) == 0 {
+		return nil, common.NewBadRequest(ctx, common.RoomDataInvalid, common.Details("the import does not contain any rooms"))
+	}
+
+	result := &modelsv1.RoomImportResult{
+		DryRun:  params.DryRun,
+		Rows:    len(params.Rows),
+		Created: make([]string, 0),
+	}
+
+	if params.DryRun {
+		rowErrors, err := validateImport(ctx, r.DB, params.Rows, make(map[string]*entity.RoomType))
+		if err != nil {
+			return nil, err
+		}
+
+		result.Errors = rowErrors
+		return result, nil
+	}
+
+	err = r.DB.Transaction(ctx, func(tx database.Repository) error {
+		// validate again inside the transaction, so no conflicting room can be created concurrently
+		roomTypes := make(map[string]*entity.RoomType)
+		rowErrors, err := validateImport(ctx, tx, params.Rows, roomTypes)
+		if err != nil {
+			return err
+		}
+		if len(rowErrors) > 0 {
+			return common.NewBadRequest(ctx, common.RoomDataInvalid, importErrorDetails(rowErrors))
+		}
+
+		result.Created = make([]string, 0, len(params.Rows))
+		for _, row := range params.Rows {
+			typeID := common.Deref(row.Room.Type)
+			size, flags, comments := applyRoomTypeDefaults(roomTypes[typeID], row.Room.Size, row.Room.Flags, row.Room.Comments)
+			roomID, err := tx.AddRoom(ctx, &entity.Room{
+				BlockID:  common.Deref(row.Room.Block),
+				TypeID:   typeID,
+				Name:     row.Room.Name,
+				Flags:    flags,
+				Comments: comments,
+				Size:     size,
+			})
+			if err != nil {
+				return errRoomWrite(ctx, err.Error())
+			}
+
+			result.Created = append(result.Created, roomID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	aulogging.Infof(ctx, "imported %d rooms by %s", len(result.Created), common.GetSubject(ctx))
+	return result, nil
+}
+
+// validateImport checks all rows of an import and returns the list of invalid rows.
+//
+// The room types referenced by the rows are added to roomTypes, keyed by id, so the defaults can be applied afterwards.
+//
+// The returned error is only set if the validation itself failed, e.g. due to a database error.
+func validateImport(ctx context.Context, db database.Repository, rows []RoomImportRow, roomTypes map[string]*entity.RoomType) ([]modelsv1.RoomImportError, error) {
+	result := make([]modelsv1.RoomImportError, 0)
+	rowByName := make(map[string]int) // keyed by block id and name, room names only need to be unique within their block
+
+	for _, row := range rows {
+		validation := url.Values{}
+		for field, messages := range row.ParseErrors {
+			validation[field] = messages
+		}
+		for field, messages := range validateRoomCreate(&row.Room) {
+			if !validation.Has(field) {
+				validation[field] = messages
+			}
+		}
+
+		typeID := common.Deref(row.Room.Type)
+		if !validation.Has("type") && typeID != "" {
+			if _, ok := roomTypes[typeID]; !ok {
+				roomType, err := db.GetRoomTypeByID(ctx, typeID)
+				if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil, errRoomRead(ctx, err.Error())
+				}
+				if err == nil && !roomType.DeletedAt.Valid {
+					roomTypes[typeID] = roomType
+				}
+			}
+			if _, ok := roomTypes[typeID]; !ok {
+				validation.Set("type", "no such room type")
+			}
+		}
+
+		size, _, _ := applyRoomTypeDefaults(roomTypes[typeID], row.Room.Size, row.Room.Flags, row.Room.Comments)
+		if size < 1 && !validation.Has("size") && !validation.Has("type") {
+			validation.Set("size", "room size must be a positive integer")
+		}
+
+		blockID := common.Deref(row.Room.Block)
+		if !validation.Has("block") && blockID != "" {
+			block, err := db.GetBlockByID(ctx, blockID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errRoomRead(ctx, err.Error())
+			}
+			if err != nil || block.DeletedAt.Valid {
+				validation.Set("block", "no such block")
+			}
+		}
+
+		if !validation.Has("name") && !validation.Has("block") {
+			key := blockID + "/" + row.Room.Name
+			if firstRow, ok := rowByName[key]; ok {
+				validation.Set("name", fmt.Sprintf("room name already used in row %d", firstRow))
+			} else {
+				rowByName[key] = row.Row
+
+				matchingIDs, _, err := db.FindRooms(ctx, database.FindRoomCriteria{Name: row.Room.Name, BlockID: blockID, MaxOccupancy: -1}, nil)
+				if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil, errRoomRead(ctx, err.Error())
+				}
+				if len(matchingIDs) > 0 {
+					validation.Set("name", "another room with this name already exists")
+				}
+			}
+		}
+
+		if len(validation) > 0 {
+			result = append(result, modelsv1.RoomImportError{
+				Row:     row.Row,
+				Name:    row.Room.Name,
+				Details: validation,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// importErrorDetails lists the validation errors of all invalid rows as error details, keyed by row.
+func importErrorDetails(rowErrors []modelsv1.RoomImportError) url.Values {
+	details := url.Values{}
+	for _, rowError := range rowErrors {
+		key := fmt.Sprintf("row %d", rowError.Row)
+		fields := make([]string, 0, len(rowError.Details))
+		for field := range rowError.Details {
+			fields = append(fields, field)
+		}
+		slices.Sort(fields)
+		for _, field := range fields {
+			details.Add(key, fmt.Sprintf("%s: %s", field, strings.Join(rowError.Details[field], ", ")))
+		}
+	}
+	return details
+}
@@ -6,19 +6,101 @@ import (
 	"github.com/eurofurence/reg-room-service/internal/repository/database"
 	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/attendeeservice"
 	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/mailservice"
+	"net/url"
+	"time"
 )
 
 // Service defines the interface for the service function implementations for the room endpoints.
 type Service interface {
 	GetRoomByID(ctx context.Context, roomID string) (*modelsv1.Room, error)
+	// CreateRoom creates a new room and returns its id.
+	//
+	// If the room references a room type, any size, flags or comments left out are taken from the room type.
 	CreateRoom(ctx context.Context, room *modelsv1.RoomCreate) (string, error)
+	// UpdateRoom replaces the data of a room. Room type defaults are applied as in CreateRoom.
 	UpdateRoom(ctx context.Context, room *modelsv1.Room) error
 	DeleteRoom(ctx context.Context, roomID string) error
 
-	AddOccupantToRoom(ctx context.Context, roomID string, badgeNumber int64) error
+	// AddOccupantToRoom adds an attendee to a room.
+	//
+	// The room must have a free bed for every night of the stay. An empty stay means the whole convention.
+	//
+	// If the attendee's group has flags that require room flags the room does not have (see
+	// service.flag_requirements), this is either logged or refused, depending on configuration.
+	// Admins can set force to add the attendee anyway.
+	AddOccupantToRoom(ctx context.Context, roomID string, badgeNumber int64, force bool, stay *modelsv1.Stay) error
 	RemoveOccupantFromRoom(ctx context.Context, roomID string, badgeNumber int64) error
+	// AddGroupToRoom adds all members of a group to a room at once, leaving out pending invitations.
+	//
+	// Either all members are added, or none. Every member must be attending, and the room must have enough free
+	// beds for all of them for the whole convention. Members already in this room are left as they are.
+	// Flag requirements and force work as in AddOccupantToRoom. Only available to admins and api token.
+	AddGroupToRoom(ctx context.Context, roomID string, groupID string, force bool) error
+	// RemoveGroupFromRoom removes all members of a group from a room. Members in other rooms are left alone.
+	//
+	// Only available to admins and api token.
+	RemoveGroupFromRoom(ctx context.Context, roomID string, groupID string) error
+	// MoveOccupant moves an occupant to another room in one step, keeping their stay dates, flags and check-in times.
+	//
+	// The target room must have a free bed for every night of the stay. Flag requirements and force work as in
+	// AddOccupantToRoom. Only available to admins and api token.
+	MoveOccupant(ctx context.Context, roomID string, badgeNumber int64, targetRoomID string, force bool) error
+	// SwapOccupants exchanges the rooms of two occupants in one step, like two simultaneous calls to MoveOccupant.
+	//
+	// The other occupant must be in a different room. Only available to admins and api token.
+	SwapOccupants(ctx context.Context, roomID string, badgeNumber int64, otherBadgeNumber int64, force bool) error
+	// CheckInOccupant records that an occupant has arrived at the hotel.
+	//
+	// Fails if the occupant is currently checked in. Checking in again after checking out starts a new stay.
+	// Only available to admins and api token.
+	CheckInOccupant(ctx context.Context, roomID string, badgeNumber int64) error
+	// CheckOutOccupant records that an occupant has left the hotel.
+	//
+	// Fails if the occupant is not currently checked in. Only available to admins and api token.
+	CheckOutOccupant(ctx context.Context, roomID string, badgeNumber int64) error
+	// UpdateOccupantFlags replaces the membership flags of a room occupant.
+	//
+	// Admins and the api token can change the flags of any occupant. Occupants can change their own flags
+	// once their room is final.
+	UpdateOccupantFlags(ctx context.Context, roomID string, badgeNumber int64, flags []string) error
+	// UpdateOccupantStay changes the arrival and departure dates of a room occupant.
+	//
+	// Stay dates must lie within service.convention_start and service.convention_end, and the room must have a
+	// free bed for every night of the new stay. Only available to admins and api token.
+	UpdateOccupantStay(ctx context.Context, roomID string, badgeNumber int64, stay *modelsv1.Stay) error
+	// RemoveNonAttendingOccupant removes an attendee who is no longer attending from their room and informs them by mail,
+	// and returns false if they were not in any room, or their membership changed concurrently.
+	//
+	// This is intended for reconciliation, so no permission checks are performed, and the caller is responsible
+	// for checking the registration status.
+	RemoveNonAttendingOccupant(ctx context.Context, badgeNo int64) (bool, error)
+
+	// ImportRooms creates many rooms at once.
+	//
+	// All rows are validated like in CreateRoom, and names must be unique both among existing rooms and
+	// within the import. The import is all-or-nothing, if any row is invalid, no rooms are created.
+	//
+	// In dry run mode, nothing is changed, and the invalid rows are listed in the result.
+	ImportRooms(ctx context.Context, params *RoomImportParams) (*modelsv1.RoomImportResult, error)
+
+	// FindFlagMismatches lists all room occupants whose group requires room flags that their room does not have.
+	//
+	// Only available to admins and api token.
+	FindFlagMismatches(ctx context.Context) (*modelsv1.FlagMismatchList, error)
+
+	// ArrivalReport compares expected arrivals to actual check-ins per day.
+	//
+	// Room occupants are expected on their arrival date, or on service.convention_start if they have none. Only available to admins and api token.
+	ArrivalReport(ctx context.Context) (*modelsv1.ArrivalReport, error)
 
-	FindRooms(ctx context.Context, params *FindRoomParams) ([]*modelsv1.Room, error)
+	// CapacityReport counts rooms, beds and occupied beds per room type.
+	//
+	// Rooms without a room type are counted in an extra entry. Only available to admins and api token.
+	CapacityReport(ctx context.Context) (*modelsv1.CapacityReport, error)
+
+	// FindRooms lists the rooms matching params, sorted and paged as requested, together with the total number
+	// of matching rooms. Only available to admins and api token.
+	FindRooms(ctx context.Context, params *FindRoomParams) (*modelsv1.RoomList, error)
 	// FindMyRoom looks up the room the currently logged-in user is in.
 	//
 	// This works for admins just like for normal users, returning their room,
@@ -32,11 +114,43 @@ type Service interface {
 type FindRoomParams struct {
 	MemberIDs []int64 // empty list or nil means no condition
 
+	BlockID string // empty means no condition
+	TypeID  string // empty means no condition
+
 	MinSize uint // 0 means no condition
 	MaxSize uint // 0 means no condition
 
+	// If the convention dates are configured, occupants are counted on the night with the most occupants.
 	MinOccupants uint // 0 means no condition
 	MaxOccupants int  // -1 means no condition, 0 means search for empty rooms only
+
+	// CheckedIn set to true finds rooms where at least one occupant is currently checked in,
+	// set to false finds rooms where at least one occupant is not currently checked in. nil means no condition.
+	CheckedIn *bool
+
+	// Name finds only rooms whose name contains this string, ignoring case. Empty means no condition.
+	Name string
+
+	// SortBy is one of the sort keys name, size, occupancy, created. Empty means name.
+	//
+	// Like for the occupancy conditions, occupancy is the peak nightly occupancy if the convention dates are configured.
+	SortBy     string
+	Descending bool
+
+	Offset uint // number of rooms to skip
+	Limit  uint // 0 means no limit
+}
+
+type RoomImportParams struct {
+	Rows   []RoomImportRow
+	DryRun bool
+}
+
+type RoomImportRow struct {
+	Row  int // row number for error reporting
+	Room modelsv1.RoomCreate
+
+	ParseErrors url.Values // errors found while parsing the row, reported together with the validation errors
 }
 
 func New(db database.Repository, attsrv attendeeservice.AttendeeService, mailsrv mailservice.MailService) Service {
@@ -44,6 +158,7 @@ func New(db database.Repository, attsrv attendeeservice.AttendeeService, mailsrv
 		DB:      db,
 		AttSrv:  attsrv,
 		MailSrv: mailsrv,
+		Now:     time.Now,
 	}
 }
 
@@ -51,4 +166,15 @@ type roomService struct {
 	DB      database.Repository
 	AttSrv  attendeeservice.AttendeeService
 	MailSrv mailservice.MailService
+	Now     func() time.Time
+}
+
+// withDB returns a copy of the service that uses the given repository, typically a transaction.
+func (r *roomService) withDB(db database.Repository) *roomService {
+	return &roomService{
+		DB:      db,
+		AttSrv:  r.AttSrv,
+		MailSrv: r.MailSrv,
+		Now:     r.Now,
+	}
 }
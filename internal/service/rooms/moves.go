@@ -0,0 +1,140 @@
+package roomservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/config"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+	"github.com/eurofurence/reg-room-service/internal/service/rbac"
+	"gorm.io/gorm"
+	"slices"
+)
+
+func (r *roomService) MoveOccupant(ctx context.Context, roomID string, badgeNumber int64, targetRoomID string, force bool) error {
+	validator, err := rbac.NewValidator(ctx)
+	if err != nil {
+		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
+		return errCouldNotGetValidator(ctx)
+	}
+
+	if !validator.IsAdmin() && !validator.IsAPITokenCall() {
+		return errNotAdminOrApiToken(ctx, roomID, "(not loaded)")
+	}
+
+	if roomID == targetRoomID {
+		return common.NewBadRequest(ctx, common.RoomDataInvalid, common.Details("the target room must be different from the current room"))
+	}
+
+	return r.moveOccupants(ctx, badgeNumber,
+		map[int64]string{badgeNumber: roomID},
+		map[int64]string{badgeNumber: targetRoomID},
+		validator.IsAdmin() && force)
+}
+
+func (r *roomService) SwapOccupants(ctx context.Context, roomID string, badgeNumber int64, otherBadgeNumber int64, force bool) error {
+	validator, err := rbac.NewValidator(ctx)
+	if err != nil {
+		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
+		return errCouldNotGetValidator(ctx)
+	}
+
+	if !validator.IsAdmin() && !validator.IsAPITokenCall() {
+		return errNotAdminOrApiToken(ctx, roomID, "(not loaded)")
+	}
+
+	if badgeNumber == otherBadgeNumber {
+		return common.NewBadRequest(ctx, common.RoomDataInvalid, common.Details("cannot swap an attendee with themselves"))
+	}
+
+	// the room of the other attendee is needed to know which rooms to lock, it is re-checked under lock
+	other, err := r.DB.GetRoomMembershipByAttendeeID(ctx, otherBadgeNumber)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.NewNotFound(ctx, common.RoomOccupantNotFound, common.Details(fmt.Sprintf("attendee %d is not in any room", otherBadgeNumber)))
+		}
+		return errRoomRead(ctx, err.Error())
+	}
+	if other.RoomID == roomID {
+		return common.NewConflict(ctx, common.RoomOccupantConflict, common.Details("both attendees are in the same room"))
+	}
+
+	return r.moveOccupants(ctx, badgeNumber,
+		map[int64]string{badgeNumber: roomID, otherBadgeNumber: other.RoomID},
+		map[int64]string{badgeNumber: other.RoomID, otherBadgeNumber: roomID},
+		validator.IsAdmin() && force)
+}
+
+// moveOccupants moves the occupants in sourceRoomIDs to the rooms in targetRoomIDs, both keyed by badge number,
+// in a single transaction.
+//
+// Fails if an occupant is not in their source room, or if afterwards any target room has more occupants than beds
+// on any night. Flag requirements are checked as for AddOccupantToRoom.
+func (r *roomService) moveOccupants(ctx context.Context, badgeNumber int64, sourceRoomIDs map[int64]string, targetRoomIDs map[int64]string, override bool) error {
+	badges := make([]int64, 0, len(sourceRoomIDs))
+	roomIDs := make([]string, 0, len(sourceRoomIDs)+len(targetRoomIDs))
+	for badge, roomID := range sourceRoomIDs {
+		badges = append(badges, badge)
+		roomIDs = append(roomIDs, roomID, targetRoomIDs[badge])
+	}
+	slices.Sort(badges)
+	// sorted, so concurrent moves lock the rooms in the same order
+	slices.Sort(roomIDs)
+	roomIDs = slices.Compact(roomIDs)
+
+	return r.DB.Transaction(ctx, func(tx database.Repository) error {
+		tr := r.withDB(tx)
+
+		for _, roomID := range roomIDs {
+			if _, err := tx.LockRoomByID(ctx, roomID); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errRoomNotFound(ctx)
+				}
+				return errRoomRead(ctx, err.Error())
+			}
+		}
+
+		for _, badge := range badges {
+			existing, err := tx.GetRoomMembershipByAttendeeID(ctx, badge)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return common.NewNotFound(ctx, common.RoomOccupantNotFound, common.Details(fmt.Sprintf("attendee %d is not in any room", badge)))
+				}
+				return errRoomRead(ctx, err.Error())
+			}
+			if existing.RoomID != sourceRoomIDs[badge] {
+				return common.NewConflict(ctx, common.RoomOccupantConflict, common.Details(fmt.Sprintf("attendee %d is in a different room", badge)))
+			}
+		}
+
+		if err := tx.MoveRoomMemberships(ctx, targetRoomIDs); err != nil {
+			return errRoomWrite(ctx, err.Error())
+		}
+
+		// checked after the move, the transaction is rolled back if a room is overfilled
+		for _, badge := range badges {
+			room, err := tx.GetRoomByID(ctx, targetRoomIDs[badge])
+			if err != nil {
+				return errRoomRead(ctx, err.Error())
+			}
+
+			occupants, err := tx.GetRoomMembersByRoomID(ctx, room.ID)
+			if err != nil {
+				return errRoomRead(ctx, err.Error())
+			}
+			if entity.PeakOccupancy(occupants, config.StayNights) > int(room.Size) {
+				return common.NewConflict(ctx, common.RoomSizeFull, common.Details(fmt.Sprintf("room %s does not have a free bed for attendee %d", room.Name, badge)))
+			}
+
+			if err := tr.checkFlagRequirements(ctx, room, badge, override); err != nil {
+				return err
+			}
+		}
+
+		aulogging.Infof(ctx, "room occupants moved from %v to %v by %s", sourceRoomIDs, targetRoomIDs, common.GetSubject(ctx))
+		return nil
+	})
+}
@@ -4,13 +4,17 @@ import (
 	"context"
 	"errors"
 	aulogging "github.com/StephanHCB/go-autumn-logging"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
 	"github.com/eurofurence/reg-room-service/internal/application/common"
 	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/config"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
 	"github.com/eurofurence/reg-room-service/internal/service/rbac"
 	"gorm.io/gorm"
+	"slices"
 )
 
-func (r *roomService) AddOccupantToRoom(ctx context.Context, roomID string, badgeNumber int64) error {
+func (r *roomService) AddOccupantToRoom(ctx context.Context, roomID string, badgeNumber int64, force bool, stay *modelsv1.Stay) error {
 	validator, err := rbac.NewValidator(ctx)
 	if err != nil {
 		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
@@ -18,6 +22,10 @@ func (r *roomService) AddOccupantToRoom(ctx context.Context, roomID string, badg
 	}
 
 	if validator.IsAdmin() || validator.IsAPITokenCall() {
+		if errs := validateStay(stay); len(errs) != 0 {
+			return common.NewBadRequest(ctx, common.RoomDataInvalid, errs)
+		}
+
 		room, existingMembership, err := r.roomMembershipExisting(ctx, roomID, badgeNumber) // existingMembership may be nil if not exists
 		if err != nil {
 			return err
@@ -27,32 +35,60 @@ func (r *roomService) AddOccupantToRoom(ctx context.Context, roomID string, badg
 		if err != nil {
 			return err
 		}
-		if err := r.checkAttending(ctx, badgeNumber, common.NewConflict(ctx, common.NotAttending, common.Details("registration is not in attending status"))); err != nil {
+		if err := r.checkAttending(ctx, badgeNumber, r.AttSrv.GetStatusWithApiToken, common.NewConflict(ctx, common.NotAttending, common.Details("registration is not in attending status"))); err != nil {
 			return err
 		}
 
-		if existingMembership != nil {
-			if room.ID != existingMembership.RoomID {
-				return common.NewConflict(ctx, common.RoomOccupantConflict, common.Details("this attendee is already in another room"))
-			} else {
-				return common.NewConflict(ctx, common.RoomOccupantDuplicate, common.Details("this attendee is already in this room"))
-			}
-		}
-
-		if err := r.checkRoomFull(ctx, roomID, room.Size); err != nil {
+		if err := checkNoExistingMembership(ctx, room, existingMembership); err != nil {
 			return err
 		}
 
-		newMembership := r.DB.NewEmptyRoomMembership(ctx, roomID, badgeNumber)
-		newMembership.Nickname = occupant.Nickname
+		// the room row lock ensures concurrent additions cannot overfill the room
+		return r.DB.Transaction(ctx, func(tx database.Repository) error {
+			tr := r.withDB(tx)
 
-		if err := r.DB.AddRoomMembership(ctx, newMembership); err != nil {
-			return errRoomWrite(ctx, err.Error())
-		}
+			room, err := tx.LockRoomByID(ctx, roomID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errRoomNotFound(ctx)
+				}
+				return errRoomRead(ctx, err.Error())
+			}
 
-		// TODO now check room size again, remove again if exceeded
+			// re-check under lock, the attendee may have been added meanwhile
+			_, existingMembership, err := tr.roomMembershipExisting(ctx, roomID, badgeNumber)
+			if err != nil {
+				return err
+			}
+			if err := checkNoExistingMembership(ctx, room, existingMembership); err != nil {
+				return err
+			}
 
-		return nil
+			if err := tr.checkRoomFull(ctx, room, badgeNumber, stay); err != nil {
+				return err
+			}
+
+			if err := tr.checkFlagRequirements(ctx, room, badgeNumber, validator.IsAdmin() && force); err != nil {
+				return err
+			}
+
+			conf, err := config.GetApplicationConfig()
+			if err != nil {
+				return common.NewInternalServerError(ctx, common.InternalErrorMessage, common.Details("configuration not loaded"))
+			}
+
+			newMembership := tx.NewEmptyRoomMembership(ctx, roomID, badgeNumber)
+			newMembership.Nickname = occupant.Nickname
+			newMembership.AvatarURL = conf.Service.AvatarURL(badgeNumber)
+			newMembership.ArrivalDate = stay.Arrival
+			newMembership.DepartureDate = stay.Departure
+
+			if err := tx.AddRoomMembership(ctx, newMembership); err != nil {
+				return errRoomWrite(ctx, err.Error())
+			}
+
+			return nil
+		})
 	} else {
 		return errNotAdminOrApiToken(ctx, roomID, "(not loaded)")
 	}
@@ -94,10 +130,77 @@ func (r *roomService) RemoveOccupantFromRoom(ctx context.Context, roomID string,
 	}
 }
 
+func (r *roomService) UpdateOccupantFlags(ctx context.Context, roomID string, badgeNumber int64, flags []string) error {
+	validator, err := rbac.NewValidator(ctx)
+	if err != nil {
+		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
+		return errCouldNotGetValidator(ctx)
+	}
+
+	fullAccess := validator.IsAdmin() || validator.IsAPITokenCall()
+	if !fullAccess {
+		attendee, err := r.loggedInUserValidRegistrationBadgeNo(ctx)
+		if err != nil {
+			return err
+		}
+		if attendee.ID != badgeNumber {
+			return errNotAdminOrApiToken(ctx, roomID, "(not loaded)")
+		}
+	}
+
+	if errs := validateOccupantFlags(flags); len(errs) != 0 {
+		return common.NewBadRequest(ctx, common.RoomDataInvalid, errs)
+	}
+
+	return r.DB.Transaction(ctx, func(tx database.Repository) error {
+		tr := r.withDB(tx)
+
+		if _, err := tx.LockRoomByID(ctx, roomID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errRoomRead(ctx, err.Error())
+		}
+
+		room, existingMembership, err := tr.roomMembershipExisting(ctx, roomID, badgeNumber) // existingMembership may be nil if not exists
+		if err != nil {
+			return err
+		}
+
+		if !fullAccess && !slices.Contains(entity.AggregateFlags(room.Flags), "final") {
+			// occupants cannot see their room until it is final, see FindMyRoom
+			return errNoRoom(ctx)
+		}
+
+		if existingMembership == nil || room.ID != existingMembership.RoomID {
+			return common.NewNotFound(ctx, common.RoomOccupantNotFound, common.Details("this attendee is not in this room"))
+		}
+
+		existingMembership.Flags = entity.CollectFlags(flags)
+		if err := tx.UpdateRoomMembership(ctx, existingMembership); err != nil {
+			return errRoomWrite(ctx, err.Error())
+		}
+
+		aulogging.Infof(ctx, "room occupant flags changed - room %s badge %d by %s", roomID, badgeNumber, common.GetSubject(ctx))
+		return nil
+	})
+}
+
 // --- helpers ---
 
-func (r *roomService) checkRoomFull(ctx context.Context, roomID string, roomSize int64) error {
-	memberIDs, err := r.DB.GetRoomMembersByRoomID(ctx, roomID)
+func checkNoExistingMembership(ctx context.Context, room *entity.Room, existingMembership *entity.RoomMember) error {
+	if existingMembership != nil {
+		if room.ID != existingMembership.RoomID {
+			return common.NewConflict(ctx, common.RoomOccupantConflict, common.Details("this attendee is already in another room"))
+		} else {
+			return common.NewConflict(ctx, common.RoomOccupantDuplicate, common.Details("this attendee is already in this room"))
+		}
+	}
+	return nil
+}
+
+// checkRoomFull checks that the room has a free bed for every night of the stay.
+//
+// The occupant with the given badge number is not counted, so this also works when changing their stay.
+func (r *roomService) checkRoomFull(ctx context.Context, room *entity.Room, badgeNumber int64, stay *modelsv1.Stay) error {
+	members, err := r.DB.GetRoomMembersByRoomID(ctx, room.ID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// empty room is acceptable
@@ -106,9 +209,29 @@ func (r *roomService) checkRoomFull(ctx context.Context, roomID string, roomSize
 			return errRoomRead(ctx, err.Error())
 		}
 	}
+	others := slices.DeleteFunc(members, func(m *entity.RoomMember) bool {
+		return m.ID == badgeNumber
+	})
+
+	nights := config.StayNights(stay.Arrival, stay.Departure)
+	if nights == nil {
+		// no convention dates configured, everyone stays for the whole convention
+		if len(others) >= int(room.Size) {
+			return errRoomFull(ctx)
+		}
+		return nil
+	}
 
-	if len(memberIDs) >= int(roomSize) {
-		return errRoomFull(ctx)
+	occupied := make(map[string]int)
+	for _, m := range others {
+		for _, night := range memberNights(m) {
+			occupied[night]++
+		}
+	}
+	for _, night := range nights {
+		if occupied[night] >= int(room.Size) {
+			return errRoomFull(ctx)
+		}
 	}
 
 	return nil
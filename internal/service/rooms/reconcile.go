@@ -0,0 +1,83 @@
+package roomservice
+
+import (
+	"context"
+	"errors"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/mailservice"
+	"gorm.io/gorm"
+)
+
+func (r *roomService) RemoveNonAttendingOccupant(ctx context.Context, badgeNo int64) (bool, error) {
+	rm, err := r.DB.GetRoomMembershipByAttendeeID(ctx, badgeNo)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, errRoomRead(ctx, err.Error())
+	}
+
+	var removedFrom *entity.Room
+	err = r.DB.Transaction(ctx, func(tx database.Repository) error {
+		room, err := tx.LockRoomByID(ctx, rm.RoomID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil // room deleted concurrently
+			}
+			return errRoomRead(ctx, err.Error())
+		}
+
+		current, err := tx.GetRoomMembershipByAttendeeID(ctx, badgeNo)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil // removed concurrently
+			}
+			return errRoomRead(ctx, err.Error())
+		}
+		if current.RoomID != rm.RoomID {
+			return nil // moved concurrently, try again next time
+		}
+
+		if err := tx.DeleteRoomMembership(ctx, badgeNo); err != nil {
+			return errRoomWrite(ctx, err.Error())
+		}
+		removedFrom = room
+		return nil
+	})
+	if err != nil || removedFrom == nil {
+		return false, err
+	}
+
+	aulogging.Infof(ctx, "attendee %d no longer attending - removed from room %s", badgeNo, removedFrom.ID)
+	_ = r.sendOccupantMail(ctx, "room-occupant-removed", removedFrom, badgeNo)
+
+	return true, nil
+}
+
+// sendOccupantMail informs a room occupant about a change to their room.
+func (r *roomService) sendOccupantMail(ctx context.Context, template string, room *entity.Room, badgeNo int64) error {
+	// the recipient is another attendee, and mails are also sent from reconciliation
+	occupant, err := r.AttSrv.GetAttendeeWithApiToken(ctx, badgeNo)
+	if err != nil {
+		aulogging.WarnErrf(ctx, err, "failed to obtain attendee info for room occupant %d: %s", badgeNo, err.Error())
+		return err
+	}
+
+	mailRequest := mailservice.MailSendDto{
+		CommonID: template,
+		Lang:     occupant.RegistrationLanguage,
+		To:       []string{occupant.Email},
+		Variables: map[string]string{
+			"nickname": occupant.Nickname,
+			"roomname": room.Name,
+		},
+	}
+
+	if err := r.MailSrv.SendEmail(ctx, mailRequest); err != nil {
+		aulogging.WarnErrf(ctx, err, "failed to send email to room occupant %d about %s: %s", badgeNo, template, err.Error())
+		return err
+	}
+	return nil
+}
@@ -10,15 +10,14 @@ import (
 	"github.com/eurofurence/reg-room-service/internal/controller/v1/util"
 	"github.com/eurofurence/reg-room-service/internal/entity"
 	"github.com/eurofurence/reg-room-service/internal/repository/config"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
 	"github.com/eurofurence/reg-room-service/internal/service/rbac"
 	"gorm.io/gorm"
 	"net/url"
-	"slices"
 	"sort"
-	"strings"
 )
 
-func (r *roomService) FindRooms(ctx context.Context, params *FindRoomParams) ([]*modelsv1.Room, error) {
+func (r *roomService) FindRooms(ctx context.Context, params *FindRoomParams) (*modelsv1.RoomList, error) {
 	validator, err := rbac.NewValidator(ctx)
 	if err != nil {
 		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
@@ -26,24 +25,68 @@ func (r *roomService) FindRooms(ctx context.Context, params *FindRoomParams) ([]
 	}
 
 	if validator.IsAdmin() || validator.IsAPITokenCall() {
-		return r.findRoomsFullAccess(ctx, params)
+		rooms, total, err := r.findRoomsFullAccess(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		return &modelsv1.RoomList{
+			Rooms: rooms,
+			Total: total,
+		}, nil
 	} else {
 		return nil, errNotAdminOrApiToken(ctx, "(not loaded)", "(not loaded)")
 	}
 }
 
-// findRoomsFullAccess obtains rooms by search criteria. No permission checks are performed in this internal method.
-func (r *roomService) findRoomsFullAccess(ctx context.Context, params *FindRoomParams) ([]*modelsv1.Room, error) {
+// findRoomsFullAccess obtains rooms by search criteria, together with the total number of matching rooms.
+// No permission checks are performed in this internal method.
+func (r *roomService) findRoomsFullAccess(ctx context.Context, params *FindRoomParams) ([]*modelsv1.Room, int64, error) {
 	result := make([]*modelsv1.Room, 0)
 
-	roomIDs, err := r.DB.FindRooms(ctx, "", params.MinOccupants, params.MaxOccupants, params.MinSize, params.MaxSize, params.MemberIDs)
+	// with stay dates, the number of occupants differs per night, so occupancy is filtered and sorted below
+	perNight := config.StayNights("", "") != nil
+	minOccupants, maxOccupants := params.MinOccupants, params.MaxOccupants
+	if perNight {
+		minOccupants, maxOccupants = 0, -1
+	}
+	filterOccupancy := perNight && (params.MinOccupants > 0 || params.MaxOccupants >= 0)
+	sortOccupancy := perNight && params.SortBy == database.SortByOccupancy
+
+	opts := &database.FindOptions{
+		NameContains: params.Name,
+		SortBy:       params.SortBy,
+		Descending:   params.Descending,
+		Offset:       params.Offset,
+		Limit:        params.Limit,
+	}
+	if opts.SortBy == "" || sortOccupancy {
+		opts.SortBy = database.SortByName
+		opts.Descending = opts.Descending && !sortOccupancy
+	}
+	// rooms that are filtered or sorted here must all be read, so paging happens below
+	pageHere := params.CheckedIn != nil || filterOccupancy || sortOccupancy
+	if pageHere {
+		opts.Offset, opts.Limit = 0, 0
+	}
+
+	criteria := database.FindRoomCriteria{
+		BlockID:       params.BlockID,
+		TypeID:        params.TypeID,
+		MinOccupancy:  minOccupants,
+		MaxOccupancy:  maxOccupants,
+		MinSize:       params.MinSize,
+		MaxSize:       params.MaxSize,
+		AnyOfMemberID: params.MemberIDs,
+	}
+	roomIDs, total, err := r.DB.FindRooms(ctx, criteria, opts)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return result, nil
+			return result, 0, nil
 		}
 
 		aulogging.ErrorErrf(ctx, err, "find rooms failed: %s", err.Error())
-		return result, errInternal(ctx, "database error while finding rooms - see logs for details")
+		return result, 0, errInternal(ctx, "database error while finding rooms - see logs for details")
 	}
 
 	for _, id := range roomIDs {
@@ -51,18 +94,37 @@ func (r *roomService) findRoomsFullAccess(ctx context.Context, params *FindRoomP
 		if err != nil {
 			if !errors.Is(err, gorm.ErrRecordNotFound) {
 				aulogging.WarnErrf(ctx, err, "find rooms failed to read room %s - maybe intermittent change: %s", id, err.Error())
-				return make([]*modelsv1.Room, 0), errInternal(ctx, "database error while finding rooms - see logs for details")
+				return make([]*modelsv1.Room, 0), 0, errInternal(ctx, "database error while finding rooms - see logs for details")
 			}
+			continue
+		}
+
+		if params.CheckedIn != nil && !checkedInMatches(room, *params.CheckedIn) {
+			continue
+		}
+
+		if filterOccupancy && !occupancyMatches(room, params.MinOccupants, params.MaxOccupants) {
+			continue
 		}
 
 		result = append(result, room)
 	}
 
-	sort.Slice(result, func(i, j int) bool {
-		return roomLessByName(result[i], result[j])
-	})
+	if !pageHere {
+		return result, total, nil
+	}
 
-	return result, nil
+	if sortOccupancy {
+		// stable, so rooms with the same occupancy stay sorted by name
+		sort.SliceStable(result, func(i, j int) bool {
+			if params.Descending {
+				return roomPeakOccupancy(result[i]) > roomPeakOccupancy(result[j])
+			}
+			return roomPeakOccupancy(result[i]) < roomPeakOccupancy(result[j])
+		})
+	}
+
+	return pageOf(result, params.Offset, params.Limit), int64(len(result)), nil
 }
 
 func (r *roomService) FindMyRoom(ctx context.Context) (*modelsv1.Room, error) {
@@ -78,7 +140,7 @@ func (r *roomService) FindMyRoom(ctx context.Context) (*modelsv1.Room, error) {
 		MinOccupants: 0,
 		MaxOccupants: -1,
 	}
-	rooms, err := r.findRoomsFullAccess(ctx, params)
+	rooms, _, err := r.findRoomsFullAccess(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -94,6 +156,7 @@ func (r *roomService) FindMyRoom(ctx context.Context) (*modelsv1.Room, error) {
 	// ensure final flag is set on room
 	for _, flag := range myRoom.Flags {
 		if flag == "final" {
+			myRoom.Occupants = hideOtherOccupantDetails(myRoom.Occupants, attendee.ID)
 			return myRoom, nil
 		}
 	}
@@ -137,14 +200,43 @@ func (r *roomService) getRoomByIDFullAccess(ctx context.Context, roomID string)
 		}
 	}
 
-	return &modelsv1.Room{
+	result := &modelsv1.Room{
 		ID:        room.ID,
 		Name:      room.Name,
-		Flags:     aggregateFlags(room.Flags),
+		Flags:     entity.AggregateFlags(room.Flags),
 		Comments:  common.ToOmitEmpty(room.Comments),
 		Size:      room.Size,
+		Block:     common.ToOmitEmpty(room.BlockID),
+		Type:      common.ToOmitEmpty(room.TypeID),
 		Occupants: toOccupants(roomMembers),
-	}, nil
+		Occupancy: nightlyOccupancy(roomMembers),
+	}
+
+	if room.BlockID != "" {
+		block, err := r.DB.GetBlockByID(ctx, room.BlockID)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errRoomRead(ctx, err.Error())
+			}
+			// the block was deleted concurrently, the room is still valid
+		} else {
+			result.BlockName = &block.Name
+		}
+	}
+
+	if room.TypeID != "" {
+		roomType, err := r.DB.GetRoomTypeByID(ctx, room.TypeID)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errRoomRead(ctx, err.Error())
+			}
+			// the room type was deleted concurrently, the room is still valid
+		} else {
+			result.TypeName = &roomType.Name
+		}
+	}
+
+	return result, nil
 }
 
 func (r *roomService) CreateRoom(ctx context.Context, room *modelsv1.RoomCreate) (string, error) {
@@ -160,8 +252,20 @@ func (r *roomService) CreateRoom(ctx context.Context, room *modelsv1.RoomCreate)
 			return "", common.NewBadRequest(ctx, common.RoomDataInvalid, validation)
 		}
 
+		blockID := common.Deref(room.Block)
+		if err := r.checkBlockExists(ctx, r.DB, blockID); err != nil {
+			return "", err
+		}
+
+		typeID := common.Deref(room.Type)
+		roomType, err := r.getRoomTypeForRoom(ctx, r.DB, typeID)
+		if err != nil {
+			return "", err
+		}
+		size, flags, comments := applyRoomTypeDefaults(roomType, room.Size, room.Flags, room.Comments)
+
 		// check for name conflicts
-		matchingIDs, err := r.DB.FindRooms(ctx, room.Name, 0, -1, 0, 0, nil)
+		matchingIDs, _, err := r.DB.FindRooms(ctx, database.FindRoomCriteria{Name: room.Name, BlockID: blockID, MaxOccupancy: -1}, nil)
 		if err != nil {
 			if !errors.Is(err, gorm.ErrRecordNotFound) {
 				return "", errRoomRead(ctx, err.Error())
@@ -173,10 +277,12 @@ func (r *roomService) CreateRoom(ctx context.Context, room *modelsv1.RoomCreate)
 		}
 
 		roomID, err := r.DB.AddRoom(ctx, &entity.Room{
+			BlockID:  blockID,
+			TypeID:   typeID,
 			Name:     room.Name,
-			Flags:    collectFlags(room.Flags),
-			Comments: common.Deref(room.Comments),
-			Size:     room.Size,
+			Flags:    flags,
+			Comments: comments,
+			Size:     size,
 		})
 
 		if err != nil {
@@ -197,53 +303,72 @@ func (r *roomService) UpdateRoom(ctx context.Context, room *modelsv1.Room) error
 	}
 
 	if validator.IsAdmin() || validator.IsAPITokenCall() {
-		dbRoom, err := r.DB.GetRoomByID(ctx, room.ID)
-		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return errRoomNotFound(ctx)
-			} else {
-				return errRoomRead(ctx, err.Error())
+		// lock the room so occupants cannot be added while we check the new size
+		return r.DB.Transaction(ctx, func(tx database.Repository) error {
+			dbRoom, err := tx.LockRoomByID(ctx, room.ID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errRoomNotFound(ctx)
+				} else {
+					return errRoomRead(ctx, err.Error())
+				}
 			}
-		}
 
-		validation := validateRoom(room)
-		if len(validation) > 0 {
-			return common.NewBadRequest(ctx, common.RoomDataInvalid, validation)
-		}
+			validation := validateRoom(room)
+			if len(validation) > 0 {
+				return common.NewBadRequest(ctx, common.RoomDataInvalid, validation)
+			}
 
-		occupants, err := r.DB.GetRoomMembersByRoomID(ctx, room.ID)
-		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				occupants = make([]*entity.RoomMember, 0)
-			} else {
-				return errRoomRead(ctx, err.Error())
+			typeID := common.Deref(room.Type)
+			roomType, err := r.getRoomTypeForRoom(ctx, tx, typeID)
+			if err != nil {
+				return err
 			}
-		}
-		if int(room.Size) < len(occupants) {
-			return common.NewConflict(ctx, common.RoomSizeTooSmall, common.Details("the room cannot be resized, too many occupants for new size"))
-		}
+			size, flags, comments := applyRoomTypeDefaults(roomType, room.Size, room.Flags, room.Comments)
 
-		// check for name conflicts
-		if dbRoom.Name != room.Name {
-			matchingIDs, err := r.DB.FindRooms(ctx, room.Name, 0, -1, 0, 0, nil)
+			occupants, err := tx.GetRoomMembersByRoomID(ctx, room.ID)
 			if err != nil {
-				if !errors.Is(err, gorm.ErrRecordNotFound) {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					occupants = make([]*entity.RoomMember, 0)
+				} else {
 					return errRoomRead(ctx, err.Error())
 				}
 			}
+			if int(size) < entity.PeakOccupancy(occupants, config.StayNights) {
+				return common.NewConflict(ctx, common.RoomSizeTooSmall, common.Details("the room cannot be resized, too many occupants for new size"))
+			}
 
-			if len(matchingIDs) > 0 {
-				return common.NewConflict(ctx, common.RoomDataDuplicate, common.Details("another room with this name already exists"))
+			blockID := common.Deref(room.Block)
+			if blockID != dbRoom.BlockID {
+				if err := r.checkBlockExists(ctx, tx, blockID); err != nil {
+					return err
+				}
 			}
-		}
 
-		// do not touch fields that we do not wish to change, like createdAt or referenced occupants
-		dbRoom.Name = room.Name
-		dbRoom.Flags = collectFlags(room.Flags)
-		dbRoom.Comments = common.Deref(room.Comments)
-		dbRoom.Size = room.Size
+			// check for name conflicts
+			if dbRoom.Name != room.Name || dbRoom.BlockID != blockID {
+				matchingIDs, _, err := tx.FindRooms(ctx, database.FindRoomCriteria{Name: room.Name, BlockID: blockID, MaxOccupancy: -1}, nil)
+				if err != nil {
+					if !errors.Is(err, gorm.ErrRecordNotFound) {
+						return errRoomRead(ctx, err.Error())
+					}
+				}
 
-		return r.DB.UpdateRoom(ctx, dbRoom)
+				if len(matchingIDs) > 0 {
+					return common.NewConflict(ctx, common.RoomDataDuplicate, common.Details("another room with this name already exists"))
+				}
+			}
+
+			// do not touch fields that we do not wish to change, like createdAt or referenced occupants
+			dbRoom.BlockID = blockID
+			dbRoom.TypeID = typeID
+			dbRoom.Name = room.Name
+			dbRoom.Flags = flags
+			dbRoom.Comments = comments
+			dbRoom.Size = size
+
+			return tx.UpdateRoom(ctx, dbRoom)
+		})
 	} else {
 		return errNotAdminOrApiToken(ctx, room.ID, "(not loaded)")
 	}
@@ -322,33 +447,92 @@ func validate(name string, flags []string) url.Values {
 	return result
 }
 
-func allowedFlags() []string {
-	conf, err := config.GetApplicationConfig()
+// checkBlockExists verifies that a room can be placed in the given block. The empty block id means no block.
+func (r *roomService) checkBlockExists(ctx context.Context, db database.Repository, blockID string) error {
+	if blockID == "" {
+		return nil
+	}
+
+	block, err := db.GetBlockByID(ctx, blockID)
 	if err != nil {
-		panic("configuration not loaded before call to allowedFlags() - this is a bug")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.NewBadRequest(ctx, common.RoomDataInvalid, url.Values{"block": []string{"no such block"}})
+		}
+		return errRoomRead(ctx, err.Error())
 	}
-	return conf.Service.RoomFlags
+	if block.DeletedAt.Valid {
+		return common.NewBadRequest(ctx, common.RoomDataInvalid, url.Values{"block": []string{"no such block"}})
+	}
+	return nil
 }
 
-func aggregateFlags(input string) []string {
-	tags := strings.Split(input, ",")
-	tags = slices.DeleteFunc(tags, func(s string) bool {
-		return s == ""
-	})
+// getRoomTypeForRoom loads the room type a room references. The empty type id means no room type, and returns nil.
+func (r *roomService) getRoomTypeForRoom(ctx context.Context, db database.Repository, typeID string) (*entity.RoomType, error) {
+	if typeID == "" {
+		return nil, nil
+	}
+
+	roomType, err := db.GetRoomTypeByID(ctx, typeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.NewBadRequest(ctx, common.RoomDataInvalid, url.Values{"type": []string{"no such room type"}})
+		}
+		return nil, errRoomRead(ctx, err.Error())
+	}
+	if roomType.DeletedAt.Valid {
+		return nil, common.NewBadRequest(ctx, common.RoomDataInvalid, url.Values{"type": []string{"no such room type"}})
+	}
+	return roomType, nil
+}
+
+// applyRoomTypeDefaults returns the size, flags and comments to store for a room.
+//
+// Values that were left out (a size of 0, or nil flags or comments) are taken from the room type, if there is one.
+// Explicitly given values override the defaults of the room type, so an empty list of flags means no flags.
+func applyRoomTypeDefaults(roomType *entity.RoomType, size int64, flags []string, comments *string) (int64, string, string) {
+	resultSize, resultFlags, resultComments := size, entity.CollectFlags(flags), common.Deref(comments)
+	if roomType == nil {
+		return resultSize, resultFlags, resultComments
+	}
 
-	if len(tags) == 0 {
-		return make([]string, 0)
+	if size == 0 {
+		resultSize = roomType.Size
+	}
+	if flags == nil {
+		resultFlags = roomType.Flags
+	}
+	if comments == nil {
+		resultComments = roomType.Comments
+	}
+	return resultSize, resultFlags, resultComments
+}
+
+// validateOccupantFlags checks membership flags against the service configuration.
+func validateOccupantFlags(flags []string) url.Values {
+	result := url.Values{}
+	allowed := allowedMemberFlags()
+	for _, flag := range flags {
+		if !util.SliceContains(flag, allowed) {
+			result.Set("flags", fmt.Sprintf("no such flag '%s'", url.PathEscape(flag)))
+		}
 	}
+	return result
+}
 
-	slices.Sort(tags)
-	return tags
+func allowedFlags() []string {
+	conf, err := config.GetApplicationConfig()
+	if err != nil {
+		panic("configuration not loaded before call to allowedFlags() - this is a bug")
+	}
+	return conf.Service.RoomFlags
 }
 
-func collectFlags(input []string) string {
-	if len(input) == 0 {
-		return ","
+func allowedMemberFlags() []string {
+	conf, err := config.GetApplicationConfig()
+	if err != nil {
+		panic("configuration not loaded before call to allowedMemberFlags() - this is a bug")
 	}
-	return fmt.Sprintf(",%s,", strings.Join(input, ","))
+	return conf.Service.MemberFlags
 }
 
 func toOccupants(roomMembers []*entity.RoomMember) []modelsv1.Member {
@@ -359,8 +543,13 @@ func toOccupants(roomMembers []*entity.RoomMember) []modelsv1.Member {
 		}
 
 		member := modelsv1.Member{
-			ID:       m.ID,
-			Nickname: m.Nickname,
+			ID:         m.ID,
+			Nickname:   m.Nickname,
+			Flags:      entity.AggregateFlags(m.Flags),
+			Arrival:    common.ToOmitEmpty(m.ArrivalDate),
+			Departure:  common.ToOmitEmpty(m.DepartureDate),
+			CheckedIn:  formatTimestamp(m.CheckedInAt),
+			CheckedOut: formatTimestamp(m.CheckedOutAt),
 		}
 		if m.AvatarURL != "" {
 			member.Avatar = &m.AvatarURL
@@ -376,6 +565,21 @@ func toOccupants(roomMembers []*entity.RoomMember) []modelsv1.Member {
 	return members
 }
 
+// hideOtherOccupantDetails removes the membership flags and check-in times of everyone except myID,
+// which only admins can see.
+func hideOtherOccupantDetails(occupants []modelsv1.Member, myID int64) []modelsv1.Member {
+	result := make([]modelsv1.Member, 0)
+	for _, occupant := range occupants {
+		if occupant.ID != myID {
+			occupant.Flags = nil
+			occupant.CheckedIn = nil
+			occupant.CheckedOut = nil
+		}
+		result = append(result, occupant)
+	}
+	return result
+}
+
 // --- errors ---
 
 func errNotAdminOrApiToken(ctx context.Context, uuid string, name string) error {
@@ -0,0 +1,156 @@
+package roomservice
+
+import (
+	"context"
+	"errors"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/config"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+	"github.com/eurofurence/reg-room-service/internal/service/rbac"
+	"gorm.io/gorm"
+	"net/url"
+	"time"
+)
+
+func (r *roomService) UpdateOccupantStay(ctx context.Context, roomID string, badgeNumber int64, stay *modelsv1.Stay) error {
+	validator, err := rbac.NewValidator(ctx)
+	if err != nil {
+		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
+		return errCouldNotGetValidator(ctx)
+	}
+
+	if !validator.IsAdmin() && !validator.IsAPITokenCall() {
+		return errNotAdminOrApiToken(ctx, roomID, "(not loaded)")
+	}
+
+	if errs := validateStay(stay); len(errs) != 0 {
+		return common.NewBadRequest(ctx, common.RoomDataInvalid, errs)
+	}
+
+	return r.DB.Transaction(ctx, func(tx database.Repository) error {
+		tr := r.withDB(tx)
+
+		room, err := tx.LockRoomByID(ctx, roomID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errRoomNotFound(ctx)
+			}
+			return errRoomRead(ctx, err.Error())
+		}
+
+		_, existingMembership, err := tr.roomMembershipExisting(ctx, roomID, badgeNumber) // existingMembership may be nil if not exists
+		if err != nil {
+			return err
+		}
+
+		if existingMembership == nil || room.ID != existingMembership.RoomID {
+			return common.NewNotFound(ctx, common.RoomOccupantNotFound, common.Details("this attendee is not in this room"))
+		}
+
+		if err := tr.checkRoomFull(ctx, room, badgeNumber, stay); err != nil {
+			return err
+		}
+
+		existingMembership.ArrivalDate = stay.Arrival
+		existingMembership.DepartureDate = stay.Departure
+		if err := tx.UpdateRoomMembership(ctx, existingMembership); err != nil {
+			return errRoomWrite(ctx, err.Error())
+		}
+
+		aulogging.Infof(ctx, "room occupant stay changed - room %s badge %d arrival '%s' departure '%s' by %s", roomID, badgeNumber, stay.Arrival, stay.Departure, common.GetSubject(ctx))
+		return nil
+	})
+}
+
+// --- helpers ---
+
+// validateStay checks that the stay lies within the convention dates. An empty stay means the whole convention,
+// and is valid even if no convention dates are configured.
+func validateStay(stay *modelsv1.Stay) url.Values {
+	result := url.Values{}
+	if stay.Arrival == "" && stay.Departure == "" {
+		return result
+	}
+
+	conventionNights := config.StayNights("", "")
+	if conventionNights == nil {
+		result.Set("stay", "stay dates are not supported because the convention dates are not configured")
+		return result
+	}
+
+	if !validDate(stay.Arrival) {
+		result.Set("arrival", "arrival must be an ISO date such as 2024-09-18")
+	}
+	if !validDate(stay.Departure) {
+		result.Set("departure", "departure must be an ISO date such as 2024-09-22")
+	}
+	if len(result) > 0 {
+		return result
+	}
+
+	nights := config.StayNights(stay.Arrival, stay.Departure)
+	if len(nights) == 0 {
+		result.Set("stay", "departure must be after arrival")
+		return result
+	}
+	if nights[0] < conventionNights[0] || nights[len(nights)-1] > conventionNights[len(conventionNights)-1] {
+		result.Set("stay", "the stay must lie within the convention dates")
+	}
+	return result
+}
+
+func validDate(value string) bool {
+	if value == "" {
+		return true
+	}
+	_, err := time.Parse(config.IsoDateFormat, value)
+	return err == nil
+}
+
+// memberNights lists the nights an occupant stays in their room, or nil if the convention dates are not configured.
+func memberNights(rm *entity.RoomMember) []string {
+	return config.StayNights(rm.ArrivalDate, rm.DepartureDate)
+}
+
+// nightlyOccupancy counts the occupants staying in the room for each night of the convention,
+// or returns nil if the convention dates are not configured.
+func nightlyOccupancy(roomMembers []*entity.RoomMember) []modelsv1.NightOccupancy {
+	nights := config.StayNights("", "")
+	if nights == nil {
+		return nil
+	}
+
+	occupied := make(map[string]int)
+	for _, m := range roomMembers {
+		for _, night := range memberNights(m) {
+			occupied[night]++
+		}
+	}
+
+	result := make([]modelsv1.NightOccupancy, 0, len(nights))
+	for _, night := range nights {
+		result = append(result, modelsv1.NightOccupancy{
+			Night:    night,
+			Occupied: occupied[night],
+		})
+	}
+	return result
+}
+
+// occupancyMatches applies the occupant count conditions of FindRoomParams to the peak nightly occupancy of a room.
+func occupancyMatches(room *modelsv1.Room, minOccupants uint, maxOccupants int) bool {
+	peak := roomPeakOccupancy(room)
+	return peak >= int(minOccupants) && (maxOccupants < 0 || peak <= maxOccupants)
+}
+
+// roomPeakOccupancy is the highest number of occupants of a room on any single night, as listed in its occupancy.
+func roomPeakOccupancy(room *modelsv1.Room) int {
+	peak := 0
+	for _, night := range room.Occupancy {
+		peak = max(peak, night.Occupied)
+	}
+	return peak
+}
@@ -0,0 +1,38 @@
+package roomtypeservice
+
+import (
+	"context"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+)
+
+// Service defines the interface for the service function implementations for the room type endpoints.
+//
+// A room type is a catalog entry such as "double standard". When a room references a room type, any size, flags
+// or comments left out when writing the room are taken from the room type.
+type Service interface {
+	// FindRoomTypes returns all room types, sorted by name. Only available to admins and api token.
+	FindRoomTypes(ctx context.Context) ([]*modelsv1.RoomType, error)
+	// GetRoomTypeByID returns a single room type. Only available to admins and api token.
+	GetRoomTypeByID(ctx context.Context, typeID string) (*modelsv1.RoomType, error)
+	// CreateRoomType creates a new room type and returns its id. Only available to admins and api token.
+	CreateRoomType(ctx context.Context, roomType *modelsv1.RoomTypeCreate) (string, error)
+	// UpdateRoomType replaces the data of a room type. Only available to admins and api token.
+	//
+	// Existing rooms of this type are not changed, the new defaults only apply when rooms are next written.
+	UpdateRoomType(ctx context.Context, roomType *modelsv1.RoomType) error
+	// DeleteRoomType deletes a room type. Only available to admins and api token.
+	//
+	// Room types that are still referenced by rooms cannot be deleted.
+	DeleteRoomType(ctx context.Context, typeID string) error
+}
+
+func New(db database.Repository) Service {
+	return &roomTypeService{
+		DB: db,
+	}
+}
+
+type roomTypeService struct {
+	DB database.Repository
+}
@@ -0,0 +1,251 @@
+package roomtypeservice
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/application/common"
+	"github.com/eurofurence/reg-room-service/internal/controller/v1/util"
+	"github.com/eurofurence/reg-room-service/internal/entity"
+	"github.com/eurofurence/reg-room-service/internal/repository/config"
+	"github.com/eurofurence/reg-room-service/internal/repository/database"
+	"github.com/eurofurence/reg-room-service/internal/service/rbac"
+	"gorm.io/gorm"
+	"net/url"
+	"slices"
+)
+
+func (s *roomTypeService) FindRoomTypes(ctx context.Context) ([]*modelsv1.RoomType, error) {
+	if err := s.adminOnly(ctx, "(all)"); err != nil {
+		return nil, err
+	}
+
+	roomTypes, err := s.DB.GetRoomTypes(ctx)
+	if err != nil {
+		return nil, errRoomTypeRead(ctx, err.Error())
+	}
+
+	result := make([]*modelsv1.RoomType, 0, len(roomTypes))
+	for _, rt := range roomTypes {
+		result = append(result, toRoomType(rt))
+	}
+	slices.SortFunc(result, func(a, b *modelsv1.RoomType) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return result, nil
+}
+
+func (s *roomTypeService) GetRoomTypeByID(ctx context.Context, typeID string) (*modelsv1.RoomType, error) {
+	if err := s.adminOnly(ctx, typeID); err != nil {
+		return nil, err
+	}
+
+	rt, err := s.getRoomType(ctx, s.DB, typeID)
+	if err != nil {
+		return nil, err
+	}
+	return toRoomType(rt), nil
+}
+
+func (s *roomTypeService) CreateRoomType(ctx context.Context, roomType *modelsv1.RoomTypeCreate) (string, error) {
+	if err := s.adminOnly(ctx, "(new)"); err != nil {
+		return "", err
+	}
+
+	validation := validate(roomType.Name, roomType.Size, roomType.Flags)
+	if len(validation) > 0 {
+		return "", common.NewBadRequest(ctx, common.RoomTypeDataInvalid, validation)
+	}
+
+	var typeID string
+	err := s.DB.Transaction(ctx, func(tx database.Repository) error {
+		if err := s.checkNameUnused(ctx, tx, roomType.Name); err != nil {
+			return err
+		}
+
+		id, err := tx.AddRoomType(ctx, &entity.RoomType{
+			Name:          roomType.Name,
+			Flags:         entity.CollectFlags(roomType.Flags),
+			Comments:      common.Deref(roomType.Comments),
+			Size:          roomType.Size,
+			PriceCategory: common.Deref(roomType.PriceCategory),
+		})
+		if err != nil {
+			return errRoomTypeWrite(ctx, err.Error())
+		}
+		typeID = id
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	aulogging.Infof(ctx, "room type %s (%s) created by %s", typeID, url.PathEscape(roomType.Name), common.GetSubject(ctx))
+	return typeID, nil
+}
+
+func (s *roomTypeService) UpdateRoomType(ctx context.Context, roomType *modelsv1.RoomType) error {
+	if err := s.adminOnly(ctx, roomType.ID); err != nil {
+		return err
+	}
+
+	return s.DB.Transaction(ctx, func(tx database.Repository) error {
+		dbRoomType, err := s.getRoomType(ctx, tx, roomType.ID)
+		if err != nil {
+			return err
+		}
+
+		validation := validate(roomType.Name, roomType.Size, roomType.Flags)
+		if len(validation) > 0 {
+			return common.NewBadRequest(ctx, common.RoomTypeDataInvalid, validation)
+		}
+
+		if dbRoomType.Name != roomType.Name {
+			if err := s.checkNameUnused(ctx, tx, roomType.Name); err != nil {
+				return err
+			}
+		}
+
+		// do not touch fields that we do not wish to change, like createdAt
+		dbRoomType.Name = roomType.Name
+		dbRoomType.Flags = entity.CollectFlags(roomType.Flags)
+		dbRoomType.Comments = common.Deref(roomType.Comments)
+		dbRoomType.Size = roomType.Size
+		dbRoomType.PriceCategory = common.Deref(roomType.PriceCategory)
+
+		if err := tx.UpdateRoomType(ctx, dbRoomType); err != nil {
+			return errRoomTypeWrite(ctx, err.Error())
+		}
+		return nil
+	})
+}
+
+func (s *roomTypeService) DeleteRoomType(ctx context.Context, typeID string) error {
+	if err := s.adminOnly(ctx, typeID); err != nil {
+		return err
+	}
+
+	return s.DB.Transaction(ctx, func(tx database.Repository) error {
+		if _, err := s.getRoomType(ctx, tx, typeID); err != nil {
+			return err
+		}
+
+		roomIDs, _, err := tx.FindRooms(ctx, database.FindRoomCriteria{TypeID: typeID, MaxOccupancy: -1}, nil)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errRoomTypeRead(ctx, err.Error())
+		}
+		if len(roomIDs) > 0 {
+			aulogging.Infof(ctx, "attempt to delete room type %s that is still in use - rejected", url.PathEscape(typeID))
+			return common.NewConflict(ctx, common.RoomTypeInUse, common.Details("room type is still used by rooms - please delete them or change their room type first"))
+		}
+
+		if err := tx.DeleteRoomTypeByID(ctx, typeID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errRoomTypeNotFound(ctx)
+			}
+			return errRoomTypeWrite(ctx, err.Error())
+		}
+
+		aulogging.Infof(ctx, "room type %s deleted by %s", typeID, common.GetSubject(ctx))
+		return nil
+	})
+}
+
+// --- helpers ---
+
+func (s *roomTypeService) adminOnly(ctx context.Context, typeID string) error {
+	validator, err := rbac.NewValidator(ctx)
+	if err != nil {
+		aulogging.ErrorErrf(ctx, err, "Could not retrieve RBAC validator from context. [error]: %v", err)
+		return common.NewInternalServerError(ctx, common.InternalErrorMessage, common.Details("unexpected error when parsing user claims"))
+	}
+
+	if !validator.IsAdmin() && !validator.IsAPITokenCall() {
+		aulogging.Warnf(ctx, "unauthorized attempt to access admin-only room type %s by %s", typeID, common.GetSubject(ctx))
+		return common.NewForbidden(ctx, common.AuthForbidden, common.Details("you are not authorized for this operation - the attempt has been logged"))
+	}
+	return nil
+}
+
+func (s *roomTypeService) getRoomType(ctx context.Context, db database.Repository, typeID string) (*entity.RoomType, error) {
+	rt, err := db.GetRoomTypeByID(ctx, typeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errRoomTypeNotFound(ctx)
+		}
+		return nil, errRoomTypeRead(ctx, err.Error())
+	}
+	if rt.DeletedAt.Valid {
+		return nil, errRoomTypeNotFound(ctx)
+	}
+	return rt, nil
+}
+
+func (s *roomTypeService) checkNameUnused(ctx context.Context, db database.Repository, name string) error {
+	roomTypes, err := db.GetRoomTypes(ctx)
+	if err != nil {
+		return errRoomTypeRead(ctx, err.Error())
+	}
+	for _, rt := range roomTypes {
+		if rt.Name == name {
+			return common.NewConflict(ctx, common.RoomTypeDataDuplicate, common.Details("another room type with this name already exists"))
+		}
+	}
+	return nil
+}
+
+func validate(name string, size int64, flags []string) url.Values {
+	result := url.Values{}
+	if len(name) == 0 {
+		result.Set("name", "room type name cannot be empty")
+	}
+	if len(name) > 50 {
+		result.Set("name", "room type name too long, max 50 characters")
+	}
+	if size < 1 {
+		result.Set("size", "size must be at least 1")
+	}
+	allowed := allowedFlags()
+	for _, flag := range flags {
+		if !util.SliceContains(flag, allowed) {
+			result.Set("flags", fmt.Sprintf("no such flag '%s'", url.PathEscape(flag)))
+		}
+	}
+	return result
+}
+
+func toRoomType(rt *entity.RoomType) *modelsv1.RoomType {
+	return &modelsv1.RoomType{
+		ID:            rt.ID,
+		Name:          rt.Name,
+		Flags:         entity.AggregateFlags(rt.Flags),
+		Comments:      common.ToOmitEmpty(rt.Comments),
+		Size:          rt.Size,
+		PriceCategory: common.ToOmitEmpty(rt.PriceCategory),
+	}
+}
+
+func allowedFlags() []string {
+	conf, err := config.GetApplicationConfig()
+	if err != nil {
+		panic("configuration not loaded before call to allowedFlags() - this is a bug")
+	}
+	return conf.Service.RoomFlags
+}
+
+// --- errors ---
+
+func errRoomTypeNotFound(ctx context.Context) error {
+	return common.NewNotFound(ctx, common.RoomTypeIDNotFound, common.Details("room type does not exist"))
+}
+
+func errRoomTypeRead(ctx context.Context, details string) error {
+	return common.NewInternalServerError(ctx, common.RoomTypeReadError, common.Details(details))
+}
+
+func errRoomTypeWrite(ctx context.Context, details string) error {
+	return common.NewInternalServerError(ctx, common.RoomTypeWriteError, common.Details(details))
+}
@@ -0,0 +1,553 @@
+package acceptance
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eurofurence/reg-room-service/docs"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// tstSetupAssignmentGroup creates a group owned by the first badge number, with the other badge numbers as members.
+func tstSetupAssignmentGroup(t *testing.T, name string, flags []string, badgeNumbers ...int64) string {
+	groupSent := modelsv1.GroupCreate{
+		Name:  name,
+		Flags: flags,
+		Owner: badgeNumbers[0],
+	}
+	response := tstPerformPost("/api/rest/v1/groups", tstRenderJson(groupSent), tstValidAdminToken(t))
+	require.Equal(t, http.StatusCreated, response.status, "unexpected http response status")
+
+	for _, badgeNo := range badgeNumbers[1:] {
+		addResponse := tstPerformPostNoBody(fmt.Sprintf("%s/members/%d?force=true", response.location, badgeNo), tstValidAdminToken(t))
+		require.Equal(t, http.StatusNoContent, addResponse.status, "unexpected http response status")
+	}
+
+	return tstRoomLocationToRoomID(response.location)
+}
+
+// tstSetupAssignmentRoom creates an empty room and returns its id.
+func tstSetupAssignmentRoom(t *testing.T, name string, size int64, flags []string) string {
+	roomSent := modelsv1.RoomCreate{
+		Name:  name,
+		Flags: flags,
+		Size:  size,
+	}
+	response := tstPerformPost("/api/rest/v1/rooms", tstRenderJson(roomSent), tstValidAdminToken(t))
+	require.Equal(t, http.StatusCreated, response.status, "unexpected http response status")
+	return tstRoomLocationToRoomID(response.location)
+}
+
+// tstSetupAssignmentRoomInBlock creates an empty room without flags in a block and returns its id.
+func tstSetupAssignmentRoomInBlock(t *testing.T, name string, size int64, blockID string) string {
+	roomSent := modelsv1.RoomCreate{
+		Name:  name,
+		Flags: []string{},
+		Size:  size,
+		Block: &blockID,
+	}
+	response := tstPerformPost("/api/rest/v1/rooms", tstRenderJson(roomSent), tstValidAdminToken(t))
+	require.Equal(t, http.StatusCreated, response.status, "unexpected http response status")
+	return tstRoomLocationToRoomID(response.location)
+}
+
+func tstSetupAssignmentWishes(t *testing.T, groupID string, wishes modelsv1.GroupWishes) {
+	response := tstPerformPut("/api/rest/v1/groups/"+groupID+"/wishes", tstRenderJson(wishes), tstValidAdminToken(t))
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+}
+
+func tstRequireRoomOccupants(t *testing.T, roomID string, expectedBadgeNumbers ...int64) {
+	room := tstReadRoom(t, "/api/rest/v1/rooms/"+roomID)
+	actual := make([]int64, 0)
+	for _, occupant := range room.Occupants {
+		actual = append(actual, occupant.ID)
+	}
+	require.ElementsMatch(t, expectedBadgeNumbers, actual)
+}
+
+// --- plan ---
+
+func TestAssignment_PlanSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given several empty rooms of different sizes, one of them with a handicapped flag")
+	roomA := tstSetupAssignmentRoom(t, "A", 4, []string{})
+	roomB := tstSetupAssignmentRoom(t, "B", 2, []string{})
+	roomC := tstSetupAssignmentRoom(t, "C", 2, []string{"handicapped"})
+
+	docs.Given("Given several groups of different sizes, one of them with a handicapped flag")
+	tstSetupConcurrentAttendees()
+	cats := tstSetupAssignmentGroup(t, "cats", []string{}, 1000, 1001, 1002)
+	dogs := tstSetupAssignmentGroup(t, "dogs", []string{"handicapped"}, 1003, 1004)
+	mice := tstSetupAssignmentGroup(t, "mice", []string{"public"}, 1005, 1006)
+	owls := tstSetupAssignmentGroup(t, "owls", []string{}, 1007)
+
+	docs.When("When an admin requests an assignment plan")
+	response := tstPerformGet("/api/rest/v1/assignment/plan", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and all groups are placed together, larger groups first, into the smallest fitting room with the required flags")
+	plan := modelsv1.AssignmentPlan{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &plan)
+	expected := []modelsv1.Assignment{
+		{GroupID: cats, GroupName: "cats", RoomID: roomA, RoomName: "A", BadgeNumbers: []int64{1000, 1001, 1002}},
+		{GroupID: dogs, GroupName: "dogs", RoomID: roomC, RoomName: "C", BadgeNumbers: []int64{1003, 1004}},
+		{GroupID: mice, GroupName: "mice", RoomID: roomB, RoomName: "B", BadgeNumbers: []int64{1005, 1006}},
+		{GroupID: owls, GroupName: "owls", RoomID: roomA, RoomName: "A", BadgeNumbers: []int64{1007}},
+	}
+	require.Equal(t, expected, plan.Assignments)
+	require.Empty(t, plan.Unplaced)
+
+	docs.Then("And nothing has been changed")
+	tstRequireRoomOccupants(t, roomA)
+	tstRequireRoomOccupants(t, roomB)
+	tstRequireRoomOccupants(t, roomC)
+}
+
+func TestAssignment_PlanUnplaced(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an empty room without flags")
+	roomA := tstSetupAssignmentRoom(t, "A", 2, []string{})
+
+	docs.Given("Given a group with a handicapped flag and a group that is too large for any room")
+	tstSetupConcurrentAttendees()
+	dogs := tstSetupAssignmentGroup(t, "dogs", []string{"handicapped"}, 1003, 1004)
+	bats := tstSetupAssignmentGroup(t, "bats", []string{}, 1005, 1006, 1007)
+
+	docs.When("When an admin requests an assignment plan")
+	response := tstPerformGet("/api/rest/v1/assignment/plan", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and both groups are listed as unplaced, and the room is not used")
+	plan := modelsv1.AssignmentPlan{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &plan)
+	require.Empty(t, plan.Assignments)
+	expected := []modelsv1.UnplacedGroup{
+		{GroupID: bats, GroupName: "bats", BadgeNumbers: []int64{1005, 1006, 1007}, Reason: "no room with enough free beds and all required flags"},
+		{GroupID: dogs, GroupName: "dogs", BadgeNumbers: []int64{1003, 1004}, Reason: "no room with enough free beds and all required flags"},
+	}
+	require.Equal(t, expected, plan.Unplaced)
+	tstRequireRoomOccupants(t, roomA)
+}
+
+func TestAssignment_PlanKeepsGroupTogether(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with two members, one of whom is already in a room with a free bed")
+	tstSetupConcurrentAttendees()
+	cats := tstSetupAssignmentGroup(t, "cats", []string{}, 1000, 1001)
+	roomA := tstSetupAssignmentRoom(t, "A", 1, []string{})
+	roomB := tstSetupAssignmentRoom(t, "B", 2, []string{})
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody("/api/rest/v1/rooms/"+roomB+"/occupants/1000", tstValidAdminToken(t)).status)
+
+	docs.When("When an admin requests an assignment plan")
+	response := tstPerformGet("/api/rest/v1/assignment/plan", tstValidAdminToken(t))
+
+	docs.Then("Then the remaining member is placed in the room of the other member, even though another room fits just as well")
+	plan := modelsv1.AssignmentPlan{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &plan)
+	expected := []modelsv1.Assignment{
+		{GroupID: cats, GroupName: "cats", RoomID: roomB, RoomName: "B", BadgeNumbers: []int64{1001}},
+	}
+	require.Equal(t, expected, plan.Assignments)
+	tstRequireRoomOccupants(t, roomA)
+}
+
+func TestAssignment_PlanPrefersWishedRooms(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given empty rooms of size 2 without flags, of size 2 with the final flag, and of a room type with size 4")
+	quad := tstSetupRoomType(t, "quad", 4, []string{}, "")
+	roomA := tstSetupAssignmentRoom(t, "A", 2, []string{})
+	roomB := tstSetupAssignmentRoom(t, "B", 2, []string{"final"})
+	roomQ := tstRoomLocationToRoomID(tstSetupRoomOfType(t, "Q", quad))
+
+	docs.Given("Given groups of the same size, one wishing for the final flag, one wishing for the room type, one without wishes")
+	tstSetupConcurrentAttendees()
+	cats := tstSetupAssignmentGroup(t, "cats", []string{}, 1000, 1001)
+	dogs := tstSetupAssignmentGroup(t, "dogs", []string{}, 1002, 1003)
+	mice := tstSetupAssignmentGroup(t, "mice", []string{}, 1004, 1005)
+	tstSetupAssignmentWishes(t, cats, modelsv1.GroupWishes{RoomFlags: []string{"final"}})
+	tstSetupAssignmentWishes(t, dogs, modelsv1.GroupWishes{Type: &quad})
+
+	docs.When("When an admin requests an assignment plan")
+	response := tstPerformGet("/api/rest/v1/assignment/plan", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the groups with wishes are placed in the rooms meeting their wishes, even though other rooms fit better")
+	plan := modelsv1.AssignmentPlan{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &plan)
+	expected := []modelsv1.Assignment{
+		{GroupID: cats, GroupName: "cats", RoomID: roomB, RoomName: "B", BadgeNumbers: []int64{1000, 1001}},
+		{GroupID: dogs, GroupName: "dogs", RoomID: roomQ, RoomName: "Q", BadgeNumbers: []int64{1002, 1003}},
+		{GroupID: mice, GroupName: "mice", RoomID: roomA, RoomName: "A", BadgeNumbers: []int64{1004, 1005}},
+	}
+	require.Equal(t, expected, plan.Assignments)
+	require.Empty(t, plan.Unplaced)
+}
+
+func TestAssignment_PlanPrefersRoomNearWishedGroup(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two blocks with an empty room each, and a group whose member is already in a room in the second block")
+	north := tstSetupBlock(t, "North", "2030-01-20T19:00:00+01:00", "north", "", "")
+	south := tstSetupBlock(t, "South", "2030-01-20T19:00:00+01:00", "south", "", "")
+	roomA := tstSetupAssignmentRoomInBlock(t, "A", 2, north)
+	roomB := tstSetupAssignmentRoomInBlock(t, "B", 2, south)
+	roomC := tstSetupAssignmentRoomInBlock(t, "C", 1, south)
+	tstSetupConcurrentAttendees()
+	cats := tstSetupAssignmentGroup(t, "cats", []string{}, 1000)
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody("/api/rest/v1/rooms/"+roomC+"/occupants/1000", tstValidAdminToken(t)).status)
+
+	docs.Given("Given another group that wishes to be near the first group")
+	dogs := tstSetupAssignmentGroup(t, "dogs", []string{}, 1001, 1002)
+	tstSetupAssignmentWishes(t, dogs, modelsv1.GroupWishes{NearGroups: []string{cats}})
+
+	docs.When("When an admin requests an assignment plan")
+	response := tstPerformGet("/api/rest/v1/assignment/plan", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the group is placed in the block of the first group")
+	plan := modelsv1.AssignmentPlan{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &plan)
+	expected := []modelsv1.Assignment{
+		{GroupID: dogs, GroupName: "dogs", RoomID: roomB, RoomName: "B", BadgeNumbers: []int64{1001, 1002}},
+	}
+	require.Equal(t, expected, plan.Assignments)
+	tstRequireRoomOccupants(t, roomA)
+}
+
+func TestAssignment_PlanUserDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration")
+	registerSubject("101")
+
+	docs.When("When they request an assignment plan")
+	response := tstPerformGet("/api/rest/v1/assignment/plan", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+}
+
+func TestAssignment_PlanApiTokenDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.When("When a downstream service using a valid api token requests an assignment plan")
+	response := tstPerformGet("/api/rest/v1/assignment/plan", tstValidApiToken())
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+}
+
+// --- apply ---
+
+func TestAssignment_ApplySuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an assignment plan for two groups and two rooms")
+	roomA := tstSetupAssignmentRoom(t, "A", 3, []string{})
+	roomB := tstSetupAssignmentRoom(t, "B", 2, []string{})
+	tstSetupConcurrentAttendees()
+	tstSetupAssignmentGroup(t, "cats", []string{}, 1000, 1001, 1002)
+	tstSetupAssignmentGroup(t, "mice", []string{}, 1005, 1006)
+	plan := modelsv1.AssignmentPlan{}
+	tstRequireSuccessResponse(t, tstPerformGet("/api/rest/v1/assignment/plan", tstValidAdminToken(t)), http.StatusOK, &plan)
+	require.Len(t, plan.Assignments, 2)
+
+	docs.When("When an admin applies the plan")
+	response := tstPerformPost("/api/rest/v1/assignment/apply", tstRenderJson(plan), tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the groups have been placed in the rooms")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	tstRequireRoomOccupants(t, roomA, 1000, 1001, 1002)
+	tstRequireRoomOccupants(t, roomB, 1005, 1006)
+
+	docs.Then("And a new plan is empty because everyone has a room")
+	newPlan := modelsv1.AssignmentPlan{}
+	tstRequireSuccessResponse(t, tstPerformGet("/api/rest/v1/assignment/plan", tstValidAdminToken(t)), http.StatusOK, &newPlan)
+	require.Empty(t, newPlan.Assignments)
+	require.Empty(t, newPlan.Unplaced)
+}
+
+func TestAssignment_ApplyRoomFullNothingChanged(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two empty rooms and two groups")
+	roomA := tstSetupAssignmentRoom(t, "A", 2, []string{})
+	roomB := tstSetupAssignmentRoom(t, "B", 2, []string{})
+	tstSetupConcurrentAttendees()
+	cats := tstSetupAssignmentGroup(t, "cats", []string{}, 1000, 1001)
+	dogs := tstSetupAssignmentGroup(t, "dogs", []string{}, 1002, 1003, 1004)
+
+	docs.When("When an admin applies a modified plan, in which the second assignment exceeds the room size")
+	plan := modelsv1.AssignmentPlan{
+		Assignments: []modelsv1.Assignment{
+			{GroupID: cats, RoomID: roomA, BadgeNumbers: []int64{1000, 1001}},
+			{GroupID: dogs, RoomID: roomB, BadgeNumbers: []int64{1002, 1003, 1004}},
+		},
+	}
+	response := tstPerformPost("/api/rest/v1/assignment/apply", tstRenderJson(plan), tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "room.size.full", "room B does not have enough free beds")
+
+	docs.Then("And no part of the plan has been applied")
+	tstRequireRoomOccupants(t, roomA)
+	tstRequireRoomOccupants(t, roomB)
+}
+
+func TestAssignment_ApplyAlreadyInRoom(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an attendee who is already in a room, and another empty room")
+	tstSetupConcurrentAttendees()
+	roomA := tstSetupAssignmentRoom(t, "A", 2, []string{})
+	roomB := tstSetupAssignmentRoom(t, "B", 2, []string{})
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody("/api/rest/v1/rooms/"+roomA+"/occupants/1000", tstValidAdminToken(t)).status)
+	cats := tstSetupAssignmentGroup(t, "cats", []string{}, 1001, 1000)
+
+	docs.When("When an admin applies a plan that places the attendee in the other room")
+	plan := modelsv1.AssignmentPlan{
+		Assignments: []modelsv1.Assignment{
+			{GroupID: cats, RoomID: roomB, BadgeNumbers: []int64{1001, 1000}},
+		},
+	}
+	response := tstPerformPost("/api/rest/v1/assignment/apply", tstRenderJson(plan), tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error and nothing has been changed")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "room.occupant.conflict", "attendee 1000 is already in a room")
+	tstRequireRoomOccupants(t, roomA, 1000)
+	tstRequireRoomOccupants(t, roomB)
+}
+
+func TestAssignment_ApplyNotAttending(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an empty room and an attendee whose registration has been cancelled")
+	roomA := tstSetupAssignmentRoom(t, "A", 2, []string{})
+	registerSubject("1234567890")
+	tstSetupConcurrentAttendees()
+	cats := tstSetupAssignmentGroup(t, "cats", []string{}, 1000)
+
+	docs.When("When an admin applies a plan that places the attendee in the room")
+	plan := modelsv1.AssignmentPlan{
+		Assignments: []modelsv1.Assignment{
+			{GroupID: cats, RoomID: roomA, BadgeNumbers: []int64{84}},
+		},
+	}
+	response := tstPerformPost("/api/rest/v1/assignment/apply", tstRenderJson(plan), tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error and nothing has been changed")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "attendee.status.not.attending", "registration of attendee 84 is not in attending status")
+	tstRequireRoomOccupants(t, roomA)
+}
+
+func TestAssignment_ApplyDuplicateAttendee(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two empty rooms and a group")
+	roomA := tstSetupAssignmentRoom(t, "A", 2, []string{})
+	roomB := tstSetupAssignmentRoom(t, "B", 2, []string{})
+	tstSetupConcurrentAttendees()
+	cats := tstSetupAssignmentGroup(t, "cats", []string{}, 1000)
+
+	docs.When("When an admin applies a plan that lists the same attendee twice")
+	plan := modelsv1.AssignmentPlan{
+		Assignments: []modelsv1.Assignment{
+			{GroupID: cats, RoomID: roomA, BadgeNumbers: []int64{1000}},
+			{GroupID: cats, RoomID: roomB, BadgeNumbers: []int64{1000}},
+		},
+	}
+	response := tstPerformPost("/api/rest/v1/assignment/apply", tstRenderJson(plan), tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "assignment.data.invalid", "attendee 1000 is listed more than once")
+}
+
+func TestAssignment_ApplyNoLongerGroupMember(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an empty room and a plan for a group")
+	roomA := tstSetupAssignmentRoom(t, "A", 3, []string{})
+	tstSetupConcurrentAttendees()
+	cats := tstSetupAssignmentGroup(t, "cats", []string{}, 1000, 1001)
+	plan := modelsv1.AssignmentPlan{}
+	tstRequireSuccessResponse(t, tstPerformGet("/api/rest/v1/assignment/plan", tstValidAdminToken(t)), http.StatusOK, &plan)
+	require.Len(t, plan.Assignments, 1)
+
+	docs.Given("Given a member has since left the group")
+	require.Equal(t, http.StatusNoContent, tstPerformDelete("/api/rest/v1/groups/"+cats+"/members/1001", tstValidAdminToken(t)).status)
+
+	docs.When("When an admin applies the plan")
+	response := tstPerformPost("/api/rest/v1/assignment/apply", tstRenderJson(plan), tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error and nothing has been changed")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "group.member.notfound", "attendee 1001 is not a member of group cats")
+	tstRequireRoomOccupants(t, roomA)
+}
+
+func TestAssignment_ApplyMissingGroup(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an empty room")
+	roomA := tstSetupAssignmentRoom(t, "A", 2, []string{})
+
+	docs.When("When an admin applies a plan that does not name the group of the attendees")
+	plan := modelsv1.AssignmentPlan{
+		Assignments: []modelsv1.Assignment{
+			{RoomID: roomA, BadgeNumbers: []int64{1000}},
+		},
+	}
+	response := tstPerformPost("/api/rest/v1/assignment/apply", tstRenderJson(plan), tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "assignment.data.invalid", "'' is not a valid group uuid")
+}
+
+func TestAssignment_ApplyFlagMismatchRefuse(t *testing.T) {
+	tstSetup(tstDefaultConfigFileFlagRules)
+	defer tstShutdown()
+
+	docs.Given("Given a room without the handicapped flag, and a group whose flags require it")
+	roomA := tstSetupAssignmentRoom(t, "A", 2, []string{})
+	tstSetupConcurrentAttendees()
+	bats := tstSetupAssignmentGroup(t, "bats", []string{"handicapped"}, 1000, 1001)
+
+	docs.Given("Given the configuration refuses flag mismatches")
+
+	docs.When("When an admin applies a modified plan that places the group in the room")
+	plan := modelsv1.AssignmentPlan{
+		Assignments: []modelsv1.Assignment{
+			{GroupID: bats, RoomID: roomA, BadgeNumbers: []int64{1000, 1001}},
+		},
+	}
+	response := tstPerformPost("/api/rest/v1/assignment/apply", tstRenderJson(plan), tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error and nothing has been changed")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "room.flags.mismatch", "group bats requires room flags room A does not have: handicapped")
+	tstRequireRoomOccupants(t, roomA)
+}
+
+func TestAssignment_ApplyGroupRoomFlagMismatchRefuse(t *testing.T) {
+	tstSetup(tstDefaultConfigFileFlagRules)
+	defer tstShutdown()
+
+	docs.Given("Given a room without the quiet flag, and a group with the quiet flag, which is also a room flag")
+	roomA := tstSetupAssignmentRoom(t, "A", 2, []string{})
+	tstSetupConcurrentAttendees()
+	owls := tstSetupAssignmentGroup(t, "owls", []string{"quiet"}, 1000, 1001)
+
+	docs.Given("Given the configuration refuses flag mismatches")
+
+	docs.When("When an admin applies a modified plan that places the group in the room")
+	plan := modelsv1.AssignmentPlan{
+		Assignments: []modelsv1.Assignment{
+			{GroupID: owls, RoomID: roomA, BadgeNumbers: []int64{1000, 1001}},
+		},
+	}
+	response := tstPerformPost("/api/rest/v1/assignment/apply", tstRenderJson(plan), tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error and nothing has been changed")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "room.flags.mismatch", "group owls requires room flags room A does not have: quiet")
+	tstRequireRoomOccupants(t, roomA)
+}
+
+func TestAssignment_ApplyInvalidJson(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.When("When an admin applies a plan, but supplies syntactically invalid JSON")
+	response := tstPerformPost("/api/rest/v1/assignment/apply", "{{{{", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "assignment.data.invalid", "invalid json provided")
+}
+
+func TestAssignment_ApplyUserDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an empty room and an authorized user with an active registration")
+	roomA := tstSetupAssignmentRoom(t, "A", 2, []string{})
+	registerSubject("101")
+	squirrels := tstSetupAssignmentGroup(t, "squirrels", []string{}, 42)
+
+	docs.When("When they attempt to apply a plan that places them in the room")
+	plan := modelsv1.AssignmentPlan{
+		Assignments: []modelsv1.Assignment{
+			{GroupID: squirrels, RoomID: roomA, BadgeNumbers: []int64{42}},
+		},
+	}
+	response := tstPerformPost("/api/rest/v1/assignment/apply", tstRenderJson(plan), tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is denied and nothing has been changed")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+	tstRequireRoomOccupants(t, roomA)
+}
+
+// --- unmet wishes ---
+
+func TestAssignment_UnmetWishesSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with wishes for a room type and size, in a small room without room type")
+	docs.Given("Given a group whose room meets its wishes, and a group with wishes that is not in a room yet")
+	quad := tstSetupRoomType(t, "quad", 4, []string{}, "")
+	roomA := tstSetupAssignmentRoom(t, "A", 2, []string{})
+	roomB := tstSetupAssignmentRoom(t, "B", 2, []string{"final"})
+	tstSetupConcurrentAttendees()
+	cats := tstSetupAssignmentGroup(t, "cats", []string{}, 1000, 1001)
+	dogs := tstSetupAssignmentGroup(t, "dogs", []string{}, 1002)
+	mice := tstSetupAssignmentGroup(t, "mice", []string{}, 1003)
+	tstSetupAssignmentWishes(t, cats, modelsv1.GroupWishes{Type: &quad, Size: 4, RoomFlags: []string{}})
+	tstSetupAssignmentWishes(t, dogs, modelsv1.GroupWishes{RoomFlags: []string{"final"}})
+	tstSetupAssignmentWishes(t, mice, modelsv1.GroupWishes{Size: 2})
+	for _, placement := range []struct {
+		roomID  string
+		badgeNo int64
+	}{{roomA, 1000}, {roomA, 1001}, {roomB, 1002}} {
+		response := tstPerformPostNoBody(fmt.Sprintf("/api/rest/v1/rooms/%s/occupants/%d", placement.roomID, placement.badgeNo), tstValidAdminToken(t))
+		require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	}
+
+	docs.When("When an admin requests the list of unmet wishes")
+	response := tstPerformGet("/api/rest/v1/assignment/unmet-wishes", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and only the group in the room that does not meet its wishes is listed")
+	list := modelsv1.UnmetWishList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &list)
+	expected := []modelsv1.UnmetWish{
+		{GroupID: cats, GroupName: "cats", Rooms: []string{"A"}, Unmet: []string{"type", "size"}},
+	}
+	require.Equal(t, expected, list.Groups)
+}
+
+func TestAssignment_UnmetWishesUserDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration")
+	registerSubject("101")
+
+	docs.When("When they request the list of unmet wishes")
+	response := tstPerformGet("/api/rest/v1/assignment/unmet-wishes", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+}
@@ -0,0 +1,399 @@
+package acceptance
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eurofurence/reg-room-service/docs"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+const validBlockLocationRegex = "^\\/api\\/rest\\/v1\\/blocks\\/[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$"
+
+// tstSetupBlock creates a block and returns its id. Leave staffStart empty for a block without staff early access.
+func tstSetupBlock(t *testing.T, name string, publicStart string, publicCode string, staffStart string, staffCode string) string {
+	blockSent := modelsv1.BlockCreate{
+		Name:              name,
+		PublicStart:       publicStart,
+		PublicBookingCode: publicCode,
+	}
+	if staffStart != "" {
+		blockSent.StaffStart = &staffStart
+		blockSent.StaffBookingCode = &staffCode
+	}
+	response := tstPerformPost("/api/rest/v1/blocks", tstRenderJson(blockSent), tstValidAdminToken(t))
+	require.Equal(t, http.StatusCreated, response.status, "unexpected http response status")
+	require.Regexp(t, validBlockLocationRegex, response.location, "invalid location header in response")
+	return tstBlockLocationToBlockID(response.location)
+}
+
+func tstBlockLocationToBlockID(location string) string {
+	return location[len("/api/rest/v1/blocks/"):]
+}
+
+func tstSetupRoomInBlock(t *testing.T, name string, blockID string) string {
+	roomSent := modelsv1.RoomCreate{
+		Name:  name,
+		Flags: []string{"final"},
+		Size:  2,
+		Block: &blockID,
+	}
+	response := tstPerformPost("/api/rest/v1/rooms", tstRenderJson(roomSent), tstValidAdminToken(t))
+	require.Equal(t, http.StatusCreated, response.status, "unexpected http response status")
+	return response.location
+}
+
+// --- create ---
+
+func TestBlocksCreate_AdminSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an admin")
+	token := tstValidAdminToken(t)
+
+	docs.When("When they create a block with valid data")
+	blockSent := modelsv1.BlockCreate{
+		Name:              "Elbe Hotel",
+		Comments:          p("the main hotel"),
+		PublicStart:       "2030-01-20T19:00:00+01:00",
+		PublicBookingCode: "Kaiser-Wilhelm-Koog",
+		StaffStart:        p("2030-01-19T19:00:00+01:00"),
+		StaffBookingCode:  p("Dithmarschen"),
+	}
+	response := tstPerformPost("/api/rest/v1/blocks", tstRenderJson(blockSent), token)
+
+	docs.Then("Then the block is successfully created")
+	require.Equal(t, http.StatusCreated, response.status, "unexpected http response status")
+	require.Regexp(t, validBlockLocationRegex, response.location, "invalid location header in response")
+
+	readAgain := tstPerformGet(response.location, token)
+	actual := modelsv1.Block{}
+	tstRequireSuccessResponse(t, readAgain, http.StatusOK, &actual)
+	expected := modelsv1.Block{
+		ID:                tstBlockLocationToBlockID(response.location),
+		Name:              blockSent.Name,
+		Comments:          blockSent.Comments,
+		PublicStart:       blockSent.PublicStart,
+		PublicBookingCode: blockSent.PublicBookingCode,
+		StaffStart:        blockSent.StaffStart,
+		StaffBookingCode:  blockSent.StaffBookingCode,
+	}
+	tstEqualResponseBodies(t, expected, actual)
+}
+
+func TestBlocksCreate_UserDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a user, who is not an admin")
+	token := tstValidUserToken(t, 101)
+
+	docs.When("When they try to create a block")
+	blockSent := modelsv1.BlockCreate{
+		Name:        "Elbe Hotel",
+		PublicStart: "2030-01-20T19:00:00+01:00",
+	}
+	response := tstPerformPost("/api/rest/v1/blocks", tstRenderJson(blockSent), token)
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+}
+
+func TestBlocksCreate_InvalidData(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an admin")
+	token := tstValidAdminToken(t)
+
+	docs.When("When they try to create a block with invalid data")
+	blockSent := modelsv1.BlockCreate{
+		Name:             "",
+		PublicStart:      "next tuesday",
+		StaffBookingCode: p("Dithmarschen"),
+	}
+	response := tstPerformPost("/api/rest/v1/blocks", tstRenderJson(blockSent), token)
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "block.data.invalid", url.Values{
+		"name":         []string{"block name cannot be empty"},
+		"public_start": []string{"public_start must be an ISO datetime such as 2024-01-20T19:00:00+01:00"},
+		"staff_start":  []string{"staff_start is required if there is a staff booking code"},
+	})
+}
+
+func TestBlocksCreate_DuplicateName(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an existing block")
+	tstSetupBlock(t, "Elbe Hotel", "2030-01-20T19:00:00+01:00", "Kaiser-Wilhelm-Koog", "", "")
+
+	docs.When("When an admin tries to create another block with the same name")
+	blockSent := modelsv1.BlockCreate{
+		Name:        "Elbe Hotel",
+		PublicStart: "2030-01-20T19:00:00+01:00",
+	}
+	response := tstPerformPost("/api/rest/v1/blocks", tstRenderJson(blockSent), tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "block.data.duplicate", "another block with this name already exists")
+}
+
+// --- read ---
+
+func TestBlocksList_AdminSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two existing blocks")
+	elbeID := tstSetupBlock(t, "Elbe Hotel", "2030-01-20T19:00:00+01:00", "Kaiser-Wilhelm-Koog", "", "")
+	alsterID := tstSetupBlock(t, "Alster Hotel", "2030-01-21T19:00:00+01:00", "Dithmarschen", "", "")
+
+	docs.When("When an admin lists the blocks")
+	response := tstPerformGet("/api/rest/v1/blocks", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the blocks are listed by name")
+	actual := modelsv1.BlockList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	expected := modelsv1.BlockList{
+		Blocks: []*modelsv1.Block{
+			{
+				ID:                alsterID,
+				Name:              "Alster Hotel",
+				PublicStart:       "2030-01-21T19:00:00+01:00",
+				PublicBookingCode: "Dithmarschen",
+			},
+			{
+				ID:                elbeID,
+				Name:              "Elbe Hotel",
+				PublicStart:       "2030-01-20T19:00:00+01:00",
+				PublicBookingCode: "Kaiser-Wilhelm-Koog",
+			},
+		},
+	}
+	tstEqualResponseBodies(t, expected, actual)
+}
+
+func TestBlocksList_UserDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an existing block and a user, who is not an admin")
+	tstSetupBlock(t, "Elbe Hotel", "2030-01-20T19:00:00+01:00", "Kaiser-Wilhelm-Koog", "", "")
+	token := tstValidUserToken(t, 101)
+
+	docs.When("When they try to list the blocks, which would reveal the booking codes")
+	response := tstPerformGet("/api/rest/v1/blocks", token)
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+}
+
+func TestBlocksGet_NotFound(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an admin")
+	token := tstValidAdminToken(t)
+
+	docs.When("When they request a block that does not exist")
+	response := tstPerformGet("/api/rest/v1/blocks/7a8c0d36-36fa-4b6b-9f3a-1e8c5e1f2d4b", token)
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "block.id.notfound", "block does not exist")
+}
+
+// --- update ---
+
+func TestBlocksUpdate_AdminSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an existing block")
+	blockID := tstSetupBlock(t, "Elbe Hotel", "2030-01-20T19:00:00+01:00", "Kaiser-Wilhelm-Koog", "2030-01-19T19:00:00+01:00", "Dithmarschen")
+
+	docs.When("When an admin renames the block, changes its booking code and removes staff early access")
+	blockSent := modelsv1.Block{
+		Name:              "Elbe Hotel Annex",
+		PublicStart:       "2030-01-20T19:00:00+01:00",
+		PublicBookingCode: "Linköping",
+	}
+	response := tstPerformPut("/api/rest/v1/blocks/"+blockID, tstRenderJson(blockSent), tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the block has been changed")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	readAgain := tstPerformGet("/api/rest/v1/blocks/"+blockID, tstValidAdminToken(t))
+	actual := modelsv1.Block{}
+	tstRequireSuccessResponse(t, readAgain, http.StatusOK, &actual)
+	blockSent.ID = blockID
+	tstEqualResponseBodies(t, blockSent, actual)
+}
+
+func TestBlocksUpdate_DuplicateName(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two existing blocks")
+	tstSetupBlock(t, "Elbe Hotel", "2030-01-20T19:00:00+01:00", "Kaiser-Wilhelm-Koog", "", "")
+	alsterID := tstSetupBlock(t, "Alster Hotel", "2030-01-21T19:00:00+01:00", "Dithmarschen", "", "")
+
+	docs.When("When an admin tries to give one of them the name of the other")
+	blockSent := modelsv1.Block{
+		Name:        "Elbe Hotel",
+		PublicStart: "2030-01-21T19:00:00+01:00",
+	}
+	response := tstPerformPut("/api/rest/v1/blocks/"+alsterID, tstRenderJson(blockSent), tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "block.data.duplicate", "another block with this name already exists")
+}
+
+// --- delete ---
+
+func TestBlocksDelete_AdminSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an existing block without rooms")
+	blockID := tstSetupBlock(t, "Elbe Hotel", "2030-01-20T19:00:00+01:00", "Kaiser-Wilhelm-Koog", "", "")
+
+	docs.When("When an admin deletes the block")
+	response := tstPerformDelete("/api/rest/v1/blocks/"+blockID, tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the block is gone")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	readAgain := tstPerformGet("/api/rest/v1/blocks/"+blockID, tstValidAdminToken(t))
+	tstRequireErrorResponse(t, readAgain, http.StatusNotFound, "block.id.notfound", "block does not exist")
+}
+
+func TestBlocksDelete_NotEmpty(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an existing block with a room")
+	blockID := tstSetupBlock(t, "Elbe Hotel", "2030-01-20T19:00:00+01:00", "Kaiser-Wilhelm-Koog", "", "")
+	tstSetupRoomInBlock(t, "101", blockID)
+
+	docs.When("When an admin tries to delete the block")
+	response := tstPerformDelete("/api/rest/v1/blocks/"+blockID, tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "block.not.empty", "block still has rooms - please delete them or move them to another block first")
+}
+
+func TestBlocksDelete_UserDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an existing block and a user, who is not an admin")
+	blockID := tstSetupBlock(t, "Elbe Hotel", "2030-01-20T19:00:00+01:00", "Kaiser-Wilhelm-Koog", "", "")
+	token := tstValidUserToken(t, 101)
+
+	docs.When("When they try to delete the block")
+	response := tstPerformDelete("/api/rest/v1/blocks/"+blockID, token)
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+}
+
+// --- rooms in blocks ---
+
+func TestBlocksRooms_CreateInBlock(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an existing block")
+	blockID := tstSetupBlock(t, "Elbe Hotel", "2030-01-20T19:00:00+01:00", "Kaiser-Wilhelm-Koog", "", "")
+
+	docs.When("When an admin creates a room in the block")
+	location := tstSetupRoomInBlock(t, "101", blockID)
+
+	docs.Then("Then the room belongs to the block")
+	room := tstReadRoom(t, location)
+	require.Equal(t, &blockID, room.Block)
+	require.Equal(t, p("Elbe Hotel"), room.BlockName)
+}
+
+func TestBlocksRooms_CreateInUnknownBlock(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an admin")
+	token := tstValidAdminToken(t)
+
+	docs.When("When they try to create a room in a block that does not exist")
+	roomSent := modelsv1.RoomCreate{
+		Name:  "101",
+		Flags: []string{},
+		Size:  2,
+		Block: p("7a8c0d36-36fa-4b6b-9f3a-1e8c5e1f2d4b"),
+	}
+	response := tstPerformPost("/api/rest/v1/rooms", tstRenderJson(roomSent), token)
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "room.data.invalid", url.Values{"block": []string{"no such block"}})
+}
+
+func TestBlocksRooms_SameNameInDifferentBlocks(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two blocks, one of which already has a room called 101")
+	elbeID := tstSetupBlock(t, "Elbe Hotel", "2030-01-20T19:00:00+01:00", "Kaiser-Wilhelm-Koog", "", "")
+	alsterID := tstSetupBlock(t, "Alster Hotel", "2030-01-21T19:00:00+01:00", "Dithmarschen", "", "")
+	tstSetupRoomInBlock(t, "101", elbeID)
+
+	docs.When("When an admin creates rooms called 101 in both blocks")
+	inOther := tstPerformPost("/api/rest/v1/rooms", tstRenderJson(modelsv1.RoomCreate{Name: "101", Flags: []string{}, Size: 2, Block: &alsterID}), tstValidAdminToken(t))
+	inSame := tstPerformPost("/api/rest/v1/rooms", tstRenderJson(modelsv1.RoomCreate{Name: "101", Flags: []string{}, Size: 2, Block: &elbeID}), tstValidAdminToken(t))
+
+	docs.Then("Then the room in the other block is created, but the duplicate within the same block is refused")
+	require.Equal(t, http.StatusCreated, inOther.status, "unexpected http response status")
+	tstRequireErrorResponse(t, inSame, http.StatusConflict, "room.data.duplicate", "another room with this name already exists")
+}
+
+func TestBlocksRooms_FilterByBlock(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given rooms in two different blocks")
+	elbeID := tstSetupBlock(t, "Elbe Hotel", "2030-01-20T19:00:00+01:00", "Kaiser-Wilhelm-Koog", "", "")
+	alsterID := tstSetupBlock(t, "Alster Hotel", "2030-01-21T19:00:00+01:00", "Dithmarschen", "", "")
+	elbeLocation := tstSetupRoomInBlock(t, "101", elbeID)
+	tstSetupRoomInBlock(t, "102", alsterID)
+
+	docs.When("When an admin lists the rooms of one block")
+	response := tstPerformGet("/api/rest/v1/rooms?block="+elbeID, tstValidAdminToken(t))
+
+	docs.Then("Then only the rooms in that block are listed")
+	actual := modelsv1.RoomList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Len(t, actual.Rooms, 1)
+	require.Equal(t, tstRoomLocationToRoomID(elbeLocation), actual.Rooms[0].ID)
+}
+
+func TestBlocksRooms_MyRoomShowsBlock(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a registered attendee in a finalized room that belongs to a block")
+	blockID := tstSetupBlock(t, "Elbe Hotel", "2030-01-20T19:00:00+01:00", "Kaiser-Wilhelm-Koog", "", "")
+	location := tstSetupRoomInBlock(t, "101", blockID)
+	registerSubject("101")
+	addResponse := tstPerformPostNoBody(location+"/occupants/42", tstValidAdminToken(t))
+	require.Equal(t, http.StatusNoContent, addResponse.status, "unexpected http response status")
+
+	docs.When("When the user requests their room")
+	response := tstPerformGet("/api/rest/v1/rooms/my", tstValidUserToken(t, 101))
+
+	docs.Then("Then the response includes the block of the room")
+	actual := modelsv1.Room{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Equal(t, &blockID, actual.Block)
+	require.Equal(t, p("Elbe Hotel"), actual.BlockName)
+}
@@ -0,0 +1,206 @@
+package acceptance
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eurofurence/reg-room-service/docs"
+	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/attendeeservice"
+)
+
+// These tests send many requests in parallel. Run them with the race detector (make test-race)
+// to find unsynchronized access.
+
+const tstConcurrentAttendees = 20
+
+// tstSetupConcurrentAttendees registers attendees with badge numbers 1000, 1001, ...
+func tstSetupConcurrentAttendees() []int64 {
+	badgeNumbers := make([]int64, tstConcurrentAttendees)
+	for i := range badgeNumbers {
+		badgeNo := int64(1000 + i)
+		attMock.SetupRegistered(fmt.Sprintf("%d", 5000+i), badgeNo, attendeeservice.StatusPaid,
+			fmt.Sprintf("Stressed%d", i), fmt.Sprintf("stressed%d@example.com", i))
+		badgeNumbers[i] = badgeNo
+	}
+	return badgeNumbers
+}
+
+// tstInParallel performs one request per badge number in parallel, also reading the given location
+// in parallel, and returns the response statuses in the order of the badge numbers.
+func tstInParallel(badgeNumbers []int64, readLocation string, token string, request func(badgeNo int64) tstWebResponse) []int {
+	statuses := make([]int, len(badgeNumbers))
+	wg := sync.WaitGroup{}
+	for i, badgeNo := range badgeNumbers {
+		wg.Add(2)
+		go func(i int, badgeNo int64) {
+			defer wg.Done()
+			statuses[i] = request(badgeNo).status
+		}(i, badgeNo)
+		go func() {
+			defer wg.Done()
+			_ = tstPerformGet(readLocation, token)
+		}()
+	}
+	wg.Wait()
+	return statuses
+}
+
+func tstCountStatus(statuses []int, wanted int) int {
+	count := 0
+	for _, status := range statuses {
+		if status == wanted {
+			count++
+		}
+	}
+	return count
+}
+
+func TestConcurrency_RoomOccupantsAddRemove(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with 2 free beds")
+	location := setupExistingRoom(t, "31415", false)
+
+	docs.Given("Given many attendees with an active registration who are not in any room")
+	badgeNumbers := tstSetupConcurrentAttendees()
+
+	docs.Given("Given an admin")
+	token := tstValidAdminToken(t)
+
+	docs.When("When the admin adds all the attendees to the room in parallel")
+	statuses := tstInParallel(badgeNumbers, location, token, func(badgeNo int64) tstWebResponse {
+		return tstPerformPostNoBody(fmt.Sprintf("%s/occupants/%d", location, badgeNo), token)
+	})
+
+	docs.Then("Then exactly two requests are successful and all others fail because the room is full")
+	require.Equal(t, 2, tstCountStatus(statuses, http.StatusNoContent))
+	require.Equal(t, len(badgeNumbers)-2, tstCountStatus(statuses, http.StatusConflict))
+
+	docs.Then("And the room contains exactly two occupants")
+	require.Equal(t, 2, len(tstReadRoom(t, location).Occupants))
+
+	docs.When("When the admin removes all the attendees from the room in parallel")
+	statuses = tstInParallel(badgeNumbers, location, token, func(badgeNo int64) tstWebResponse {
+		return tstPerformDelete(fmt.Sprintf("%s/occupants/%d", location, badgeNo), token)
+	})
+
+	docs.Then("Then exactly two requests are successful and all others fail because the attendee is not in the room")
+	require.Equal(t, 2, tstCountStatus(statuses, http.StatusNoContent))
+	require.Equal(t, len(badgeNumbers)-2, tstCountStatus(statuses, http.StatusNotFound))
+
+	docs.Then("And the room is empty")
+	tstRoomState(t, location)
+}
+
+func TestConcurrency_GroupMembersInviteRemove(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is owner of a group")
+	id1 := setupExistingGroup(t, "kittens", true, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	token := tstValidUserToken(t, 101)
+
+	docs.Given("Given many attendees with an active registration who are not in any group")
+	badgeNumbers := tstSetupConcurrentAttendees()
+
+	docs.When("When the group owner invites all the attendees in parallel")
+	statuses := tstInParallel(badgeNumbers, groupLocation, token, func(badgeNo int64) tstWebResponse {
+		return tstPerformPostNoBody(fmt.Sprintf("%s/members/%d?nickname=Stressed%d", groupLocation, badgeNo, badgeNo-1000), token)
+	})
+
+	docs.Then("Then only as many invitations are created as the maximum group size allows")
+	require.Equal(t, 5, tstCountStatus(statuses, http.StatusNoContent))
+	require.Equal(t, len(badgeNumbers)-5, tstCountStatus(statuses, http.StatusConflict))
+
+	docs.Then("And the group contains the owner and exactly five invitations")
+	group := tstReadGroup(t, groupLocation)
+	require.Equal(t, 1, len(group.Members))
+	require.Equal(t, 5, len(group.Invites))
+
+	docs.When("When the group owner removes all the attendees in parallel")
+	statuses = tstInParallel(badgeNumbers, groupLocation, token, func(badgeNo int64) tstWebResponse {
+		return tstPerformDelete(fmt.Sprintf("%s/members/%d", groupLocation, badgeNo), token)
+	})
+
+	docs.Then("Then exactly the five invitations are removed")
+	require.Equal(t, 5, tstCountStatus(statuses, http.StatusNoContent))
+
+	docs.Then("And the group contains only the owner again")
+	group = tstReadGroup(t, groupLocation)
+	require.Equal(t, 1, len(group.Members))
+	require.Equal(t, 0, len(group.Invites))
+}
+
+func TestConcurrency_GroupMembersAndRoomOccupantsAdminForce(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group and a room")
+	id1 := setupExistingGroup(t, "kittens", true, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	roomLocation := setupExistingRoom(t, "31415", false)
+
+	docs.Given("Given many attendees with an active registration who are not in any group or room")
+	badgeNumbers := tstSetupConcurrentAttendees()
+
+	docs.Given("Given an admin")
+	token := tstValidAdminToken(t)
+
+	docs.When("When the admin force adds all attendees to the group while also adding them to the room, all in parallel")
+	roomStatuses := make([]int, 0)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		roomStatuses = tstInParallel(badgeNumbers, "/api/rest/v1/rooms", token, func(badgeNo int64) tstWebResponse {
+			return tstPerformPostNoBody(fmt.Sprintf("%s/occupants/%d", roomLocation, badgeNo), token)
+		})
+	}()
+	groupStatuses := tstInParallel(badgeNumbers, "/api/rest/v1/groups", token, func(badgeNo int64) tstWebResponse {
+		return tstPerformPostNoBody(fmt.Sprintf("%s/members/%d?force=true", groupLocation, badgeNo), token)
+	})
+	wg.Wait()
+
+	docs.Then("Then all group additions are successful, even though they exceed the maximum group size")
+	require.Equal(t, len(badgeNumbers), tstCountStatus(groupStatuses, http.StatusNoContent))
+	require.Equal(t, len(badgeNumbers)+1, len(tstReadGroup(t, groupLocation).Members))
+
+	docs.Then("And the room has been filled but not overfilled")
+	require.Equal(t, 2, tstCountStatus(roomStatuses, http.StatusNoContent))
+	require.Equal(t, 2, len(tstReadRoom(t, roomLocation).Occupants))
+}
+
+func TestConcurrency_AttendeeStatusEventDuplicates(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group and a room with an attendee whose registration has just been cancelled")
+	groupLocation, roomLocation := tstSetupReconciliationData(t)
+	attMock.SetupRegistered("202", 43, attendeeservice.StatusCancelled, "Snep", "snep@example.com")
+
+	docs.When("When the attendee service delivers the same status change event many times in parallel")
+	deliveries := make([]int64, tstConcurrentAttendees)
+	body := tstAttendeeStatusEvent("ev-0001", 43, attendeeservice.StatusPaid, attendeeservice.StatusCancelled, "")
+	statuses := tstInParallel(deliveries, roomLocation, tstValidAdminToken(t), func(_ int64) tstWebResponse {
+		return tstPerformPost("/api/rest/v1/reconciliation/attendee-events", body, tstValidApiToken())
+	})
+
+	docs.Then("Then all deliveries are successful")
+	require.Equal(t, len(deliveries), tstCountStatus(statuses, http.StatusNoContent))
+
+	docs.Then("And the attendee has been removed from the group and the room")
+	require.Len(t, tstReadGroup(t, groupLocation).Members, 1)
+	require.Empty(t, tstReadRoom(t, roomLocation).Occupants)
+
+	docs.Then("And the event has been processed only once, so the mails have been sent only once")
+	tstRequireMailRequests(t,
+		tstGroupMailToOwner("group-member-removed", "kittens", "101", "202"),
+		tstRoomMailToOccupant("room-occupant-removed", "31415", "202"))
+}
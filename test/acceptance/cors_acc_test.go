@@ -11,8 +11,9 @@ import (
 
 func TestCountdownNoCors(t *testing.T) {
 	docs.Given("given a valid configuration for production")
-	tstSetup(tstDefaultConfigFileBeforeLaunch)
+	tstSetup(tstDefaultConfigFile)
 	defer tstShutdown()
+	tstSetupBlock(t, "Elbe Hotel", "3021-12-31T23:59:59+01:00", "Kaiser-Wilhelm-Koog", "", "")
 
 	docs.When("when they request the countdown resource")
 	response := tstPerformGet("/api/rest/v1/countdown", "")
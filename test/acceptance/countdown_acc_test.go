@@ -15,9 +15,10 @@ import (
 // ------------------------------------------
 
 func TestCountdownBeforeLaunch(t *testing.T) {
-	docs.Given("given a launch date in the future")
-	tstSetup(tstDefaultConfigFileBeforeLaunch)
+	docs.Given("given a block with a launch date in the future")
+	tstSetup(tstDefaultConfigFile)
 	defer tstShutdown()
+	blockID := tstSetupBlock(t, "Elbe Hotel", "3021-12-31T23:59:59+01:00", "Kaiser-Wilhelm-Koog", "", "")
 
 	docs.When("when they request the countdown resource before the launch time has been reached")
 	response := tstPerformGet("/api/rest/v1/countdown", "")
@@ -31,12 +32,15 @@ func TestCountdownBeforeLaunch(t *testing.T) {
 	require.Equal(t, "3021-12-31T23:59:59+01:00", responseDto.TargetTimeIsoDateTime, "unexpected target time")
 	require.NotNil(t, responseDto.CurrentTimeIsoDateTime, "unexpected current time is nil")
 	require.Equal(t, "", responseDto.Secret, "unexpected secret is not empty")
+	require.Equal(t, blockID, responseDto.Block, "unexpected block")
+	require.Equal(t, "Elbe Hotel", responseDto.BlockName, "unexpected block name")
 }
 
 func TestCountdownAfterPublicLaunch(t *testing.T) {
-	docs.Given("given a public launch date in the past")
-	tstSetup(tstDefaultConfigFileAfterPublicLaunch)
+	docs.Given("given a block with a public launch date in the past")
+	tstSetup(tstDefaultConfigFile)
 	defer tstShutdown()
+	tstSetupBlock(t, "Elbe Hotel", "2020-12-31T23:59:59+01:00", "Kaiser-Wilhelm-Koog", "2020-12-30T23:59:59+01:00", "Dithmarschen")
 
 	docs.When("when they request the countdown resource after the public launch time has been reached")
 	response := tstPerformGet("/api/rest/v1/countdown", "")
@@ -53,14 +57,15 @@ func TestCountdownAfterPublicLaunch(t *testing.T) {
 }
 
 func TestCountdownAfterStaffLaunchWithoutStaffClaim(t *testing.T) {
-	docs.Given("given a staff launch date in the past")
-	tstSetup(tstDefaultConfigFileAfterStaffLaunch)
+	docs.Given("given a block with a staff launch date in the past")
+	tstSetup(tstDefaultConfigFile)
 	defer tstShutdown()
+	tstSetupBlock(t, "Elbe Hotel", "3021-12-31T23:59:59+01:00", "Kaiser-Wilhelm-Koog", "2020-12-31T23:59:59+01:00", "Dithmarschen")
 
 	docs.When("when they request the countdown resource after the staff launch time has been reached")
 	response := tstPerformGet("/api/rest/v1/countdown", "")
 
-	docs.Then("then a valid response is sent with countdown <= 0 that includes the secret")
+	docs.Then("then a valid response is sent with countdown > 0 that does not include the secret")
 	require.Equal(t, http.StatusOK, response.status, "unexpected http response status")
 	responseDto := v1.Countdown{}
 	tstParseJson(response.body, &responseDto)
@@ -72,9 +77,10 @@ func TestCountdownAfterStaffLaunchWithoutStaffClaim(t *testing.T) {
 }
 
 func TestCountdownAfterStaffLaunchWithStaffClaim(t *testing.T) {
-	docs.Given("given a staff launch date in the past")
-	tstSetup(tstDefaultConfigFileAfterStaffLaunch)
+	docs.Given("given a block with a staff launch date in the past")
+	tstSetup(tstDefaultConfigFile)
 	defer tstShutdown()
+	tstSetupBlock(t, "Elbe Hotel", "3021-12-31T23:59:59+01:00", "Kaiser-Wilhelm-Koog", "2020-12-31T23:59:59+01:00", "Dithmarschen")
 
 	docs.When("when they request the countdown resource after the staff launch time has been reached")
 	response := tstPerformGet("/api/rest/v1/countdown", valid_JWT_is_staff_sub202)
@@ -91,9 +97,10 @@ func TestCountdownAfterStaffLaunchWithStaffClaim(t *testing.T) {
 }
 
 func TestCountdownBeforeLaunchWithMockTime(t *testing.T) {
-	docs.Given("given a launch date in the future")
-	tstSetup(tstDefaultConfigFileBeforeLaunch)
+	docs.Given("given a block with a launch date in the future")
+	tstSetup(tstDefaultConfigFile)
 	defer tstShutdown()
+	tstSetupBlock(t, "Elbe Hotel", "3021-12-31T23:59:59+01:00", "Kaiser-Wilhelm-Koog", "", "")
 
 	docs.When("when they request the countdown resource before the launch time has been reached using a mock time in the future")
 	response := tstPerformGet("/api/rest/v1/countdown?currentTimeIso=3022-12-31T23:59:59%2B01:00", "")
@@ -109,12 +116,115 @@ func TestCountdownBeforeLaunchWithMockTime(t *testing.T) {
 	require.Equal(t, "[demo-secret]", responseDto.Secret, "unexpected secret is not demo secret")
 }
 
+func TestCountdownSelectsEarliestBlock(t *testing.T) {
+	docs.Given("given two blocks, one of which opens earlier than the other")
+	tstSetup(tstDefaultConfigFile)
+	defer tstShutdown()
+	tstSetupBlock(t, "Alster Hotel", "3021-12-31T23:59:59+01:00", "Kaiser-Wilhelm-Koog", "", "")
+	earlyID := tstSetupBlock(t, "Elbe Hotel", "2020-12-31T23:59:59+01:00", "Dithmarschen", "", "")
+
+	docs.When("when they request the countdown resource without specifying a block")
+	response := tstPerformGet("/api/rest/v1/countdown", "")
+
+	docs.Then("then the countdown is for the block that opens first")
+	require.Equal(t, http.StatusOK, response.status, "unexpected http response status")
+	responseDto := v1.Countdown{}
+	tstParseJson(response.body, &responseDto)
+
+	require.Equal(t, earlyID, responseDto.Block, "unexpected block")
+	require.Equal(t, "Dithmarschen", responseDto.Secret, "unexpected secret")
+}
+
+func TestCountdownForSpecificBlock(t *testing.T) {
+	docs.Given("given two blocks, one of which opens earlier than the other")
+	tstSetup(tstDefaultConfigFile)
+	defer tstShutdown()
+	lateID := tstSetupBlock(t, "Alster Hotel", "3021-12-31T23:59:59+01:00", "Kaiser-Wilhelm-Koog", "", "")
+	tstSetupBlock(t, "Elbe Hotel", "2020-12-31T23:59:59+01:00", "Dithmarschen", "", "")
+
+	docs.When("when they request the countdown resource for the block that opens later")
+	response := tstPerformGet("/api/rest/v1/countdown?block="+lateID, "")
+
+	docs.Then("then the countdown is for that block and does not include its secret")
+	require.Equal(t, http.StatusOK, response.status, "unexpected http response status")
+	responseDto := v1.Countdown{}
+	tstParseJson(response.body, &responseDto)
+
+	require.Equal(t, lateID, responseDto.Block, "unexpected block")
+	require.Equal(t, "Alster Hotel", responseDto.BlockName, "unexpected block name")
+	require.Equal(t, "3021-12-31T23:59:59+01:00", responseDto.TargetTimeIsoDateTime, "unexpected target time")
+	require.Equal(t, "", responseDto.Secret, "unexpected secret is not empty")
+}
+
+func TestCountdownNoBlocks(t *testing.T) {
+	docs.Given("given no blocks have been set up")
+	tstSetup(tstDefaultConfigFile)
+	defer tstShutdown()
+
+	docs.When("when they request the countdown resource")
+	response := tstPerformGet("/api/rest/v1/countdown", "")
+
+	docs.Then("then the request fails with the appropriate error")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "block.id.notfound", "no blocks have been set up yet")
+}
+
+func TestCountdownNoBlocksDeprecatedGoLive(t *testing.T) {
+	docs.Given("given no blocks have been set up, but the deprecated go_live configuration has a staff launch date in the past")
+	tstSetup(tstDefaultConfigFileGoLive)
+	defer tstShutdown()
+
+	docs.When("when staff request the countdown resource")
+	response := tstPerformGet("/api/rest/v1/countdown", valid_JWT_is_staff_sub202)
+
+	docs.Then("then a valid response is sent from the configuration, using the staff group from go_live, that includes the staff secret")
+	require.Equal(t, http.StatusOK, response.status, "unexpected http response status")
+	responseDto := v1.Countdown{}
+	tstParseJson(response.body, &responseDto)
+
+	require.True(t, responseDto.CountdownSeconds <= 0, "unexpected countdown value is not negative")
+	require.Equal(t, "2020-12-31T23:59:59+01:00", responseDto.TargetTimeIsoDateTime, "unexpected target time")
+	require.Equal(t, "Dithmarschen", responseDto.Secret, "unexpected secret")
+	require.Equal(t, "", responseDto.Block, "unexpected block")
+}
+
+func TestCountdownBlocksOverrideDeprecatedGoLive(t *testing.T) {
+	docs.Given("given the deprecated go_live configuration, and a block that has been set up")
+	tstSetup(tstDefaultConfigFileGoLive)
+	defer tstShutdown()
+	blockID := tstSetupBlock(t, "Elbe Hotel", "3022-12-31T23:59:59+01:00", "Husum", "", "")
+
+	docs.When("when they request the countdown resource")
+	response := tstPerformGet("/api/rest/v1/countdown", "")
+
+	docs.Then("then the countdown is for the block, the configuration is ignored")
+	require.Equal(t, http.StatusOK, response.status, "unexpected http response status")
+	responseDto := v1.Countdown{}
+	tstParseJson(response.body, &responseDto)
+
+	require.Equal(t, "3022-12-31T23:59:59+01:00", responseDto.TargetTimeIsoDateTime, "unexpected target time")
+	require.Equal(t, blockID, responseDto.Block, "unexpected block")
+}
+
+func TestCountdownInvalidBlock(t *testing.T) {
+	docs.Given("given a block")
+	tstSetup(tstDefaultConfigFile)
+	defer tstShutdown()
+	tstSetupBlock(t, "Elbe Hotel", "3021-12-31T23:59:59+01:00", "Kaiser-Wilhelm-Koog", "", "")
+
+	docs.When("when they request the countdown resource for an invalid block id")
+	response := tstPerformGet("/api/rest/v1/countdown?block=kittycat", "")
+
+	docs.Then("then the request fails with the appropriate error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "block.id.invalid", "you must specify a valid uuid")
+}
+
 // security tests
 
 func TestCountdownBeforeLaunch_DenyNonStaffToken(t *testing.T) {
-	docs.Given("given a launch date in the future")
-	tstSetup(tstDefaultConfigFileBeforeLaunch)
+	docs.Given("given a block with a launch date in the future")
+	tstSetup(tstDefaultConfigFile)
 	defer tstShutdown()
+	tstSetupBlock(t, "Elbe Hotel", "3021-12-31T23:59:59+01:00", "Kaiser-Wilhelm-Koog", "2020-12-31T23:59:59+01:00", "Dithmarschen")
 
 	docs.When("when they request the countdown resource before the launch time has been reached, using a non-staff token")
 	response := tstPerformGet("/api/rest/v1/countdown", valid_JWT_is_registered_sub101)
@@ -0,0 +1,240 @@
+package acceptance
+
+import (
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eurofurence/reg-room-service/docs"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// --- list ---
+
+func TestGroupsBans_OwnerListEmpty(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is owner of a group")
+	id1 := setupExistingGroup(t, "kittens", false, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When they request the auto-decline list of the group")
+	response := tstPerformGet(groupLocation+"/bans", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is successful and the list is empty")
+	bans := modelsv1.GroupBanList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &bans)
+	require.Empty(t, bans.Bans)
+}
+
+func TestGroupsBans_OwnerListAfterAutoDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is owner of a group")
+	docs.Given("Given another attendee who has been kicked from the group with autodeny")
+	id1 := setupExistingGroup(t, "kittens", false, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	token := tstValidUserToken(t, 101)
+	require.Equal(t, http.StatusNoContent, tstPerformDelete(groupLocation+"/members/43?autodeny=true", token).status)
+
+	docs.When("When the group owner requests the auto-decline list of the group")
+	response := tstPerformGet(groupLocation+"/bans", token)
+
+	docs.Then("Then the request is successful and the list contains the attendee, without comments")
+	bans := modelsv1.GroupBanList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &bans)
+	require.Equal(t, []modelsv1.GroupBan{{ID: 43}}, bans.Bans)
+}
+
+func TestGroupsBans_AdminListWithComments(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with an auto-decline list entry added by an admin")
+	id1 := setupExistingGroup(t, "kittens", false, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	token := tstValidAdminToken(t)
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody(groupLocation+"/bans/43", token).status)
+
+	docs.When("When an admin requests the auto-decline list of the group")
+	response := tstPerformGet(groupLocation+"/bans", token)
+
+	docs.Then("Then the request is successful and the list contains the attendee, including comments")
+	bans := modelsv1.GroupBanList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &bans)
+	require.Equal(t, []modelsv1.GroupBan{{ID: 43, Comments: p("group ban added by 1234567890")}}, bans.Bans)
+}
+
+func TestGroupsBans_MemberListDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is a member of a group, but not its owner")
+	id1 := setupExistingGroup(t, "kittens", false, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When they request the auto-decline list of the group")
+	response := tstPerformGet(groupLocation+"/bans", tstValidUserToken(t, 202))
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "only the group owner or an admin can manage the auto-decline list of a group")
+}
+
+func TestGroupsBans_AnonymousListDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group")
+	id1 := setupExistingGroup(t, "kittens", false, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When an anonymous user requests the auto-decline list of the group")
+	response := tstPerformGet(groupLocation+"/bans", tstNoToken())
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusUnauthorized, "auth.unauthorized", "you must be logged in for this operation")
+}
+
+func TestGroupsBans_ListGroupNotFound(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.When("When an admin requests the auto-decline list of a group that does not exist")
+	response := tstPerformGet("/api/rest/v1/groups/7a8b9c0d-1e2f-4a5b-8c7d-9e0f1a2b3c4d/bans", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "group.id.notfound", "this group does not exist")
+}
+
+// --- add ---
+
+func TestGroupsBans_OwnerAddSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is owner of a group")
+	id1 := setupExistingGroup(t, "kittens", true, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.Given("Given another attendee with an active registration who is not in any group")
+	registerSubject("202")
+
+	docs.When("When the group owner adds the attendee to the auto-decline list")
+	response := tstPerformPostNoBody(groupLocation+"/bans/43", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+
+	docs.Then("And the attendee can no longer request to join the group")
+	response = tstPerformPostNoBody(groupLocation+"/members/43", tstValidUserToken(t, 202))
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you cannot join this group - please stop trying")
+}
+
+func TestGroupsBans_AddDuplicate(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with an auto-decline list entry")
+	id1 := setupExistingGroup(t, "kittens", false, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	token := tstValidUserToken(t, 101)
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody(groupLocation+"/bans/43", token).status)
+
+	docs.When("When the group owner attempts to add the same attendee to the auto-decline list again")
+	response := tstPerformPostNoBody(groupLocation+"/bans/43", token)
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "group.ban.duplicate", "this attendee is already on the auto-decline list of this group")
+}
+
+func TestGroupsBans_MemberAddDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is a member of a group, but not its owner")
+	id1 := setupExistingGroup(t, "kittens", false, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When they attempt to add an attendee to the auto-decline list of the group")
+	response := tstPerformPostNoBody(groupLocation+"/bans/84", tstValidUserToken(t, 202))
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "only the group owner or an admin can manage the auto-decline list of a group")
+
+	docs.Then("And the auto-decline list is unchanged")
+	bans := modelsv1.GroupBanList{}
+	tstRequireSuccessResponse(t, tstPerformGet(groupLocation+"/bans", tstValidAdminToken(t)), http.StatusOK, &bans)
+	require.Empty(t, bans.Bans)
+}
+
+func TestGroupsBans_AddBadgeNumberInvalid(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is owner of a group")
+	id1 := setupExistingGroup(t, "kittens", false, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When they attempt to add an invalid badge number to the auto-decline list")
+	response := tstPerformPostNoBody(groupLocation+"/bans/floof", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "request.parse.failed", "invalid badge number - must be positive integer")
+}
+
+// --- remove ---
+
+func TestGroupsBans_OwnerRemoveSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is owner of a group")
+	docs.Given("Given another attendee who has been kicked from the group with autodeny")
+	id1 := setupExistingGroup(t, "kittens", true, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	token := tstValidUserToken(t, 101)
+	require.Equal(t, http.StatusNoContent, tstPerformDelete(groupLocation+"/members/43?autodeny=true", token).status)
+
+	docs.When("When the group owner removes the attendee from the auto-decline list")
+	response := tstPerformDelete(groupLocation+"/bans/43", token)
+
+	docs.Then("Then the request is successful and the auto-decline list is empty")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	bans := modelsv1.GroupBanList{}
+	tstRequireSuccessResponse(t, tstPerformGet(groupLocation+"/bans", token), http.StatusOK, &bans)
+	require.Empty(t, bans.Bans)
+
+	docs.Then("And the attendee can request to join the group again")
+	response = tstPerformPostNoBody(groupLocation+"/members/43", tstValidUserToken(t, 202))
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+}
+
+func TestGroupsBans_AdminRemoveNotFound(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with an empty auto-decline list")
+	id1 := setupExistingGroup(t, "kittens", false, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When an admin attempts to remove an attendee from the auto-decline list")
+	response := tstPerformDelete(groupLocation+"/bans/43", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "group.ban.notfound", "this attendee is not on the auto-decline list of this group")
+}
+
+func TestGroupsBans_RemoveInvalidGroupID(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.When("When an admin attempts to remove an auto-decline list entry, but supplies an invalid group id")
+	response := tstPerformDelete("/api/rest/v1/groups/kittens/bans/43", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "group.id.invalid", "'kittens' is not a valid UUID")
+}
@@ -30,6 +30,25 @@ func TestGroupsDelete_AdminSuccess(t *testing.T) {
 	require.Equal(t, http.StatusNotFound, user202group.status, "unexpected http response status")
 }
 
+func TestGroupsDelete_AdminSuccessWithBans(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with an auto-decline list entry")
+	id1 := setupExistingGroup(t, "kittens", true, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	token := tstValidAdminToken(t)
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody(groupLocation+"/bans/43", token).status)
+
+	docs.When("When an admin deletes the group")
+	response := tstPerformDelete(groupLocation, token)
+
+	docs.Then("Then the group is successfully deleted together with its auto-decline list")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	getResponse := tstPerformGet(groupLocation, token)
+	require.Equal(t, http.StatusNotFound, getResponse.status, "unexpected http response status")
+}
+
 func TestGroupsDelete_AnonymousDeny(t *testing.T) {
 	tstSetup(tstDefaultConfigFileRoomGroups)
 	defer tstShutdown()
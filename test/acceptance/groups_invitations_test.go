@@ -0,0 +1,329 @@
+package acceptance
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eurofurence/reg-room-service/docs"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/attendeeservice"
+)
+
+// tstSetupInvitation lets the owner of the group (subject 101) invite the panther (badge 84), and returns the join link.
+func tstSetupInvitation(t *testing.T, groupLocation string) string {
+	attMock.SetupRegistered("1234567890", 84, attendeeservice.StatusApproved, "Panther", "panther@example.com")
+	response := tstPerformPostNoBody(groupLocation+"/members/84?nickname=Panther", tstValidUserToken(t, 101))
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	mailMock.Reset()
+	return response.location
+}
+
+// tstExpireInvitation moves the time an invitation was sent into the past, beyond the configured lifetime of 72 hours.
+func tstExpireInvitation(t *testing.T, badgeNo int64) {
+	gm, err := db.GetGroupMembershipByAttendeeID(context.TODO(), badgeNo)
+	require.NoError(t, err)
+	invitedAt := time.Now().Add(-73 * time.Hour)
+	gm.InvitedAt = &invitedAt
+	require.NoError(t, db.UpdateGroupMembership(context.TODO(), gm))
+}
+
+func tstRequireInvites(t *testing.T, groupLocation string, expectedBadgeNumbers ...int64) {
+	group := modelsv1.Group{}
+	tstRequireSuccessResponse(t, tstPerformGet(groupLocation, tstValidAdminToken(t)), http.StatusOK, &group)
+	actual := make([]int64, 0)
+	for _, invite := range group.Invites {
+		actual = append(actual, invite.ID)
+	}
+	require.ElementsMatch(t, expectedBadgeNumbers, actual)
+}
+
+// --- expiry ---
+
+func TestGroupsInvitations_AcceptExpired(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an attendee who was invited to a group, but the invitation has expired")
+	id1 := setupExistingGroup(t, "kittens", false, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	joinLink := tstSetupInvitation(t, groupLocation)
+	tstExpireInvitation(t, 84)
+
+	docs.When("When they attempt to accept the invitation using the personalized join link")
+	response := tstPerformPostNoBody(joinLink, tstValidUserToken(t, 1234567890))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "group.invite.expired", "this invitation has expired - please ask the group owner to send it again")
+
+	docs.Then("And no mails have been sent")
+	tstRequireMailRequests(t)
+}
+
+func TestGroupsInvitations_ExpiredNotCountedTowardsSize(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with maximum size 2, whose owner has invited another attendee, but the invitation has expired")
+	registerSubject("101")
+	groupSent := modelsv1.GroupCreate{
+		Name:        "kittens",
+		Flags:       []string{},
+		MaximumSize: 2,
+		Owner:       42,
+	}
+	created := tstPerformPost("/api/rest/v1/groups", tstRenderJson(groupSent), tstValidAdminToken(t))
+	require.Equal(t, http.StatusCreated, created.status, "unexpected http response status")
+	groupLocation := created.location
+	_ = tstSetupInvitation(t, groupLocation)
+	tstExpireInvitation(t, 84)
+
+	docs.When("When the owner invites yet another attendee")
+	registerSubject("202")
+	response := tstPerformPostNoBody(groupLocation+"/members/43?nickname=Snep", tstValidUserToken(t, 101))
+
+	docs.Then("Then the invitation is successfully created, because the expired invitation does not count towards the group size")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	tstRequireInvites(t, groupLocation, 43, 84)
+}
+
+// --- resend ---
+
+func TestGroupsInvitations_OwnerResendSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an attendee who was invited to a group, but the invitation has expired")
+	id1 := setupExistingGroup(t, "kittens", false, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	joinLink := tstSetupInvitation(t, groupLocation)
+	tstExpireInvitation(t, 84)
+
+	docs.When("When the group owner resends the invitation")
+	response := tstPerformPostNoBody(groupLocation+"/members/84/resend", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+
+	docs.Then("And the invitation mail has been sent again, with the same join link")
+	tstRequireMailRequests(t,
+		tstGroupMailToMember("group-invited", "kittens", "1234567890", "101", joinLink))
+
+	docs.Then("And the invited attendee can now accept the invitation")
+	joinResponse := tstPerformPostNoBody(joinLink, tstValidUserToken(t, 1234567890))
+	require.Equal(t, http.StatusNoContent, joinResponse.status, "unexpected http response status")
+}
+
+func TestGroupsInvitations_ResendExpiredGroupFull(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with maximum size 2, whose owner has invited another attendee, but the invitation has expired")
+	registerSubject("101")
+	groupSent := modelsv1.GroupCreate{
+		Name:        "kittens",
+		Flags:       []string{},
+		MaximumSize: 2,
+		Owner:       42,
+	}
+	created := tstPerformPost("/api/rest/v1/groups", tstRenderJson(groupSent), tstValidAdminToken(t))
+	require.Equal(t, http.StatusCreated, created.status, "unexpected http response status")
+	groupLocation := created.location
+	tstSetupGroupMaximumSize(t, groupLocation, 2)
+	_ = tstSetupInvitation(t, groupLocation)
+	tstExpireInvitation(t, 84)
+
+	docs.Given("Given the group has since been filled up by another invitation")
+	registerSubject("202")
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody(groupLocation+"/members/43?nickname=Snep", tstValidUserToken(t, 101)).status)
+	mailMock.Reset()
+
+	docs.When("When the group owner resends the expired invitation")
+	response := tstPerformPostNoBody(groupLocation+"/members/84/resend", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request fails with the appropriate error")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "group.size.full", "this group is full - members and outstanding invitations have reached the maximum group size")
+
+	docs.Then("And the invitation is still expired and no mail has been sent")
+	tstRequireMailRequests(t)
+	gm, err := db.GetGroupMembershipByAttendeeID(context.TODO(), 84)
+	require.NoError(t, err)
+	require.True(t, gm.InvitedAt.Before(time.Now().Add(-72*time.Hour)), "expired invitation must not have been revived")
+}
+
+func TestGroupsInvitations_OwnerAddExpiredGroupFull(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with maximum size 2, whose owner has invited another attendee, but the invitation has expired")
+	registerSubject("101")
+	groupSent := modelsv1.GroupCreate{
+		Name:        "kittens",
+		Flags:       []string{},
+		MaximumSize: 2,
+		Owner:       42,
+	}
+	created := tstPerformPost("/api/rest/v1/groups", tstRenderJson(groupSent), tstValidAdminToken(t))
+	require.Equal(t, http.StatusCreated, created.status, "unexpected http response status")
+	groupLocation := created.location
+	tstSetupGroupMaximumSize(t, groupLocation, 2)
+	_ = tstSetupInvitation(t, groupLocation)
+	tstExpireInvitation(t, 84)
+
+	docs.Given("Given the group has since been filled up by another invitation")
+	registerSubject("202")
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody(groupLocation+"/members/43?nickname=Snep", tstValidUserToken(t, 101)).status)
+	mailMock.Reset()
+
+	docs.When("When the group owner directly adds the attendee with the expired invitation")
+	response := tstPerformPostNoBody(groupLocation+"/members/84", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request fails with the appropriate error")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "group.size.full", "this group is full - members and outstanding invitations have reached the maximum group size")
+
+	docs.Then("And the attendee is still only invited and no mail has been sent")
+	tstRequireMailRequests(t)
+	tstRequireInvites(t, groupLocation, 43, 84)
+}
+
+func TestGroupsInvitations_AdminResendSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an attendee who was invited to a group")
+	id1 := setupExistingGroup(t, "kittens", false, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	joinLink := tstSetupInvitation(t, groupLocation)
+
+	docs.When("When an admin resends the invitation")
+	response := tstPerformPostNoBody(groupLocation+"/members/84/resend", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the invitation mail has been sent again")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	tstRequireMailRequests(t,
+		tstGroupMailToMember("group-invited", "kittens", "1234567890", "101", joinLink))
+}
+
+func TestGroupsInvitations_MemberResendDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is a member of a group, but not its owner")
+	docs.Given("Given another attendee who was invited to the group")
+	id1 := setupExistingGroup(t, "kittens", false, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	_ = tstSetupInvitation(t, groupLocation)
+
+	docs.When("When the member attempts to resend the invitation")
+	response := tstPerformPostNoBody(groupLocation+"/members/84/resend", tstValidUserToken(t, 202))
+
+	docs.Then("Then the request is denied and no mails have been sent")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "only the group owner or an admin can resend invitations")
+	tstRequireMailRequests(t)
+}
+
+func TestGroupsInvitations_ResendJoinRequest(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an attendee who has requested to join a public group")
+	id1 := setupExistingGroup(t, "kittens", true, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	registerSubject("202")
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody(groupLocation+"/members/43", tstValidUserToken(t, 202)).status)
+	mailMock.Reset()
+
+	docs.When("When the group owner attempts to resend an invitation to the attendee")
+	response := tstPerformPostNoBody(groupLocation+"/members/43/resend", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request fails with the expected error, because there is no invitation to resend")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "group.member.notfound", "this attendee has not been invited to this group")
+	tstRequireMailRequests(t)
+}
+
+func TestGroupsInvitations_ResendMember(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with an owner and a member")
+	id1 := setupExistingGroup(t, "kittens", false, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When the group owner attempts to resend an invitation to the member")
+	response := tstPerformPostNoBody(groupLocation+"/members/43/resend", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "group.member.duplicate", "this attendee is already a member of this group")
+}
+
+func TestGroupsInvitations_ResendMailError(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an attendee who was invited to a group")
+	id1 := setupExistingGroup(t, "kittens", false, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	_ = tstSetupInvitation(t, groupLocation)
+
+	docs.Given("Given the mail service is unavailable")
+	mailMock.SimulateError(errors.New("mail service down"))
+
+	docs.When("When the group owner resends the invitation")
+	response := tstPerformPostNoBody(groupLocation+"/members/84/resend", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadGateway, "group.mail.error", "failed to send the invitation mail - see logs for details")
+}
+
+// --- sweeper ---
+
+func TestGroupsInvitations_SweepExpired(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with an expired invitation and a join request")
+	id1 := setupExistingGroup(t, "kittens", true, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	_ = tstSetupInvitation(t, groupLocation)
+	tstExpireInvitation(t, 84)
+	registerSubject("202")
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody(groupLocation+"/members/43", tstValidUserToken(t, 202)).status)
+
+	docs.When("When the background job deletes expired invitations")
+	count, err := grpsvc.SweepExpiredInvitations(context.TODO())
+
+	docs.Then("Then only the expired invitation has been deleted")
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	tstRequireInvites(t, groupLocation, 43)
+
+	docs.Then("And the deletion has been historized")
+	history := modelsv1.HistoryList{}
+	tstRequireSuccessResponse(t, tstPerformGet("/api/rest/v1/attendees/84/history", tstValidAdminToken(t)), http.StatusOK, &history)
+	latest := history.Entries[len(history.Entries)-1]
+	require.Equal(t, "GroupMember", latest.Entity)
+	require.Equal(t, "delete", latest.Operation)
+}
+
+func TestGroupsInvitations_SweepKeepsValid(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with an invitation that has not expired")
+	id1 := setupExistingGroup(t, "kittens", false, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	_ = tstSetupInvitation(t, groupLocation)
+
+	docs.When("When the background job deletes expired invitations")
+	count, err := grpsvc.SweepExpiredInvitations(context.TODO())
+
+	docs.Then("Then nothing has been deleted")
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+	tstRequireInvites(t, groupLocation, 84)
+}
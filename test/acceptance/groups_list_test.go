@@ -3,6 +3,7 @@ package acceptance
 import (
 	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
 	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/attendeeservice"
+	"github.com/stretchr/testify/require"
 	"net/http"
 	"net/url"
 	"testing"
@@ -57,7 +58,7 @@ func TestGroupsList_AdminSuccess(t *testing.T) {
 		},
 		Invites: nil,
 	}
-	expected := modelsv1.GroupList{}
+	expected := modelsv1.GroupList{Total: 2}
 	expected.Groups = append(expected.Groups, &grp1, &grp2) // sorted alphabetically by name
 	tstEqualResponseBodies(t, expected, actual)
 }
@@ -95,6 +96,7 @@ func TestGroupsList_AdminSuccess_Filtered(t *testing.T) {
 				Invites: nil,
 			},
 		},
+		Total: 1,
 	}
 	tstEqualResponseBodies(t, expected, actual)
 }
@@ -133,6 +135,7 @@ func TestGroupsList_AdminSuccess_Public(t *testing.T) {
 				Invites: nil,
 			},
 		},
+		Total: 1,
 	}
 	tstEqualResponseBodies(t, expected, actual)
 }
@@ -168,6 +171,7 @@ func TestGroupsList_UserSuccess_Public(t *testing.T) {
 				},
 			},
 		},
+		Total: 1,
 	}
 	tstEqualResponseBodies(t, expected, actual)
 }
@@ -193,6 +197,73 @@ func TestGroupsList_UserSuccess_NotFindingPrivate(t *testing.T) {
 	tstEqualResponseBodies(t, expected, actual)
 }
 
+func TestGroupsList_AdminSuccess_NameSearch(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two registered attendees with an active registration who are in a group each")
+	id1 := setupExistingGroup(t, "kittens", true, "101")
+	_ = setupExistingGroup(t, "puppies", false, "202")
+
+	docs.When("When an admin searches for groups by part of their name, in different case")
+	response := tstPerformGet("/api/rest/v1/groups?name=TEN", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and only the matching group is listed")
+	actual := modelsv1.GroupList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Len(t, actual.Groups, 1)
+	require.Equal(t, id1, actual.Groups[0].ID)
+	require.Equal(t, int64(1), actual.Total)
+}
+
+func TestGroupsList_AdminSuccess_SortedAndPaged(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two groups, created one after the other")
+	id1 := setupExistingGroup(t, "puppies", false, "101")
+	id2 := setupExistingGroup(t, "kittens", false, "202")
+
+	docs.When("When an admin requests the first page of groups, newest groups first, one group per page")
+	response := tstPerformGet("/api/rest/v1/groups?sort=created&order=desc&limit=1", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the page lists the newer group, with the total count of all groups")
+	actual := modelsv1.GroupList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Len(t, actual.Groups, 1)
+	require.Equal(t, id2, actual.Groups[0].ID)
+	require.Equal(t, int64(2), actual.Total)
+
+	docs.When("When an admin requests the second page")
+	response = tstPerformGet("/api/rest/v1/groups?sort=created&order=desc&offset=1&limit=1", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the page lists the older group")
+	actual = modelsv1.GroupList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Len(t, actual.Groups, 1)
+	require.Equal(t, id1, actual.Groups[0].ID)
+	require.Equal(t, int64(2), actual.Total)
+}
+
+func TestGroupsList_UserSuccess_PublicPaged(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a public group, and a registered attendee who owns a private group")
+	_ = setupExistingGroup(t, "kittens", true, "101")
+	_ = setupExistingGroup(t, "ducklings", false, "202")
+
+	docs.When("When the attendee requests the first page of public groups")
+	response := tstPerformGet("/api/rest/v1/groups?show=public&limit=1", tstValidUserToken(t, 202))
+
+	docs.Then("Then the request is successful and the page and the total count only include the public group")
+	actual := modelsv1.GroupList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Len(t, actual.Groups, 1)
+	require.Equal(t, "kittens", actual.Groups[0].Name)
+	require.Equal(t, int64(1), actual.Total)
+}
+
 func TestGroupsList_UserDeny_NonPublic(t *testing.T) {
 	tstSetup(tstDefaultConfigFileRoomGroups)
 	defer tstShutdown()
@@ -266,3 +337,17 @@ func TestGroupsList_InvalidQueryParams(t *testing.T) {
 	docs.Then("Then the request fails with the expected error")
 	tstRequireErrorResponse(t, response, http.StatusBadRequest, "request.parse.failed", url.Values{"details": []string{"member ids must be numeric and valid. Invalid member id: kittycat"}})
 }
+
+func TestGroupsList_InvalidListParams(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an admin")
+	token := tstValidAdminToken(t)
+
+	docs.When("When they try to list groups, but supply an invalid sort order")
+	response := tstPerformGet("/api/rest/v1/groups?sort=name&order=up", token)
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "request.parse.failed", url.Values{"details": []string{"order must be asc or desc"}})
+}
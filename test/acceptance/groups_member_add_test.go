@@ -1,6 +1,7 @@
 package acceptance
 
 import (
+	"context"
 	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
 	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/attendeeservice"
 	"net/http"
@@ -485,6 +486,135 @@ func TestGroupsAddMember_OwnerInviteRemovesBan(t *testing.T) {
 	tstRequireBanned(t, id1, 43, false)
 }
 
+// group size handling
+
+func tstSetupGroupMaximumSize(t *testing.T, groupLocation string, maximumSize int64) {
+	t.Helper()
+
+	grp := tstReadGroup(t, groupLocation)
+	grp.MaximumSize = maximumSize
+	response := tstPerformPut(groupLocation, tstRenderJson(grp), tstValidAdminToken(t))
+	require.Equal(t, http.StatusNoContent, response.status, "setup group maximum size failed")
+}
+
+func TestGroupsAddMember_OwnerInviteGroupFull(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a user with an active registration who is owner of a group with maximum size 2")
+	id1 := setupExistingGroup(t, "kittens", false, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	tstSetupGroupMaximumSize(t, groupLocation, 2)
+	token := tstValidUserToken(t, 101)
+
+	docs.Given("Given the owner has already invited another attendee, which fills the group")
+	attMock.SetupRegistered("202", 43, attendeeservice.StatusApproved, "Snep", "snep@example.com")
+	inviteResponse := tstPerformPostNoBody(groupLocation+"/members/43?nickname=Snep", token)
+	require.Equal(t, http.StatusNoContent, inviteResponse.status, "unexpected http response status")
+	mailMock.Reset()
+
+	docs.Given("Given a third attendee with an active registration who is not in any group")
+	attMock.SetupRegistered("1234567890", 84, attendeeservice.StatusApproved, "Panther", "panther@example.com")
+
+	docs.When("When the group owner tries to invite the third attendee")
+	response := tstPerformPostNoBody(groupLocation+"/members/84?nickname=Panther", token)
+
+	docs.Then("Then the request fails with the appropriate error message")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "group.size.full", "this group is full - members and outstanding invitations have reached the maximum group size")
+
+	docs.Then("And the group still only contains the owner and the first invitation")
+	grp := tstReadGroup(t, groupLocation)
+	require.Equal(t, []modelsv1.Member{squirrel}, grp.Members)
+	require.Equal(t, []modelsv1.Member{snep}, grp.Invites)
+
+	docs.Then("And no emails have been sent")
+	tstRequireMailRequests(t)
+}
+
+func TestGroupsAddMember_ApplyGroupFull(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a public group with maximum size 2 that already has 2 members")
+	id1 := setupExistingGroup(t, "kittens", true, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	tstSetupGroupMaximumSize(t, groupLocation, 2)
+
+	docs.Given("Given another attendee with an active registration who is not in any group")
+	attMock.SetupRegistered("1234567890", 84, attendeeservice.StatusApproved, "Panther", "panther@example.com")
+	token := tstValidUserToken(t, 1234567890)
+
+	docs.When("When they apply for the group")
+	response := tstPerformPostNoBody(groupLocation+"/members/84", token)
+
+	docs.Then("Then the request fails with the appropriate error message")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "group.size.full", "this group is full - members and outstanding invitations have reached the maximum group size")
+
+	docs.Then("And no application has been recorded")
+	grp := tstReadGroup(t, groupLocation)
+	require.Equal(t, []modelsv1.Member{squirrel, snep}, grp.Members)
+	require.Empty(t, grp.Invites)
+
+	docs.Then("And no emails have been sent")
+	tstRequireMailRequests(t)
+}
+
+func TestGroupsAddMember_AcceptInviteGroupFullSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with maximum size 2, with an owner and one outstanding invitation")
+	id1 := setupExistingGroup(t, "kittens", false, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	tstSetupGroupMaximumSize(t, groupLocation, 2)
+	attMock.SetupRegistered("202", 43, attendeeservice.StatusApproved, "Snep", "snep@example.com")
+	inviteResponse := tstPerformPostNoBody(groupLocation+"/members/43?nickname=Snep", tstValidUserToken(t, 101))
+	require.Equal(t, http.StatusNoContent, inviteResponse.status, "unexpected http response status")
+	mailMock.Reset()
+
+	docs.When("When the invited attendee accepts the invitation")
+	response := tstPerformPostNoBody(inviteResponse.location, tstValidUserToken(t, 202))
+
+	docs.Then("Then the request is successful because the invitation was already counted")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+
+	docs.Then("And the attendee is now a member")
+	grp := tstReadGroup(t, groupLocation)
+	require.Equal(t, []modelsv1.Member{squirrel, snep}, grp.Members)
+}
+
+func TestGroupsAddMember_AdminForceGroupFullSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with maximum size 2 that already has 2 members")
+	id1 := setupExistingGroup(t, "kittens", false, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	tstSetupGroupMaximumSize(t, groupLocation, 2)
+
+	docs.Given("Given another attendee with an active registration who is not in any group")
+	attMock.SetupRegistered("1234567890", 84, attendeeservice.StatusApproved, "Panther", "panther@example.com")
+
+	docs.When("When an admin adds the attendee to the group, using force=true")
+	response := tstPerformPostNoBody(groupLocation+"/members/84?force=true", tstValidAdminToken(t))
+
+	docs.Then("Then the attendee is added even though this exceeds the maximum group size")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	grp := tstReadGroup(t, groupLocation)
+	require.Equal(t, []modelsv1.Member{squirrel, snep, panther}, grp.Members)
+
+	docs.Then("And the forced addition is recorded in the history of the attendee")
+	history := tstReadAttendeeHistory(t, 84)
+	entry := history.Entries[len(history.Entries)-1]
+	require.Equal(t, "GroupMember", entry.Entity)
+	require.Equal(t, "add", entry.Operation)
+
+	docs.Then("And the override of the maximum group size is noted in the comments of the membership")
+	gm, err := db.GetGroupMembershipByAttendeeID(context.TODO(), 84)
+	require.NoError(t, err)
+	require.Contains(t, gm.Comments, "exceeds maximum group size 2")
+}
+
 func TestGroupsAddMember_GroupNotFound(t *testing.T) {
 	tstSetup(tstDefaultConfigFileRoomGroups)
 	defer tstShutdown()
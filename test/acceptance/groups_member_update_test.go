@@ -0,0 +1,161 @@
+package acceptance
+
+import (
+	"github.com/eurofurence/reg-room-service/docs"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+// tstWithFlags returns a copy of the member with the given membership flags.
+func tstWithFlags(member modelsv1.Member, flags ...string) modelsv1.Member {
+	member.Flags = flags
+	return member
+}
+
+func tstSetupMemberFlags(t *testing.T, location string, badgeNo string, flags ...string) {
+	body := tstRenderJson(modelsv1.MemberUpdate{Flags: flags})
+	response := tstPerformPut(location+"/members/"+badgeNo, body, tstValidAdminToken(t))
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+}
+
+func TestGroupsUpdateMember_OwnerSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an attendee with an active registration who is the owner of a group with another member")
+	id := setupExistingGroup(t, "kittens", false, "101", "202")
+	location := "/api/rest/v1/groups/" + id
+
+	docs.When("When the owner sets the flags of the other member")
+	body := tstRenderJson(modelsv1.MemberUpdate{Flags: []string{"early-arrival", "needs-ground-floor"}})
+	response := tstPerformPut(location+"/members/43", body, tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status)
+
+	docs.Then("And the member has the expected flags")
+	tstGroupState(t, id, location, []modelsv1.Member{tstWithFlags(snep, "early-arrival", "needs-ground-floor")}, nil)
+}
+
+func TestGroupsUpdateMember_SelfSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an attendee with an active registration who is a member of a group")
+	id := setupExistingGroup(t, "kittens", false, "101", "202")
+	location := "/api/rest/v1/groups/" + id
+
+	docs.When("When the member sets their own flags")
+	body := tstRenderJson(modelsv1.MemberUpdate{Flags: []string{"early-arrival"}})
+	response := tstPerformPut(location+"/members/43", body, tstValidUserToken(t, 202))
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status)
+
+	docs.Then("And the member has the expected flags")
+	tstGroupState(t, id, location, []modelsv1.Member{tstWithFlags(snep, "early-arrival")}, nil)
+}
+
+func TestGroupsUpdateMember_AdminClear(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with a member who has flags")
+	id := setupExistingGroup(t, "kittens", false, "101", "202")
+	location := "/api/rest/v1/groups/" + id
+	tstSetupMemberFlags(t, location, "43", "early-arrival")
+
+	docs.When("When an admin sets an empty list of flags for the member")
+	response := tstPerformPut(location+"/members/43", `{"flags":[]}`, tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status)
+
+	docs.Then("And the member no longer has any flags")
+	tstGroupState(t, id, location, []modelsv1.Member{snep}, nil)
+}
+
+func TestGroupsUpdateMember_OtherMemberDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an attendee with an active registration who is a member, but not the owner of a group")
+	id := setupExistingGroup(t, "kittens", false, "101", "202")
+	location := "/api/rest/v1/groups/" + id
+
+	docs.When("When they attempt to set the flags of the owner")
+	body := tstRenderJson(modelsv1.MemberUpdate{Flags: []string{"early-arrival"}})
+	response := tstPerformPut(location+"/members/42", body, tstValidUserToken(t, 202))
+
+	docs.Then("Then the request is denied with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "only the group owner, an admin or the member themself can change member flags")
+
+	docs.Then("And the group is unchanged")
+	tstGroupState(t, id, location, []modelsv1.Member{snep}, nil)
+}
+
+func TestGroupsUpdateMember_InvalidFlag(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an attendee with an active registration who is the owner of a group with another member")
+	id := setupExistingGroup(t, "kittens", false, "101", "202")
+	location := "/api/rest/v1/groups/" + id
+
+	docs.When("When the owner attempts to set a flag that is not configured")
+	body := tstRenderJson(modelsv1.MemberUpdate{Flags: []string{"teleport"}})
+	response := tstPerformPut(location+"/members/43", body, tstValidUserToken(t, 101))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "group.data.invalid", url.Values{"flags": []string{"no such flag 'teleport'"}})
+
+	docs.Then("And the group is unchanged")
+	tstGroupState(t, id, location, []modelsv1.Member{snep}, nil)
+}
+
+func TestGroupsUpdateMember_NotMember(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group and an attendee with an active registration who is not in it")
+	id := setupExistingGroup(t, "kittens", false, "101")
+	location := "/api/rest/v1/groups/" + id
+	registerSubject("202")
+
+	docs.When("When an admin attempts to set the flags of the attendee in the group")
+	body := tstRenderJson(modelsv1.MemberUpdate{Flags: []string{"early-arrival"}})
+	response := tstPerformPut(location+"/members/43", body, tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "group.member.notfound", "this attendee is not a member of this group")
+}
+
+func TestGroupsUpdateMember_Visibility(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group where both the owner and another member have flags")
+	id := setupExistingGroup(t, "kittens", false, "101", "202")
+	location := "/api/rest/v1/groups/" + id
+	tstSetupMemberFlags(t, location, "42", "needs-ground-floor")
+	tstSetupMemberFlags(t, location, "43", "early-arrival")
+
+	docs.When("When the other member reads the group")
+	response := tstPerformGet(location, tstValidUserToken(t, 202))
+
+	docs.Then("Then the request is successful and they can only see their own flags")
+	actual := modelsv1.Group{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Equal(t, []modelsv1.Member{squirrel, tstWithFlags(snep, "early-arrival")}, actual.Members)
+
+	docs.When("When the owner reads the group")
+	response = tstPerformGet(location, tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is successful and they can see the flags of all members")
+	actual = modelsv1.Group{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Equal(t, []modelsv1.Member{tstWithFlags(squirrel, "needs-ground-floor"), tstWithFlags(snep, "early-arrival")}, actual.Members)
+}
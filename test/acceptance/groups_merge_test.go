@@ -0,0 +1,299 @@
+package acceptance
+
+import (
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eurofurence/reg-room-service/docs"
+	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/attendeeservice"
+)
+
+// tstSetupMergeGroups creates a group owned by subject 101 (kittens) and a group owned by subject 202 (puppies),
+// and returns their ids.
+func tstSetupMergeGroups(t *testing.T) (string, string) {
+	sourceID := setupExistingGroup(t, "kittens", false, "101")
+	targetID := setupExistingGroup(t, "puppies", false, "202")
+	return sourceID, targetID
+}
+
+func tstRequireMerged(t *testing.T, sourceID string, targetID string) {
+	t.Helper()
+
+	response := tstPerformGet(path.Join("/api/rest/v1/groups/", sourceID), tstValidAdminToken(t))
+	require.Equal(t, http.StatusNotFound, response.status, "source group should have been deleted")
+
+	group := tstReadGroup(t, path.Join("/api/rest/v1/groups/", targetID))
+	require.Equal(t, "puppies", group.Name)
+	require.Equal(t, int64(43), group.Owner)
+	badges := make([]int64, 0, len(group.Members))
+	for _, m := range group.Members {
+		badges = append(badges, m.ID)
+	}
+	require.ElementsMatch(t, []int64{42, 43}, badges)
+}
+
+func tstRequireNotMerged(t *testing.T, sourceID string, targetID string, expectedPendingMerge string) {
+	t.Helper()
+
+	source := tstReadGroup(t, path.Join("/api/rest/v1/groups/", sourceID))
+	require.Equal(t, int64(42), source.Owner)
+	require.Equal(t, 1, len(source.Members))
+	require.Equal(t, expectedPendingMerge, source.PendingMerge)
+
+	target := tstReadGroup(t, path.Join("/api/rest/v1/groups/", targetID))
+	require.Equal(t, int64(43), target.Owner)
+	require.Equal(t, 1, len(target.Members))
+}
+
+// --- merge ---
+
+func TestGroupsMerge_AdminSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two groups with different owners")
+	sourceID, targetID := tstSetupMergeGroups(t)
+
+	docs.When("When an admin merges the first group into the second group")
+	response := tstPerformPostNoBody("/api/rest/v1/groups/"+sourceID+"/merge/"+targetID, tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+
+	docs.Then("And all members are now in the second group, and the first group has been deleted")
+	tstRequireMerged(t, sourceID, targetID)
+
+	docs.Then("And all members of the merged group have been informed by mail")
+	tstRequireMailRequests(t,
+		tstGroupMailToMember("group-merged", "puppies", "101", "202", ""),
+		tstGroupMailToMember("group-merged", "puppies", "202", "202", ""))
+}
+
+func TestGroupsMerge_OwnersAgree(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two groups with different owners")
+	sourceID, targetID := tstSetupMergeGroups(t)
+
+	docs.When("When the owner of the first group requests to merge it into the second group")
+	response := tstPerformPostNoBody("/api/rest/v1/groups/"+sourceID+"/merge/"+targetID, tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is successful, but the groups are not merged yet")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	tstRequireNotMerged(t, sourceID, targetID, targetID)
+
+	docs.Then("And the owner of the second group has been asked by mail")
+	tstRequireMailRequests(t,
+		tstGroupMailToOwner("group-merge-requested", "puppies", "202", "101"))
+
+	docs.When("When the owner of the second group agrees")
+	response = tstPerformPostNoBody("/api/rest/v1/groups/"+sourceID+"/merge/"+targetID, tstValidUserToken(t, 202))
+
+	docs.Then("Then the request is successful and the groups have been merged")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	tstRequireMerged(t, sourceID, targetID)
+	tstRequireMailRequests(t,
+		tstGroupMailToMember("group-merged", "puppies", "101", "202", ""),
+		tstGroupMailToMember("group-merged", "puppies", "202", "202", ""))
+}
+
+func TestGroupsMerge_TargetOwnerWithoutRequest(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two groups with different owners, and no pending merge request")
+	sourceID, targetID := tstSetupMergeGroups(t)
+
+	docs.When("When the owner of the second group attempts to merge the first group into theirs")
+	response := tstPerformPostNoBody("/api/rest/v1/groups/"+sourceID+"/merge/"+targetID, tstValidUserToken(t, 202))
+
+	docs.Then("Then the request fails with the appropriate error")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "group.merge.notfound", "there is no pending request to merge this group into yours")
+
+	docs.Then("And both groups are unchanged and no mails have been sent")
+	tstRequireNotMerged(t, sourceID, targetID, "")
+	tstRequireMailRequests(t)
+}
+
+func TestGroupsMerge_OtherUserDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two groups with different owners")
+	sourceID, targetID := tstSetupMergeGroups(t)
+
+	docs.Given("Given an authorized user with an active registration who owns neither group")
+	attMock.SetupRegistered("1234567890", 84, attendeeservice.StatusApproved, "Panther", "panther@example.com")
+
+	docs.When("When they attempt to merge the groups")
+	response := tstPerformPostNoBody("/api/rest/v1/groups/"+sourceID+"/merge/"+targetID, tstValidUserToken(t, 1234567890))
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "only the owners of both groups or an admin can merge groups")
+
+	docs.Then("And both groups are unchanged and no mails have been sent")
+	tstRequireNotMerged(t, sourceID, targetID, "")
+	tstRequireMailRequests(t)
+}
+
+func TestGroupsMerge_SameGroup(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group")
+	sourceID, _ := tstSetupMergeGroups(t)
+
+	docs.When("When an admin attempts to merge the group into itself")
+	response := tstPerformPostNoBody("/api/rest/v1/groups/"+sourceID+"/merge/"+sourceID, tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the appropriate error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "group.data.invalid", "cannot merge a group into itself")
+}
+
+func TestGroupsMerge_TargetNotFound(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group")
+	sourceID, _ := tstSetupMergeGroups(t)
+
+	docs.When("When an admin attempts to merge the group into a group that does not exist")
+	response := tstPerformPostNoBody("/api/rest/v1/groups/"+sourceID+"/merge/7a8ac9d6-e7a6-4b1e-a4f8-d0e8d2c1b1a1", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the appropriate error")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "group.id.notfound", "this group does not exist")
+}
+
+func TestGroupsMerge_SizeExceeded(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two groups with different owners, where the second group cannot take another member")
+	sourceID, targetID := tstSetupMergeGroups(t)
+	tstSetupGroupMaximumSize(t, path.Join("/api/rest/v1/groups/", targetID), 1)
+
+	docs.When("When an admin attempts to merge the groups")
+	response := tstPerformPostNoBody("/api/rest/v1/groups/"+sourceID+"/merge/"+targetID, tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the appropriate error")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "group.size.full", "the merged group would exceed the maximum group size")
+
+	docs.Then("And both groups are unchanged and no mails have been sent")
+	tstRequireNotMerged(t, sourceID, targetID, "")
+	tstRequireMailRequests(t)
+}
+
+func TestGroupsMerge_BansCombined(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two groups with different owners")
+	sourceID, targetID := tstSetupMergeGroups(t)
+
+	docs.Given("Given the first group has another attendee on its auto-decline list")
+	registerSubject("1234567890")
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody("/api/rest/v1/groups/"+sourceID+"/bans/84", tstValidAdminToken(t)).status)
+
+	docs.Given("Given the second group has the owner of the first group on its auto-decline list")
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody("/api/rest/v1/groups/"+targetID+"/bans/42", tstValidAdminToken(t)).status)
+
+	docs.When("When an admin merges the first group into the second group")
+	response := tstPerformPostNoBody("/api/rest/v1/groups/"+sourceID+"/merge/"+targetID, tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	tstRequireMerged(t, sourceID, targetID)
+
+	docs.Then("And the other attendee is now on the auto-decline list of the merged group, but its new member is not")
+	tstRequireBanned(t, targetID, 84, true)
+	tstRequireBanned(t, targetID, 42, false)
+}
+
+func TestGroupsMerge_PendingMergeIntoSourceDropped(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two groups with different owners, and a third group")
+	kittensID, puppiesID := tstSetupMergeGroups(t)
+	foxesID := setupExistingGroup(t, "foxes", false, "1234567890")
+
+	docs.Given("Given the owner of the first group has requested to merge it into the third group")
+	response := tstPerformPostNoBody("/api/rest/v1/groups/"+kittensID+"/merge/"+foxesID, tstValidUserToken(t, 101))
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+
+	docs.When("When an admin merges the third group into the second group")
+	response = tstPerformPostNoBody("/api/rest/v1/groups/"+foxesID+"/merge/"+puppiesID, tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+
+	docs.Then("And the first group no longer has a pending request to merge into the deleted third group")
+	kittens := tstReadGroup(t, path.Join("/api/rest/v1/groups/", kittensID))
+	require.Equal(t, "", kittens.PendingMerge)
+}
+
+// --- cancel ---
+
+func TestGroupsMerge_TargetOwnerDeclines(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two groups with different owners, and a pending request to merge the first into the second")
+	sourceID, targetID := tstSetupMergeGroups(t)
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody("/api/rest/v1/groups/"+sourceID+"/merge/"+targetID, tstValidUserToken(t, 101)).status)
+	mailMock.Reset()
+
+	docs.When("When the owner of the second group declines")
+	response := tstPerformDelete("/api/rest/v1/groups/"+sourceID+"/merge", tstValidUserToken(t, 202))
+
+	docs.Then("Then the request is successful and the pending merge has been removed")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	tstRequireNotMerged(t, sourceID, targetID, "")
+
+	docs.Then("And the owner of the first group has been informed by mail")
+	tstRequireMailRequests(t,
+		tstGroupMailToOwner("group-merge-declined", "kittens", "101", "202"))
+
+	docs.Then("And the owner of the second group can no longer complete the merge")
+	response = tstPerformPostNoBody("/api/rest/v1/groups/"+sourceID+"/merge/"+targetID, tstValidUserToken(t, 202))
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "group.merge.notfound", "there is no pending request to merge this group into yours")
+}
+
+func TestGroupsMerge_SourceOwnerWithdraws(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two groups with different owners, and a pending request to merge the first into the second")
+	sourceID, targetID := tstSetupMergeGroups(t)
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody("/api/rest/v1/groups/"+sourceID+"/merge/"+targetID, tstValidUserToken(t, 101)).status)
+	mailMock.Reset()
+
+	docs.When("When the owner of the first group withdraws the request")
+	response := tstPerformDelete("/api/rest/v1/groups/"+sourceID+"/merge", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is successful and the pending merge has been removed")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	tstRequireNotMerged(t, sourceID, targetID, "")
+
+	docs.Then("And the owner of the second group has been informed by mail")
+	tstRequireMailRequests(t,
+		tstGroupMailToOwner("group-merge-withdrawn", "puppies", "202", "101"))
+}
+
+func TestGroupsMerge_CancelWithoutRequest(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group without a pending merge request")
+	sourceID, _ := tstSetupMergeGroups(t)
+
+	docs.When("When its owner attempts to withdraw a merge request")
+	response := tstPerformDelete("/api/rest/v1/groups/"+sourceID+"/merge", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request fails with the appropriate error")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "group.merge.notfound", "there is no pending merge request for this group")
+}
@@ -0,0 +1,166 @@
+package acceptance
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eurofurence/reg-room-service/docs"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+func tstReadGroupHistory(t *testing.T, groupID string) modelsv1.HistoryList {
+	history := modelsv1.HistoryList{}
+	response := tstPerformGet("/api/rest/v1/groups/"+groupID+"/history", tstValidAdminToken(t))
+	tstRequireSuccessResponse(t, response, http.StatusOK, &history)
+	return history
+}
+
+func tstRequireGroupMembers(t *testing.T, groupLocation string, expectedBadgeNumbers ...int64) {
+	t.Helper()
+
+	group := tstReadGroup(t, groupLocation)
+	badges := make([]int64, 0, len(group.Members))
+	for _, m := range group.Members {
+		badges = append(badges, m.ID)
+	}
+	require.ElementsMatch(t, expectedBadgeNumbers, badges)
+}
+
+func TestGroupsSplit_AdminSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a public group with an owner and another member")
+	id1 := setupExistingGroup(t, "kittens", true, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When an admin splits off the other member into a new group, owned by them")
+	split := modelsv1.GroupSplit{Members: []int64{43}, Owner: 43}
+	response := tstPerformPost(groupLocation+"/split", tstRenderJson(split), tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the location of the new group is returned")
+	require.Equal(t, http.StatusCreated, response.status, "unexpected http response status")
+	require.Regexp(t, validGroupLocationRegex, response.location, "invalid location header in response")
+
+	docs.Then("And the new group has a derived name, the same flags, and the other member as owner")
+	newGroup := tstReadGroup(t, response.location)
+	require.Equal(t, "kittens (2)", newGroup.Name)
+	require.Equal(t, []string{"public"}, newGroup.Flags)
+	require.Equal(t, int64(43), newGroup.Owner)
+	tstRequireGroupMembers(t, response.location, 43)
+
+	docs.Then("And the original group keeps its owner")
+	tstRequireOwnership(t, groupLocation, 42, 0)
+	tstRequireGroupMembers(t, groupLocation, 42)
+
+	docs.Then("And the split is recorded in the history of both groups")
+	for _, groupID := range []string{id1, newGroup.ID} {
+		history := tstReadGroupHistory(t, groupID)
+		entry := history.Entries[len(history.Entries)-1]
+		require.Equal(t, "Group", entry.Entity)
+		require.Equal(t, "move", entry.Operation)
+		require.Equal(t, []modelsv1.HistoryDiff{{Field: "[43]", Value: fmt.Sprintf("%q", id1)}}, entry.Diff)
+	}
+
+	docs.Then("And the member who moved has been informed by mail")
+	tstRequireMailRequests(t,
+		tstGroupMailToMember("group-split", "kittens (2)", "202", "202", ""))
+}
+
+func TestGroupsSplit_NameTaken(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with an owner and another member")
+	id1 := setupExistingGroup(t, "kittens", false, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.Given("Given another group already has the name the new group would get")
+	_ = setupExistingGroup(t, "kittens (2)", false, "1234567890")
+
+	docs.When("When an admin splits off the other member into a new group")
+	split := modelsv1.GroupSplit{Members: []int64{43}, Owner: 43}
+	response := tstPerformPost(groupLocation+"/split", tstRenderJson(split), tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the new group gets the next free name")
+	require.Equal(t, http.StatusCreated, response.status, "unexpected http response status")
+	require.Equal(t, "kittens (3)", tstReadGroup(t, response.location).Name)
+}
+
+func TestGroupsSplit_OwnerDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is the owner of a group with another member")
+	id1 := setupExistingGroup(t, "kittens", false, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When they attempt to split off the other member into a new group")
+	split := modelsv1.GroupSplit{Members: []int64{43}, Owner: 43}
+	response := tstPerformPost(groupLocation+"/split", tstRenderJson(split), tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "only an admin can split a group")
+
+	docs.Then("And the group is unchanged and no mails have been sent")
+	tstRequireGroupMembers(t, groupLocation, 42, 43)
+	tstRequireMailRequests(t)
+}
+
+func TestGroupsSplit_GroupOwnerCannotMove(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with an owner and another member")
+	id1 := setupExistingGroup(t, "kittens", false, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When an admin attempts to split off the owner into a new group")
+	split := modelsv1.GroupSplit{Members: []int64{42}, Owner: 42}
+	response := tstPerformPost(groupLocation+"/split", tstRenderJson(split), tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the appropriate error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "group.owner.cannot.remove", "the owner of the group cannot move to the new group, change the owner first")
+
+	docs.Then("And the group is unchanged")
+	tstRequireGroupMembers(t, groupLocation, 42, 43)
+}
+
+func TestGroupsSplit_NotMember(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with an owner and another member")
+	id1 := setupExistingGroup(t, "kittens", false, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When an admin attempts to split off an attendee who is not in the group")
+	split := modelsv1.GroupSplit{Members: []int64{43, 84}, Owner: 43}
+	response := tstPerformPost(groupLocation+"/split", tstRenderJson(split), tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the appropriate error")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "group.member.notfound", "attendee 84 is not a member of this group")
+
+	docs.Then("And the group is unchanged")
+	tstRequireGroupMembers(t, groupLocation, 42, 43)
+}
+
+func TestGroupsSplit_OwnerNotMoving(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with an owner and another member")
+	id1 := setupExistingGroup(t, "kittens", false, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When an admin attempts to split off a member, but names someone else as the new owner")
+	split := modelsv1.GroupSplit{Members: []int64{43}, Owner: 84}
+	response := tstPerformPost(groupLocation+"/split", tstRenderJson(split), tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the appropriate error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "group.data.invalid", "the owner of the new group must be one of the members who move")
+}
@@ -0,0 +1,372 @@
+package acceptance
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eurofurence/reg-room-service/docs"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/attendeeservice"
+)
+
+// tstSetupTransferOffer lets the owner of the group (subject 101) offer ownership to the member with subject 202.
+func tstSetupTransferOffer(t *testing.T, groupLocation string) {
+	response := tstPerformPostNoBody(groupLocation+"/transfer/43", tstValidUserToken(t, 101))
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	mailMock.Reset()
+}
+
+func tstRequireOwnership(t *testing.T, groupLocation string, expectedOwner int64, expectedPendingOwner int64) {
+	group := tstReadGroup(t, groupLocation)
+	require.Equal(t, expectedOwner, group.Owner, "unexpected group owner")
+	require.Equal(t, expectedPendingOwner, group.PendingOwner, "unexpected pending group owner")
+}
+
+// --- propose ---
+
+func TestGroupsTransfer_OwnerProposeSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is the owner of a group with another member")
+	id1 := setupExistingGroup(t, "kittens", true, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When they offer ownership of the group to the other member")
+	response := tstPerformPostNoBody(groupLocation+"/transfer/43", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+
+	docs.Then("And ownership has not changed yet, but the pending transfer is visible on the group")
+	tstRequireOwnership(t, groupLocation, 42, 43)
+
+	docs.Then("And the other member has been asked by mail")
+	tstRequireMailRequests(t,
+		tstGroupMailToMember("group-transfer-offered", "kittens", "202", "101", ""))
+
+	docs.Then("And the other member can see the pending transfer")
+	memberView := modelsv1.Group{}
+	tstRequireSuccessResponse(t, tstPerformGet(groupLocation, tstValidUserToken(t, 202)), http.StatusOK, &memberView)
+	require.Equal(t, int64(43), memberView.PendingOwner)
+
+	docs.Then("But attendees who are not in the group cannot")
+	attMock.SetupRegistered("1234567890", 84, attendeeservice.StatusApproved, "Panther", "panther@example.com")
+	outsiderView := modelsv1.Group{}
+	tstRequireSuccessResponse(t, tstPerformGet(groupLocation, tstValidUserToken(t, 1234567890)), http.StatusOK, &outsiderView)
+	require.Equal(t, int64(0), outsiderView.PendingOwner)
+}
+
+func TestGroupsTransfer_OwnerUpdateOnlyProposes(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is the owner of a group with another member")
+	id1 := setupExistingGroup(t, "kittens", true, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When they update the group, setting the other member as owner")
+	group := tstReadGroup(t, groupLocation)
+	group.Owner = 43
+	response := tstPerformPut(groupLocation, tstRenderJson(group), tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+
+	docs.Then("But ownership has only been offered to the other member, who has been asked by mail")
+	tstRequireOwnership(t, groupLocation, 42, 43)
+	tstRequireMailRequests(t,
+		tstGroupMailToMember("group-transfer-offered", "kittens", "202", "101", ""))
+}
+
+func TestGroupsTransfer_AdminUpdateDirect(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with an owner and another member, and a pending ownership transfer")
+	id1 := setupExistingGroup(t, "kittens", true, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	tstSetupTransferOffer(t, groupLocation)
+
+	docs.When("When an admin updates the group, setting the other member as owner")
+	group := tstReadGroup(t, groupLocation)
+	group.Owner = 43
+	response := tstPerformPut(groupLocation, tstRenderJson(group), tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and ownership has changed immediately")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	tstRequireOwnership(t, groupLocation, 43, 0)
+
+	docs.Then("And the new owner has been informed by mail")
+	tstRequireMailRequests(t,
+		tstGroupMailToMember("group-new-owner", "kittens", "202", "101", ""))
+}
+
+func TestGroupsTransfer_MemberProposeDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is a member of a group, but not its owner")
+	id1 := setupExistingGroup(t, "kittens", true, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When they attempt to offer ownership of the group to themselves")
+	response := tstPerformPostNoBody(groupLocation+"/transfer/43", tstValidUserToken(t, 202))
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "only the group owner or an admin can transfer ownership of a group")
+
+	docs.Then("And the group is unchanged and no mails have been sent")
+	tstRequireOwnership(t, groupLocation, 42, 0)
+	tstRequireMailRequests(t)
+}
+
+func TestGroupsTransfer_ProposeToNonMember(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is the owner of a group")
+	docs.Given("Given another attendee who has been invited to the group, but has not accepted yet")
+	id1 := setupExistingGroup(t, "kittens", true, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	_ = tstSetupInvitation(t, groupLocation)
+
+	docs.When("When the owner attempts to offer ownership of the group to the invited attendee")
+	response := tstPerformPostNoBody(groupLocation+"/transfer/84", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "group.member.notfound", "ownership can only be transferred to a member of this group")
+	tstRequireOwnership(t, groupLocation, 42, 0)
+}
+
+func TestGroupsTransfer_ProposeToSelf(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is the owner of a group")
+	id1 := setupExistingGroup(t, "kittens", true, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When they attempt to offer ownership of the group to themselves")
+	response := tstPerformPostNoBody(groupLocation+"/transfer/42", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "group.data.invalid", "this attendee already owns the group")
+}
+
+// --- accept ---
+
+func TestGroupsTransfer_AcceptSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group member who has been offered ownership of the group")
+	id1 := setupExistingGroup(t, "kittens", true, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	tstSetupTransferOffer(t, groupLocation)
+
+	docs.When("When they accept")
+	response := tstPerformPostNoBody(groupLocation+"/transfer/accept", tstValidUserToken(t, 202))
+
+	docs.Then("Then the request is successful and they are now the owner of the group")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	tstRequireOwnership(t, groupLocation, 43, 0)
+
+	docs.Then("And the previous owner has been informed by mail")
+	tstRequireMailRequests(t,
+		tstGroupMailToOwner("group-transfer-accepted", "kittens", "101", "202"))
+}
+
+func TestGroupsTransfer_AcceptNotOffered(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group member who has not been offered ownership of the group")
+	id1 := setupExistingGroup(t, "kittens", true, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When they attempt to accept ownership")
+	response := tstPerformPostNoBody(groupLocation+"/transfer/accept", tstValidUserToken(t, 202))
+
+	docs.Then("Then the request fails with the expected error and ownership is unchanged")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "group.transfer.notfound", "there is no pending ownership transfer to you for this group")
+	tstRequireOwnership(t, groupLocation, 42, 0)
+}
+
+// --- cancel ---
+
+func TestGroupsTransfer_Decline(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group member who has been offered ownership of the group")
+	id1 := setupExistingGroup(t, "kittens", true, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	tstSetupTransferOffer(t, groupLocation)
+
+	docs.When("When they decline")
+	response := tstPerformDelete(groupLocation+"/transfer", tstValidUserToken(t, 202))
+
+	docs.Then("Then the request is successful and the pending transfer is gone")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	tstRequireOwnership(t, groupLocation, 42, 0)
+
+	docs.Then("And the owner has been informed by mail")
+	tstRequireMailRequests(t,
+		tstGroupMailToOwner("group-transfer-declined", "kittens", "101", "202"))
+}
+
+func TestGroupsTransfer_OwnerWithdraw(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group owner who has offered ownership of the group to another member")
+	id1 := setupExistingGroup(t, "kittens", true, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	tstSetupTransferOffer(t, groupLocation)
+
+	docs.When("When they withdraw the offer")
+	response := tstPerformDelete(groupLocation+"/transfer", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is successful and the pending transfer is gone")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	tstRequireOwnership(t, groupLocation, 42, 0)
+
+	docs.Then("And the other member has been informed by mail")
+	tstRequireMailRequests(t,
+		tstGroupMailToMember("group-transfer-withdrawn", "kittens", "202", "101", ""))
+
+	docs.Then("And the other member can no longer accept")
+	acceptResponse := tstPerformPostNoBody(groupLocation+"/transfer/accept", tstValidUserToken(t, 202))
+	tstRequireErrorResponse(t, acceptResponse, http.StatusNotFound, "group.transfer.notfound", "there is no pending ownership transfer to you for this group")
+}
+
+func TestGroupsTransfer_CancelNonePending(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group without a pending ownership transfer")
+	id1 := setupExistingGroup(t, "kittens", true, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When the owner attempts to withdraw an offer")
+	response := tstPerformDelete(groupLocation+"/transfer", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "group.transfer.notfound", "there is no pending ownership transfer for this group")
+}
+
+func TestGroupsTransfer_CancelOtherMemberDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group owner who has offered ownership of the group to another member")
+	docs.Given("Given a third member of the group")
+	id1 := setupExistingGroup(t, "kittens", true, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	attMock.SetupRegistered("1234567890", 84, attendeeservice.StatusApproved, "Panther", "panther@example.com")
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody(groupLocation+"/members/84?force=true", tstValidAdminToken(t)).status)
+	tstSetupTransferOffer(t, groupLocation)
+
+	docs.When("When the third member attempts to cancel the pending transfer")
+	response := tstPerformDelete(groupLocation+"/transfer", tstValidUserToken(t, 1234567890))
+
+	docs.Then("Then the request is denied and the pending transfer is unchanged")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "only the group owner, the member who was offered ownership, or an admin can cancel an ownership transfer")
+	tstRequireOwnership(t, groupLocation, 42, 43)
+}
+
+func TestGroupsTransfer_ClearedWhenMemberLeaves(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group member who has been offered ownership of the group")
+	id1 := setupExistingGroup(t, "kittens", true, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	tstSetupTransferOffer(t, groupLocation)
+
+	docs.When("When they leave the group")
+	response := tstPerformDelete(groupLocation+"/members/43", tstValidUserToken(t, 202))
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+
+	docs.Then("Then the pending transfer is gone")
+	tstRequireOwnership(t, groupLocation, 42, 0)
+}
+
+// --- fallback ---
+
+func TestGroupsTransfer_FallbackOwnerCancelled(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with an owner and another member")
+	id1 := setupExistingGroup(t, "kittens", true, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.Given("Given the registration of the owner has been cancelled")
+	attMock.SetupRegistered("101", 42, attendeeservice.StatusCancelled, "Squirrel", "squirrel@example.com")
+
+	docs.When("When the background job checks the group owners")
+	count, err := grpsvc.ReassignCancelledOwners(context.TODO())
+
+	docs.Then("Then ownership has passed to the other member")
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	tstRequireOwnership(t, groupLocation, 43, 0)
+
+	docs.Then("And the new owner has been informed by mail")
+	tstRequireMailRequests(t,
+		tstGroupMailToOwner("group-owner-fallback", "kittens", "202", "101"))
+}
+
+func TestGroupsTransfer_FallbackPrefersPendingOwner(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with an owner and two other members, one of which has been offered ownership")
+	id1 := setupExistingGroup(t, "kittens", true, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	attMock.SetupRegistered("1234567890", 84, attendeeservice.StatusApproved, "Panther", "panther@example.com")
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody(groupLocation+"/members/84?force=true", tstValidAdminToken(t)).status)
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody(groupLocation+"/transfer/84", tstValidUserToken(t, 101)).status)
+	mailMock.Reset()
+
+	docs.Given("Given the registration of the owner has been cancelled")
+	attMock.SetupRegistered("101", 42, attendeeservice.StatusCancelled, "Squirrel", "squirrel@example.com")
+
+	docs.When("When the background job checks the group owners")
+	count, err := grpsvc.ReassignCancelledOwners(context.TODO())
+
+	docs.Then("Then ownership has passed to the member who was offered ownership")
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	tstRequireOwnership(t, groupLocation, 84, 0)
+}
+
+func TestGroupsTransfer_FallbackNoOtherMember(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with just its owner, and another group whose owner is still attending")
+	id1 := setupExistingGroup(t, "kittens", true, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	id2 := setupExistingGroup(t, "puppies", true, "202")
+	otherGroupLocation := path.Join("/api/rest/v1/groups/", id2)
+
+	docs.Given("Given the registration of the first owner has been cancelled")
+	attMock.SetupRegistered("101", 42, attendeeservice.StatusCancelled, "Squirrel", "squirrel@example.com")
+
+	docs.When("When the background job checks the group owners")
+	count, err := grpsvc.ReassignCancelledOwners(context.TODO())
+
+	docs.Then("Then no ownership has changed, because there is nobody to take over")
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+	tstRequireOwnership(t, groupLocation, 42, 0)
+	tstRequireOwnership(t, otherGroupLocation, 43, 0)
+	tstRequireMailRequests(t)
+}
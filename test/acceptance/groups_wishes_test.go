@@ -0,0 +1,224 @@
+package acceptance
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eurofurence/reg-room-service/docs"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// --- get ---
+
+func TestGroupsWishes_OwnerGetEmpty(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is owner of a group without room wishes")
+	id1 := setupExistingGroup(t, "kittens", false, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When they request the room wishes of the group")
+	response := tstPerformGet(groupLocation+"/wishes", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is successful and the wishes are empty")
+	wishes := modelsv1.GroupWishes{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &wishes)
+	tstEqualResponseBodies(t, modelsv1.GroupWishes{RoomFlags: []string{}, NearGroups: []string{}}, wishes)
+}
+
+func TestGroupsWishes_MemberGetDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is a member of a group, but not its owner")
+	id1 := setupExistingGroup(t, "kittens", false, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When they request the room wishes of the group")
+	response := tstPerformGet(groupLocation+"/wishes", tstValidUserToken(t, 202))
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "only the group owner or an admin can manage the room wishes of a group")
+}
+
+func TestGroupsWishes_GetGroupNotFound(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.When("When an admin requests the room wishes of a group that does not exist")
+	response := tstPerformGet("/api/rest/v1/groups/7a8b9c0d-1e2f-4a5b-8c7d-9e0f1a2b3c4d/wishes", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "group.id.notfound", "this group does not exist")
+}
+
+// --- update ---
+
+func TestGroupsWishes_OwnerUpdateSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is owner of a group")
+	docs.Given("Given a room type and another group")
+	id1 := setupExistingGroup(t, "kittens", false, "101")
+	id2 := setupExistingGroup(t, "puppies", true, "202")
+	typeID := tstSetupRoomType(t, "quad", 4, []string{}, "")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	token := tstValidUserToken(t, 101)
+
+	docs.When("When they set the room wishes of their group")
+	wishesSent := modelsv1.GroupWishes{
+		Type:       &typeID,
+		Size:       4,
+		RoomFlags:  []string{"handicapped"},
+		NearGroups: []string{id2},
+		Comments:   p("we snore"),
+	}
+	response := tstPerformPut(groupLocation+"/wishes", tstRenderJson(wishesSent), token)
+
+	docs.Then("Then the request is successful and the wishes can be read back")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	wishes := modelsv1.GroupWishes{}
+	tstRequireSuccessResponse(t, tstPerformGet(groupLocation+"/wishes", token), http.StatusOK, &wishes)
+	tstEqualResponseBodies(t, wishesSent, wishes)
+
+	docs.Then("And the change is recorded in the history of the group")
+	history := modelsv1.HistoryList{}
+	tstRequireSuccessResponse(t, tstPerformGet(groupLocation+"/history", tstValidAdminToken(t)), http.StatusOK, &history)
+	entry := history.Entries[len(history.Entries)-1]
+	require.Equal(t, "GroupWish", entry.Entity)
+	require.Equal(t, id1, entry.EntityID)
+	require.Equal(t, "add", entry.Operation)
+	require.Contains(t, entry.Diff, modelsv1.HistoryDiff{Field: "Size", Value: "4"})
+}
+
+func TestGroupsWishes_AdminClearSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with room wishes")
+	id1 := setupExistingGroup(t, "kittens", false, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	token := tstValidAdminToken(t)
+	wishesSent := modelsv1.GroupWishes{Size: 3}
+	require.Equal(t, http.StatusNoContent, tstPerformPut(groupLocation+"/wishes", tstRenderJson(wishesSent), token).status)
+
+	docs.When("When an admin sends empty room wishes for the group")
+	response := tstPerformPut(groupLocation+"/wishes", "{}", token)
+
+	docs.Then("Then the request is successful and the wishes are empty")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	wishes := modelsv1.GroupWishes{}
+	tstRequireSuccessResponse(t, tstPerformGet(groupLocation+"/wishes", token), http.StatusOK, &wishes)
+	tstEqualResponseBodies(t, modelsv1.GroupWishes{RoomFlags: []string{}, NearGroups: []string{}}, wishes)
+}
+
+func TestGroupsWishes_MemberUpdateDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is a member of a group, but not its owner")
+	id1 := setupExistingGroup(t, "kittens", false, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When they try to set the room wishes of the group")
+	response := tstPerformPut(groupLocation+"/wishes", tstRenderJson(modelsv1.GroupWishes{Size: 2}), tstValidUserToken(t, 202))
+
+	docs.Then("Then the request is denied and the wishes are unchanged")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "only the group owner or an admin can manage the room wishes of a group")
+	wishes := modelsv1.GroupWishes{}
+	tstRequireSuccessResponse(t, tstPerformGet(groupLocation+"/wishes", tstValidUserToken(t, 101)), http.StatusOK, &wishes)
+	require.Equal(t, int64(0), wishes.Size)
+}
+
+func TestGroupsWishes_UpdateInvalidData(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is owner of a group")
+	id1 := setupExistingGroup(t, "kittens", false, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When they try to set room wishes with an unknown room type, negative size, a flag that is not a room flag, and their own group as near group")
+	wishesSent := modelsv1.GroupWishes{
+		Type:       p("7a8b9c0d-1e2f-4a5b-8c7d-9e0f1a2b3c4d"),
+		Size:       -1,
+		RoomFlags:  []string{"public"},
+		NearGroups: []string{id1},
+	}
+	response := tstPerformPut(groupLocation+"/wishes", tstRenderJson(wishesSent), tstValidUserToken(t, 101))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "group.data.invalid", url.Values{
+		"type":        []string{"no such room type"},
+		"size":        []string{"size must not be negative"},
+		"room_flags":  []string{"no such room flag 'public'"},
+		"near_groups": []string{"a group cannot wish to be near itself"},
+	})
+}
+
+func TestGroupsWishes_UpdateUnknownNearGroup(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is owner of a group")
+	id1 := setupExistingGroup(t, "kittens", false, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When they try to set room wishes with a near group that does not exist")
+	wishesSent := modelsv1.GroupWishes{
+		NearGroups: []string{"7a8b9c0d-1e2f-4a5b-8c7d-9e0f1a2b3c4d"},
+	}
+	response := tstPerformPut(groupLocation+"/wishes", tstRenderJson(wishesSent), tstValidUserToken(t, 101))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "group.data.invalid", url.Values{
+		"near_groups": []string{"no such group '7a8b9c0d-1e2f-4a5b-8c7d-9e0f1a2b3c4d'"},
+	})
+}
+
+func TestGroupsWishes_UpdateInvalidJson(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is owner of a group")
+	id1 := setupExistingGroup(t, "kittens", false, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When they send room wishes with an unknown field")
+	response := tstPerformPut(groupLocation+"/wishes", `{"floor":3}`, tstValidUserToken(t, 101))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "group.data.invalid", "invalid json provided")
+}
+
+// --- delete group ---
+
+func TestGroupsWishes_RemovedWithGroup(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group with room wishes")
+	id1 := setupExistingGroup(t, "kittens", false, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	token := tstValidAdminToken(t)
+	require.Equal(t, http.StatusNoContent, tstPerformPut(groupLocation+"/wishes", tstRenderJson(modelsv1.GroupWishes{Size: 3}), token).status)
+
+	docs.When("When the group is deleted")
+	response := tstPerformDelete(groupLocation, token)
+
+	docs.Then("Then the request is successful and the removal of the wishes is recorded in the history of the group")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	history := modelsv1.HistoryList{}
+	tstRequireSuccessResponse(t, tstPerformGet(groupLocation+"/history", token), http.StatusOK, &history)
+	operations := make([]string, 0)
+	for _, entry := range history.Entries {
+		operations = append(operations, entry.Entity+" "+entry.Operation)
+	}
+	require.Equal(t, []string{"Group add", "GroupMember add", "GroupWish add", "GroupMember delete", "GroupWish delete", "Group delete"}, operations)
+}
@@ -15,7 +15,7 @@ import (
 
 func TestHealthEndpoint(t *testing.T) {
 	docs.Given("given an unauthenticated user")
-	tstSetup(tstDefaultConfigFileBeforeLaunch)
+	tstSetup(tstDefaultConfigFile)
 	defer tstShutdown()
 
 	docs.When("when the user accesses the health endpoint")
@@ -0,0 +1,349 @@
+package acceptance
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eurofurence/reg-room-service/docs"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+// --- group history ---
+
+func TestHistoryGroup_AdminSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group that has been renamed")
+	id1 := setupExistingGroup(t, "kittens", true, "101")
+	location := path.Join("/api/rest/v1/groups/", id1)
+	tstRenameGroup(t, location, "dogs")
+
+	docs.Given("Given an authorized admin")
+	token := tstValidAdminToken(t)
+
+	docs.When("When they request the history of the group")
+	response := tstPerformGet(location+"/history", token)
+
+	docs.Then("Then the request is successful and the creation is listed with the initial state")
+	history := modelsv1.HistoryList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &history)
+	require.Equal(t, int64(3), history.Total)
+	require.Len(t, history.Entries, 3)
+	require.Equal(t, "add", history.Entries[0].Operation)
+	require.Contains(t, history.Entries[0].Diff, modelsv1.HistoryDiff{Field: "Name", Value: `"kittens"`})
+	require.Contains(t, history.Entries[0].Diff, modelsv1.HistoryDiff{Field: "Owner", Value: "42"})
+
+	docs.Then("And the owner joining the group is listed")
+	require.Equal(t, "GroupMember", history.Entries[1].Entity)
+	require.Equal(t, id1, history.Entries[1].EntityID)
+	require.Equal(t, "add", history.Entries[1].Operation)
+	require.Contains(t, history.Entries[1].Diff, modelsv1.HistoryDiff{Field: "Member.ID", Value: "42"})
+
+	docs.Then("And the change is listed with the previous name")
+	entry := history.Entries[2]
+	require.Equal(t, "Group", entry.Entity)
+	require.Equal(t, id1, entry.EntityID)
+	require.Equal(t, "update", entry.Operation)
+	require.Equal(t, "1234567890", entry.Identity)
+	require.NotEmpty(t, entry.Requestid)
+	require.NotEmpty(t, entry.Timestamp)
+	require.Contains(t, entry.Diff, modelsv1.HistoryDiff{Field: "Name", Value: `"kittens"`})
+}
+
+func TestHistoryGroup_DeletedGroupSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group that has been deleted")
+	id1 := setupExistingGroup(t, "kittens", true, "101")
+	location := path.Join("/api/rest/v1/groups/", id1)
+	require.Equal(t, http.StatusNoContent, tstPerformDelete(location, tstValidAdminToken(t)).status)
+
+	docs.When("When an admin requests the history of the group")
+	response := tstPerformGet(location+"/history", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the creation, the removal of the owner and the deletion are listed")
+	history := modelsv1.HistoryList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &history)
+	require.Equal(t, int64(4), history.Total)
+	operations := make([]string, 0)
+	for _, entry := range history.Entries {
+		operations = append(operations, entry.Entity+" "+entry.Operation)
+	}
+	require.Equal(t, []string{"Group add", "GroupMember add", "GroupMember delete", "Group delete"}, operations)
+	require.Contains(t, history.Entries[3].Diff, modelsv1.HistoryDiff{Field: "Name", Value: `"kittens"`})
+}
+
+func TestHistoryGroup_Paging(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group that has been renamed several times")
+	id1 := setupExistingGroup(t, "kittens", true, "101")
+	location := path.Join("/api/rest/v1/groups/", id1)
+	tstRenameGroup(t, location, "dogs")
+	tstRenameGroup(t, location, "birds")
+	tstRenameGroup(t, location, "snakes")
+
+	docs.When("When an admin requests the history starting after the fourth entry, with a page size of 2")
+	response := tstPerformGet(location+"/history?offset=4&limit=2", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and only the last change is listed, but the total counts all entries")
+	history := modelsv1.HistoryList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &history)
+	require.Equal(t, int64(5), history.Total)
+	require.Len(t, history.Entries, 1)
+	require.Contains(t, history.Entries[0].Diff, modelsv1.HistoryDiff{Field: "Name", Value: `"birds"`})
+
+	docs.When("When an admin requests the first page of the history, with a page size of 3")
+	response = tstPerformGet(location+"/history?limit=3", tstValidAdminToken(t))
+
+	docs.Then("Then the creation, the owner joining and the first change are listed in chronological order")
+	tstRequireSuccessResponse(t, response, http.StatusOK, &history)
+	require.Len(t, history.Entries, 3)
+	require.Equal(t, "add", history.Entries[0].Operation)
+	require.Equal(t, "add", history.Entries[1].Operation)
+	require.Equal(t, "update", history.Entries[2].Operation)
+	require.Contains(t, history.Entries[2].Diff, modelsv1.HistoryDiff{Field: "Name", Value: `"kittens"`})
+}
+
+func TestHistoryGroup_MembersAndBans(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group whose owner has kicked a member and put them on the auto-decline list")
+	id1 := setupExistingGroup(t, "kittens", true, "101", "202")
+	location := path.Join("/api/rest/v1/groups/", id1)
+	require.Equal(t, http.StatusNoContent, tstPerformDelete(location+"/members/43?autodeny=true", tstValidUserToken(t, 101)).status)
+
+	docs.When("When an admin requests the history of the group")
+	response := tstPerformGet(location+"/history", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the membership changes and the ban are listed")
+	history := modelsv1.HistoryList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &history)
+	operations := make([]string, 0)
+	for _, entry := range history.Entries {
+		require.Equal(t, id1, entry.EntityID)
+		operations = append(operations, entry.Entity+" "+entry.Operation)
+	}
+	require.Contains(t, operations, "GroupMember delete")
+	require.Contains(t, operations, "GroupBan add")
+
+	docs.Then("And the entries show which attendee they are about")
+	for _, entry := range history.Entries {
+		if entry.Entity == "GroupBan" {
+			require.Contains(t, entry.Diff, modelsv1.HistoryDiff{Field: "ID", Value: "43"})
+		} else if entry.Entity == "GroupMember" && entry.Operation == "delete" {
+			require.Contains(t, entry.Diff, modelsv1.HistoryDiff{Field: "Member.ID", Value: "43"})
+		}
+	}
+}
+
+func TestHistoryGroup_UserDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a user who is the owner of a group")
+	id1 := setupExistingGroup(t, "kittens", true, "101")
+	location := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When they request the history of the group")
+	response := tstPerformGet(location+"/history", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+}
+
+func TestHistoryGroup_AnonymousDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group")
+	id1 := setupExistingGroup(t, "kittens", true, "101")
+	location := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When an anonymous user requests the history of the group")
+	response := tstPerformGet(location+"/history", tstNoToken())
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusUnauthorized, "auth.unauthorized", "you must be logged in for this operation")
+}
+
+func TestHistoryGroup_NotFound(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.When("When an admin requests the history of a group that does not exist")
+	response := tstPerformGet("/api/rest/v1/groups/7a8b9c0d-1e2f-4a5b-8c7d-9e0f1a2b3c4d/history", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "group.id.notfound", "group does not exist and has no history")
+}
+
+func TestHistoryGroup_InvalidID(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.When("When an admin requests the history of a group with an invalid id")
+	response := tstPerformGet("/api/rest/v1/groups/kittens/history", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "group.id.invalid", "you must specify a valid uuid")
+}
+
+func TestHistoryGroup_InvalidPaging(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group")
+	id1 := setupExistingGroup(t, "kittens", true, "101")
+	location := path.Join("/api/rest/v1/groups/", id1)
+
+	docs.When("When an admin requests the history with an invalid page size")
+	response := tstPerformGet(location+"/history?limit=0", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "request.parse.failed", "limit must be an integer between 1 and 1000")
+}
+
+// --- room history ---
+
+func TestHistoryRoom_AdminSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room that has been resized")
+	location := setupExistingRoom(t, "31415", false)
+	room := tstReadRoom(t, location)
+	room.Size = 3
+	require.Equal(t, http.StatusNoContent, tstPerformPut(location, tstRenderJson(room), tstValidAdminToken(t)).status)
+
+	docs.When("When an admin requests the history of the room")
+	response := tstPerformGet(location+"/history", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the change is listed with the previous size")
+	history := modelsv1.HistoryList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &history)
+	require.Equal(t, int64(2), history.Total)
+	require.Equal(t, "add", history.Entries[0].Operation)
+	require.Contains(t, history.Entries[0].Diff, modelsv1.HistoryDiff{Field: "Name", Value: `"31415"`})
+	entry := history.Entries[1]
+	require.Equal(t, "Room", entry.Entity)
+	require.Equal(t, tstRoomLocationToRoomID(location), entry.EntityID)
+	require.Equal(t, "update", entry.Operation)
+	require.Contains(t, entry.Diff, modelsv1.HistoryDiff{Field: "Size", Value: "2"})
+}
+
+func TestHistoryRoom_Occupants(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with an occupant who has since been removed")
+	location := setupExistingRoom(t, "31415", false, modelsv1.Member{ID: 43, Nickname: "Snep"})
+	require.Equal(t, http.StatusNoContent, tstPerformDelete(location+"/occupants/43", tstValidAdminToken(t)).status)
+
+	docs.When("When an admin requests the history of the room")
+	response := tstPerformGet(location+"/history", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the occupant being added and removed is listed")
+	history := modelsv1.HistoryList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &history)
+	operations := make([]string, 0)
+	for _, entry := range history.Entries {
+		require.Equal(t, tstRoomLocationToRoomID(location), entry.EntityID)
+		operations = append(operations, entry.Entity+" "+entry.Operation)
+	}
+	require.Equal(t, []string{"Room add", "RoomMember add", "RoomMember delete"}, operations)
+	require.Contains(t, history.Entries[1].Diff, modelsv1.HistoryDiff{Field: "Member.ID", Value: "43"})
+	require.Contains(t, history.Entries[2].Diff, modelsv1.HistoryDiff{Field: "Member.ID", Value: "43"})
+}
+
+func TestHistoryRoom_ApiTokenDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room")
+	location := setupExistingRoom(t, "31415", false)
+
+	docs.When("When a downstream service using a valid api token requests the history of the room")
+	response := tstPerformGet(location+"/history", tstValidApiToken())
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+}
+
+func TestHistoryRoom_NotFound(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.When("When an admin requests the history of a room that does not exist")
+	response := tstPerformGet("/api/rest/v1/rooms/7a8b9c0d-1e2f-4a5b-8c7d-9e0f1a2b3c4d/history", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "room.id.notfound", "room does not exist and has no history")
+}
+
+// --- attendee history ---
+
+func TestHistoryAttendee_AdminSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an attendee who was added to a group and then removed again")
+	id1 := setupExistingGroup(t, "kittens", true, "101", "202")
+	location := path.Join("/api/rest/v1/groups/", id1)
+	require.Equal(t, http.StatusNoContent, tstPerformDelete(fmt.Sprintf("%s/members/%d", location, snep.ID), tstValidAdminToken(t)).status)
+
+	docs.When("When an admin requests the history of the attendee")
+	response := tstPerformGet(fmt.Sprintf("/api/rest/v1/attendees/%d/history", snep.ID), tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and both changes are listed in chronological order")
+	history := modelsv1.HistoryList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &history)
+	require.Equal(t, int64(2), history.Total)
+	require.Equal(t, "GroupMember", history.Entries[0].Entity)
+	require.Equal(t, "43", history.Entries[0].EntityID)
+	require.Equal(t, "add", history.Entries[0].Operation)
+	require.Contains(t, history.Entries[0].Diff, modelsv1.HistoryDiff{Field: "GroupID", Value: fmt.Sprintf("%q", id1)})
+	require.Equal(t, "delete", history.Entries[1].Operation)
+}
+
+func TestHistoryAttendee_NoHistorySuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.When("When an admin requests the history of an attendee that has never been in a group or room")
+	response := tstPerformGet("/api/rest/v1/attendees/84/history", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the history is empty")
+	history := modelsv1.HistoryList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &history)
+	require.Equal(t, int64(0), history.Total)
+	require.Empty(t, history.Entries)
+}
+
+func TestHistoryAttendee_InvalidBadgeNumber(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.When("When an admin requests the history with an invalid badge number")
+	response := tstPerformGet("/api/rest/v1/attendees/panther/history", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "request.parse.failed", "invalid badge number - must be positive integer")
+}
+
+// --- helpers ---
+
+func tstRenameGroup(t *testing.T, location string, name string) {
+	group := tstReadGroup(t, location)
+	group.Name = name
+	response := tstPerformPut(location, tstRenderJson(group), tstValidAdminToken(t))
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+}
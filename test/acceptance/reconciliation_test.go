@@ -0,0 +1,565 @@
+package acceptance
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eurofurence/reg-room-service/docs"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/attendeeservice"
+)
+
+// tstSetupReconciliationData creates a group "kittens" with subjects 101 (owner) and 202, and a room "31415" with 202.
+func tstSetupReconciliationData(t *testing.T) (string, string) {
+	id1 := setupExistingGroup(t, "kittens", true, "101", "202")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	roomLocation := setupExistingRoom(t, "31415", false, modelsv1.Member{ID: 43, Nickname: "Snep"})
+	return groupLocation, roomLocation
+}
+
+// --- report ---
+
+func TestReconciliation_ReportSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group and a room with an attendee whose registration has since been cancelled")
+	groupLocation, roomLocation := tstSetupReconciliationData(t)
+	attMock.SetupRegistered("202", 43, attendeeservice.StatusCancelled, "Snep", "snep@example.com")
+
+	docs.When("When an admin requests the reconciliation report")
+	response := tstPerformGet("/api/rest/v1/reconciliation/report", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the report lists the attendee with their group and room")
+	actual := modelsv1.ReconciliationReport{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	expected := modelsv1.ReconciliationReport{
+		Applied: false,
+		Checked: 2,
+		NotAttending: []modelsv1.ReconciliationEntry{
+			{
+				ID:        43,
+				Nickname:  "Snep",
+				Status:    "cancelled",
+				GroupID:   tstReadGroup(t, groupLocation).ID,
+				GroupName: "kittens",
+				RoomID:    tstRoomLocationToRoomID(roomLocation),
+				RoomName:  "31415",
+				Removed:   false,
+			},
+		},
+	}
+	tstEqualResponseBodies(t, expected, actual)
+
+	docs.Then("And nothing has been changed")
+	require.Len(t, tstReadGroup(t, groupLocation).Members, 2)
+	require.Len(t, tstReadRoom(t, roomLocation).Occupants, 1)
+	tstRequireMailRequests(t)
+}
+
+func TestReconciliation_ReportApiTokenSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group whose members are all attending")
+	_, _ = tstSetupReconciliationData(t)
+
+	docs.When("When the reconciliation report is requested with the api token")
+	response := tstPerformGet("/api/rest/v1/reconciliation/report", tstValidApiToken())
+
+	docs.Then("Then the request is successful and the report is empty")
+	actual := modelsv1.ReconciliationReport{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Equal(t, 2, actual.Checked)
+	require.Empty(t, actual.NotAttending)
+}
+
+func TestReconciliation_UserDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is the owner of a group")
+	_, _ = tstSetupReconciliationData(t)
+
+	docs.When("When they attempt to request the reconciliation report or apply it")
+	reportResponse := tstPerformGet("/api/rest/v1/reconciliation/report", tstValidUserToken(t, 101))
+	applyResponse := tstPerformPostNoBody("/api/rest/v1/reconciliation/apply", tstValidUserToken(t, 101))
+
+	docs.Then("Then both requests are denied")
+	tstRequireErrorResponse(t, reportResponse, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+	tstRequireErrorResponse(t, applyResponse, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+}
+
+func TestReconciliation_AttSrvDown(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group and a room with occupants")
+	_, _ = tstSetupReconciliationData(t)
+
+	docs.Given("Given the attendee service is unavailable")
+	attMock.Unavailable()
+
+	docs.When("When an admin requests the reconciliation report")
+	response := tstPerformGet("/api/rest/v1/reconciliation/report", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadGateway, "attendee.validation.error", "downstream error when contacting attendee service")
+}
+
+// --- apply ---
+
+func TestReconciliation_ApplyRemovesMember(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group and a room with an attendee whose registration has since been cancelled")
+	groupLocation, roomLocation := tstSetupReconciliationData(t)
+	attMock.SetupRegistered("202", 43, attendeeservice.StatusCancelled, "Snep", "snep@example.com")
+
+	docs.When("When an admin applies reconciliation")
+	response := tstPerformPostNoBody("/api/rest/v1/reconciliation/apply", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the report shows the attendee as removed")
+	actual := modelsv1.ReconciliationReport{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.True(t, actual.Applied)
+	require.Len(t, actual.NotAttending, 1)
+	require.Equal(t, int64(43), actual.NotAttending[0].ID)
+	require.True(t, actual.NotAttending[0].Removed)
+
+	docs.Then("And the attendee has been removed from the group and the room")
+	group := tstReadGroup(t, groupLocation)
+	require.Equal(t, []modelsv1.Member{{ID: 42, Nickname: "Squirrel"}}, group.Members)
+	require.Empty(t, tstReadRoom(t, roomLocation).Occupants)
+
+	docs.Then("And the group owner and the attendee have been informed by mail")
+	tstRequireMailRequests(t,
+		tstGroupMailToOwner("group-member-removed", "kittens", "101", "202"),
+		tstRoomMailToOccupant("room-occupant-removed", "31415", "202"))
+
+	docs.Then("And the removal has been historized")
+	history := modelsv1.HistoryList{}
+	tstRequireSuccessResponse(t, tstPerformGet("/api/rest/v1/attendees/43/history", tstValidAdminToken(t)), http.StatusOK, &history)
+	latest := history.Entries[len(history.Entries)-2:]
+	require.Equal(t, "delete", latest[0].Operation)
+	require.Equal(t, "delete", latest[1].Operation)
+}
+
+func TestReconciliation_CommandLineWithoutToken(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group and a room with an attendee whose registration has since been cancelled")
+	groupLocation, roomLocation := tstSetupReconciliationData(t)
+	attMock.SetupRegistered("202", 43, attendeeservice.StatusCancelled, "Snep", "snep@example.com")
+
+	docs.When("When reconciliation is applied from the command line, where there is no user token to forward")
+	report, err := reconcilesvc.RunReconciliation(context.TODO(), true)
+
+	docs.Then("Then it is successful, because the attendee service is contacted with the api token")
+	require.NoError(t, err)
+	require.Len(t, report.NotAttending, 1)
+	require.True(t, report.NotAttending[0].Removed)
+
+	docs.Then("And the attendee has been removed from the group and the room")
+	require.Len(t, tstReadGroup(t, groupLocation).Members, 1)
+	require.Empty(t, tstReadRoom(t, roomLocation).Occupants)
+
+	docs.Then("And the group owner and the attendee have been informed by mail")
+	tstRequireMailRequests(t,
+		tstGroupMailToOwner("group-member-removed", "kittens", "101", "202"),
+		tstRoomMailToOccupant("room-occupant-removed", "31415", "202"))
+}
+
+func TestReconciliation_ApplyOwnerCancelled(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group whose owner's registration has since been cancelled, with another attending member")
+	groupLocation, _ := tstSetupReconciliationData(t)
+	attMock.SetupRegistered("101", 42, attendeeservice.StatusCancelled, "Squirrel", "squirrel@example.com")
+
+	docs.When("When an admin applies reconciliation")
+	response := tstPerformPostNoBody("/api/rest/v1/reconciliation/apply", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the report shows the owner as removed")
+	actual := modelsv1.ReconciliationReport{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Len(t, actual.NotAttending, 1)
+	require.True(t, actual.NotAttending[0].GroupOwner)
+	require.True(t, actual.NotAttending[0].Removed)
+
+	docs.Then("And ownership has passed to the other member, and the previous owner has been removed from the group")
+	group := tstReadGroup(t, groupLocation)
+	require.Equal(t, int64(43), group.Owner)
+	require.Equal(t, []modelsv1.Member{{ID: 43, Nickname: "Snep"}}, group.Members)
+
+	docs.Then("And the new owner has been informed by mail")
+	tstRequireMailRequests(t,
+		tstGroupMailToOwner("group-owner-fallback", "kittens", "202", "101"),
+		tstGroupMailToOwner("group-member-removed", "kittens", "202", "101"))
+}
+
+func TestReconciliation_ApplyDisbandsGroup(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group whose only member is its owner, whose registration has since been cancelled, with a pending invitation")
+	id1 := setupExistingGroup(t, "kittens", true, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	tstSetupInvitation(t, groupLocation)
+	attMock.SetupRegistered("101", 42, attendeeservice.StatusCancelled, "Squirrel", "squirrel@example.com")
+
+	docs.When("When an admin applies reconciliation")
+	response := tstPerformPostNoBody("/api/rest/v1/reconciliation/apply", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful")
+	actual := modelsv1.ReconciliationReport{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Len(t, actual.NotAttending, 1)
+	require.True(t, actual.NotAttending[0].Removed)
+
+	docs.Then("And the group has been disbanded, because nobody can take over")
+	getResponse := tstPerformGet(groupLocation, tstValidAdminToken(t))
+	tstRequireErrorResponse(t, getResponse, http.StatusNotFound, "group.id.notfound", "group does not exist")
+
+	docs.Then("And the owner and the invited attendee have been informed by mail")
+	tstRequireMailRequests(t,
+		tstGroupMailToMember("group-disbanded", "kittens", "101", "101", ""),
+		tstGroupMailToMember("group-disbanded", "kittens", "1234567890", "101", ""))
+}
+
+func TestReconciliation_ApplyDisbandsGroupWithBans(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group whose only member is its owner, whose registration has since been cancelled, with an auto-decline list entry")
+	id1 := setupExistingGroup(t, "kittens", true, "101")
+	groupLocation := path.Join("/api/rest/v1/groups/", id1)
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody(groupLocation+"/bans/43", tstValidAdminToken(t)).status)
+	attMock.SetupRegistered("101", 42, attendeeservice.StatusCancelled, "Squirrel", "squirrel@example.com")
+
+	docs.When("When an admin applies reconciliation")
+	response := tstPerformPostNoBody("/api/rest/v1/reconciliation/apply", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful")
+	actual := modelsv1.ReconciliationReport{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Len(t, actual.NotAttending, 1)
+	require.True(t, actual.NotAttending[0].Removed)
+
+	docs.Then("And the group has been disbanded together with its auto-decline list")
+	getResponse := tstPerformGet(groupLocation, tstValidAdminToken(t))
+	tstRequireErrorResponse(t, getResponse, http.StatusNotFound, "group.id.notfound", "group does not exist")
+
+	docs.Then("And the owner has been informed by mail")
+	tstRequireMailRequests(t,
+		tstGroupMailToMember("group-disbanded", "kittens", "101", "101", ""))
+}
+
+func TestReconciliation_ApplyNothingToDo(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group and a room whose members are all attending")
+	groupLocation, roomLocation := tstSetupReconciliationData(t)
+
+	docs.When("When the api token is used to apply reconciliation")
+	response := tstPerformPostNoBody("/api/rest/v1/reconciliation/apply", tstValidApiToken())
+
+	docs.Then("Then the request is successful and nothing has been changed")
+	actual := modelsv1.ReconciliationReport{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Empty(t, actual.NotAttending)
+	require.Len(t, tstReadGroup(t, groupLocation).Members, 2)
+	require.Len(t, tstReadRoom(t, roomLocation).Occupants, 1)
+	tstRequireMailRequests(t)
+}
+
+// --- attendee status events ---
+
+func tstAttendeeStatusEvent(eventID string, badgeNo int64, oldStatus attendeeservice.Status, newStatus attendeeservice.Status, nickname string) string {
+	return tstRenderJson(modelsv1.AttendeeStatusEvent{
+		EventID:   eventID,
+		ID:        badgeNo,
+		OldStatus: string(oldStatus),
+		NewStatus: string(newStatus),
+		Nickname:  nickname,
+	})
+}
+
+func TestAttendeeStatusEvent_CancelledRemoves(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group and a room with an attendee whose registration has just been cancelled")
+	groupLocation, roomLocation := tstSetupReconciliationData(t)
+	attMock.SetupRegistered("202", 43, attendeeservice.StatusCancelled, "Snep", "snep@example.com")
+
+	docs.When("When the attendee service sends the status change event")
+	body := tstAttendeeStatusEvent("ev-0001", 43, attendeeservice.StatusPaid, attendeeservice.StatusCancelled, "")
+	response := tstPerformPost("/api/rest/v1/reconciliation/attendee-events", body, tstValidApiToken())
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status)
+
+	docs.Then("And the attendee has been removed from the group and the room")
+	require.Equal(t, []modelsv1.Member{{ID: 42, Nickname: "Squirrel"}}, tstReadGroup(t, groupLocation).Members)
+	require.Empty(t, tstReadRoom(t, roomLocation).Occupants)
+
+	docs.Then("And the group owner and the attendee have been informed by mail")
+	tstRequireMailRequests(t,
+		tstGroupMailToOwner("group-member-removed", "kittens", "101", "202"),
+		tstRoomMailToOccupant("room-occupant-removed", "31415", "202"))
+}
+
+func TestAttendeeStatusEvent_OutOfOrder(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group and a room with an attendee whose registration was cancelled and then reinstated")
+	groupLocation, roomLocation := tstSetupReconciliationData(t)
+
+	docs.When("When the attendee service sends the cancellation event only after the registration was reinstated")
+	body := tstAttendeeStatusEvent("ev-0001", 43, attendeeservice.StatusPaid, attendeeservice.StatusCancelled, "")
+	response := tstPerformPost("/api/rest/v1/reconciliation/attendee-events", body, tstValidApiToken())
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status)
+
+	docs.Then("And the attendee has not been removed, because their current status is attending")
+	require.Len(t, tstReadGroup(t, groupLocation).Members, 2)
+	require.Len(t, tstReadRoom(t, roomLocation).Occupants, 1)
+	tstRequireMailRequests(t)
+}
+
+func TestAttendeeStatusEvent_NicknameChanged(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group and a room with an attendee who has just changed their nickname")
+	groupLocation, roomLocation := tstSetupReconciliationData(t)
+
+	docs.When("When the attendee service sends the change event")
+	body := tstAttendeeStatusEvent("ev-0001", 43, attendeeservice.StatusPaid, attendeeservice.StatusPaid, "Snow Leopard")
+	response := tstPerformPost("/api/rest/v1/reconciliation/attendee-events", body, tstValidApiToken())
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status)
+
+	docs.Then("And the cached nickname has been updated in the group and the room")
+	require.Equal(t, []modelsv1.Member{{ID: 42, Nickname: "Squirrel"}, {ID: 43, Nickname: "Snow Leopard"}}, tstReadGroup(t, groupLocation).Members)
+	require.Equal(t, []modelsv1.Member{{ID: 43, Nickname: "Snow Leopard"}}, tstReadRoom(t, roomLocation).Occupants)
+}
+
+func TestAttendeeStatusEvent_Duplicate(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group and a room with an attendee whose nickname change event has already been processed")
+	groupLocation, roomLocation := tstSetupReconciliationData(t)
+	body := tstAttendeeStatusEvent("ev-0001", 43, attendeeservice.StatusPaid, attendeeservice.StatusPaid, "Snow Leopard")
+	response := tstPerformPost("/api/rest/v1/reconciliation/attendee-events", body, tstValidApiToken())
+	require.Equal(t, http.StatusNoContent, response.status)
+
+	docs.When("When the attendee service sends an event with the same event id again")
+	body = tstAttendeeStatusEvent("ev-0001", 43, attendeeservice.StatusPaid, attendeeservice.StatusPaid, "Tiger")
+	response = tstPerformPost("/api/rest/v1/reconciliation/attendee-events", body, tstValidApiToken())
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status)
+
+	docs.Then("And the event has been ignored")
+	require.Equal(t, []modelsv1.Member{{ID: 43, Nickname: "Snow Leopard"}}, tstReadRoom(t, roomLocation).Occupants)
+	require.Equal(t, "Snow Leopard", tstReadGroup(t, groupLocation).Members[1].Nickname)
+}
+
+func TestAttendeeStatusEvent_AdminDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group and a room with an attendee whose registration has just been cancelled")
+	groupLocation, _ := tstSetupReconciliationData(t)
+	attMock.SetupRegistered("202", 43, attendeeservice.StatusCancelled, "Snep", "snep@example.com")
+
+	docs.When("When an admin attempts to send the status change event")
+	body := tstAttendeeStatusEvent("ev-0001", 43, attendeeservice.StatusPaid, attendeeservice.StatusCancelled, "")
+	response := tstPerformPost("/api/rest/v1/reconciliation/attendee-events", body, tstValidAdminToken(t))
+
+	docs.Then("Then the request is denied, because only the attendee service may send events")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+
+	docs.Then("And nothing has been changed")
+	require.Len(t, tstReadGroup(t, groupLocation).Members, 2)
+}
+
+func TestAttendeeStatusEvent_InvalidData(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given the attendee service")
+
+	docs.When("When it sends events with invalid data")
+	noEventID := tstAttendeeStatusEvent("", 43, attendeeservice.StatusPaid, attendeeservice.StatusCancelled, "")
+	noEventIDResponse := tstPerformPost("/api/rest/v1/reconciliation/attendee-events", noEventID, tstValidApiToken())
+	badStatus := tstAttendeeStatusEvent("ev-0001", 43, attendeeservice.StatusPaid, "gone", "")
+	badStatusResponse := tstPerformPost("/api/rest/v1/reconciliation/attendee-events", badStatus, tstValidApiToken())
+	badJsonResponse := tstPerformPost("/api/rest/v1/reconciliation/attendee-events", `{"event_id":"ev-0002","unknown":1}`, tstValidApiToken())
+
+	docs.Then("Then the requests fail with the expected errors")
+	tstRequireErrorResponse(t, noEventIDResponse, http.StatusBadRequest, "attendee.event.invalid", "event_id must be between 1 and 64 characters long")
+	tstRequireErrorResponse(t, badStatusResponse, http.StatusBadRequest, "attendee.event.invalid", "new_status must be a valid registration status")
+	tstRequireErrorResponse(t, badJsonResponse, http.StatusBadRequest, "attendee.event.invalid", "invalid json provided")
+}
+
+func TestAttendeeStatusEvent_AttSrvDown(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group and a room with an attendee whose registration has just been cancelled")
+	groupLocation, roomLocation := tstSetupReconciliationData(t)
+	attMock.SetupRegistered("202", 43, attendeeservice.StatusCancelled, "Snep", "snep@example.com")
+
+	docs.Given("Given the attendee service is unavailable for status requests")
+	attMock.Unavailable()
+
+	docs.When("When the status change event is sent")
+	body := tstAttendeeStatusEvent("ev-0001", 43, attendeeservice.StatusPaid, attendeeservice.StatusCancelled, "")
+	response := tstPerformPost("/api/rest/v1/reconciliation/attendee-events", body, tstValidApiToken())
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadGateway, "attendee.validation.error", "downstream error when contacting attendee service")
+
+	docs.Then("And the attendee has not been removed")
+	require.Len(t, tstReadGroup(t, groupLocation).Members, 2)
+	require.Len(t, tstReadRoom(t, roomLocation).Occupants, 1)
+
+	docs.When("When the attendee service is available again, and the event is sent again")
+	attMock.(*attendeeservice.MockImpl).IsUnavailable = false
+	response = tstPerformPost("/api/rest/v1/reconciliation/attendee-events", body, tstValidApiToken())
+
+	docs.Then("Then the event is processed, because it was not recorded as processed")
+	require.Equal(t, http.StatusNoContent, response.status)
+	require.Len(t, tstReadGroup(t, groupLocation).Members, 1)
+	require.Empty(t, tstReadRoom(t, roomLocation).Occupants)
+}
+
+// --- member refresh ---
+
+func tstAvatar(badgeNo string) *string {
+	url := "https://example.com/avatars/" + badgeNo + ".png"
+	return &url
+}
+
+func TestMemberRefresh_Success(t *testing.T) {
+	tstSetup(tstDefaultConfigFileAvatars)
+	defer tstShutdown()
+
+	docs.Given("Given a group and a room with an attendee who has changed their nickname since joining")
+	groupLocation, roomLocation := tstSetupReconciliationData(t)
+	attMock.SetupRegistered("202", 43, attendeeservice.StatusPaid, "Snow Leopard", "snep@example.com")
+
+	docs.When("When an admin requests a refresh of the cached member data")
+	response := tstPerformPostNoBody("/api/rest/v1/reconciliation/refresh-members", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and reports one updated attendee")
+	actual := modelsv1.MemberRefreshResult{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	tstEqualResponseBodies(t, modelsv1.MemberRefreshResult{Checked: 2, Updated: 1}, actual)
+
+	docs.Then("And the group and room show the current nickname, and avatars according to the configured template")
+	require.Equal(t, []modelsv1.Member{
+		{ID: 42, Nickname: "Squirrel", Avatar: tstAvatar("42")},
+		{ID: 43, Nickname: "Snow Leopard", Avatar: tstAvatar("43")},
+	}, tstReadGroup(t, groupLocation).Members)
+	require.Equal(t, []modelsv1.Member{
+		{ID: 43, Nickname: "Snow Leopard", Avatar: tstAvatar("43")},
+	}, tstReadRoom(t, roomLocation).Occupants)
+}
+
+func TestMemberRefresh_NothingToDo(t *testing.T) {
+	tstSetup(tstDefaultConfigFileAvatars)
+	defer tstShutdown()
+
+	docs.Given("Given a group and a room whose cached member data is current")
+	_, _ = tstSetupReconciliationData(t)
+
+	docs.When("When the api token is used to request a refresh of the cached member data")
+	response := tstPerformPostNoBody("/api/rest/v1/reconciliation/refresh-members", tstValidApiToken())
+
+	docs.Then("Then the request is successful and nothing has been updated")
+	actual := modelsv1.MemberRefreshResult{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	tstEqualResponseBodies(t, modelsv1.MemberRefreshResult{Checked: 2, Updated: 0}, actual)
+}
+
+func TestMemberRefresh_JobOnlyDue(t *testing.T) {
+	tstSetup(tstDefaultConfigFileAvatars)
+	defer tstShutdown()
+
+	docs.Given("Given a group and a room with an attendee who has changed their nickname since joining")
+	groupLocation, roomLocation := tstSetupReconciliationData(t)
+	attMock.SetupRegistered("202", 43, attendeeservice.StatusPaid, "Snow Leopard", "snep@example.com")
+
+	docs.When("When the periodic refresh runs for the first time")
+	count, err := reconcilesvc.RunMemberRefresh(context.TODO())
+
+	docs.Then("Then it reads everyone, and updates the attendee")
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	require.Equal(t, "Snow Leopard", tstReadRoom(t, roomLocation).Occupants[0].Nickname)
+
+	docs.When("When the attendee changes their nickname again, and the periodic refresh runs again before the data is due")
+	attMock.SetupRegistered("202", 43, attendeeservice.StatusPaid, "Tiger", "snep@example.com")
+	count, err = reconcilesvc.RunMemberRefresh(context.TODO())
+
+	docs.Then("Then nobody is read or updated")
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+	require.Equal(t, "Snow Leopard", tstReadRoom(t, roomLocation).Occupants[0].Nickname)
+
+	docs.When("When an admin requests a refresh of the cached member data")
+	response := tstPerformPostNoBody("/api/rest/v1/reconciliation/refresh-members", tstValidAdminToken(t))
+
+	docs.Then("Then everyone is read regardless, and the attendee is updated")
+	actual := modelsv1.MemberRefreshResult{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	tstEqualResponseBodies(t, modelsv1.MemberRefreshResult{Checked: 2, Updated: 1}, actual)
+	require.Equal(t, "Tiger", tstReadGroup(t, groupLocation).Members[1].Nickname)
+}
+
+func TestMemberRefresh_UserDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileAvatars)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration who is the owner of a group")
+	_, _ = tstSetupReconciliationData(t)
+
+	docs.When("When they attempt to request a refresh of the cached member data")
+	response := tstPerformPostNoBody("/api/rest/v1/reconciliation/refresh-members", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+}
+
+func TestMemberRefresh_AttSrvDown(t *testing.T) {
+	tstSetup(tstDefaultConfigFileAvatars)
+	defer tstShutdown()
+
+	docs.Given("Given a group and a room with occupants")
+	_, _ = tstSetupReconciliationData(t)
+
+	docs.Given("Given the attendee service is unavailable")
+	attMock.Unavailable()
+
+	docs.When("When an admin requests a refresh of the cached member data")
+	response := tstPerformPostNoBody("/api/rest/v1/reconciliation/refresh-members", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadGateway, "attendee.validation.error", "downstream error when contacting attendee service")
+}
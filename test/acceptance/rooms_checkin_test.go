@@ -0,0 +1,301 @@
+package acceptance
+
+import (
+	"fmt"
+	"github.com/eurofurence/reg-room-service/docs"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func tstCheckIn(t *testing.T, location string, badgeNo int64) {
+	response := tstPerformPostNoBody(fmt.Sprintf("%s/occupants/%d/checkin", location, badgeNo), tstValidAdminToken(t))
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+}
+
+func tstRequireCheckin(t *testing.T, location string, badgeNo int64, checkedIn bool, checkedOut bool) {
+	room := tstReadRoom(t, location)
+	for _, occupant := range room.Occupants {
+		if occupant.ID == badgeNo {
+			require.Equal(t, checkedIn, occupant.CheckedIn != nil, "unexpected check-in state")
+			require.Equal(t, checkedOut, occupant.CheckedOut != nil, "unexpected check-out state")
+			return
+		}
+	}
+	require.Fail(t, "occupant not found in room")
+}
+
+// --- check in ---
+
+func TestRoomsCheckIn_AdminSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with an occupant who has not checked in")
+	location := setupExistingRoom(t, "31415", false, squirrel)
+
+	docs.When("When an admin checks in the occupant")
+	response := tstPerformPostNoBody(location+"/occupants/42/checkin", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status)
+
+	docs.Then("And the occupant is checked in")
+	tstRequireCheckin(t, location, 42, true, false)
+
+	docs.Then("And the check-in has been recorded in the history of the room membership")
+	historyResponse := tstPerformGet("/api/rest/v1/attendees/42/history", tstValidAdminToken(t))
+	history := modelsv1.HistoryList{}
+	tstRequireSuccessResponse(t, historyResponse, http.StatusOK, &history)
+	last := history.Entries[len(history.Entries)-1]
+	require.Equal(t, "RoomMember", last.Entity)
+	require.Equal(t, "update", last.Operation)
+	fields := make([]string, 0)
+	for _, diff := range last.Diff {
+		fields = append(fields, diff.Field)
+	}
+	require.Contains(t, fields, "CheckedInAt")
+}
+
+func TestRoomsCheckIn_ApiTokenCheckInAndOut(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with an occupant who has not checked in")
+	location := setupExistingRoom(t, "31415", false, squirrel)
+
+	docs.When("When a downstream service using a valid api token checks the occupant in and then out")
+	checkinResponse := tstPerformPostNoBody(location+"/occupants/42/checkin", tstValidApiToken())
+	checkoutResponse := tstPerformPostNoBody(location+"/occupants/42/checkout", tstValidApiToken())
+
+	docs.Then("Then both requests are successful")
+	require.Equal(t, http.StatusNoContent, checkinResponse.status)
+	require.Equal(t, http.StatusNoContent, checkoutResponse.status)
+
+	docs.Then("And the occupant has both a check-in and a check-out time")
+	tstRequireCheckin(t, location, 42, true, true)
+}
+
+func TestRoomsCheckIn_AlreadyCheckedIn(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with an occupant who has checked in")
+	location := setupExistingRoom(t, "31415", false, squirrel)
+	tstCheckIn(t, location, 42)
+
+	docs.When("When an admin attempts to check in the occupant again")
+	response := tstPerformPostNoBody(location+"/occupants/42/checkin", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "room.checkin.conflict", "this attendee has already checked in")
+}
+
+func TestRoomsCheckIn_AgainAfterCheckOut(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with an occupant who has checked in and out")
+	location := setupExistingRoom(t, "31415", false, squirrel)
+	tstCheckIn(t, location, 42)
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody(location+"/occupants/42/checkout", tstValidAdminToken(t)).status)
+
+	docs.When("When an admin checks in the occupant again")
+	response := tstPerformPostNoBody(location+"/occupants/42/checkin", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status)
+
+	docs.Then("And the occupant is checked in, and the check-out time has been cleared")
+	tstRequireCheckin(t, location, 42, true, false)
+}
+
+func TestRoomsCheckIn_UserDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a finalized room with an occupant")
+	location := setupExistingRoom(t, "31415", true, squirrel)
+
+	docs.When("When the occupant attempts to check themself in")
+	response := tstPerformPostNoBody(location+"/occupants/42/checkin", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+
+	docs.Then("And the occupant is not checked in")
+	tstRequireCheckin(t, location, 42, false, false)
+}
+
+func TestRoomsCheckIn_NotOccupant(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room and an attendee with an active registration who is not in any room")
+	location := setupExistingRoom(t, "31415", false)
+	registerSubject("101")
+
+	docs.When("When an admin attempts to check in the attendee")
+	response := tstPerformPostNoBody(location+"/occupants/42/checkin", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "room.occupant.notfound", "this attendee is not in any room")
+}
+
+// --- check out ---
+
+func TestRoomsCheckOut_NotCheckedIn(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with an occupant who has not checked in")
+	location := setupExistingRoom(t, "31415", false, squirrel)
+
+	docs.When("When an admin attempts to check out the occupant")
+	response := tstPerformPostNoBody(location+"/occupants/42/checkout", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "room.checkin.conflict", "this attendee has not checked in")
+}
+
+// --- list filter ---
+
+func TestRoomsList_CheckedInFilter(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with an occupant who has checked in, and a room with an occupant who has not")
+	location1 := setupExistingRoom(t, "31415", false, squirrel)
+	tstCheckIn(t, location1, 42)
+	roomID2 := tstSetupAssignmentRoom(t, "27182", 2, []string{})
+	registerSubject("202")
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody("/api/rest/v1/rooms/"+roomID2+"/occupants/43", tstValidAdminToken(t)).status)
+
+	docs.Given("Given an empty room")
+	_ = tstSetupAssignmentRoom(t, "16180", 2, []string{})
+
+	docs.When("When an admin lists the rooms where someone is checked in")
+	response := tstPerformGet("/api/rest/v1/rooms?checked_in=true", tstValidAdminToken(t))
+
+	docs.Then("Then only the room with the checked in occupant is listed")
+	actual := modelsv1.RoomList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Equal(t, 1, len(actual.Rooms))
+	require.Equal(t, tstRoomLocationToRoomID(location1), actual.Rooms[0].ID)
+
+	docs.When("When an admin lists the rooms where someone is not checked in")
+	response = tstPerformGet("/api/rest/v1/rooms?checked_in=false", tstValidAdminToken(t))
+
+	docs.Then("Then only the room with the occupant who has not checked in is listed")
+	actual = modelsv1.RoomList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Equal(t, 1, len(actual.Rooms))
+	require.Equal(t, roomID2, actual.Rooms[0].ID)
+}
+
+func TestRoomsList_CheckedInFilterInvalid(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.When("When an admin lists the rooms with an invalid checked_in parameter")
+	response := tstPerformGet("/api/rest/v1/rooms?checked_in=perhaps", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "request.parse.failed", "invalid checked_in parameter, try true, 1, false, 0 or omit")
+}
+
+// --- my room ---
+
+func TestRoomsMy_CheckinOnlyOwnVisible(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a finalized room where both occupants have checked in")
+	location := setupExistingRoom(t, "31415", true, squirrel, snep)
+	tstCheckIn(t, location, 42)
+	tstCheckIn(t, location, 43)
+
+	docs.When("When one of the occupants requests their room")
+	response := tstPerformGet("/api/rest/v1/rooms/my", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is successful and they can only see their own check-in time")
+	actual := modelsv1.Room{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Equal(t, 2, len(actual.Occupants))
+	require.NotNil(t, actual.Occupants[0].CheckedIn)
+	require.Nil(t, actual.Occupants[1].CheckedIn)
+}
+
+// --- arrival report ---
+
+func TestRoomsArrivals_Success(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with two occupants, one of whom has checked in")
+	location := setupExistingRoom(t, "31415", false, squirrel, snep)
+	tstCheckIn(t, location, 42)
+
+	docs.Given("Given the configuration sets the convention start to 2030-09-18")
+
+	docs.When("When an admin requests the arrival report")
+	response := tstPerformGet("/api/rest/v1/rooms/arrivals?format=yaml", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and both occupants are expected on the first day, one of whom has arrived today")
+	require.Equal(t, http.StatusOK, response.status, "unexpected http response status")
+	require.Equal(t, "application/yaml", response.contentType)
+	actual := modelsv1.ArrivalReport{}
+	require.Nil(t, yaml.Unmarshal([]byte(response.body), &actual))
+	expected := modelsv1.ArrivalReport{
+		Days: []modelsv1.ArrivalDay{
+			{
+				Date:       time.Now().Format("2006-01-02"),
+				Expected:   0,
+				Arrived:    1,
+				NotArrived: []int64{},
+			},
+			{
+				Date:       "2030-09-18",
+				Expected:   2,
+				Arrived:    0,
+				NotArrived: []int64{43},
+			},
+		},
+	}
+	tstEqualResponseBodies(t, expected, actual)
+}
+
+func TestRoomsArrivals_CSV(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with two occupants who have not checked in")
+	_ = setupExistingRoom(t, "31415", false, squirrel, snep)
+
+	docs.When("When an admin requests the arrival report as csv")
+	response := tstPerformGet("/api/rest/v1/rooms/arrivals?format=csv", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the response lists the expected arrivals")
+	require.Equal(t, http.StatusOK, response.status, "unexpected http response status")
+	require.Equal(t, "text/csv; charset=utf-8", response.contentType)
+	expected := "date,expected,arrived,not_arrived\n" +
+		"2030-09-18,2,0,\"42,43\"\n"
+	require.Equal(t, expected, response.body)
+}
+
+func TestRoomsArrivals_UserDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration")
+	registerSubject("101")
+
+	docs.When("When they attempt to request the arrival report")
+	response := tstPerformGet("/api/rest/v1/rooms/arrivals", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+}
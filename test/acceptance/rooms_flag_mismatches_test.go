@@ -0,0 +1,63 @@
+package acceptance
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/eurofurence/reg-room-service/docs"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoomsFlagMismatches_Success(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group whose flags require the handicapped room flag, with two members")
+	registerSubject("101")
+	registerSubject("202")
+	groupID := tstSetupAssignmentGroup(t, "kittens", []string{"handicapped"}, squirrel.ID, snep.ID)
+
+	docs.Given("Given one member is in a room with the handicapped flag, and the other in a room without it")
+	roomWithFlag := tstSetupAssignmentRoom(t, "27182", 2, []string{"handicapped"})
+	roomWithoutFlag := tstSetupAssignmentRoom(t, "31415", 2, []string{})
+	addResponse := tstPerformPostNoBody("/api/rest/v1/rooms/"+roomWithFlag+"/occupants/43", tstValidAdminToken(t))
+	require.Equal(t, http.StatusNoContent, addResponse.status, "unexpected http response status")
+	addResponse = tstPerformPostNoBody("/api/rest/v1/rooms/"+roomWithoutFlag+"/occupants/42?force=true", tstValidAdminToken(t))
+	require.Equal(t, http.StatusNoContent, addResponse.status, "unexpected http response status")
+
+	docs.When("When an admin requests the list of flag mismatches")
+	response := tstPerformGet("/api/rest/v1/rooms/flag-mismatches", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and lists the member in the room without the flag")
+	actual := modelsv1.FlagMismatchList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	expected := modelsv1.FlagMismatchList{
+		Mismatches: []modelsv1.FlagMismatch{
+			{
+				RoomID:       roomWithoutFlag,
+				RoomName:     "31415",
+				ID:           42,
+				Nickname:     "Squirrel",
+				GroupID:      groupID,
+				GroupName:    "kittens",
+				MissingFlags: []string{"handicapped"},
+			},
+		},
+	}
+	tstEqualResponseBodies(t, expected, actual)
+}
+
+func TestRoomsFlagMismatches_UserDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration")
+	registerSubject("101")
+
+	docs.When("When they attempt to request the list of flag mismatches")
+	response := tstPerformGet("/api/rest/v1/rooms/flag-mismatches", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+}
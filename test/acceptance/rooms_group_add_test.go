@@ -0,0 +1,219 @@
+package acceptance
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eurofurence/reg-room-service/docs"
+	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/attendeeservice"
+)
+
+// --- add group ---
+
+func TestRoomsAddGroup_AdminSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an empty room with four beds and a group of four attending members")
+	roomID := tstSetupAssignmentRoom(t, "quad", 4, []string{})
+	tstSetupConcurrentAttendees()
+	groupID := tstSetupAssignmentGroup(t, "cats", []string{}, 1000, 1001, 1002, 1003)
+
+	docs.When("When an admin adds the group to the room")
+	response := tstPerformPostNoBody("/api/rest/v1/rooms/"+roomID+"/groups/"+groupID, tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and all members are in the room")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	tstRequireRoomOccupants(t, roomID, 1000, 1001, 1002, 1003)
+}
+
+func TestRoomsAddGroup_SomeAlreadyInRoom(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with three beds and a group of three, one of whom is already in the room")
+	roomID := tstSetupAssignmentRoom(t, "triple", 3, []string{})
+	tstSetupConcurrentAttendees()
+	groupID := tstSetupAssignmentGroup(t, "cats", []string{}, 1000, 1001, 1002)
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody("/api/rest/v1/rooms/"+roomID+"/occupants/1001", tstValidAdminToken(t)).status)
+
+	docs.When("When an admin adds the group to the room")
+	response := tstPerformPostNoBody("/api/rest/v1/rooms/"+roomID+"/groups/"+groupID, tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the remaining members are added")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	tstRequireRoomOccupants(t, roomID, 1000, 1001, 1002)
+}
+
+func TestRoomsAddGroup_RoomTooSmall(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with four beds, one of them taken, and a group of four attending members")
+	roomID := tstSetupAssignmentRoom(t, "quad", 4, []string{})
+	tstSetupConcurrentAttendees()
+	groupID := tstSetupAssignmentGroup(t, "cats", []string{}, 1000, 1001, 1002, 1003)
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody("/api/rest/v1/rooms/"+roomID+"/occupants/1004", tstValidAdminToken(t)).status)
+
+	docs.When("When an admin adds the group to the room")
+	response := tstPerformPostNoBody("/api/rest/v1/rooms/"+roomID+"/groups/"+groupID, tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error and nobody is added")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "room.size.full", "this room does not have 4 free beds for the group")
+	tstRequireRoomOccupants(t, roomID, 1004)
+}
+
+func TestRoomsAddGroup_MemberNotAttending(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an empty room and a group with a member whose registration was cancelled")
+	roomID := tstSetupAssignmentRoom(t, "quad", 4, []string{})
+	tstSetupConcurrentAttendees()
+	groupID := tstSetupAssignmentGroup(t, "cats", []string{}, 1000, 1001, 1002)
+	attMock.SetupRegistered("5002", 1002, attendeeservice.StatusCancelled, "Stressed2", "stressed2@example.com")
+
+	docs.When("When an admin adds the group to the room")
+	response := tstPerformPostNoBody("/api/rest/v1/rooms/"+roomID+"/groups/"+groupID, tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error and nobody is added")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "attendee.status.not.attending", "registration of group member 1002 is not in attending status")
+	tstRequireRoomOccupants(t, roomID)
+}
+
+func TestRoomsAddGroup_MemberInOtherRoom(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an empty room and a group with a member who is already in another room")
+	roomID := tstSetupAssignmentRoom(t, "quad", 4, []string{})
+	otherRoomID := tstSetupAssignmentRoom(t, "single", 1, []string{})
+	tstSetupConcurrentAttendees()
+	groupID := tstSetupAssignmentGroup(t, "cats", []string{}, 1000, 1001)
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody("/api/rest/v1/rooms/"+otherRoomID+"/occupants/1001", tstValidAdminToken(t)).status)
+
+	docs.When("When an admin adds the group to the room")
+	response := tstPerformPostNoBody("/api/rest/v1/rooms/"+roomID+"/groups/"+groupID, tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error and nobody is added")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "room.occupant.conflict", "group member 1001 is already in another room")
+	tstRequireRoomOccupants(t, roomID)
+	tstRequireRoomOccupants(t, otherRoomID, 1001)
+}
+
+func TestRoomsAddGroup_AllAlreadyInRoom(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a group whose members are all in a room")
+	roomID := tstSetupAssignmentRoom(t, "double", 2, []string{})
+	tstSetupConcurrentAttendees()
+	groupID := tstSetupAssignmentGroup(t, "cats", []string{}, 1000, 1001)
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody("/api/rest/v1/rooms/"+roomID+"/groups/"+groupID, tstValidAdminToken(t)).status)
+
+	docs.When("When an admin adds the group to the room again")
+	response := tstPerformPostNoBody("/api/rest/v1/rooms/"+roomID+"/groups/"+groupID, tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "room.occupant.duplicate", "all members of this group are already in this room")
+}
+
+func TestRoomsAddGroup_GroupNotFound(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an empty room")
+	roomID := tstSetupAssignmentRoom(t, "double", 2, []string{})
+
+	docs.When("When an admin adds a group that does not exist to the room")
+	response := tstPerformPostNoBody("/api/rest/v1/rooms/"+roomID+"/groups/7a8b9c0d-1e2f-4a5b-8c7d-9e0f1a2b3c4d", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "group.id.notfound", "group does not exist")
+}
+
+func TestRoomsAddGroup_InvalidGroupID(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an empty room")
+	roomID := tstSetupAssignmentRoom(t, "double", 2, []string{})
+
+	docs.When("When an admin adds a group with an invalid id to the room")
+	response := tstPerformPostNoBody("/api/rest/v1/rooms/"+roomID+"/groups/kittens", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "group.id.invalid", "you must specify a valid group uuid")
+}
+
+func TestRoomsAddGroup_UserDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an empty room and a group owned by a user")
+	roomID := tstSetupAssignmentRoom(t, "double", 2, []string{})
+	groupID := setupExistingGroup(t, "kittens", false, "101")
+
+	docs.When("When the group owner tries to add the group to the room")
+	response := tstPerformPostNoBody("/api/rest/v1/rooms/"+roomID+"/groups/"+groupID, tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is denied and nobody is added")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+	tstRequireRoomOccupants(t, roomID)
+}
+
+// --- remove group ---
+
+func TestRoomsRemoveGroup_AdminSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with a group and another occupant in it")
+	roomID := tstSetupAssignmentRoom(t, "quad", 4, []string{})
+	tstSetupConcurrentAttendees()
+	groupID := tstSetupAssignmentGroup(t, "cats", []string{}, 1000, 1001)
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody("/api/rest/v1/rooms/"+roomID+"/groups/"+groupID, tstValidAdminToken(t)).status)
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody("/api/rest/v1/rooms/"+roomID+"/occupants/1002", tstValidAdminToken(t)).status)
+
+	docs.When("When an admin removes the group from the room")
+	response := tstPerformDelete("/api/rest/v1/rooms/"+roomID+"/groups/"+groupID, tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and only the other occupant remains")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	tstRequireRoomOccupants(t, roomID, 1002)
+}
+
+func TestRoomsRemoveGroup_NotInRoom(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an empty room and a group")
+	roomID := tstSetupAssignmentRoom(t, "quad", 4, []string{})
+	tstSetupConcurrentAttendees()
+	groupID := tstSetupAssignmentGroup(t, "cats", []string{}, 1000, 1001)
+
+	docs.When("When an admin removes the group from the room")
+	response := tstPerformDelete("/api/rest/v1/rooms/"+roomID+"/groups/"+groupID, tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "room.occupant.notfound", "no member of this group is in this room")
+}
+
+func TestRoomsRemoveGroup_UserDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with a group in it")
+	roomID := tstSetupAssignmentRoom(t, "double", 2, []string{})
+	groupID := setupExistingGroup(t, "kittens", false, "101")
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody("/api/rest/v1/rooms/"+roomID+"/groups/"+groupID, tstValidAdminToken(t)).status)
+
+	docs.When("When the group owner tries to remove the group from the room")
+	response := tstPerformDelete("/api/rest/v1/rooms/"+roomID+"/groups/"+groupID, tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is denied and the group stays in the room")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+	tstRequireRoomOccupants(t, roomID, 42)
+}
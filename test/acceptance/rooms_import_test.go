@@ -0,0 +1,303 @@
+package acceptance
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eurofurence/reg-room-service/docs"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+const tstRoomImportCSV = `name,size,flags,comments
+31415,2,,
+27182,4,"handicapped,final",near the elevator
+`
+
+const tstRoomImportYAML = `rooms:
+  - name: "31415"
+    size: 2
+  - name: "27182"
+    size: 4
+    flags:
+      - handicapped
+      - final
+    comments: near the elevator
+`
+
+func tstRequireImportedRooms(t *testing.T, result modelsv1.RoomImportResult) {
+	require.Len(t, result.Created, 2)
+
+	room1 := tstReadRoom(t, "/api/rest/v1/rooms/"+result.Created[0])
+	require.Equal(t, modelsv1.Room{
+		ID:    result.Created[0],
+		Name:  "31415",
+		Flags: []string{},
+		Size:  2,
+	}, room1)
+
+	room2 := tstReadRoom(t, "/api/rest/v1/rooms/"+result.Created[1])
+	require.Equal(t, modelsv1.Room{
+		ID:       result.Created[1],
+		Name:     "27182",
+		Flags:    []string{"final", "handicapped"},
+		Comments: p("near the elevator"),
+		Size:     4,
+	}, room2)
+}
+
+func TestRoomsImport_AdminCSVSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.When("When an admin imports a valid room list in CSV format")
+	response := tstPerformPost("/api/rest/v1/rooms/import?format=csv", tstRoomImportCSV, tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the rooms have been created in the order of the import")
+	result := modelsv1.RoomImportResult{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &result)
+	require.False(t, result.DryRun)
+	require.Equal(t, 2, result.Rows)
+	require.Empty(t, result.Errors)
+	tstRequireImportedRooms(t, result)
+}
+
+func TestRoomsImport_ApiTokenYAMLSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.When("When a downstream service using a valid api token imports a valid room list in YAML format")
+	response := tstPerformPost("/api/rest/v1/rooms/import?format=yaml", tstRoomImportYAML, tstValidApiToken())
+
+	docs.Then("Then the request is successful and the rooms have been created in the order of the import")
+	result := modelsv1.RoomImportResult{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &result)
+	require.Equal(t, 2, result.Rows)
+	tstRequireImportedRooms(t, result)
+}
+
+func TestRoomsImport_DryRunReportsRowErrors(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an existing room")
+	_ = setupExistingRoom(t, "31415", false)
+
+	docs.When("When an admin imports a room list with several invalid rows in dry run mode")
+	body := `name,size,flags
+31415,2,
+,2,
+27182,two,
+16180,3,floof
+16180,3,
+`
+	response := tstPerformPost("/api/rest/v1/rooms/import?format=csv&dryrun=true", body, tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the invalid rows are reported with their line numbers")
+	result := modelsv1.RoomImportResult{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &result)
+	expected := modelsv1.RoomImportResult{
+		DryRun:  true,
+		Rows:    5,
+		Created: []string{},
+		Errors: []modelsv1.RoomImportError{
+			{Row: 2, Name: "31415", Details: map[string][]string{"name": {"another room with this name already exists"}}},
+			{Row: 3, Details: map[string][]string{"name": {"room name cannot be empty"}}},
+			{Row: 4, Name: "27182", Details: map[string][]string{"size": {"room size must be a positive integer"}}},
+			{Row: 5, Name: "16180", Details: map[string][]string{"flags": {"no such flag 'floof'"}}},
+			{Row: 6, Name: "16180", Details: map[string][]string{"name": {"room name already used in row 5"}}},
+		},
+	}
+	require.Equal(t, expected, result)
+
+	docs.Then("And no rooms have been created")
+	rooms := modelsv1.RoomList{}
+	tstRequireSuccessResponse(t, tstPerformGet("/api/rest/v1/rooms", tstValidAdminToken(t)), http.StatusOK, &rooms)
+	require.Len(t, rooms.Rooms, 1)
+}
+
+func TestRoomsImport_DryRunValid(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.When("When an admin imports a valid room list in dry run mode")
+	response := tstPerformPost("/api/rest/v1/rooms/import?dryrun=true", tstRoomImportYAML, tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and no errors are reported")
+	result := modelsv1.RoomImportResult{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &result)
+	require.Equal(t, modelsv1.RoomImportResult{DryRun: true, Rows: 2, Created: []string{}}, result)
+
+	docs.Then("And no rooms have been created")
+	rooms := modelsv1.RoomList{}
+	tstRequireSuccessResponse(t, tstPerformGet("/api/rest/v1/rooms", tstValidAdminToken(t)), http.StatusOK, &rooms)
+	require.Empty(t, rooms.Rooms)
+}
+
+func TestRoomsImport_InvalidRowNothingCreated(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.When("When an admin imports a room list with one invalid row")
+	body := `name,size
+31415,2
+27182,0
+`
+	response := tstPerformPost("/api/rest/v1/rooms/import?format=csv", body, tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "room.data.invalid", url.Values{
+		"row 3": {"size: room size must be a positive integer"},
+	})
+
+	docs.Then("And no rooms have been created, not even for the valid rows")
+	rooms := modelsv1.RoomList{}
+	tstRequireSuccessResponse(t, tstPerformGet("/api/rest/v1/rooms", tstValidAdminToken(t)), http.StatusOK, &rooms)
+	require.Empty(t, rooms.Rooms)
+}
+
+func TestRoomsImport_Empty(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.When("When an admin imports a room list that contains only the header line")
+	response := tstPerformPost("/api/rest/v1/rooms/import?format=csv", "name,size\n", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "room.data.invalid", "the import does not contain any rooms")
+}
+
+func TestRoomsImport_CSVHeaderMissingColumn(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.When("When an admin imports a room list in CSV format without a size column")
+	response := tstPerformPost("/api/rest/v1/rooms/import?format=csv", "name,flags\n31415,\n", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "request.parse.failed", "csv header line must contain the columns name and size or type")
+}
+
+func TestRoomsImport_InvalidYAML(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.When("When an admin imports a room list, but supplies syntactically invalid YAML")
+	response := tstPerformPost("/api/rest/v1/rooms/import", "rooms: [[[", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "request.parse.failed", "invalid yaml provided")
+}
+
+func TestRoomsImport_InvalidFormat(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.When("When an admin imports a room list, but requests an unsupported format")
+	response := tstPerformPost("/api/rest/v1/rooms/import?format=xlsx", tstRoomImportCSV, tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "request.parse.failed", "invalid format parameter, try csv, yaml or omit")
+}
+
+func TestRoomsImport_UserDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration")
+	registerSubject("101")
+
+	docs.When("When they attempt to import a room list")
+	response := tstPerformPost("/api/rest/v1/rooms/import?format=csv", tstRoomImportCSV, tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+}
+
+// --- export ---
+
+func TestRoomsExport_AdminYAMLSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a registered attendee with an active registration who is in a room")
+	location := setupExistingRoom(t, "rodents", true, squirrel)
+
+	docs.When("When an admin exports the rooms in YAML format")
+	response := tstPerformGet("/api/rest/v1/rooms/export?format=yaml", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the response contains the rooms with their occupants")
+	require.Equal(t, http.StatusOK, response.status, "unexpected http response status")
+	require.Equal(t, "application/yaml", response.contentType)
+	expected := `rooms:
+    - id: ` + tstRoomLocationToRoomID(location) + `
+      name: rodents
+      flags:
+        - final
+      comments: A nice comment for rodents
+      size: 2
+      occupants:
+        - id: 42
+          nickname: Squirrel
+`
+	require.Equal(t, expected, response.body)
+}
+
+func TestRoomsExport_AdminCSVSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two registered attendees with an active registration who are in the same room")
+	location := setupExistingRoom(t, "rodents", true, squirrel, snep)
+
+	docs.When("When an admin exports the rooms in CSV format")
+	response := tstPerformGet("/api/rest/v1/rooms/export?format=csv", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the response contains the rooms with their occupants")
+	require.Equal(t, http.StatusOK, response.status, "unexpected http response status")
+	require.Equal(t, "text/csv; charset=utf-8", response.contentType)
+	expected := "id,name,size,flags,comments,occupants,block,type\n" +
+		tstRoomLocationToRoomID(location) + ",rodents,2,final,A nice comment for rodents,\"42,43\",,\n"
+	require.Equal(t, expected, response.body)
+}
+
+func TestRoomsExport_ReimportAfterDelete(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an export of a room")
+	location := setupExistingRoom(t, "rodents", true)
+	export := tstPerformGet("/api/rest/v1/rooms/export", tstValidAdminToken(t))
+	require.Equal(t, http.StatusOK, export.status, "unexpected http response status")
+
+	docs.Given("Given the room has been deleted")
+	require.Equal(t, http.StatusNoContent, tstPerformDelete(location, tstValidAdminToken(t)).status)
+
+	docs.When("When an admin imports the export")
+	response := tstPerformPost("/api/rest/v1/rooms/import", export.body, tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the room has been created again")
+	result := modelsv1.RoomImportResult{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &result)
+	require.Len(t, result.Created, 1)
+	room := tstReadRoom(t, "/api/rest/v1/rooms/"+result.Created[0])
+	require.Equal(t, "rodents", room.Name)
+	require.Equal(t, []string{"final"}, room.Flags)
+	require.Equal(t, int64(2), room.Size)
+}
+
+func TestRoomsExport_UserDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user with an active registration")
+	registerSubject("101")
+
+	docs.When("When they attempt to export the rooms")
+	response := tstPerformGet("/api/rest/v1/rooms/export", tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+}
@@ -3,6 +3,7 @@ package acceptance
 import (
 	"github.com/eurofurence/reg-room-service/docs"
 	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/stretchr/testify/require"
 	"net/http"
 	"net/url"
 	"testing"
@@ -39,7 +40,7 @@ func TestRoomsList_AdminSuccess(t *testing.T) {
 		Size:      2,
 		Occupants: []modelsv1.Member{squirrel},
 	}
-	expected := modelsv1.RoomList{}
+	expected := modelsv1.RoomList{Total: 2}
 	expected.Rooms = append(expected.Rooms, &rm2, &rm1) // sorted by name
 	tstEqualResponseBodies(t, expected, actual)
 }
@@ -67,7 +68,7 @@ func TestRoomsList_AdminSuccess_Filtered(t *testing.T) {
 		Size:      2,
 		Occupants: []modelsv1.Member{squirrel},
 	}
-	expected := modelsv1.RoomList{Rooms: []*modelsv1.Room{&rm1}}
+	expected := modelsv1.RoomList{Rooms: []*modelsv1.Room{&rm1}, Total: 1}
 	tstEqualResponseBodies(t, expected, actual)
 }
 
@@ -104,7 +105,7 @@ func TestRoomsList_ApiTokenSuccess(t *testing.T) {
 		Size:      2,
 		Occupants: []modelsv1.Member{snep},
 	}
-	expected := modelsv1.RoomList{}
+	expected := modelsv1.RoomList{Total: 2}
 	expected.Rooms = append(expected.Rooms, &rm2, &rm1) // sorted by name
 	tstEqualResponseBodies(t, expected, actual)
 }
@@ -151,3 +152,118 @@ func TestRoomsList_InvalidQueryParams(t *testing.T) {
 	docs.Then("Then the request fails with the expected error")
 	tstRequireErrorResponse(t, response, http.StatusBadRequest, "request.parse.failed", url.Values{"details": []string{"member ids must be numeric and valid. Invalid member id: kittycat"}})
 }
+
+func TestRoomsList_NameSearch(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given three rooms, two of which have a name containing 'cat' in different case")
+	_ = setupExistingRoom(t, "Cats", false, snep)
+	_ = setupExistingRoom(t, "bobcats", false)
+	_ = setupExistingRoom(t, "rodents", false, squirrel)
+
+	docs.When("When an admin searches for rooms by part of their name")
+	response := tstPerformGet("/api/rest/v1/rooms?name=CAT", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and only the matching rooms are listed, sorted by name ignoring case")
+	actual := modelsv1.RoomList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Equal(t, []string{"bobcats", "Cats"}, tstRoomNames(actual))
+	require.Equal(t, int64(2), actual.Total)
+}
+
+func TestRoomsList_SortedByOccupancyAndPaged(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an empty room and two rooms with one occupant each")
+	_ = setupExistingRoom(t, "aardvarks", false)
+	_ = setupExistingRoom(t, "rodents", false, squirrel)
+	_ = setupExistingRoom(t, "cats", false, snep)
+
+	docs.When("When an admin requests the first page of rooms, fullest rooms first")
+	response := tstPerformGet("/api/rest/v1/rooms?sort=occupancy&order=desc&limit=2", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the page lists the occupied rooms by name, with the total count of all rooms")
+	actual := modelsv1.RoomList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Equal(t, []string{"cats", "rodents"}, tstRoomNames(actual))
+	require.Equal(t, int64(3), actual.Total)
+
+	docs.When("When an admin requests the second page")
+	response = tstPerformGet("/api/rest/v1/rooms?sort=occupancy&order=desc&offset=2&limit=2", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the page lists the empty room")
+	actual = modelsv1.RoomList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Equal(t, []string{"aardvarks"}, tstRoomNames(actual))
+	require.Equal(t, int64(3), actual.Total)
+}
+
+func TestRoomsList_SortedBySize(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given rooms of different sizes")
+	_ = tstSetupAssignmentRoom(t, "single", 1, []string{})
+	_ = tstSetupAssignmentRoom(t, "suite", 4, []string{})
+	_ = tstSetupAssignmentRoom(t, "double", 2, []string{})
+
+	docs.When("When an admin lists the rooms, largest rooms first")
+	response := tstPerformGet("/api/rest/v1/rooms?sort=size&order=desc", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the rooms are listed by size")
+	actual := modelsv1.RoomList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Equal(t, []string{"suite", "double", "single"}, tstRoomNames(actual))
+	require.Equal(t, int64(3), actual.Total)
+}
+
+func TestRoomsList_FilteredAndPaged(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two rooms whose occupants have not checked in, and an empty room")
+	_ = setupExistingRoom(t, "rodents", false, squirrel)
+	_ = setupExistingRoom(t, "cats", false, snep)
+	_ = setupExistingRoom(t, "aardvarks", false)
+
+	docs.When("When an admin requests the second page of rooms where someone is not checked in")
+	response := tstPerformGet("/api/rest/v1/rooms?checked_in=false&offset=1&limit=1", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the total only counts the rooms matching the filter")
+	actual := modelsv1.RoomList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Equal(t, []string{"rodents"}, tstRoomNames(actual))
+	require.Equal(t, int64(2), actual.Total)
+}
+
+func TestRoomsList_InvalidListParams(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an admin")
+	token := tstValidAdminToken(t)
+
+	docs.When("When they try to list rooms sorted by an unknown key")
+	response := tstPerformGet("/api/rest/v1/rooms?sort=color", token)
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "request.parse.failed", url.Values{"details": []string{"sort must be one of name, size, occupancy, created"}})
+
+	docs.When("When they try to list rooms with a page size that is too large")
+	response = tstPerformGet("/api/rest/v1/rooms?limit=1001", token)
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "request.parse.failed", url.Values{"details": []string{"limit must be an integer between 1 and 1000"}})
+}
+
+// helpers
+
+func tstRoomNames(list modelsv1.RoomList) []string {
+	names := make([]string, 0, len(list.Rooms))
+	for _, room := range list.Rooms {
+		names = append(names, room.Name)
+	}
+	return names
+}
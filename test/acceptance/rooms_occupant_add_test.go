@@ -302,3 +302,142 @@ func TestRoomsAddOccupant_BadgeNumberNegative(t *testing.T) {
 }
 
 // TODO technical errors (downstream failures etc.)
+
+// --- flag requirements ---
+
+// tstSetupHandicappedGroup creates a group with the handicapped flag, which requires rooms with the handicapped flag
+// in the test configurations, and squirrel as its owner.
+func tstSetupHandicappedGroup(t *testing.T) {
+	registerSubject("101")
+	_ = tstSetupAssignmentGroup(t, "kittens", []string{"handicapped"}, squirrel.ID)
+}
+
+func TestRoomsAddOccupant_FlagMismatchWarn(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with free beds but without the handicapped flag")
+	location := setupExistingRoom(t, "31415", false)
+
+	docs.Given("Given an attendee in a group whose flags require the handicapped room flag")
+	tstSetupHandicappedGroup(t)
+
+	docs.Given("Given the configuration only warns about flag mismatches")
+
+	docs.When("When an admin adds the attendee to the room")
+	response := tstPerformPostNoBody(location+"/occupants/42", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status)
+
+	docs.Then("And the attendee has been added to the room")
+	tstRoomState(t, location, squirrel)
+}
+
+func TestRoomsAddOccupant_FlagMismatchRefuse(t *testing.T) {
+	tstSetup(tstDefaultConfigFileFlagRules)
+	defer tstShutdown()
+
+	docs.Given("Given a room with free beds but without the handicapped flag")
+	location := setupExistingRoom(t, "31415", false)
+
+	docs.Given("Given an attendee in a group whose flags require the handicapped room flag")
+	tstSetupHandicappedGroup(t)
+
+	docs.Given("Given the configuration refuses flag mismatches")
+
+	docs.When("When an admin adds the attendee to the room")
+	response := tstPerformPostNoBody(location+"/occupants/42", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "room.flags.mismatch", "the attendee's group requires room flags this room does not have: handicapped")
+
+	docs.Then("And the room is unchanged")
+	tstRoomState(t, location)
+}
+
+func TestRoomsAddOccupant_FlagMismatchAdminForce(t *testing.T) {
+	tstSetup(tstDefaultConfigFileFlagRules)
+	defer tstShutdown()
+
+	docs.Given("Given a room with free beds but without the handicapped flag")
+	location := setupExistingRoom(t, "31415", false)
+
+	docs.Given("Given an attendee in a group whose flags require the handicapped room flag")
+	tstSetupHandicappedGroup(t)
+
+	docs.Given("Given the configuration refuses flag mismatches")
+
+	docs.When("When an admin adds the attendee to the room with the force parameter")
+	response := tstPerformPostNoBody(location+"/occupants/42?force=true", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status)
+
+	docs.Then("And the attendee has been added to the room")
+	tstRoomState(t, location, squirrel)
+}
+
+func TestRoomsAddOccupant_FlagMismatchApiTokenForce(t *testing.T) {
+	tstSetup(tstDefaultConfigFileFlagRules)
+	defer tstShutdown()
+
+	docs.Given("Given a room with free beds but without the handicapped flag")
+	location := setupExistingRoom(t, "31415", false)
+
+	docs.Given("Given an attendee in a group whose flags require the handicapped room flag")
+	tstSetupHandicappedGroup(t)
+
+	docs.Given("Given the configuration refuses flag mismatches")
+
+	docs.When("When the attendee is added to the room with the force parameter, using the api token")
+	response := tstPerformPostNoBody(location+"/occupants/42?force=true", tstValidApiToken())
+
+	docs.Then("Then the request fails with the expected error, because only admins can override flag requirements")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "room.flags.mismatch", "the attendee's group requires room flags this room does not have: handicapped")
+
+	docs.Then("And the room is unchanged")
+	tstRoomState(t, location)
+}
+
+func TestRoomsAddOccupant_FlagMatch(t *testing.T) {
+	tstSetup(tstDefaultConfigFileFlagRules)
+	defer tstShutdown()
+
+	docs.Given("Given a room with free beds and the handicapped flag")
+	roomID := tstSetupAssignmentRoom(t, "31415", 2, []string{"handicapped"})
+
+	docs.Given("Given an attendee in a group whose flags require the handicapped room flag")
+	tstSetupHandicappedGroup(t)
+
+	docs.Given("Given the configuration refuses flag mismatches")
+
+	docs.When("When an admin adds the attendee to the room")
+	response := tstPerformPostNoBody("/api/rest/v1/rooms/"+roomID+"/occupants/42", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status)
+
+	docs.Then("And the attendee has been added to the room")
+	tstRequireRoomOccupants(t, roomID, 42)
+}
+
+func TestRoomsAddOccupant_ForceInvalid(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with free beds")
+	location := setupExistingRoom(t, "31415", false)
+
+	docs.Given("Given an attendee with an active registration who is not in any room")
+	registerSubject("101")
+
+	docs.When("When an admin adds the attendee to the room with an invalid force parameter")
+	response := tstPerformPostNoBody(location+"/occupants/42?force=perhaps", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "request.parse.failed", "invalid force parameter, try true, 1, false, 0 or omit")
+
+	docs.Then("And the room is unchanged")
+	tstRoomState(t, location)
+}
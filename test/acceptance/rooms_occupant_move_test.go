@@ -0,0 +1,247 @@
+package acceptance
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eurofurence/reg-room-service/docs"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+func tstSetupOccupant(t *testing.T, roomID string, badgeNo int64) {
+	response := tstPerformPostNoBody(fmt.Sprintf("/api/rest/v1/rooms/%s/occupants/%d", roomID, badgeNo), tstValidAdminToken(t))
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+}
+
+func tstReadRoomHistory(t *testing.T, roomID string) modelsv1.HistoryList {
+	history := modelsv1.HistoryList{}
+	response := tstPerformGet("/api/rest/v1/rooms/"+roomID+"/history", tstValidAdminToken(t))
+	tstRequireSuccessResponse(t, response, http.StatusOK, &history)
+	return history
+}
+
+func tstReadAttendeeHistory(t *testing.T, badgeNo int64) modelsv1.HistoryList {
+	history := modelsv1.HistoryList{}
+	response := tstPerformGet(fmt.Sprintf("/api/rest/v1/attendees/%d/history", badgeNo), tstValidAdminToken(t))
+	tstRequireSuccessResponse(t, response, http.StatusOK, &history)
+	return history
+}
+
+// --- move ---
+
+func TestRoomsMoveOccupant_AdminSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two rooms, one with an occupant and one with a free bed")
+	tstSetupConcurrentAttendees()
+	roomA := tstSetupAssignmentRoom(t, "A", 2, []string{})
+	roomB := tstSetupAssignmentRoom(t, "B", 2, []string{})
+	tstSetupOccupant(t, roomA, 1000)
+	tstSetupOccupant(t, roomB, 1001)
+
+	docs.When("When an admin moves the occupant to the other room")
+	response := tstPerformPostNoBody(fmt.Sprintf("/api/rest/v1/rooms/%s/occupants/1000/move/%s", roomA, roomB), tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the occupant is in the other room")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	tstRequireRoomOccupants(t, roomA)
+	tstRequireRoomOccupants(t, roomB, 1000, 1001)
+
+	docs.Then("And the move is recorded in the history of the occupant and both rooms, listing the previous and new room")
+	expectedDiff := []modelsv1.HistoryDiff{
+		{Field: "From[1000]", Value: fmt.Sprintf("%q", roomA)},
+		{Field: "To[1000]", Value: fmt.Sprintf("%q", roomB)},
+	}
+	history := tstReadAttendeeHistory(t, 1000)
+	entry := history.Entries[len(history.Entries)-1]
+	require.Equal(t, "RoomMember", entry.Entity)
+	require.Equal(t, "move", entry.Operation)
+	require.Equal(t, expectedDiff, entry.Diff)
+	for _, roomID := range []string{roomA, roomB} {
+		roomHistory := tstReadRoomHistory(t, roomID)
+		roomEntry := roomHistory.Entries[len(roomHistory.Entries)-1]
+		require.Equal(t, "Room", roomEntry.Entity)
+		require.Equal(t, "move", roomEntry.Operation)
+		require.Equal(t, entry.Requestid, roomEntry.Requestid)
+		require.Equal(t, expectedDiff, roomEntry.Diff)
+	}
+}
+
+func TestRoomsMoveOccupant_TargetFull(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two rooms with one bed each, both occupied")
+	tstSetupConcurrentAttendees()
+	roomA := tstSetupAssignmentRoom(t, "A", 1, []string{})
+	roomB := tstSetupAssignmentRoom(t, "B", 1, []string{})
+	tstSetupOccupant(t, roomA, 1000)
+	tstSetupOccupant(t, roomB, 1001)
+
+	docs.When("When an admin tries to move an occupant to the other room")
+	response := tstPerformPostNoBody(fmt.Sprintf("/api/rest/v1/rooms/%s/occupants/1000/move/%s", roomA, roomB), tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error and nobody is moved")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "room.size.full", "room B does not have a free bed for attendee 1000")
+	tstRequireRoomOccupants(t, roomA, 1000)
+	tstRequireRoomOccupants(t, roomB, 1001)
+}
+
+func TestRoomsMoveOccupant_WrongRoom(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given three rooms, one of them with an occupant")
+	tstSetupConcurrentAttendees()
+	roomA := tstSetupAssignmentRoom(t, "A", 2, []string{})
+	roomB := tstSetupAssignmentRoom(t, "B", 2, []string{})
+	roomC := tstSetupAssignmentRoom(t, "C", 2, []string{})
+	tstSetupOccupant(t, roomA, 1000)
+
+	docs.When("When an admin tries to move the occupant out of a room they are not in")
+	response := tstPerformPostNoBody(fmt.Sprintf("/api/rest/v1/rooms/%s/occupants/1000/move/%s", roomB, roomC), tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error and nobody is moved")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "room.occupant.conflict", "attendee 1000 is in a different room")
+	tstRequireRoomOccupants(t, roomA, 1000)
+}
+
+func TestRoomsMoveOccupant_SameRoom(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with an occupant")
+	tstSetupConcurrentAttendees()
+	roomA := tstSetupAssignmentRoom(t, "A", 2, []string{})
+	tstSetupOccupant(t, roomA, 1000)
+
+	docs.When("When an admin tries to move the occupant to the room they are already in")
+	response := tstPerformPostNoBody(fmt.Sprintf("/api/rest/v1/rooms/%s/occupants/1000/move/%s", roomA, roomA), tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "room.data.invalid", "the target room must be different from the current room")
+}
+
+func TestRoomsMoveOccupant_UserDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an authorized user who is in a room, and another room with a free bed")
+	squirrel := registerSubject("101")
+	roomA := tstSetupAssignmentRoom(t, "A", 2, []string{})
+	roomB := tstSetupAssignmentRoom(t, "B", 2, []string{})
+	tstSetupOccupant(t, roomA, squirrel)
+
+	docs.When("When they try to move themselves to the other room")
+	response := tstPerformPostNoBody(fmt.Sprintf("/api/rest/v1/rooms/%s/occupants/%d/move/%s", roomA, squirrel, roomB), tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is denied and nobody is moved")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+	tstRequireRoomOccupants(t, roomA, squirrel)
+}
+
+// --- swap ---
+
+func TestRoomsSwapOccupants_AdminSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two full rooms with one bed each")
+	tstSetupConcurrentAttendees()
+	roomA := tstSetupAssignmentRoom(t, "A", 1, []string{})
+	roomB := tstSetupAssignmentRoom(t, "B", 1, []string{})
+	tstSetupOccupant(t, roomA, 1000)
+	tstSetupOccupant(t, roomB, 1001)
+
+	docs.When("When an admin swaps the two occupants")
+	response := tstPerformPostNoBody(fmt.Sprintf("/api/rest/v1/rooms/%s/occupants/1000/swap/1001", roomA), tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the occupants have exchanged rooms")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	tstRequireRoomOccupants(t, roomA, 1001)
+	tstRequireRoomOccupants(t, roomB, 1000)
+
+	docs.Then("And the swap is recorded in the history of each occupant, listing their previous and new room")
+	history := tstReadAttendeeHistory(t, 1000)
+	entry := history.Entries[len(history.Entries)-1]
+	require.Equal(t, "move", entry.Operation)
+	require.Equal(t, []modelsv1.HistoryDiff{
+		{Field: "From[1000]", Value: fmt.Sprintf("%q", roomA)},
+		{Field: "To[1000]", Value: fmt.Sprintf("%q", roomB)},
+	}, entry.Diff)
+	otherHistory := tstReadAttendeeHistory(t, 1001)
+	otherEntry := otherHistory.Entries[len(otherHistory.Entries)-1]
+	require.Equal(t, "move", otherEntry.Operation)
+	require.Equal(t, entry.Requestid, otherEntry.Requestid)
+	require.Equal(t, []modelsv1.HistoryDiff{
+		{Field: "From[1001]", Value: fmt.Sprintf("%q", roomB)},
+		{Field: "To[1001]", Value: fmt.Sprintf("%q", roomA)},
+	}, otherEntry.Diff)
+
+	docs.Then("And in the history of both rooms, listing the previous and new rooms of both occupants")
+	for _, roomID := range []string{roomA, roomB} {
+		roomHistory := tstReadRoomHistory(t, roomID)
+		roomEntry := roomHistory.Entries[len(roomHistory.Entries)-1]
+		require.Equal(t, "move", roomEntry.Operation)
+		require.ElementsMatch(t, []modelsv1.HistoryDiff{
+			{Field: "From[1000]", Value: fmt.Sprintf("%q", roomA)},
+			{Field: "From[1001]", Value: fmt.Sprintf("%q", roomB)},
+			{Field: "To[1000]", Value: fmt.Sprintf("%q", roomB)},
+			{Field: "To[1001]", Value: fmt.Sprintf("%q", roomA)},
+		}, roomEntry.Diff)
+	}
+}
+
+func TestRoomsSwapOccupants_SameRoom(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with two occupants")
+	tstSetupConcurrentAttendees()
+	roomA := tstSetupAssignmentRoom(t, "A", 2, []string{})
+	tstSetupOccupant(t, roomA, 1000)
+	tstSetupOccupant(t, roomA, 1001)
+
+	docs.When("When an admin tries to swap the two occupants")
+	response := tstPerformPostNoBody(fmt.Sprintf("/api/rest/v1/rooms/%s/occupants/1000/swap/1001", roomA), tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "room.occupant.conflict", "both attendees are in the same room")
+}
+
+func TestRoomsSwapOccupants_OtherNotInRoom(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with an occupant, and an attendee who is not in any room")
+	tstSetupConcurrentAttendees()
+	roomA := tstSetupAssignmentRoom(t, "A", 2, []string{})
+	tstSetupOccupant(t, roomA, 1000)
+
+	docs.When("When an admin tries to swap the two")
+	response := tstPerformPostNoBody(fmt.Sprintf("/api/rest/v1/rooms/%s/occupants/1000/swap/1001", roomA), tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error and nobody is moved")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "room.occupant.notfound", "attendee 1001 is not in any room")
+	tstRequireRoomOccupants(t, roomA, 1000)
+}
+
+func TestRoomsSwapOccupants_Themselves(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with an occupant")
+	tstSetupConcurrentAttendees()
+	roomA := tstSetupAssignmentRoom(t, "A", 2, []string{})
+	tstSetupOccupant(t, roomA, 1000)
+
+	docs.When("When an admin tries to swap the occupant with themselves")
+	response := tstPerformPostNoBody(fmt.Sprintf("/api/rest/v1/rooms/%s/occupants/1000/swap/1000", roomA), tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "room.data.invalid", "cannot swap an attendee with themselves")
+}
@@ -0,0 +1,124 @@
+package acceptance
+
+import (
+	"github.com/eurofurence/reg-room-service/docs"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestRoomsUpdateOccupant_AdminSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with an occupant")
+	location := setupExistingRoom(t, "31415", false, squirrel)
+
+	docs.When("When an admin sets the flags of the occupant")
+	body := tstRenderJson(modelsv1.MemberUpdate{Flags: []string{"early-arrival"}})
+	response := tstPerformPut(location+"/occupants/42", body, tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status)
+
+	docs.Then("And the occupant has the expected flags")
+	tstRoomState(t, location, tstWithFlags(squirrel, "early-arrival"))
+}
+
+func TestRoomsUpdateOccupant_SelfSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a finalized room with two occupants, one of whom has flags")
+	location := setupExistingRoom(t, "31415", true, squirrel, snep)
+	adminBody := tstRenderJson(modelsv1.MemberUpdate{Flags: []string{"needs-ground-floor"}})
+	require.Equal(t, http.StatusNoContent, tstPerformPut(location+"/occupants/43", adminBody, tstValidAdminToken(t)).status)
+
+	docs.When("When an occupant sets their own flags")
+	body := tstRenderJson(modelsv1.MemberUpdate{Flags: []string{"early-arrival"}})
+	response := tstPerformPut(location+"/occupants/42", body, tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status)
+
+	docs.Then("And when they request their room, they can only see their own flags")
+	myResponse := tstPerformGet("/api/rest/v1/rooms/my", tstValidUserToken(t, 101))
+	actual := modelsv1.Room{}
+	tstRequireSuccessResponse(t, myResponse, http.StatusOK, &actual)
+	require.Equal(t, []modelsv1.Member{tstWithFlags(squirrel, "early-arrival"), snep}, actual.Occupants)
+
+	docs.Then("And admins can see the flags of all occupants")
+	adminResponse := tstPerformGet(location, tstValidAdminToken(t))
+	actual = modelsv1.Room{}
+	tstRequireSuccessResponse(t, adminResponse, http.StatusOK, &actual)
+	require.Equal(t, []modelsv1.Member{tstWithFlags(squirrel, "early-arrival"), tstWithFlags(snep, "needs-ground-floor")}, actual.Occupants)
+}
+
+func TestRoomsUpdateOccupant_SelfNotFinal(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room that is not finalized with an occupant")
+	location := setupExistingRoom(t, "31415", false, squirrel)
+
+	docs.When("When the occupant attempts to set their own flags")
+	body := tstRenderJson(modelsv1.MemberUpdate{Flags: []string{"early-arrival"}})
+	response := tstPerformPut(location+"/occupants/42", body, tstValidUserToken(t, 101))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "room.occupant.notfound", "not in a room, or final flag not set on room")
+
+	docs.Then("And the room is unchanged")
+	tstRoomState(t, location, squirrel)
+}
+
+func TestRoomsUpdateOccupant_OtherOccupantDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a finalized room with two occupants")
+	location := setupExistingRoom(t, "31415", true, squirrel, snep)
+
+	docs.When("When one occupant attempts to set the flags of the other")
+	body := tstRenderJson(modelsv1.MemberUpdate{Flags: []string{"early-arrival"}})
+	response := tstPerformPut(location+"/occupants/43", body, tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is denied with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+}
+
+func TestRoomsUpdateOccupant_InvalidFlag(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room with an occupant")
+	location := setupExistingRoom(t, "31415", false, squirrel)
+
+	docs.When("When an admin attempts to set a flag that is not configured")
+	body := tstRenderJson(modelsv1.MemberUpdate{Flags: []string{"teleport"}})
+	response := tstPerformPut(location+"/occupants/42", body, tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "room.data.invalid", url.Values{"flags": []string{"no such flag 'teleport'"}})
+
+	docs.Then("And the room is unchanged")
+	tstRoomState(t, location, squirrel)
+}
+
+func TestRoomsUpdateOccupant_NotOccupant(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a room and an attendee with an active registration who is not in it")
+	location := setupExistingRoom(t, "31415", false)
+	registerSubject("101")
+
+	docs.When("When an admin attempts to set the flags of the attendee in the room")
+	body := tstRenderJson(modelsv1.MemberUpdate{Flags: []string{"early-arrival"}})
+	response := tstPerformPut(location+"/occupants/42", body, tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "room.occupant.notfound", "this attendee is not in this room")
+}
@@ -0,0 +1,299 @@
+package acceptance
+
+import (
+	"github.com/eurofurence/reg-room-service/docs"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/attendeeservice"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func tstWithStay(member modelsv1.Member, arrival string, departure string) modelsv1.Member {
+	if arrival != "" {
+		member.Arrival = &arrival
+	}
+	if departure != "" {
+		member.Departure = &departure
+	}
+	return member
+}
+
+func tstOccupancy(occupied ...int) []modelsv1.NightOccupancy {
+	nights := []string{"2030-09-18", "2030-09-19", "2030-09-20", "2030-09-21"}
+	result := make([]modelsv1.NightOccupancy, 0)
+	for i, count := range occupied {
+		result = append(result, modelsv1.NightOccupancy{Night: nights[i], Occupied: count})
+	}
+	return result
+}
+
+// --- add with stay dates ---
+
+func TestRoomsStay_AddSharedBed(t *testing.T) {
+	tstSetup(tstDefaultConfigFileStayDates)
+	defer tstShutdown()
+
+	docs.Given("Given a room with a single bed, and the convention dates are configured")
+	location := "/api/rest/v1/rooms/" + tstSetupAssignmentRoom(t, "single", 1, []string{})
+	registerSubject("101")
+	registerSubject("202")
+
+	docs.When("When an admin adds two attendees whose stays do not overlap")
+	response1 := tstPerformPostNoBody(location+"/occupants/42?departure=2030-09-20", tstValidAdminToken(t))
+	response2 := tstPerformPostNoBody(location+"/occupants/43?arrival=2030-09-20", tstValidAdminToken(t))
+
+	docs.Then("Then both requests are successful")
+	require.Equal(t, http.StatusNoContent, response1.status)
+	require.Equal(t, http.StatusNoContent, response2.status)
+
+	docs.Then("And the room lists the stay dates and the occupancy per night")
+	room := tstReadRoom(t, location)
+	require.Equal(t, []modelsv1.Member{tstWithStay(squirrel, "", "2030-09-20"), tstWithStay(snep, "2030-09-20", "")}, room.Occupants)
+	require.Equal(t, tstOccupancy(1, 1, 1, 1), room.Occupancy)
+}
+
+func TestRoomsStay_AddOverlapFull(t *testing.T) {
+	tstSetup(tstDefaultConfigFileStayDates)
+	defer tstShutdown()
+
+	docs.Given("Given a room with a single bed that is taken for the first two nights")
+	location := "/api/rest/v1/rooms/" + tstSetupAssignmentRoom(t, "single", 1, []string{})
+	registerSubject("101")
+	registerSubject("202")
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody(location+"/occupants/42?departure=2030-09-20", tstValidAdminToken(t)).status)
+
+	docs.When("When an admin attempts to add an attendee who arrives on the second night")
+	response := tstPerformPostNoBody(location+"/occupants/43?arrival=2030-09-19", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "room.size.full", "this room is full")
+
+	docs.Then("And the room is unchanged")
+	tstRequireRoomOccupants(t, tstRoomLocationToRoomID(location), 42)
+}
+
+func TestRoomsStay_AddInvalidDates(t *testing.T) {
+	tstSetup(tstDefaultConfigFileStayDates)
+	defer tstShutdown()
+
+	docs.Given("Given an empty room, and the convention dates are configured")
+	location := "/api/rest/v1/rooms/" + tstSetupAssignmentRoom(t, "double", 2, []string{})
+	registerSubject("101")
+
+	docs.When("When an admin attempts to add an attendee who leaves before they arrive")
+	response := tstPerformPostNoBody(location+"/occupants/42?arrival=2030-09-20&departure=2030-09-19", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "room.data.invalid", url.Values{"stay": []string{"departure must be after arrival"}})
+
+	docs.When("When an admin attempts to add an attendee who stays beyond the convention")
+	response = tstPerformPostNoBody(location+"/occupants/42?departure=2030-09-23", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "room.data.invalid", url.Values{"stay": []string{"the stay must lie within the convention dates"}})
+
+	docs.When("When an admin attempts to add an attendee with a malformed arrival date")
+	response = tstPerformPostNoBody(location+"/occupants/42?arrival=tomorrow", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "room.data.invalid", url.Values{"arrival": []string{"arrival must be an ISO date such as 2024-09-18"}})
+
+	docs.Then("And the room is still empty")
+	tstRequireRoomOccupants(t, tstRoomLocationToRoomID(location))
+}
+
+func TestRoomsStay_AddNotConfigured(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an empty room, and the convention end is not configured")
+	location := "/api/rest/v1/rooms/" + tstSetupAssignmentRoom(t, "double", 2, []string{})
+	registerSubject("101")
+
+	docs.When("When an admin attempts to add an attendee with stay dates")
+	response := tstPerformPostNoBody(location+"/occupants/42?arrival=2030-09-19", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "room.data.invalid", url.Values{"stay": []string{"stay dates are not supported because the convention dates are not configured"}})
+
+	docs.Then("And the room lists no occupancy per night")
+	room := tstReadRoom(t, location)
+	require.Nil(t, room.Occupancy)
+}
+
+// --- update stay ---
+
+func TestRoomsStay_UpdateSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileStayDates)
+	defer tstShutdown()
+
+	docs.Given("Given a room with two beds and two occupants who stay for the whole convention")
+	location := "/api/rest/v1/rooms/" + tstSetupAssignmentRoom(t, "double", 2, []string{})
+	registerSubject("101")
+	registerSubject("202")
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody(location+"/occupants/42", tstValidAdminToken(t)).status)
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody(location+"/occupants/43", tstValidAdminToken(t)).status)
+
+	docs.When("When an admin changes the stay of one occupant")
+	body := tstRenderJson(modelsv1.Stay{Arrival: "2030-09-19", Departure: "2030-09-21"})
+	response := tstPerformPut(location+"/occupants/43/stay", body, tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful")
+	require.Equal(t, http.StatusNoContent, response.status)
+
+	docs.Then("And the room lists the new stay and occupancy")
+	room := tstReadRoom(t, location)
+	require.Equal(t, []modelsv1.Member{squirrel, tstWithStay(snep, "2030-09-19", "2030-09-21")}, room.Occupants)
+	require.Equal(t, tstOccupancy(1, 2, 2, 1), room.Occupancy)
+}
+
+func TestRoomsStay_UpdateFull(t *testing.T) {
+	tstSetup(tstDefaultConfigFileStayDates)
+	defer tstShutdown()
+
+	docs.Given("Given a room with a single bed shared by two occupants")
+	location := "/api/rest/v1/rooms/" + tstSetupAssignmentRoom(t, "single", 1, []string{})
+	registerSubject("101")
+	registerSubject("202")
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody(location+"/occupants/42?departure=2030-09-20", tstValidAdminToken(t)).status)
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody(location+"/occupants/43?arrival=2030-09-20", tstValidAdminToken(t)).status)
+
+	docs.When("When an admin attempts to extend the stay of the first occupant")
+	body := tstRenderJson(modelsv1.Stay{Departure: "2030-09-21"})
+	response := tstPerformPut(location+"/occupants/42/stay", body, tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "room.size.full", "this room is full")
+
+	docs.Then("And the occupancy is unchanged")
+	require.Equal(t, tstOccupancy(1, 1, 1, 1), tstReadRoom(t, location).Occupancy)
+}
+
+func TestRoomsStay_UpdateDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileStayDates)
+	defer tstShutdown()
+
+	docs.Given("Given a finalized room with an occupant")
+	location := setupExistingRoom(t, "31415", true, squirrel)
+
+	docs.When("When the occupant attempts to change their own stay")
+	body := tstRenderJson(modelsv1.Stay{Arrival: "2030-09-19"})
+	response := tstPerformPut(location+"/occupants/42/stay", body, tstValidUserToken(t, 101))
+
+	docs.Then("Then the request is denied with the appropriate error")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+
+	docs.Then("And the occupant still stays for the whole convention")
+	require.Equal(t, []modelsv1.Member{squirrel}, tstReadRoom(t, location).Occupants)
+}
+
+func TestRoomsStay_UpdateNotInRoom(t *testing.T) {
+	tstSetup(tstDefaultConfigFileStayDates)
+	defer tstShutdown()
+
+	docs.Given("Given a room with an occupant")
+	location := setupExistingRoom(t, "31415", false, squirrel)
+
+	docs.When("When an admin attempts to change the stay of an attendee who is not in the room")
+	body := tstRenderJson(modelsv1.Stay{Arrival: "2030-09-19"})
+	response := tstPerformPut(location+"/occupants/43/stay", body, tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "room.occupant.notfound", "this attendee is not in this room")
+}
+
+// --- capacity per night ---
+
+func TestRoomsStay_FindByPeakOccupancy(t *testing.T) {
+	tstSetup(tstDefaultConfigFileStayDates)
+	defer tstShutdown()
+
+	docs.Given("Given a room with two beds whose occupants never stay on the same night")
+	roomID := tstSetupAssignmentRoom(t, "double", 2, []string{})
+	location := "/api/rest/v1/rooms/" + roomID
+	registerSubject("101")
+	registerSubject("202")
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody(location+"/occupants/42?departure=2030-09-20", tstValidAdminToken(t)).status)
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody(location+"/occupants/43?arrival=2030-09-20", tstValidAdminToken(t)).status)
+
+	docs.When("When an admin searches for rooms with at most one occupant")
+	response := tstPerformGet("/api/rest/v1/rooms?max_occupants=1", tstValidAdminToken(t))
+
+	docs.Then("Then the room is found, because at most one occupant stays on any night")
+	actual := modelsv1.RoomList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Len(t, actual.Rooms, 1)
+	require.Equal(t, roomID, actual.Rooms[0].ID)
+
+	docs.When("When an admin searches for rooms with at least two occupants")
+	response = tstPerformGet("/api/rest/v1/rooms?min_occupants=2", tstValidAdminToken(t))
+
+	docs.Then("Then the room is not found")
+	actual = modelsv1.RoomList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Empty(t, actual.Rooms)
+
+	docs.When("When an admin shrinks the room to a single bed")
+	roomSent := modelsv1.Room{Name: "double", Flags: []string{}, Size: 1}
+	updateResponse := tstPerformPut(location, tstRenderJson(roomSent), tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful, because the occupants can share the bed")
+	require.Equal(t, http.StatusNoContent, updateResponse.status)
+}
+
+func TestRoomsStay_ArrivalReport(t *testing.T) {
+	tstSetup(tstDefaultConfigFileStayDates)
+	defer tstShutdown()
+
+	docs.Given("Given a room with an occupant who arrives late and one who arrives at the start of the convention")
+	location := "/api/rest/v1/rooms/" + tstSetupAssignmentRoom(t, "double", 2, []string{})
+	registerSubject("101")
+	registerSubject("202")
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody(location+"/occupants/42?arrival=2030-09-20", tstValidAdminToken(t)).status)
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody(location+"/occupants/43", tstValidAdminToken(t)).status)
+
+	docs.When("When an admin requests the arrival report")
+	response := tstPerformGet("/api/rest/v1/rooms/arrivals?format=yaml", tstValidAdminToken(t))
+
+	docs.Then("Then each occupant is expected on their arrival date")
+	require.Equal(t, http.StatusOK, response.status, "unexpected http response status")
+	actual := modelsv1.ArrivalReport{}
+	require.Nil(t, yaml.Unmarshal([]byte(response.body), &actual))
+	expected := modelsv1.ArrivalReport{
+		Days: []modelsv1.ArrivalDay{
+			{Date: "2030-09-18", Expected: 1, NotArrived: []int64{43}},
+			{Date: "2030-09-20", Expected: 1, NotArrived: []int64{42}},
+		},
+	}
+	require.Equal(t, expected, actual)
+}
+
+func TestRoomsStay_SortByPeakOccupancy(t *testing.T) {
+	tstSetup(tstDefaultConfigFileStayDates)
+	defer tstShutdown()
+
+	docs.Given("Given a room whose two occupants never stay on the same night")
+	roomID := tstSetupAssignmentRoom(t, "zigzag", 2, []string{})
+	registerSubject("101")
+	registerSubject("202")
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody("/api/rest/v1/rooms/"+roomID+"/occupants/42?departure=2030-09-20", tstValidAdminToken(t)).status)
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody("/api/rest/v1/rooms/"+roomID+"/occupants/43?arrival=2030-09-20", tstValidAdminToken(t)).status)
+
+	docs.Given("Given a room with a single occupant for the whole convention, and an empty room")
+	attMock.SetupRegistered("303", 44, attendeeservice.StatusPaid, "Fox", "fox@example.com")
+	singleID := tstSetupAssignmentRoom(t, "single", 2, []string{})
+	require.Equal(t, http.StatusNoContent, tstPerformPostNoBody("/api/rest/v1/rooms/"+singleID+"/occupants/44", tstValidAdminToken(t)).status)
+	_ = tstSetupAssignmentRoom(t, "empty", 2, []string{})
+
+	docs.When("When an admin requests the first page of rooms sorted by occupancy, fullest rooms first")
+	response := tstPerformGet("/api/rest/v1/rooms?sort=occupancy&order=desc&limit=2", tstValidAdminToken(t))
+
+	docs.Then("Then the rooms are sorted by their peak nightly occupancy, and the tie is sorted by name")
+	actual := modelsv1.RoomList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Equal(t, []string{"single", "zigzag"}, tstRoomNames(actual))
+	require.Equal(t, int64(3), actual.Total)
+}
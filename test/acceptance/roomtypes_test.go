@@ -0,0 +1,483 @@
+package acceptance
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eurofurence/reg-room-service/docs"
+	modelsv1 "github.com/eurofurence/reg-room-service/internal/api/v1"
+)
+
+const validRoomTypeLocationRegex = "^\\/api\\/rest\\/v1\\/roomtypes\\/[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$"
+
+// tstSetupRoomType creates a room type and returns its id.
+func tstSetupRoomType(t *testing.T, name string, size int64, flags []string, priceCategory string) string {
+	roomTypeSent := modelsv1.RoomTypeCreate{
+		Name:          name,
+		Flags:         flags,
+		Comments:      p("A nice comment for " + name),
+		Size:          size,
+		PriceCategory: p(priceCategory),
+	}
+	response := tstPerformPost("/api/rest/v1/roomtypes", tstRenderJson(roomTypeSent), tstValidAdminToken(t))
+	require.Equal(t, http.StatusCreated, response.status, "unexpected http response status")
+	require.Regexp(t, validRoomTypeLocationRegex, response.location, "invalid location header in response")
+	return tstRoomTypeLocationToRoomTypeID(response.location)
+}
+
+func tstRoomTypeLocationToRoomTypeID(location string) string {
+	return location[len("/api/rest/v1/roomtypes/"):]
+}
+
+// tstSetupRoomOfType creates a room that takes all values from its room type.
+func tstSetupRoomOfType(t *testing.T, name string, typeID string) string {
+	roomSent := modelsv1.RoomCreate{
+		Name: name,
+		Type: &typeID,
+	}
+	response := tstPerformPost("/api/rest/v1/rooms", tstRenderJson(roomSent), tstValidAdminToken(t))
+	require.Equal(t, http.StatusCreated, response.status, "unexpected http response status")
+	return response.location
+}
+
+// --- create ---
+
+func TestRoomTypesCreate_AdminSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an admin")
+	token := tstValidAdminToken(t)
+
+	docs.When("When they create a room type with valid data")
+	roomTypeSent := modelsv1.RoomTypeCreate{
+		Name:          "triple accessible",
+		Flags:         []string{"handicapped"},
+		Comments:      p("wide doors"),
+		Size:          3,
+		PriceCategory: p("B"),
+	}
+	response := tstPerformPost("/api/rest/v1/roomtypes", tstRenderJson(roomTypeSent), token)
+
+	docs.Then("Then the room type is successfully created")
+	require.Equal(t, http.StatusCreated, response.status, "unexpected http response status")
+	require.Regexp(t, validRoomTypeLocationRegex, response.location, "invalid location header in response")
+
+	readAgain := tstPerformGet(response.location, token)
+	actual := modelsv1.RoomType{}
+	tstRequireSuccessResponse(t, readAgain, http.StatusOK, &actual)
+	expected := modelsv1.RoomType{
+		ID:            tstRoomTypeLocationToRoomTypeID(response.location),
+		Name:          roomTypeSent.Name,
+		Flags:         roomTypeSent.Flags,
+		Comments:      roomTypeSent.Comments,
+		Size:          roomTypeSent.Size,
+		PriceCategory: roomTypeSent.PriceCategory,
+	}
+	tstEqualResponseBodies(t, expected, actual)
+}
+
+func TestRoomTypesCreate_UserDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a user, who is not an admin")
+	token := tstValidUserToken(t, 101)
+
+	docs.When("When they try to create a room type")
+	roomTypeSent := modelsv1.RoomTypeCreate{
+		Name: "double standard",
+		Size: 2,
+	}
+	response := tstPerformPost("/api/rest/v1/roomtypes", tstRenderJson(roomTypeSent), token)
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+}
+
+func TestRoomTypesCreate_InvalidData(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an admin")
+	token := tstValidAdminToken(t)
+
+	docs.When("When they try to create a room type with invalid data")
+	roomTypeSent := modelsv1.RoomTypeCreate{
+		Name:  "",
+		Flags: []string{"floof"},
+		Size:  0,
+	}
+	response := tstPerformPost("/api/rest/v1/roomtypes", tstRenderJson(roomTypeSent), token)
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "roomtype.data.invalid", url.Values{
+		"name":  []string{"room type name cannot be empty"},
+		"flags": []string{"no such flag 'floof'"},
+		"size":  []string{"size must be at least 1"},
+	})
+}
+
+func TestRoomTypesCreate_DuplicateName(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an existing room type")
+	tstSetupRoomType(t, "double standard", 2, []string{}, "A")
+
+	docs.When("When an admin tries to create another room type with the same name")
+	roomTypeSent := modelsv1.RoomTypeCreate{
+		Name: "double standard",
+		Size: 2,
+	}
+	response := tstPerformPost("/api/rest/v1/roomtypes", tstRenderJson(roomTypeSent), tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "roomtype.data.duplicate", "another room type with this name already exists")
+}
+
+// --- read ---
+
+func TestRoomTypesList_AdminSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two existing room types")
+	tripleID := tstSetupRoomType(t, "triple accessible", 3, []string{"handicapped"}, "B")
+	doubleID := tstSetupRoomType(t, "double standard", 2, []string{}, "A")
+
+	docs.When("When an admin lists the room types")
+	response := tstPerformGet("/api/rest/v1/roomtypes", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the room types are listed by name")
+	actual := modelsv1.RoomTypeList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	expected := modelsv1.RoomTypeList{
+		RoomTypes: []*modelsv1.RoomType{
+			{
+				ID:            doubleID,
+				Name:          "double standard",
+				Flags:         []string{},
+				Comments:      p("A nice comment for double standard"),
+				Size:          2,
+				PriceCategory: p("A"),
+			},
+			{
+				ID:            tripleID,
+				Name:          "triple accessible",
+				Flags:         []string{"handicapped"},
+				Comments:      p("A nice comment for triple accessible"),
+				Size:          3,
+				PriceCategory: p("B"),
+			},
+		},
+	}
+	tstEqualResponseBodies(t, expected, actual)
+}
+
+func TestRoomTypesGet_NotFound(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an admin")
+	token := tstValidAdminToken(t)
+
+	docs.When("When they request a room type that does not exist")
+	response := tstPerformGet("/api/rest/v1/roomtypes/7a8c0d36-36fa-4b6b-9f3a-1e8c5e1f2d4b", token)
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusNotFound, "roomtype.id.notfound", "room type does not exist")
+}
+
+func TestRoomTypesGet_InvalidID(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an admin")
+	token := tstValidAdminToken(t)
+
+	docs.When("When they request a room type with an invalid id")
+	response := tstPerformGet("/api/rest/v1/roomtypes/floof", token)
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "roomtype.id.invalid", "you must specify a valid uuid")
+}
+
+// --- update ---
+
+func TestRoomTypesUpdate_AdminSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an existing room type that is used by a room")
+	typeID := tstSetupRoomType(t, "double standard", 2, []string{}, "A")
+	location := tstSetupRoomOfType(t, "101", typeID)
+
+	docs.When("When an admin changes the room type")
+	roomTypeSent := modelsv1.RoomType{
+		Name:          "double deluxe",
+		Flags:         []string{"final"},
+		Size:          2,
+		PriceCategory: p("C"),
+	}
+	response := tstPerformPut("/api/rest/v1/roomtypes/"+typeID, tstRenderJson(roomTypeSent), tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the room type has been changed")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	readAgain := tstPerformGet("/api/rest/v1/roomtypes/"+typeID, tstValidAdminToken(t))
+	actual := modelsv1.RoomType{}
+	tstRequireSuccessResponse(t, readAgain, http.StatusOK, &actual)
+	roomTypeSent.ID = typeID
+	tstEqualResponseBodies(t, roomTypeSent, actual)
+
+	docs.Then("And the existing room keeps its values, but shows the new name of its room type")
+	room := tstReadRoom(t, location)
+	require.Equal(t, []string{}, room.Flags)
+	require.Equal(t, p("double deluxe"), room.TypeName)
+}
+
+// --- delete ---
+
+func TestRoomTypesDelete_AdminSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an existing room type without rooms")
+	typeID := tstSetupRoomType(t, "double standard", 2, []string{}, "A")
+
+	docs.When("When an admin deletes the room type")
+	response := tstPerformDelete("/api/rest/v1/roomtypes/"+typeID, tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the room type is gone")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	readAgain := tstPerformGet("/api/rest/v1/roomtypes/"+typeID, tstValidAdminToken(t))
+	tstRequireErrorResponse(t, readAgain, http.StatusNotFound, "roomtype.id.notfound", "room type does not exist")
+}
+
+func TestRoomTypesDelete_InUse(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an existing room type that is used by a room")
+	typeID := tstSetupRoomType(t, "double standard", 2, []string{}, "A")
+	tstSetupRoomOfType(t, "101", typeID)
+
+	docs.When("When an admin tries to delete the room type")
+	response := tstPerformDelete("/api/rest/v1/roomtypes/"+typeID, tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusConflict, "roomtype.in.use", "room type is still used by rooms - please delete them or change their room type first")
+}
+
+func TestRoomTypesDelete_UserDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an existing room type and a user, who is not an admin")
+	typeID := tstSetupRoomType(t, "double standard", 2, []string{}, "A")
+	token := tstValidUserToken(t, 101)
+
+	docs.When("When they try to delete the room type")
+	response := tstPerformDelete("/api/rest/v1/roomtypes/"+typeID, token)
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+}
+
+// --- rooms of a type ---
+
+func TestRoomTypesRooms_CreateWithDefaults(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an existing room type")
+	typeID := tstSetupRoomType(t, "triple accessible", 3, []string{"handicapped"}, "B")
+
+	docs.When("When an admin creates a room of that type without size, flags or comments")
+	location := tstSetupRoomOfType(t, "101", typeID)
+
+	docs.Then("Then the room has the values of its room type")
+	room := tstReadRoom(t, location)
+	require.Equal(t, modelsv1.Room{
+		ID:       tstRoomLocationToRoomID(location),
+		Name:     "101",
+		Flags:    []string{"handicapped"},
+		Comments: p("A nice comment for triple accessible"),
+		Size:     3,
+		Type:     &typeID,
+		TypeName: p("triple accessible"),
+	}, room)
+}
+
+func TestRoomTypesRooms_CreateWithOverrides(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an existing room type")
+	typeID := tstSetupRoomType(t, "triple accessible", 3, []string{"handicapped"}, "B")
+
+	docs.When("When an admin creates a room of that type with its own size, flags and comments")
+	roomSent := modelsv1.RoomCreate{
+		Name:     "101",
+		Flags:    []string{},
+		Comments: p("the one with the balcony"),
+		Size:     4,
+		Type:     &typeID,
+	}
+	response := tstPerformPost("/api/rest/v1/rooms", tstRenderJson(roomSent), tstValidAdminToken(t))
+
+	docs.Then("Then the room has its own values instead of those of the room type")
+	require.Equal(t, http.StatusCreated, response.status, "unexpected http response status")
+	room := tstReadRoom(t, response.location)
+	require.Equal(t, []string{}, room.Flags)
+	require.Equal(t, roomSent.Comments, room.Comments)
+	require.Equal(t, int64(4), room.Size)
+	require.Equal(t, &typeID, room.Type)
+}
+
+func TestRoomTypesRooms_UpdateWithDefaults(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an existing room without a room type and an existing room type")
+	location := setupExistingRoom(t, "101", false)
+	typeID := tstSetupRoomType(t, "triple accessible", 3, []string{"handicapped"}, "B")
+
+	docs.When("When an admin changes the room to that type, keeping only its comments")
+	roomSent := modelsv1.Room{
+		Name:     "101",
+		Comments: p("keep me"),
+		Type:     &typeID,
+	}
+	response := tstPerformPut(location, tstRenderJson(roomSent), tstValidAdminToken(t))
+
+	docs.Then("Then the room takes its size and flags from the room type")
+	require.Equal(t, http.StatusNoContent, response.status, "unexpected http response status")
+	room := tstReadRoom(t, location)
+	require.Equal(t, []string{"handicapped"}, room.Flags)
+	require.Equal(t, p("keep me"), room.Comments)
+	require.Equal(t, int64(3), room.Size)
+	require.Equal(t, &typeID, room.Type)
+}
+
+func TestRoomTypesRooms_CreateWithUnknownType(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an admin")
+	token := tstValidAdminToken(t)
+
+	docs.When("When they try to create a room of a room type that does not exist")
+	roomSent := modelsv1.RoomCreate{
+		Name: "101",
+		Type: p("7a8c0d36-36fa-4b6b-9f3a-1e8c5e1f2d4b"),
+	}
+	response := tstPerformPost("/api/rest/v1/rooms", tstRenderJson(roomSent), token)
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "room.data.invalid", url.Values{"type": []string{"no such room type"}})
+}
+
+func TestRoomTypesRooms_FilterByType(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given rooms of two different room types")
+	doubleID := tstSetupRoomType(t, "double standard", 2, []string{}, "A")
+	tripleID := tstSetupRoomType(t, "triple accessible", 3, []string{"handicapped"}, "B")
+	tstSetupRoomOfType(t, "101", doubleID)
+	tripleLocation := tstSetupRoomOfType(t, "102", tripleID)
+
+	docs.When("When an admin lists the rooms of one room type")
+	response := tstPerformGet("/api/rest/v1/rooms?type="+tripleID, tstValidAdminToken(t))
+
+	docs.Then("Then only the rooms of that room type are listed")
+	actual := modelsv1.RoomList{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	require.Len(t, actual.Rooms, 1)
+	require.Equal(t, tstRoomLocationToRoomID(tripleLocation), actual.Rooms[0].ID)
+}
+
+func TestRoomTypesRooms_FilterByInvalidType(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.When("When an admin lists the rooms of a room type with an invalid id")
+	response := tstPerformGet("/api/rest/v1/rooms?type=floof", tstValidAdminToken(t))
+
+	docs.Then("Then the request fails with the expected error")
+	tstRequireErrorResponse(t, response, http.StatusBadRequest, "roomtype.id.invalid", "you must specify a valid uuid")
+}
+
+func TestRoomTypesRooms_ImportCSVWithDefaults(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given an existing room type")
+	typeID := tstSetupRoomType(t, "triple accessible", 3, []string{"handicapped"}, "B")
+
+	docs.When("When an admin imports rooms in CSV format that only specify name and type")
+	body := fmt.Sprintf("name,type\n101,%s\n102,%s\n", typeID, typeID)
+	response := tstPerformPost("/api/rest/v1/rooms/import?format=csv", body, tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the rooms have the values of their room type")
+	result := modelsv1.RoomImportResult{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &result)
+	require.Len(t, result.Created, 2)
+	for _, roomID := range result.Created {
+		room := tstReadRoom(t, "/api/rest/v1/rooms/"+roomID)
+		require.Equal(t, []string{"handicapped"}, room.Flags)
+		require.Equal(t, int64(3), room.Size)
+		require.Equal(t, &typeID, room.Type)
+	}
+}
+
+// --- capacity report ---
+
+func TestRoomTypesCapacity_AdminSuccess(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given two room types, of which only one has rooms, a room without a type, and an attendee in one of the rooms")
+	doubleID := tstSetupRoomType(t, "double standard", 2, []string{}, "A")
+	tripleID := tstSetupRoomType(t, "triple accessible", 3, []string{"handicapped"}, "B")
+	tstSetupRoomOfType(t, "101", doubleID)
+	location := tstSetupRoomOfType(t, "102", doubleID)
+	setupExistingRoom(t, "201", false)
+	registerSubject("101")
+	addResponse := tstPerformPostNoBody(location+"/occupants/42", tstValidAdminToken(t))
+	require.Equal(t, http.StatusNoContent, addResponse.status, "unexpected http response status")
+
+	docs.When("When an admin requests the capacity report")
+	response := tstPerformGet("/api/rest/v1/rooms/capacity", tstValidAdminToken(t))
+
+	docs.Then("Then the request is successful and the capacity is listed per room type")
+	actual := modelsv1.CapacityReport{}
+	tstRequireSuccessResponse(t, response, http.StatusOK, &actual)
+	expected := modelsv1.CapacityReport{
+		Types: []modelsv1.TypeCapacity{
+			{Type: doubleID, TypeName: "double standard", PriceCategory: "A", Rooms: 2, Beds: 4, Occupied: 1, Free: 3},
+			{Type: tripleID, TypeName: "triple accessible", PriceCategory: "B", Rooms: 0, Beds: 0, Occupied: 0, Free: 0},
+			{Type: "", TypeName: "", Rooms: 1, Beds: 2, Occupied: 0, Free: 2},
+		},
+	}
+	tstEqualResponseBodies(t, expected, actual)
+}
+
+func TestRoomTypesCapacity_UserDeny(t *testing.T) {
+	tstSetup(tstDefaultConfigFileRoomGroups)
+	defer tstShutdown()
+
+	docs.Given("Given a user, who is not an admin")
+	token := tstValidUserToken(t, 101)
+
+	docs.When("When they try to request the capacity report")
+	response := tstPerformGet("/api/rest/v1/rooms/capacity", token)
+
+	docs.Then("Then the request is denied")
+	tstRequireErrorResponse(t, response, http.StatusForbidden, "auth.forbidden", "you are not authorized for this operation - the attempt has been logged")
+}
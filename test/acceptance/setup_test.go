@@ -5,8 +5,13 @@ import (
 	"github.com/eurofurence/reg-room-service/internal/application/server"
 	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/attendeeservice"
 	"github.com/eurofurence/reg-room-service/internal/repository/downstreams/mailservice"
+	assignmentservice "github.com/eurofurence/reg-room-service/internal/service/assignment"
+	blockservice "github.com/eurofurence/reg-room-service/internal/service/blocks"
 	groupservice "github.com/eurofurence/reg-room-service/internal/service/groups"
+	historyservice "github.com/eurofurence/reg-room-service/internal/service/history"
+	reconcileservice "github.com/eurofurence/reg-room-service/internal/service/reconcile"
 	roomservice "github.com/eurofurence/reg-room-service/internal/service/rooms"
+	roomtypeservice "github.com/eurofurence/reg-room-service/internal/service/roomtypes"
 	"net/http/httptest"
 
 	"github.com/eurofurence/reg-room-service/internal/repository/config"
@@ -21,12 +26,16 @@ var db database.Repository
 var authMock authservice.Mock
 var attMock attendeeservice.Mock
 var mailMock mailservice.Mock
+var grpsvc groupservice.Service
+var reconcilesvc reconcileservice.Service
 
 const (
-	tstDefaultConfigFileBeforeLaunch      = "../resources/testconfig_beforeLaunch.yaml"
-	tstDefaultConfigFileAfterStaffLaunch  = "../resources/testconfig_afterStaffLaunch.yaml"
-	tstDefaultConfigFileAfterPublicLaunch = "../resources/testconfig_afterPublicLaunch.yaml"
-	tstDefaultConfigFileRoomGroups        = "../resources/testconfig_roomgroups.yaml"
+	tstDefaultConfigFile           = "../resources/testconfig_default.yaml"
+	tstDefaultConfigFileRoomGroups = "../resources/testconfig_roomgroups.yaml"
+	tstDefaultConfigFileAvatars    = "../resources/testconfig_avatars.yaml"
+	tstDefaultConfigFileFlagRules  = "../resources/testconfig_flagrules.yaml"
+	tstDefaultConfigFileStayDates  = "../resources/testconfig_staydates.yaml"
+	tstDefaultConfigFileGoLive     = "../resources/testconfig_golive.yaml"
 )
 
 func tstSetup(configfile string) {
@@ -37,15 +46,20 @@ func tstSetup(configfile string) {
 	attMock = attendeeservice.NewMock()
 	mailMock = mailservice.NewMock()
 
-	grpsvc := groupservice.New(db, attMock, mailMock)
+	grpsvc = groupservice.New(db, attMock, mailMock)
 	roomsvc := roomservice.New(db, attMock, mailMock)
+	historysvc := historyservice.New(db)
+	assignmentsvc := assignmentservice.New(db, attMock)
+	reconcilesvc = reconcileservice.New(db, attMock, grpsvc, roomsvc)
+	blocksvc := blockservice.New(db)
+	roomtypesvc := roomtypeservice.New(db)
 
 	tstSetupAuthMockResponses()
-	tstSetupHttpTestServer(grpsvc, roomsvc)
+	tstSetupHttpTestServer(grpsvc, roomsvc, historysvc, assignmentsvc, reconcilesvc, blocksvc, roomtypesvc)
 }
 
-func tstSetupHttpTestServer(grpsrv groupservice.Service, roomsvc roomservice.Service) {
-	router := server.Router(grpsrv, roomsvc)
+func tstSetupHttpTestServer(grpsrv groupservice.Service, roomsvc roomservice.Service, historysvc historyservice.Service, assignmentsvc assignmentservice.Service, reconcilesvc reconcileservice.Service, blocksvc blockservice.Service, roomtypesvc roomtypeservice.Service) {
+	router := server.Router(grpsrv, roomsvc, historysvc, assignmentsvc, reconcilesvc, blocksvc, roomtypesvc)
 	ts = httptest.NewServer(router)
 }
 
@@ -82,6 +82,5 @@ func TestConfigurationFull(t *testing.T) {
 	err = conf.Validate()
 	require.Nil(t, err)
 	require.Equal(t, ":12345", net.JoinHostPort(conf.Server.BaseAddress, fmt.Sprintf("%d", conf.Server.Port)))
-	require.Equal(t, "Linköping", conf.GoLive.Public.BookingCode)
-	// require.Equal(t, time.Date(2020, 11, 6, 21, 22, 23, 0, time.UTC).Unix(), config.PublicBookingStartTime().Unix())
+	require.Equal(t, "admin", conf.Security.Oidc.StaffGroup)
 }
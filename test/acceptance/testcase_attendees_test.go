@@ -242,3 +242,17 @@ func tstGroupMailToMember(cid string, groupName string, target string, owner str
 	}
 	return result
 }
+
+func tstRoomMailToOccupant(cid string, roomName string, target string) mailservice.MailSendDto {
+	_, targetNick, targetEmail := tstInfosBySubject(target)
+
+	return mailservice.MailSendDto{
+		CommonID: cid,
+		Lang:     "en-US",
+		To:       []string{targetEmail},
+		Variables: map[string]string{
+			"nickname": targetNick,
+			"roomname": roomName,
+		},
+	}
+}